@@ -38,6 +38,7 @@ fi
 go-bindata -pkg asset  -o asset/asset.go byteplus-sdk-metadata/metadata/... byteplus-sdk-metadata/explorer_descriptions/...
 go-bindata -pkg typeset  -o typeset/typeset.go byteplus-sdk-metadata/metatype/...
 go-bindata -pkg structset  -o structset/structset.go byteplus-sdk-metadata/structure/...
+go run ./scripts/generate_structure_index --structure-dir byteplus-sdk-metadata/structure --out structset/index.go
 
 
 #clean git cache after build
@@ -3,17 +3,27 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
 	"strings"
 
 	"github.com/byteplus-sdk/byteplus-cli/util"
 )
 
+// cliInputYAMLFlag 从 YAML 文件（"-" 表示标准输入）读取请求参数，命令行中的其他参数覆盖文件中的同名字段。
+const cliInputYAMLFlag = "cli-input-yaml"
+
+var actionInputStdin io.Reader = os.Stdin
+
 // buildActionInput 根据 API 的 Content-Type 构造 SDK 入参。
 // JSON API 支持两种互斥输入：--body 传完整 JSON，或通过扁平参数自动展开为 JSON body。
+// 两类 API 都可以用 --cli-input-yaml 提供基础参数，再由扁平参数覆盖。
 func buildActionInput(flags []*Flag, apiMeta *ApiMeta, jsonBody bool) (interface{}, bool, error) {
 	hasBody := false
 	hasFlat := false
-	var bodyVal string
+	hasYAML := false
+	var bodyVal, yamlSource string
 	flat := make(map[string]string)
 
 	for _, f := range flags {
@@ -22,6 +32,11 @@ func buildActionInput(flags []*Flag, apiMeta *ApiMeta, jsonBody bool) (interface
 			bodyVal = f.value
 			continue
 		}
+		if f.Name == cliInputYAMLFlag {
+			hasYAML = true
+			yamlSource = f.value
+			continue
+		}
 		hasFlat = true
 		flat[f.Name] = f.value
 	}
@@ -29,6 +44,17 @@ func buildActionInput(flags []*Flag, apiMeta *ApiMeta, jsonBody bool) (interface
 	if hasBody && hasFlat {
 		return nil, false, fmt.Errorf("--body cannot be used together with flattened parameters")
 	}
+	if hasBody && hasYAML {
+		return nil, false, fmt.Errorf("--body cannot be used together with --%s", cliInputYAMLFlag)
+	}
+
+	var base map[string]interface{}
+	if hasYAML {
+		var err error
+		if base, err = readCliInputYAML(yamlSource); err != nil {
+			return nil, false, err
+		}
+	}
 
 	if hasBody {
 		parsed, err := parseJSONBody(bodyVal)
@@ -43,11 +69,17 @@ func buildActionInput(flags []*Flag, apiMeta *ApiMeta, jsonBody bool) (interface
 		if err != nil {
 			return nil, false, err
 		}
+		if base != nil {
+			return mergeInputOverrides(base, nested), false, nil
+		}
 		return nested, false, nil
 	}
 
 	// 非 JSON API 保持历史 dotted-key 行为，服务端会继续按原规则处理参数。
 	input := make(map[string]interface{})
+	if base != nil {
+		input = base
+	}
 	for name, val := range flat {
 		if isStringParam(apiMeta, name) {
 			input[name] = val
@@ -74,3 +106,48 @@ func parseJSONBody(body string) (interface{}, error) {
 
 	return nil, fmt.Errorf("json format error")
 }
+
+// readCliInputYAML 读取 --cli-input-yaml 指定的文件或标准输入，文档顶层必须是映射。
+func readCliInputYAML(source string) (map[string]interface{}, error) {
+	source = strings.TrimSpace(source)
+	if source == "" {
+		return nil, fmt.Errorf("--%s requires a file path or - for standard input", cliInputYAMLFlag)
+	}
+	var data []byte
+	var err error
+	if source == "-" {
+		data, err = ioutil.ReadAll(actionInputStdin)
+	} else {
+		data, err = ioutil.ReadFile(source)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read --%s: %w", cliInputYAMLFlag, err)
+	}
+
+	doc, err := util.ParseYAML(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse --%s: %w", cliInputYAMLFlag, err)
+	}
+	if doc == nil {
+		return map[string]interface{}{}, nil
+	}
+	m, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("--%s must contain a YAML mapping of request parameters", cliInputYAMLFlag)
+	}
+	return m, nil
+}
+
+// mergeInputOverrides 把 overrides 合并到 base：两侧都是对象的字段递归合并，其余字段以 overrides 为准。
+func mergeInputOverrides(base, overrides map[string]interface{}) map[string]interface{} {
+	for k, v := range overrides {
+		baseObj, ok1 := base[k].(map[string]interface{})
+		overrideObj, ok2 := v.(map[string]interface{})
+		if ok1 && ok2 {
+			base[k] = mergeInputOverrides(baseObj, overrideObj)
+			continue
+		}
+		base[k] = v
+	}
+	return base
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"io/ioutil"
 	"reflect"
 	"strings"
 	"testing"
@@ -89,3 +90,82 @@ func TestBuildActionInputParsesJSONBodyObject(t *testing.T) {
 	}
 }
 
+func TestBuildActionInputMergesCliInputYAML(t *testing.T) {
+	dir := t.TempDir()
+	path := dir + "/input.yaml"
+	content := "# request payload\n" +
+		"InstanceName: web-1\n" +
+		"Placement:\n" +
+		"  ZoneId: zone-a\n" +
+		"  HostId: host-1\n" +
+		"Tags:\n" +
+		"  - Key: env\n" +
+		"    Value: prod\n"
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	apiMeta := &ApiMeta{
+		Request: &Meta{
+			MetaTypes: map[string]*MetaType{
+				"Placement": {TypeName: "object"},
+			},
+			ChildMetas: map[string]*Meta{
+				"Placement": {MetaTypes: map[string]*MetaType{"ZoneId": {TypeName: "string"}}},
+			},
+		},
+	}
+	flags := []*Flag{
+		{Name: cliInputYAMLFlag, value: path},
+		{Name: "Placement.ZoneId", value: "zone-b"},
+	}
+
+	got, fromBody, err := buildActionInput(flags, apiMeta, true)
+	if err != nil {
+		t.Fatalf("buildActionInput() error = %v", err)
+	}
+	if fromBody {
+		t.Fatal("buildActionInput() fromBody = true, want false for --cli-input-yaml")
+	}
+	want := map[string]interface{}{
+		"InstanceName": "web-1",
+		"Placement":    map[string]interface{}{"ZoneId": "zone-b", "HostId": "host-1"},
+		"Tags":         []interface{}{map[string]interface{}{"Key": "env", "Value": "prod"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("buildActionInput() = %#v, want %#v", got, want)
+	}
+}
+
+func TestBuildActionInputReadsCliInputYAMLFromStdin(t *testing.T) {
+	old := actionInputStdin
+	defer func() { actionInputStdin = old }()
+	actionInputStdin = strings.NewReader("InstanceId: i-1\nMaxResults: 20\n")
+
+	flags := []*Flag{
+		{Name: cliInputYAMLFlag, value: "-"},
+		{Name: "MaxResults", value: "50"},
+	}
+	got, _, err := buildActionInput(flags, nil, false)
+	if err != nil {
+		t.Fatalf("buildActionInput() error = %v", err)
+	}
+	want := map[string]interface{}{"InstanceId": "i-1", "MaxResults": "50"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("buildActionInput() = %#v, want %#v", got, want)
+	}
+}
+
+func TestBuildActionInputRejectsCliInputYAMLErrors(t *testing.T) {
+	old := actionInputStdin
+	defer func() { actionInputStdin = old }()
+
+	actionInputStdin = strings.NewReader("- a\n- b\n")
+	if _, _, err := buildActionInput([]*Flag{{Name: cliInputYAMLFlag, value: "-"}}, nil, false); err == nil || !strings.Contains(err.Error(), "YAML mapping") {
+		t.Fatalf("buildActionInput() error = %v, want mapping error", err)
+	}
+
+	flags := []*Flag{{Name: cliInputYAMLFlag, value: "-"}, {Name: "body", value: "{}"}}
+	if _, _, err := buildActionInput(flags, nil, true); err == nil || !strings.Contains(err.Error(), "--body cannot be used together with --cli-input-yaml") {
+		t.Fatalf("buildActionInput() error = %v, want --body conflict", err)
+	}
+}
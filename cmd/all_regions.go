@@ -0,0 +1,219 @@
+/*
+ * // Copyright (c) 2024 Bytedance Ltd. and/or its affiliates
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at
+ * //
+ * //	http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/byteplus-sdk/byteplus-cli/util"
+)
+
+// sourceRegionField 是 ---all-regions 合并结果中标注每条记录来源区域的字段名。
+const sourceRegionField = "SourceRegion"
+
+// allRegionsConcurrency 限制 ---all-regions 同时调用的区域数。
+const allRegionsConcurrency = 8
+
+// allRegionsReadPrefixes 限定 ---all-regions 只用于只读 action，避免把写操作扇出到所有区域。
+var allRegionsReadPrefixes = []string{"Describe", "List", "Get"}
+
+// allRegionsRequested 判断本次调用是否指定了 ---all-regions。
+func allRegionsRequested(ctx *Context) bool {
+	f := ctx.fixedFlags.GetByName("all-regions")
+	return f != nil && f.GetValue() == "true"
+}
+
+// explicitLocationFlag 返回命令行显式传入的 ---region 或 ---endpoint 名称，需在合并 profile extra 之前调用。
+func explicitLocationFlag(ctx *Context) string {
+	for _, name := range []string{"region", "endpoint"} {
+		if f := ctx.fixedFlags.GetByName(name); f != nil && f.GetValue() != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// validateAllRegions 校验 ---all-regions 的使用条件，返回要调用的区域列表。
+// explicitLocation 为命令行显式传入的 ---region/---endpoint，profile extra 中的取值不算冲突。
+func validateAllRegions(ctx *Context, action, explicitLocation string) ([]string, error) {
+	if explicitLocation != "" {
+		return nil, fmt.Errorf("---all-regions cannot be used with ---%s", explicitLocation)
+	}
+	if ignoreConfigRequested(ctx) {
+		return nil, fmt.Errorf("---all-regions reads its region list from the config file and cannot be used with ---no-config")
+	}
+	readOnly := false
+	for _, prefix := range allRegionsReadPrefixes {
+		if strings.HasPrefix(action, prefix) {
+			readOnly = true
+			break
+		}
+	}
+	if !readOnly {
+		return nil, fmt.Errorf("---all-regions only supports read actions (%s*), got %s", strings.Join(allRegionsReadPrefixes, "*, "), action)
+	}
+
+	var regions []string
+	seen := make(map[string]struct{})
+	if ctx.config != nil {
+		for _, r := range ctx.config.Regions {
+			r = strings.TrimSpace(r)
+			if _, ok := seen[r]; ok || r == "" {
+				continue
+			}
+			seen[r] = struct{}{}
+			regions = append(regions, r)
+		}
+	}
+	if len(regions) == 0 {
+		return nil, fmt.Errorf("---all-regions requires a region list, set \"regions\" in the config file, e.g. \"regions\": [\"ap-southeast-1\", \"ap-southeast-3\"]")
+	}
+	return regions, nil
+}
+
+// contextWithRegion 复制 ctx 并把 region 设为固定值，profile extra 带来的 region/endpoint 不再生效。
+func contextWithRegion(c *Context, region string) *Context {
+	clone := *c
+	clone.fixedFlags = NewFlagSet()
+	for _, f := range c.fixedFlags.GetFlags() {
+		if f.Name == "region" || f.Name == "endpoint" {
+			continue
+		}
+		clone.fixedFlags.AddFlag(&Flag{Name: f.Name, value: f.GetValue(), values: f.GetValues()})
+	}
+	clone.fixedFlags.AddFlag(&Flag{Name: "region", value: region})
+	return &clone
+}
+
+// doActionAllRegions 在每个区域调用同一个 action 并合并结果：列表中的每条记录标注 SourceRegion，
+// 合并后的响应按正常的输出格式输出。client 按区域顺序创建，避免凭证刷新并发写配置文件；
+// 调用阶段最多 allRegionsConcurrency 个区域并发执行。部分区域失败时仍输出成功区域的结果，并返回列出失败区域的错误。
+func doActionAllRegions(ctx *Context, serviceName string, info SdkClientInfo, regions []string, output *actionOutput) error {
+	debugLog := debugLoggerFromContext(ctx)
+	apiMeta := rootSupport.GetApiMeta(serviceName, info.Action)
+	jsonBody := strings.ToLower(info.ContentType) == "application/json"
+
+	clients := make([]*SdkClient, len(regions))
+	for i, region := range regions {
+		sdk, err := NewSimpleClient(contextWithRegion(ctx, region))
+		if err != nil {
+			debugLogError(debugLog, "client_init_error", err)
+			return fmt.Errorf("region %s: %w", region, err)
+		}
+		clients[i] = sdk
+	}
+
+	results := util.RunBounded(len(regions), util.BoundedOptions{Concurrency: allRegionsConcurrency}, func(i int) (interface{}, error) {
+		return collectActionPages(ctx, clients[i], info, apiMeta, jsonBody, output.paginate)
+	})
+	if err := commandContext.Err(); err != nil {
+		return err
+	}
+
+	combined := map[string]interface{}{}
+	errorPaths := softErrorPaths(ctx)
+	validateResponse := validateResponseRequested(ctx)
+	var failures, softErrors, schemaMismatches []string
+	succeeded := 0
+	for i, region := range regions {
+		if results[i].Err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", region, results[i].Err))
+			continue
+		}
+		response := results[i].Value.(map[string]interface{})
+		key, items, ok := resultListField(responseResult(response))
+		if !ok {
+			failures = append(failures, fmt.Sprintf("%s: ---all-regions requires a list response, but the response Result contains no array", region))
+			continue
+		}
+		succeeded++
+		for _, e := range detectSoftErrors(response, errorPaths) {
+			softErrors = append(softErrors, region+" "+e)
+		}
+		if validateResponse {
+			for _, m := range validateResponseSchema(response, apiMeta) {
+				schemaMismatches = append(schemaMismatches, region+" "+m)
+			}
+		}
+		tagged := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			if row, isObject := item.(map[string]interface{}); isObject {
+				row[sourceRegionField] = region
+				tagged = append(tagged, row)
+			} else {
+				tagged = append(tagged, map[string]interface{}{sourceRegionField: region, "Value": item})
+			}
+		}
+		existing, _ := combined[key].([]interface{})
+		combined[key] = append(existing, tagged...)
+	}
+
+	if succeeded > 0 {
+		handlePage, finish := output.newPageHandler()
+		if err := handlePage(map[string]interface{}{"Result": combined}); err != nil {
+			return err
+		}
+		if err := finish(); err != nil {
+			return err
+		}
+	}
+	reportResponseSchemaMismatches(schemaMismatches)
+	if len(failures) > 0 {
+		_ = reportSoftErrors(ctx, softErrors)
+		return fmt.Errorf("%d of %d regions failed: %s", len(failures), len(regions), strings.Join(failures, "; "))
+	}
+	if err := reportSoftErrors(ctx, softErrors); err != nil {
+		return err
+	}
+	return output.deliverResult()
+}
+
+// collectActionPages 在一个区域调用 action，开启 ---paginate 时取完所有页并合并列表。
+// 每个区域单独构造请求参数，翻页参数的修改不会影响其他区域。
+func collectActionPages(ctx *Context, sdk *SdkClient, info SdkClientInfo, apiMeta *ApiMeta, jsonBody, paginate bool) (map[string]interface{}, error) {
+	debugLog := debugLoggerFromContext(ctx)
+	input, inputFromBody, err := buildActionInput(ctx.dynamicFlags.flags, apiMeta, jsonBody)
+	if err != nil {
+		return nil, err
+	}
+	var callInput interface{} = input
+	if !jsonBody || !inputFromBody {
+		inputMap, _ := input.(map[string]interface{})
+		callInput = &inputMap
+	}
+	params := pageParams(callInput)
+
+	var merged map[string]interface{}
+	for {
+		start := time.Now()
+		out, err := sdk.CallSdk(info, callInput)
+		debugLogSdkEnd(debugLog, start, err)
+		if err != nil {
+			return nil, formatActionError(err)
+		}
+		if merged == nil {
+			merged = *out
+		} else {
+			mergePageList(merged, *out)
+		}
+		if !paginate || !setNextPageParams(*out, params, jsonBody) {
+			return merged, nil
+		}
+	}
+}
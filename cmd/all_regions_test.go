@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestValidateAllRegions(t *testing.T) {
+	cases := []struct {
+		name     string
+		action   string
+		explicit string
+		regions  []string
+		wantErr  string
+		want     []string
+	}{
+		{name: "explicit region", action: "DescribeInstances", explicit: "region", regions: []string{"r1"}, wantErr: "cannot be used with ---region"},
+		{name: "write action", action: "RunInstances", regions: []string{"r1"}, wantErr: "only supports read actions"},
+		{name: "no regions", action: "DescribeInstances", wantErr: "requires a region list"},
+		{name: "dedupe", action: "ListUsers", regions: []string{"r1", " r2", "r1", ""}, want: []string{"r1", "r2"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			testCtx := NewContext()
+			testCtx.SetConfig(&Configure{Profiles: map[string]*Profile{}, Regions: tc.regions})
+			got, err := validateAllRegions(testCtx, tc.action, tc.explicit)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("validateAllRegions() error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("validateAllRegions() error = %v", err)
+			}
+			if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+				t.Fatalf("regions = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDoActionAllRegionsTagsSourceRegion(t *testing.T) {
+	defer disableProxyEnvForTest(t)()
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ResponseMetadata":{"RequestId":"req"},"Result":{"Instances":[{"InstanceId":"i-1"}]}}`))
+	}))
+	defer server.Close()
+
+	defer setenvForTest(t, "BYTEPLUS_ACCESS_KEY", "ak-test")()
+	defer setenvForTest(t, "BYTEPLUS_SECRET_KEY", "sk-test")()
+	defer setenvForTest(t, "BYTEPLUS_ENDPOINT", server.URL)()
+
+	var out bytes.Buffer
+	prevWriter := actionOutputWriter
+	actionOutputWriter = &out
+	defer func() { actionOutputWriter = prevWriter }()
+
+	testCtx := NewContext()
+	testCtx.SetConfig(&Configure{Profiles: map[string]*Profile{}, Regions: []string{"r1", "r2"}})
+	parser := NewParser([]string{"---all-regions", "---output", "table", "---fields", "InstanceId,SourceRegion"})
+	if _, err := parser.ReadArgs(testCtx); err != nil {
+		t.Fatalf("ReadArgs() error = %v", err)
+	}
+	if err := doAction(testCtx, "ecs", "DescribeInstances"); err != nil {
+		t.Fatalf("doAction() error = %v", err)
+	}
+
+	if calls != 2 {
+		t.Fatalf("server calls = %d, want 2", calls)
+	}
+	want := "InstanceId  SourceRegion\n----------  ------------\ni-1         r1\ni-1         r2\n"
+	if out.String() != want {
+		t.Fatalf("table output =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestDoActionAllRegionsBoundsConcurrency(t *testing.T) {
+	defer disableProxyEnvForTest(t)()
+
+	var inFlight, maxInFlight, calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ResponseMetadata":{"RequestId":"req"},"Result":{"Instances":[]}}`))
+	}))
+	defer server.Close()
+
+	defer setenvForTest(t, "BYTEPLUS_ACCESS_KEY", "ak-test")()
+	defer setenvForTest(t, "BYTEPLUS_SECRET_KEY", "sk-test")()
+	defer setenvForTest(t, "BYTEPLUS_ENDPOINT", server.URL)()
+
+	prevWriter := actionOutputWriter
+	actionOutputWriter = &bytes.Buffer{}
+	defer func() { actionOutputWriter = prevWriter }()
+
+	regions := make([]string, allRegionsConcurrency+4)
+	for i := range regions {
+		regions[i] = fmt.Sprintf("r%d", i)
+	}
+	testCtx := NewContext()
+	testCtx.SetConfig(&Configure{Profiles: map[string]*Profile{}, Regions: regions})
+	if _, err := NewParser([]string{"---all-regions"}).ReadArgs(testCtx); err != nil {
+		t.Fatalf("ReadArgs() error = %v", err)
+	}
+	if err := doAction(testCtx, "ecs", "DescribeInstances"); err != nil {
+		t.Fatalf("doAction() error = %v", err)
+	}
+
+	if int(calls) != len(regions) {
+		t.Fatalf("server calls = %d, want %d", calls, len(regions))
+	}
+	if maxInFlight > allRegionsConcurrency {
+		t.Fatalf("max concurrent calls = %d, want at most %d", maxInFlight, allRegionsConcurrency)
+	}
+}
+
+func TestContextWithRegionKeepsRepeatedHeaders(t *testing.T) {
+	testCtx := NewContext()
+	parser := NewParser([]string{"---all-regions", "---header", "X-Tag: a", "---header", "X-Tag: b", "---endpoint", "open.byteplusapi.com"})
+	if _, err := parser.ReadArgs(testCtx); err != nil {
+		t.Fatalf("ReadArgs() error = %v", err)
+	}
+
+	for _, region := range []string{"r1", "r2"} {
+		regionCtx := contextWithRegion(testCtx, region)
+		headers, err := resolveExtraHeaders(regionCtx)
+		if err != nil {
+			t.Fatalf("region %s: resolveExtraHeaders() error = %v", region, err)
+		}
+		if got := strings.Join(headers["X-Tag"], ","); got != "a,b" {
+			t.Fatalf("region %s: X-Tag = %q, want both ---header values a,b", region, got)
+		}
+		if f := regionCtx.fixedFlags.GetByName("region"); f == nil || f.GetValue() != region {
+			t.Fatalf("region %s: ---region = %v, want %s", region, f, region)
+		}
+		if regionCtx.fixedFlags.GetByName("endpoint") != nil {
+			t.Fatalf("region %s: ---endpoint kept in the per-region context", region)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// renameFile 是替换目标文件时使用的 rename 注入点，测试中用于模拟跨设备与权限错误。
+var renameFile = os.Rename
+
+// replaceFile 用已写入并 fsync 的临时文件替换 target，并 fsync 所在目录，保证 rename 在断电后仍然可见。
+//
+// rename 失败时不再删除目标文件后重试：权限错误直接返回，避免掩盖真实问题并留下缺失的文件；
+// 只有跨设备（EXDEV，常见于 overlay/网络文件系统）时才退化为复制到目标文件并 fsync，此时替换不再是原子的。
+// 成功时临时文件已不存在，失败时由调用方负责清理临时文件。
+func replaceFile(tempName, target string, perm os.FileMode) error {
+	err := renameFile(tempName, target)
+	if err == nil {
+		syncDir(filepath.Dir(target))
+		return nil
+	}
+	if os.IsPermission(err) || !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+	if err := copyFileSync(tempName, target, perm); err != nil {
+		return fmt.Errorf("failed to copy %s across devices: %w", filepath.Base(target), err)
+	}
+	_ = os.Remove(tempName)
+	syncDir(filepath.Dir(target))
+	return nil
+}
+
+func copyFileSync(src, dst string, perm os.FileMode) (retErr error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := out.Close(); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+	if _, err := io.Copy(out, in); err != nil {
+		return err
+	}
+	if err := out.Chmod(perm); err != nil {
+		return err
+	}
+	return out.Sync()
+}
+
+// syncDir 尽力 fsync 目录；部分平台（如 Windows）不支持打开目录同步，忽略错误。
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	_ = d.Sync()
+	_ = d.Close()
+}
+
+// tempFilePrefix 是原子写入所用临时文件的公共前缀（.tmp-、.tmp-config-、.tmp-metrics-、.tmp-login-cache-）。
+const tempFilePrefix = ".tmp-"
+
+// staleTempFileAge 之前修改的临时文件不可能属于仍在进行的写入，启动时视为被中断的进程遗留并删除。
+const staleTempFileAge = 10 * time.Minute
+
+var (
+	activeTempFilesMu sync.Mutex
+	activeTempFiles   = map[string]struct{}{}
+)
+
+// trackTempFile 登记正在写入的临时文件，进程因再次收到 SIGINT/SIGTERM 强制退出前由 removeActiveTempFiles 删除，
+// 此时不会执行 defer 中的清理。返回的函数在写入结束后取消登记。
+func trackTempFile(name string) func() {
+	activeTempFilesMu.Lock()
+	activeTempFiles[name] = struct{}{}
+	activeTempFilesMu.Unlock()
+	return func() {
+		activeTempFilesMu.Lock()
+		delete(activeTempFiles, name)
+		activeTempFilesMu.Unlock()
+	}
+}
+
+// removeActiveTempFiles 删除所有仍在登记中的临时文件。
+func removeActiveTempFiles() {
+	activeTempFilesMu.Lock()
+	defer activeTempFilesMu.Unlock()
+	for name := range activeTempFiles {
+		_ = os.Remove(name)
+		delete(activeTempFiles, name)
+	}
+}
+
+// tempFileDirs 返回原子写入会创建临时文件的目录：配置目录、SSO token 与角色凭证缓存目录、Console Login 缓存目录。
+// 只计算路径，不创建目录。
+func tempFileDirs() []string {
+	configDir, err := configFileDirFunc()
+	if err != nil {
+		return nil
+	}
+	dirs := []string{
+		configDir,
+		filepath.Join(configDir, "sso", "cache"),
+		filepath.Join(configDir, "sso", "credentials"),
+		filepath.Join(configDir, "login", "cache"),
+	}
+	if customCacheDir := os.Getenv(loginCacheDirectoryEnv); customCacheDir != "" {
+		dirs = append(dirs, customCacheDir)
+	}
+	return dirs
+}
+
+// sweepStaleTempFiles 尽力删除 dirs 中早于 now 减 staleTempFileAge 的临时文件，
+// 清理进程被 SIGKILL 或断电中断后留下的 .tmp-* 文件；目录不存在或删除失败时忽略。
+func sweepStaleTempFiles(dirs []string, now time.Time) {
+	cutoff := now.Add(-staleTempFileAge)
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if !entry.Type().IsRegular() || !strings.HasPrefix(entry.Name(), tempFilePrefix) {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil || !info.ModTime().Before(cutoff) {
+				continue
+			}
+			_ = os.Remove(filepath.Join(dir, entry.Name()))
+		}
+	}
+}
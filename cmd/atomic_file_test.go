@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func withRenameFileForTest(t *testing.T, fn func(oldpath, newpath string) error) {
+	t.Helper()
+	old := renameFile
+	renameFile = fn
+	t.Cleanup(func() { renameFile = old })
+}
+
+func TestWriteJSONFileAtomicCopiesAcrossDevices(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "cache.json")
+	if err := ioutil.WriteFile(target, []byte("old\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	withRenameFileForTest(t, func(oldpath, newpath string) error {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: syscall.EXDEV}
+	})
+
+	if err := writeJSONFileAtomic(target, 0600, map[string]string{"k": "v"}); err != nil {
+		t.Fatalf("writeJSONFileAtomic() error = %v", err)
+	}
+	data, err := ioutil.ReadFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "{\"k\":\"v\"}\n" {
+		t.Fatalf("target = %q, want new payload", data)
+	}
+	entries, _ := ioutil.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Fatalf("directory has %d entries, want temp file removed", len(entries))
+	}
+}
+
+func TestWriteConfigToFileFailsOnPermissionErrorWithoutRemovingTarget(t *testing.T) {
+	dir := withTestConfigDir(t)
+	target := filepath.Join(dir, ConfigFile)
+	if err := ioutil.WriteFile(target, []byte("{}\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var renames int
+	withRenameFileForTest(t, func(oldpath, newpath string) error {
+		renames++
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: syscall.EACCES}
+	})
+
+	err := WriteConfigToFile(&Configure{Profiles: map[string]*Profile{}})
+	if !os.IsPermission(err) {
+		t.Fatalf("WriteConfigToFile() error = %v, want permission error", err)
+	}
+	if renames != 1 {
+		t.Fatalf("rename attempts = %d, want 1", renames)
+	}
+	if data, err := ioutil.ReadFile(target); err != nil || string(data) != "{}\n" {
+		t.Fatalf("target = %q, %v, want original content kept", data, err)
+	}
+}
+
+func TestSweepStaleTempFilesRemovesOnlyOldTempFiles(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	old := now.Add(-2 * staleTempFileAge)
+	files := map[string]time.Time{
+		".tmp-123":              old,
+		".tmp-config-456":       old,
+		".tmp-login-cache-789":  old,
+		".tmp-recent":           now,
+		"config.json":           old,
+		".doctor-probe-not-tmp": old,
+	}
+	for name, mtime := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("{}"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sweepStaleTempFiles([]string{dir, filepath.Join(dir, "missing")}, now)
+
+	for name := range files {
+		_, err := os.Stat(filepath.Join(dir, name))
+		removed := os.IsNotExist(err)
+		wantRemoved := strings.HasPrefix(name, tempFilePrefix) && name != ".tmp-recent"
+		if removed != wantRemoved {
+			t.Fatalf("%s removed = %v, want %v", name, removed, wantRemoved)
+		}
+	}
+}
+
+func TestRemoveActiveTempFilesDeletesTrackedFiles(t *testing.T) {
+	dir := t.TempDir()
+	tracked := filepath.Join(dir, ".tmp-tracked")
+	finished := filepath.Join(dir, ".tmp-finished")
+	for _, path := range []string{tracked, finished} {
+		if err := os.WriteFile(path, nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	untrack := trackTempFile(tracked)
+	defer untrack()
+	trackTempFile(finished)()
+
+	removeActiveTempFiles()
+
+	if _, err := os.Stat(tracked); !os.IsNotExist(err) {
+		t.Fatalf("tracked temp file still exists: %v", err)
+	}
+	if _, err := os.Stat(finished); err != nil {
+		t.Fatalf("untracked temp file was removed: %v", err)
+	}
+}
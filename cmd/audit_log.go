@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const envCLIAuditLog = "BYTEPLUS_CLI_AUDIT_LOG"
+
+// auditLogWarningOut 为审计日志写入失败提示的输出目标。
+var auditLogWarningOut io.Writer = os.Stderr
+
+// auditLogEntry 是审计日志中的一行。只记录参数名不记录参数值，疑似敏感的参数名也会被脱敏。
+type auditLogEntry struct {
+	Time    string   `json:"time"`
+	Profile string   `json:"profile,omitempty"`
+	Service string   `json:"service"`
+	Action  string   `json:"action"`
+	Params  []string `json:"params"`
+	Status  string   `json:"status"`
+}
+
+// auditLogPath 返回审计日志路径：BYTEPLUS_CLI_AUDIT_LOG 优先，其次为配置文件中的 audit-log，都未设置时不记录。
+func auditLogPath(ctx *Context) string {
+	if path := strings.TrimSpace(os.Getenv(envCLIAuditLog)); path != "" {
+		return path
+	}
+	if ctx != nil && ctx.config != nil && !ignoreConfigRequested(ctx) {
+		return strings.TrimSpace(ctx.config.AuditLog)
+	}
+	return ""
+}
+
+// newAuditLogEntry 根据本次调用的上下文生成审计记录，callErr 决定 status 为 ok 或 error。
+func newAuditLogEntry(ctx *Context, serviceName, action string, callErr error) auditLogEntry {
+	entry := auditLogEntry{
+		Time:    nowFunc().UTC().Format(time.RFC3339),
+		Service: serviceName,
+		Action:  action,
+		Params:  []string{},
+		Status:  "ok",
+	}
+	if callErr != nil {
+		entry.Status = "error"
+	}
+	if f := ctx.fixedFlags.GetByName("profile"); f != nil && f.GetValue() != "" {
+		entry.Profile = f.GetValue()
+	} else if !ignoreConfigRequested(ctx) {
+		entry.Profile = defaultProfileName(ctx.config)
+	}
+	for _, f := range ctx.dynamicFlags.GetFlags() {
+		name := f.Name
+		if isSensitiveDebugKey(name) {
+			name = maskedDebugValue
+		}
+		entry.Params = append(entry.Params, name)
+	}
+	sort.Strings(entry.Params)
+	return entry
+}
+
+// writeAuditLog 在启用审计日志时追加一行 JSON。写入失败只在 stderr 提示，不改变命令结果。
+func writeAuditLog(ctx *Context, serviceName, action string, callErr error) {
+	path := auditLogPath(ctx)
+	if path == "" {
+		return
+	}
+	if err := appendAuditLog(path, newAuditLogEntry(ctx, serviceName, action, callErr)); err != nil {
+		fmt.Fprintf(auditLogWarningOut, "Warning: failed to write audit log %s: %v\n", path, err)
+	}
+}
+
+// appendAuditLog 复用 debug 日志的文件校验，拒绝 symlink/hardlink 目标，文件权限固定为 0600。
+func appendAuditLog(path string, entry auditLogEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	file, err := openDebugLogFile(path)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewAuditLogEntryRecordsNamesOnly(t *testing.T) {
+	withFixedNow(t, time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC))
+	testCtx := NewContext()
+	testCtx.SetConfig(&Configure{Current: "prod", Profiles: map[string]*Profile{}})
+	if _, err := NewParser([]string{"--InstanceId", "i-1", "--Password", "p@ss"}).ReadArgs(testCtx); err != nil {
+		t.Fatalf("ReadArgs() error = %v", err)
+	}
+
+	entry := newAuditLogEntry(testCtx, "ecs", "ModifyInstanceAttribute", nil)
+	data, _ := json.Marshal(entry)
+	want := `{"time":"2030-01-01T12:00:00Z","profile":"prod","service":"ecs","action":"ModifyInstanceAttribute","params":["***MASKED***","InstanceId"],"status":"ok"}`
+	if string(data) != want {
+		t.Fatalf("audit entry = %s, want %s", data, want)
+	}
+}
+
+func TestDoActionAppendsAuditLog(t *testing.T) {
+	defer disableProxyEnvForTest(t)()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ResponseMetadata":{"RequestId":"req"},"Result":{"Instances":[]}}`))
+	}))
+	defer server.Close()
+
+	logPath := filepath.Join(t.TempDir(), "audit", "bp.log")
+	defer setenvForTest(t, envCLIAuditLog, logPath)()
+	defer setenvForTest(t, "BYTEPLUS_ACCESS_KEY", "ak-test")()
+	defer setenvForTest(t, "BYTEPLUS_SECRET_KEY", "sk-test")()
+	defer setenvForTest(t, "BYTEPLUS_REGION", "ap-southeast-1")()
+
+	prevWriter := actionOutputWriter
+	actionOutputWriter = io.Discard
+	defer func() { actionOutputWriter = prevWriter }()
+
+	for i := 0; i < 2; i++ {
+		testCtx := NewContext()
+		if _, err := NewParser([]string{"---endpoint", server.URL, "---count", "--InstanceIds.1", "i-1"}).ReadArgs(testCtx); err != nil {
+			t.Fatalf("ReadArgs() error = %v", err)
+		}
+		if err := doAction(testCtx, "ecs", "DescribeInstances"); err != nil {
+			t.Fatalf("doAction() error = %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("read audit log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("audit log lines = %d, want 2:\n%s", len(lines), data)
+	}
+	var entry auditLogEntry
+	if err := json.Unmarshal([]byte(lines[1]), &entry); err != nil {
+		t.Fatalf("unmarshal audit line: %v", err)
+	}
+	if entry.Service != "ecs" || entry.Action != "DescribeInstances" || entry.Status != "ok" || len(entry.Params) != 1 || entry.Params[0] != "InstanceIds.1" {
+		t.Fatalf("audit entry = %+v", entry)
+	}
+	if strings.Contains(string(data), "i-1") {
+		t.Fatalf("audit log contains a parameter value:\n%s", data)
+	}
+}
@@ -0,0 +1,374 @@
+/*
+ * // Copyright (c) 2024 Bytedance Ltd. and/or its affiliates
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at
+ * //
+ * //	http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/byteplus-sdk/byteplus-go-sdk-v2/byteplus/credentials"
+	"github.com/byteplus-sdk/byteplus-go-sdk-v2/byteplus/credentials/clicreds"
+	"github.com/byteplus-sdk/byteplus-go-sdk-v2/byteplus/defaults"
+)
+
+// Value sources reported by resolveCredentials and ---verbose.
+const (
+	sourceFlag       = "flag"
+	sourceProfile    = "profile"
+	sourceSsoSession = "sso-session"
+	sourceUnset      = "unset"
+)
+
+// Values of ---credential-source.
+const (
+	credentialSourceAuto        = "auto"
+	credentialSourceProfile     = "profile"
+	credentialSourceEnvironment = "env"
+)
+
+// credentialSourceEnv sets the default of ---credential-source, e.g. for a
+// parent tool that exports BYTEPLUS_ACCESS_KEY but wants the profile to win.
+const credentialSourceEnv = "BYTEPLUS_CREDENTIAL_SOURCE"
+
+// resolveCredentialSource returns the effective credential source: the flag
+// value, then BYTEPLUS_CREDENTIAL_SOURCE, then "auto".
+func resolveCredentialSource(flagValue string) (string, error) {
+	value, from := flagValue, "---credential-source"
+	if strings.TrimSpace(value) == "" {
+		value, from = os.Getenv(credentialSourceEnv), credentialSourceEnv
+	}
+	switch source := strings.ToLower(strings.TrimSpace(value)); source {
+	case "", credentialSourceAuto:
+		return credentialSourceAuto, nil
+	case credentialSourceProfile, credentialSourceEnvironment:
+		return source, nil
+	}
+	return "", fmt.Errorf("%s %q is not supported, supported values: profile, env, auto", from, value)
+}
+
+// clientOverrides are the per-invocation values that take precedence over
+// the profile and the environment.
+type clientOverrides struct {
+	Profile            string
+	Region             string
+	Endpoint           string
+	InsecureSkipVerify bool
+	// CredentialSource forces where credentials come from: "profile", "env"
+	// or "auto" (the default precedence).
+	CredentialSource string
+	// Proxy replaces the profile's proxy for one call.
+	Proxy string
+}
+
+// clientOverridesFromFlags collects ---profile, ---region, ---endpoint,
+// ---insecure-skip-verify, ---credential-source and ---proxy.
+func clientOverridesFromFlags(ctx *Context) clientOverrides {
+	var o clientOverrides
+	if f := ctx.fixedFlags.GetByName("profile"); f != nil {
+		o.Profile = f.GetValue()
+	}
+	if f := ctx.fixedFlags.GetByName("region"); f != nil {
+		o.Region = f.GetValue()
+	}
+	if f := ctx.fixedFlags.GetByName("endpoint"); f != nil {
+		o.Endpoint = f.GetValue()
+	}
+	if f := ctx.fixedFlags.GetByName("insecure-skip-verify"); f != nil {
+		o.InsecureSkipVerify = f.GetValue() == "true"
+	}
+	if f := ctx.fixedFlags.GetByName("credential-source"); f != nil {
+		o.CredentialSource = f.GetValue()
+	}
+	if f := ctx.fixedFlags.GetByName("proxy"); f != nil {
+		o.Proxy = f.GetValue()
+	}
+	return o
+}
+
+// resolvedClient is the effective client configuration of one invocation.
+// Each *Source field names where the matching value came from: "flag",
+// "profile", "sso-session", "env:<NAME>" or "unset".
+type resolvedClient struct {
+	Profile       *Profile
+	ProfileName   string
+	ProfileSource string
+
+	Credentials *credentials.Credentials
+	// CredentialSource is "credential-process", "profile:<mode>", "env-only"
+	// or "default-chain". The key itself is fetched lazily by the SDK provider.
+	CredentialSource string
+
+	Region           string
+	RegionSource     string
+	Endpoint         string
+	EndpointSource   string
+	EndpointResolver string
+	ServiceEndpoints map[string]string
+
+	HTTPProxy  string
+	HTTPSProxy string
+	// Proxy, when set, is used for every request instead of HTTPProxy,
+	// HTTPSProxy and the proxy environment variables, except for hosts
+	// matched by NoProxy.
+	Proxy        string
+	ProxySource  string
+	NoProxy      string
+	DisableSSL   bool
+	UseDualStack bool
+	// InsecureSkipVerify disables TLS certificate verification; unlike
+	// DisableSSL it keeps the https scheme.
+	InsecureSkipVerify bool
+	// Transport tunes the connection pool; it comes from the top-level
+	// config and is left at the defaults when the config file is ignored.
+	Transport transportSettings
+}
+
+// resolveCredentials applies the precedence rules for every client setting in
+// one place:
+//
+//	profile:     ---profile > BYTEPLUS_PROFILE > current > SDK default chain
+//	credentials: credential-process > profile mode > env only (---no-config) > SDK default chain;
+//	             ---credential-source profile|env forces one side
+//	region:      ---region > profile > SSO session (sso profiles) > BYTEPLUS_REGION
+//	             > instance metadata (opt-in, looked up by NewSimpleClient)
+//	endpoint:    ---endpoint > profile > BYTEPLUS_ENDPOINT
+//	proxy:       ---proxy > profile proxy > http-proxy/https-proxy > HTTP(S)_PROXY
+//
+// SSO and Console Login credentials are refreshed here, so the returned
+// credentials are ready to use.
+func resolveCredentials(ctx *Context, overrides clientOverrides) (*resolvedClient, error) {
+	r := &resolvedClient{ProfileSource: "default-chain"}
+	source, err := resolveCredentialSource(overrides.CredentialSource)
+	if err != nil {
+		return nil, err
+	}
+	ignoreConfig := ignoreConfigRequested(ctx)
+	if ignoreConfig && source == credentialSourceProfile {
+		return nil, fmt.Errorf("---credential-source profile cannot be used when the config file is ignored (---no-config or %s=true)", ignoreConfigEnv)
+	}
+	if ignoreConfig || source == credentialSourceEnvironment {
+		if overrides.Profile != "" {
+			if ignoreConfig {
+				return nil, fmt.Errorf("---profile cannot be used when the config file is ignored (---no-config or %s=true)", ignoreConfigEnv)
+			}
+			return nil, fmt.Errorf("---profile cannot be used with ---credential-source env")
+		}
+		r.ProfileSource = "env-only"
+		if !ignoreConfig && ctx.config != nil {
+			r.Transport = transportSettingsFromConfig(ctx.config)
+		}
+	} else if ctx.config != nil {
+		r.Transport = transportSettingsFromConfig(ctx.config)
+		// Empty Current with no env does NOT fall back to a default profile;
+		// it goes to the default credential chain instead.
+		r.ProfileName, r.ProfileSource = defaultProfileNameWithSource(ctx.config)
+		if overrides.Profile != "" {
+			r.ProfileName = overrides.Profile
+			r.ProfileSource = sourceFlag
+		}
+		r.Profile = ctx.config.Profiles[r.ProfileName]
+		if r.Profile == nil && r.ProfileSource != "current" && r.ProfileSource != "default-chain" {
+			if r.ProfileSource == sourceFlag {
+				return nil, fmt.Errorf("profile %q not found", r.ProfileName)
+			}
+			return nil, fmt.Errorf("profile %q not found (selected by %s)", r.ProfileName, strings.TrimPrefix(r.ProfileSource, "env:"))
+		}
+	}
+
+	switch {
+	case r.Profile != nil:
+		if err := r.resolveProfileCredentials(ctx); err != nil {
+			return nil, err
+		}
+		r.setProfileLocation(ctx.config)
+	case ignoreConfig || source == credentialSourceEnvironment:
+		// 忽略配置文件或指定 ---credential-source env：只接受环境变量中的 AK/SK，缺失时直接报错，不回退到默认凭证链
+		reason := fmt.Sprintf("the config file is ignored (---no-config or %s=true)", ignoreConfigEnv)
+		if !ignoreConfig {
+			reason = "credentials are taken from the environment (---credential-source env)"
+		}
+		envCreds, err := envOnlyCredentials(ctx, reason)
+		if err != nil {
+			return nil, err
+		}
+		r.Credentials = envCreds
+		r.CredentialSource = "env-only"
+		r.setEnvLocation()
+	case source == credentialSourceProfile:
+		return nil, fmt.Errorf("---credential-source profile requires a profile, but none is selected; set one with ---profile, BYTEPLUS_PROFILE or 'bp configure profile --profile name'")
+	default:
+		// 禁用默认凭证链
+		if os.Getenv("BYTEPLUS_DISABLE_DEFAULT_CREDENTIALS") == "true" {
+			return nil, fmt.Errorf("no profile configured and default credential chain is disabled (BYTEPLUS_DISABLE_DEFAULT_CREDENTIALS=true)")
+		}
+		// 无 profile，使用 SDK 默认凭证链（Env → OIDC → CliProvider → EcsRole）
+		r.Credentials = defaults.NewDefaultCredentialProvider()
+		r.CredentialSource = "default-chain"
+		r.setEnvLocation()
+	}
+
+	if overrides.Region != "" {
+		r.Region, r.RegionSource = overrides.Region, sourceFlag
+	}
+	// ---endpoint 同时覆盖 endpoint-resolver 与 profile 中按服务配置的 endpoints
+	if overrides.Endpoint != "" {
+		r.Endpoint, r.EndpointSource = overrides.Endpoint, sourceFlag
+		r.EndpointResolver = ""
+		r.ServiceEndpoints = nil
+	}
+	if overrides.InsecureSkipVerify {
+		r.InsecureSkipVerify = true
+	}
+	if overrides.Proxy != "" {
+		r.Proxy, r.ProxySource = overrides.Proxy, sourceFlag
+	}
+	return r, nil
+}
+
+// resolveProfileCredentials builds the credentials of a configured profile.
+func (r *resolvedClient) resolveProfileCredentials(ctx *Context) error {
+	profile := r.Profile
+	if strings.TrimSpace(profile.CredentialProcess) != "" {
+		// 外部凭证命令优先于 mode，其输出直接作为静态凭证使用
+		processCreds, err := resolveCredentialProcess(profile)
+		if err != nil {
+			return err
+		}
+		r.Credentials = credentials.NewStaticCredentials(processCreds.AccessKeyId, processCreds.SecretAccessKey, processCreds.SessionToken)
+		r.CredentialSource = "credential-process"
+		return nil
+	}
+
+	mode := strings.ToLower(strings.TrimSpace(profile.Mode))
+	if expiredAt, stale := staleAKSessionToken(profile); stale {
+		// 过期的遗留 token 只会导致签名失败，忽略它，只用 AK/SK 调用
+		fmt.Fprintf(staleProfileWarningOut, "Warning: profile %q (mode ak) has a session-token that expired at %s, probably left over from an SSO or role configuration; it is ignored. Set the access key again with 'bp configure set --profile %s --access-key AK --secret-key SK' to remove it\n",
+			r.ProfileName, expiredAt.Format(time.RFC3339), r.ProfileName)
+		r.Credentials = credentials.NewStaticCredentials(profile.AccessKey, profile.SecretKey, "")
+		r.CredentialSource = "profile:" + ModeAK
+		warnStaleAKProfile(ctx.config, profile)
+		return nil
+	}
+	// SSO 模式：CLI 负责刷新凭证并写回 config.json，再交给 SDK CliProvider 读取
+	if mode == ModeSSO {
+		sso := &Sso{
+			Profile:        profile,
+			SsoSessionName: profile.SsoSessionName,
+		}
+		if err := sso.EnsureValidStsToken(ctx); err != nil {
+			return err
+		}
+		// 缓存的 STS 凭证仍有效时调用可以成功，但过期后无法刷新，提前提示
+		if err := missingSsoSessionError(ctx.config, profile); err != nil {
+			fmt.Fprintf(danglingSsoSessionWarningOut, "Warning: %v\n", err)
+		}
+	}
+	if mode == ModeConsoleLogin {
+		// Console Login 模式：CLI 负责刷新 login cache，再交给 SDK CliProvider 读取
+		if _, err := EnsureValidLoginToken(ctx.config, r.ProfileName); err != nil {
+			return err
+		}
+	}
+
+	// 所有模式统一委托 SDK CliProvider 解析凭证
+	r.Credentials = clicreds.NewCliCredentials("", r.ProfileName)
+	r.CredentialSource = "profile:" + debugCredentialMode(profile)
+	warnStaleAKProfile(ctx.config, profile)
+	return nil
+}
+
+// setProfileLocation fills region, endpoint and network settings from the
+// profile, falling back to the environment for values the profile leaves empty.
+func (r *resolvedClient) setProfileLocation(cfg *Configure) {
+	profile := r.Profile
+	r.Region, r.RegionSource = profile.Region, sourceProfile
+	if r.Region == "" {
+		// SSO profile 未单独设置 region 时沿用其 sso-session 的 region
+		r.Region, r.RegionSource = ssoSessionRegion(cfg, profile), sourceSsoSession
+	}
+	if r.Region == "" {
+		r.Region, r.RegionSource = envWithSource("BYTEPLUS_REGION")
+	}
+	r.Endpoint, r.EndpointSource = profile.Endpoint, sourceProfile
+	if r.Endpoint == "" {
+		r.Endpoint, r.EndpointSource = envWithSource("BYTEPLUS_ENDPOINT")
+	}
+	r.ServiceEndpoints = profile.Endpoints
+	r.EndpointResolver = profile.EndpointResolver
+	if r.EndpointResolver == "" {
+		r.EndpointResolver = os.Getenv("BYTEPLUS_ENDPOINT_RESOLVER")
+	}
+	r.HTTPProxy = profile.HTTPProxy
+	r.HTTPSProxy = profile.HTTPSProxy
+	if profile.Proxy != "" {
+		r.Proxy, r.ProxySource = profile.Proxy, sourceProfile
+	}
+	r.NoProxy = profile.NoProxy
+	if profile.DisableSSL != nil {
+		r.DisableSSL = *profile.DisableSSL
+	}
+	if profile.UseDualStack != nil {
+		r.UseDualStack = *profile.UseDualStack
+	}
+	if profile.InsecureSkipVerify != nil {
+		r.InsecureSkipVerify = *profile.InsecureSkipVerify
+	}
+}
+
+// setEnvLocation fills region, endpoint and network settings from the
+// environment when no profile is in use.
+func (r *resolvedClient) setEnvLocation() {
+	r.Region, r.RegionSource = envWithSource("BYTEPLUS_REGION")
+	r.Endpoint, r.EndpointSource = envWithSource("BYTEPLUS_ENDPOINT")
+	r.EndpointResolver = os.Getenv("BYTEPLUS_ENDPOINT_RESOLVER")
+	if ssl := os.Getenv("BYTEPLUS_DISABLE_SSL"); ssl == "true" || ssl == "false" {
+		r.DisableSSL, _ = strconv.ParseBool(ssl)
+	}
+	if dualStack := os.Getenv("BYTEPLUS_USE_DUALSTACK"); dualStack == "true" || dualStack == "false" {
+		r.UseDualStack, _ = strconv.ParseBool(dualStack)
+	}
+}
+
+// envWithSource returns the value of name and "env:<name>", or "unset" when
+// the variable is empty.
+func envWithSource(name string) (string, string) {
+	if v := os.Getenv(name); v != "" {
+		return v, "env:" + name
+	}
+	return "", sourceUnset
+}
+
+// printResolvedSources writes which source won for each client setting when
+// ---verbose is set, answering why a profile, credential or region was used.
+func (r *resolvedClient) printResolvedSources(ctx *Context) {
+	if f := ctx.fixedFlags.GetByName("verbose"); f == nil || f.GetValue() != "true" {
+		return
+	}
+	endpoint, endpointSource := r.Endpoint, r.EndpointSource
+	if endpoint == "" {
+		endpoint, endpointSource = "-", "resolver"
+	}
+	profileName := r.ProfileName
+	if r.Profile == nil {
+		profileName = "-"
+	}
+	fmt.Fprintf(verboseOutput, "[verbose] profile=%s profile_source=%s credentials=%s region=%s region_source=%s endpoint=%s endpoint_source=%s\n",
+		profileName, r.ProfileSource, r.CredentialSource, r.Region, r.RegionSource, endpoint, endpointSource)
+}
@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResolveCredentialsReportsSources(t *testing.T) {
+	t.Setenv("BYTEPLUS_PROFILE", "")
+	t.Setenv("BYTEPLUS_CLI_PROFILE", "")
+	t.Setenv("BYTEPLUS_REGION", "env-region")
+	t.Setenv("BYTEPLUS_ENDPOINT", "")
+	falseVal := false
+	cfg := &Configure{
+		Current: "ak",
+		Profiles: map[string]*Profile{
+			"ak": {Name: "ak", Mode: ModeAK, AccessKey: "ak", SecretKey: "sk", Endpoint: "open.example.com", DisableSSL: &falseVal},
+			"sso-dev": {
+				Name:           "sso-dev",
+				Mode:           ModeSSO,
+				SsoSessionName: "my-sso",
+				SessionToken:   "token",
+				StsExpiration:  time.Now().Add(time.Hour).Unix(),
+			},
+		},
+		SsoSession: map[string]*SsoSession{
+			"my-sso": {Name: "my-sso", StartURL: "https://example.com/userportal", Region: "ap-southeast-3"},
+		},
+	}
+
+	cases := []struct {
+		name      string
+		overrides clientOverrides
+		want      string
+	}{
+		{
+			name: "current profile with env region",
+			want: "profile=ak/current credentials=profile:ak region=env-region/env:BYTEPLUS_REGION endpoint=open.example.com/profile",
+		},
+		{
+			name:      "flags win",
+			overrides: clientOverrides{Region: "flag-region", Endpoint: "flag.example.com"},
+			want:      "profile=ak/current credentials=profile:ak region=flag-region/flag endpoint=flag.example.com/flag",
+		},
+		{
+			name:      "sso profile takes session region",
+			overrides: clientOverrides{Profile: "sso-dev"},
+			want:      "profile=sso-dev/flag credentials=profile:sso region=ap-southeast-3/sso-session endpoint=/unset",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			testCtx := NewContext()
+			testCtx.SetConfig(cfg)
+			r, err := resolveCredentials(testCtx, tc.overrides)
+			if err != nil {
+				t.Fatalf("resolveCredentials() error = %v", err)
+			}
+			got := fmt.Sprintf("profile=%s/%s credentials=%s region=%s/%s endpoint=%s/%s",
+				r.ProfileName, r.ProfileSource, r.CredentialSource, r.Region, r.RegionSource, r.Endpoint, r.EndpointSource)
+			if got != tc.want {
+				t.Fatalf("resolveCredentials() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNewSimpleClientVerbosePrintsSources(t *testing.T) {
+	t.Setenv("BYTEPLUS_PROFILE", "")
+	t.Setenv("BYTEPLUS_DISABLE_DEFAULT_CREDENTIALS", "")
+	t.Setenv("BYTEPLUS_REGION", "ap-southeast-1")
+	t.Setenv("BYTEPLUS_ENDPOINT", "")
+
+	var out bytes.Buffer
+	prevOutput := verboseOutput
+	verboseOutput = &out
+	defer func() { verboseOutput = prevOutput }()
+
+	testCtx := NewContext()
+	testCtx.SetConfig(&Configure{Profiles: map[string]*Profile{}})
+	if _, err := NewParser([]string{"---verbose"}).ReadArgs(testCtx); err != nil {
+		t.Fatalf("ReadArgs() error = %v", err)
+	}
+	if _, err := NewSimpleClient(testCtx); err != nil {
+		t.Fatalf("NewSimpleClient returned error: %v", err)
+	}
+	want := "[verbose] profile=- profile_source=default-chain credentials=default-chain region=ap-southeast-1 region_source=env:BYTEPLUS_REGION endpoint=- endpoint_source=resolver\n"
+	if !strings.HasPrefix(out.String(), want) {
+		t.Fatalf("verbose output = %q, want prefix %q", out.String(), want)
+	}
+}
+
+func TestResolveCredentialsIgnoresExpiredSessionTokenOfAKProfile(t *testing.T) {
+	t.Setenv("BYTEPLUS_PROFILE", "")
+	now := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	withFixedNow(t, now)
+	var warnings bytes.Buffer
+	prevOut := staleProfileWarningOut
+	staleProfileWarningOut = &warnings
+	defer func() { staleProfileWarningOut = prevOut }()
+
+	resolve := func(profile *Profile) *resolvedClient {
+		t.Helper()
+		testCtx := NewContext()
+		testCtx.SetConfig(&Configure{Current: "dev", Profiles: map[string]*Profile{"dev": profile}})
+		r, err := resolveCredentials(testCtx, clientOverrides{})
+		if err != nil {
+			t.Fatalf("resolveCredentials() error = %v", err)
+		}
+		return r
+	}
+
+	r := resolve(&Profile{Name: "dev", Mode: ModeAK, AccessKey: "ak", SecretKey: "sk", Region: "ap-southeast-1",
+		SessionToken: "old-sts-token", StsExpiration: now.Add(-time.Hour).Unix()})
+	value, err := r.Credentials.Get()
+	if err != nil {
+		t.Fatalf("Credentials.Get() error = %v", err)
+	}
+	if value.AccessKeyID != "ak" || value.SessionToken != "" || r.CredentialSource != "profile:ak" {
+		t.Fatalf("credentials = %+v from %s, want ak without session token (%q)", value, r.CredentialSource, warnings.String())
+	}
+	if !strings.Contains(warnings.String(), `profile "dev" (mode ak) has a session-token that expired at 2030-01-01T11:00:00Z`) {
+		t.Fatalf("warning = %q", warnings.String())
+	}
+
+	// 没有过期时间或尚未过期的 token 是有意配置的临时凭证，不提示
+	warnings.Reset()
+	for _, expiration := range []int64{0, now.Add(time.Hour).Unix()} {
+		resolve(&Profile{Name: "dev", Mode: ModeAK, AccessKey: "ak", SecretKey: "sk", SessionToken: "sts-token", StsExpiration: expiration})
+	}
+	if warnings.Len() != 0 {
+		t.Fatalf("warning = %q, want none for an intended session token", warnings.String())
+	}
+}
+
+func TestConfigureSetNewAccessKeyClearsLeftoverSessionToken(t *testing.T) {
+	withTestConfigDir(t)
+	withTestCtxConfig(t, &Configure{Current: "dev", Profiles: map[string]*Profile{
+		"dev": {Name: "dev", Mode: ModeAK, AccessKey: "old-ak", SecretKey: "old-sk", SessionToken: "old-token", StsExpiration: 1},
+	}})
+
+	if err := setConfigProfile(&Profile{Name: "dev", Region: "ap-southeast-1"}); err != nil {
+		t.Fatalf("setConfigProfile() error = %v", err)
+	}
+	if p := ctx.config.Profiles["dev"]; p.SessionToken != "old-token" {
+		t.Fatalf("session token = %q, want it kept when the access key is unchanged", p.SessionToken)
+	}
+	if err := setConfigProfile(&Profile{Name: "dev", AccessKey: "new-ak", SecretKey: "new-sk"}); err != nil {
+		t.Fatalf("setConfigProfile() error = %v", err)
+	}
+	if p := ctx.config.Profiles["dev"]; p.SessionToken != "" || p.StsExpiration != 0 {
+		t.Fatalf("profile = %+v, want the leftover session token cleared", p)
+	}
+}
+
+func TestResolveCredentialsHonoursCredentialSource(t *testing.T) {
+	t.Setenv("BYTEPLUS_PROFILE", "")
+	t.Setenv("BYTEPLUS_CLI_PROFILE", "")
+	t.Setenv("BYTEPLUS_ACCESS_KEY", "env-ak")
+	t.Setenv("BYTEPLUS_SECRET_KEY", "env-sk")
+	t.Setenv("BYTEPLUS_REGION", "env-region")
+	t.Setenv(credentialSourceEnv, "")
+	withProfile := &Configure{
+		Current:  "ak",
+		Profiles: map[string]*Profile{"ak": {Name: "ak", Mode: ModeAK, AccessKey: "ak", SecretKey: "sk", Region: "profile-region"}},
+	}
+	withoutProfile := &Configure{Profiles: map[string]*Profile{}}
+
+	cases := []struct {
+		name    string
+		cfg     *Configure
+		env     string
+		flag    string
+		want    string
+		wantErr string
+	}{
+		{name: "auto prefers the profile", cfg: withProfile, want: "profile=ak credentials=profile:ak region=profile-region"},
+		{name: "env wins over a present profile", cfg: withProfile, flag: "env", want: "profile= credentials=env-only region=env-region"},
+		{name: "flag wins over the variable", cfg: withProfile, env: "env", flag: "auto", want: "profile=ak credentials=profile:ak region=profile-region"},
+		{name: "variable applies without the flag", cfg: withProfile, env: "ENV", want: "profile= credentials=env-only region=env-region"},
+		{name: "profile does not fall back to the default chain", cfg: withoutProfile, flag: "profile", wantErr: "requires a profile"},
+		{name: "unknown value", cfg: withProfile, flag: "file", wantErr: `---credential-source "file" is not supported`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(credentialSourceEnv, tc.env)
+			testCtx := NewContext()
+			testCtx.SetConfig(tc.cfg)
+			r, err := resolveCredentials(testCtx, clientOverrides{CredentialSource: tc.flag})
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("resolveCredentials() error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveCredentials() error = %v", err)
+			}
+			got := fmt.Sprintf("profile=%s credentials=%s region=%s", r.ProfileName, r.CredentialSource, r.Region)
+			if got != tc.want {
+				t.Fatalf("resolveCredentials() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
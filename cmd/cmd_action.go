@@ -24,7 +24,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/byteplus-sdk/byteplus-cli/util"
 	"github.com/spf13/cobra"
 )
 
@@ -35,6 +34,7 @@ type paramValue struct {
 
 func generateActionCmd(serviceName string, actionMeta map[string]*ByteplusMeta, apiMetas map[string]*ApiMeta) (actionCmds []*cobra.Command) {
 	for action, meta := range actionMeta {
+		meta := meta
 		var apiMeta *ApiMeta
 		if len(apiMetas) > 0 {
 			apiMeta = apiMetas[action]
@@ -44,18 +44,23 @@ func generateActionCmd(serviceName string, actionMeta map[string]*ByteplusMeta,
 			Short:              formatActionShort(serviceName, action),
 			Long:               formatActionLong(serviceName, action),
 			DisableFlagParsing: true,
+			ValidArgsFunction:  completeActionArgs,
 			RunE: func(cmd *cobra.Command, args []string) error {
 				if len(args) == 1 && (args[0] == "-h" || args[0] == "--help") {
 					cmd.Usage()
 					return nil
 				}
+				if len(args) == 1 && args[0] == "--"+helpTreeFlag {
+					fmt.Fprint(cmd.OutOrStdout(), actionParamsTree(actionTreeParams(meta, apiMeta), apiMeta))
+					return nil
+				}
 
 				parser := NewParser(args)
 				if _, err := parser.ReadArgs(ctx); err != nil {
 					return err
 				}
 
-				return doAction(ctx, cmd.Parent().Name(), cmd.Name())
+				return doAction(ctx, serviceName, cmd.Name())
 			},
 		}
 
@@ -69,21 +74,29 @@ func generateActionCmd(serviceName string, actionMeta map[string]*ByteplusMeta,
 				actionCmd.Flags().StringVar(&paramValues[i].value, paramValues[i].param, "", "")
 			}
 
-			actionCmd.SetUsageTemplate(actionUsageTemplate(actionCmd.Long, formatParamsHelpUsage(params)))
+			actionCmd.Example = actionExample(serviceName, action, params, apiMeta)
+			required, optional := formatParamsHelpUsage(params)
+			actionCmd.SetUsageTemplate(actionUsageTemplate(actionCmd.Long, required, optional))
 		} else {
 			var paramBody string
 			actionCmd.Flags().StringVar(&paramBody, "body", "", "")
 			var bodyStr []byte
-			params := []string{fmt.Sprintf(`body '%s'`, string(bodyStr))}
+			var required []string
+			optional := []string{fmt.Sprintf(`body '%s'`, string(bodyStr))}
 			if apiMeta != nil && apiMeta.Request != nil {
 				bodyMap := apiMeta.Request.GetReqBody()
 				bodyStr, _ = json.MarshalIndent(bodyMap, "", "    ")
-				params = append([]string{fmt.Sprintf(`body '%s'`, string(bodyStr))}, formatParamsHelpUsage(apiMeta.GetRequestParams())...)
+				var flatOptional []string
+				required, flatOptional = formatParamsHelpUsage(apiMeta.GetRequestParams())
+				optional = append([]string{fmt.Sprintf(`body '%s'`, string(bodyStr))}, flatOptional...)
+				actionCmd.Example = actionExample(serviceName, action, apiMeta.GetRequestParams(), apiMeta)
 			}
-			actionCmd.SetUsageTemplate(actionUsageTemplate(actionCmd.Long, params))
+			actionCmd.SetUsageTemplate(actionUsageTemplate(actionCmd.Long, required, optional))
 		}
 
+		actionCmd.Flags().String(cliInputYAMLFlag, "", "")
 		actionCmd.Flags().BoolP("help", "h", false, "")
+		actionCmd.Flags().Bool(helpTreeFlag, false, "")
 
 		actionCmds = append(actionCmds, actionCmd)
 	}
@@ -91,11 +104,25 @@ func generateActionCmd(serviceName string, actionMeta map[string]*ByteplusMeta,
 	return
 }
 
+// actionTreeParams returns the parameters used by --help-tree, matching the
+// source the usage template lists for the action's content type.
+func actionTreeParams(meta *ByteplusMeta, apiMeta *ApiMeta) []param {
+	if meta.ApiInfo == nil || strings.ToLower(meta.ApiInfo.ContentType) != "application/json" {
+		return meta.GetRequestParams(apiMeta)
+	}
+	return apiMeta.GetRequestParams()
+}
+
 func doAction(ctx *Context, serviceName, action string) (err error) {
 	if !rootSupport.IsValidAction(serviceName, action) {
 		err = fmt.Errorf("%s.%s is unsupport action", serviceName, action)
 		return
 	}
+	started := nowFunc()
+	defer func() {
+		writeAuditLog(ctx, serviceName, action, err)
+		recordMetrics(ctx, serviceName, action, started, err)
+	}()
 
 	debugLog, closeDebugLog, err := prepareDebugLogger(ctx)
 	if err != nil {
@@ -108,25 +135,43 @@ func doAction(ctx *Context, serviceName, action string) (err error) {
 	}()
 
 	var (
-		sdk *SdkClient
-		out *map[string]interface{}
+		sdk    *SdkClient
+		out    *map[string]interface{}
+		output *actionOutput
 	)
 
-	method := "GET"
-	contentType := ""
-	apiInfo := rootSupport.GetApiInfo(serviceName, action)
-	apiMeta := rootSupport.GetApiMeta(serviceName, action)
-
-	if apiInfo != nil && apiInfo.Method != "" {
-		method = apiInfo.Method
+	explicitLocation := explicitLocationFlag(ctx)
+	// 在合并 profile extra 之前检查，只有命令行中显式给出的格式化 flag 与 ---output-raw 冲突
+	raw, err := resolveRawOutput(ctx, action)
+	if err != nil {
+		return
 	}
-
-	if apiInfo != nil && apiInfo.ContentType != "" {
-		contentType = apiInfo.ContentType
+	if err = applyProfileExtra(ctx); err != nil {
+		return
+	}
+	var regions []string
+	if raw == nil && allRegionsRequested(ctx) {
+		if regions, err = validateAllRegions(ctx, action, explicitLocation); err != nil {
+			return
+		}
 	}
+	output, err = resolveActionOutput(ctx)
+	if err != nil {
+		return
+	}
+	// 调用失败时 finish 可能没有执行，已启动的分页器在返回前关闭
+	defer output.closePager()
 
-	version := rootSupport.GetVersion(serviceName)
-	debugLogActionStart(debugLog, serviceName, action, version, method, contentType)
+	info := resolveActionCallInfo(serviceName, action)
+	if err = applyProtocolOverride(ctx, &info); err != nil {
+		return
+	}
+	if len(regions) > 0 {
+		debugLogActionStart(debugLog, serviceName, action, info.Version, info.Method, info.ContentType)
+		return doActionAllRegions(ctx, serviceName, info, regions, output)
+	}
+	apiMeta := rootSupport.GetApiMeta(serviceName, action)
+	debugLogActionStart(debugLog, serviceName, action, info.Version, info.Method, info.ContentType)
 
 	sdk, err = NewSimpleClient(ctx)
 	if err != nil {
@@ -134,7 +179,7 @@ func doAction(ctx *Context, serviceName, action string) (err error) {
 		return
 	}
 
-	jsonBody := strings.ToLower(contentType) == "application/json"
+	jsonBody := strings.ToLower(info.ContentType) == "application/json"
 	input, inputFromBody, err := buildActionInput(ctx.dynamicFlags.flags, apiMeta, jsonBody)
 	if err != nil {
 		debugLogError(debugLog, "input_build_error", err)
@@ -142,45 +187,84 @@ func doAction(ctx *Context, serviceName, action string) (err error) {
 	}
 	debugLogInput(debugLog, ctx.dynamicFlags.flags, input, inputFromBody)
 
-	if svc, ok := GetServiceMapping(serviceName); ok {
-		serviceName = svc
-	}
-
-	start := time.Now()
-	if strings.ToLower(contentType) != "application/json" {
+	var callInput interface{} = input
+	if !jsonBody || !inputFromBody {
 		inputMap, _ := input.(map[string]interface{})
-		out, err = sdk.CallSdk(SdkClientInfo{
-			ServiceName: serviceName,
-			Action:      action,
-			Version:     version,
-			Method:      method,
-			ContentType: contentType,
-		}, &inputMap)
-	} else {
-		if !inputFromBody {
-			inputMap, _ := input.(map[string]interface{})
-			input = &inputMap
-		}
-		out, err = sdk.CallSdk(SdkClientInfo{
-			ServiceName: serviceName,
-			Action:      action,
-			Version:     version,
-			Method:      method,
-			ContentType: contentType,
-		}, input)
+		callInput = &inputMap
 	}
-	if err != nil {
+	if raw != nil {
+		start := time.Now()
+		err = sdk.CallSdkRaw(info, callInput, raw)
 		debugLogSdkEnd(debugLog, start, err)
-		return formatActionError(err)
+		if err != nil {
+			return formatActionError(err)
+		}
+		return writeRawDownloadSummary(actionOutputWriter, raw)
+	}
+	handlePage, finish := output.newPageHandler()
+	params := pageParams(callInput)
+	errorPaths := softErrorPaths(ctx)
+	validateResponse := validateResponseRequested(ctx)
+	var softErrors, schemaMismatches []string
+	for {
+		start := time.Now()
+		out, err = sdk.CallSdk(info, callInput)
+		if err != nil {
+			debugLogSdkEnd(debugLog, start, err)
+			if output.format == outputFormatTable {
+				// 已获取的行仍然输出到终端，避免翻页中途失败时丢失前面的结果；失败的调用不写 ---output-file
+				if flushErr := output.finishPartial(finish); flushErr != nil {
+					return fmt.Errorf("%w (failed to print the rows fetched so far: %v)", formatActionError(err), flushErr)
+				}
+			}
+			return formatActionError(err)
+		}
+		debugLogSdkEnd(debugLog, start, nil)
+		softErrors = append(softErrors, detectSoftErrors(*out, errorPaths)...)
+		if validateResponse {
+			schemaMismatches = append(schemaMismatches, validateResponseSchema(*out, apiMeta)...)
+		}
+		if err = handlePage(*out); err != nil {
+			return
+		}
+		if !output.paginate || !setNextPageParams(*out, params, jsonBody) {
+			break
+		}
+	}
+	if err = finish(); err != nil {
+		return
 	}
-	debugLogSdkEnd(debugLog, start, nil)
+	reportResponseSchemaMismatches(schemaMismatches)
+	if err = reportSoftErrors(ctx, softErrors); err != nil {
+		return
+	}
+	return output.deliverResult()
+}
 
-	if config == nil || !config.EnableColor {
-		util.ShowJson(*out, false)
-	} else {
-		util.ShowJson(*out, true)
+// resolveActionCallInfo collects the method, content type, version and SDK
+// service name used to call serviceName.action from the embedded metadata.
+func resolveActionCallInfo(serviceName, action string) SdkClientInfo {
+	info := SdkClientInfo{
+		ServiceName: serviceName,
+		Action:      action,
+		Version:     rootSupport.GetVersion(serviceName),
+		Method:      "GET",
 	}
-	return
+	apiInfo := rootSupport.GetApiInfo(serviceName, action)
+	if apiInfo != nil {
+		if apiInfo.Method != "" {
+			info.Method = apiInfo.Method
+		}
+		info.ContentType = apiInfo.ContentType
+	}
+	info.Protocol = detectProtocol(apiInfo)
+	if info.Protocol == protocolJSON && info.ContentType == "" {
+		info.ContentType = "application/json"
+	}
+	if svc, ok := GetServiceMapping(serviceName); ok {
+		info.ServiceName = svc
+	}
+	return info
 }
 
 func prepareDebugLogger(ctx *Context) (*DebugLogger, func() error, error) {
@@ -328,11 +412,65 @@ func normalizeMetaTypeKey(name string) string {
 	return strings.Join(parts, ".")
 }
 
-func actionUsageTemplate(description string, params []string) string {
-	sort.Strings(params)
+// actionExample builds a copy-pasteable invocation that sets every required
+// parameter to a placeholder for its type. A nested parameter is skipped when
+// one of its declared parents is optional, and list indexes (N) become 1.
+func actionExample(serviceName, action string, params []param, apiMeta *ApiMeta) string {
+	var flags []string
+	for _, p := range params {
+		if !p.required || apiMeta == nil {
+			continue
+		}
+		segments := strings.Split(p.key, ".")
+		required := true
+		for i := 1; i < len(segments) && required; i++ {
+			prefix := strings.Join(segments[:i], ".")
+			if segments[i-1] != "N" && strings.TrimSpace(apiMeta.GetReqTypeName(prefix)) != "" {
+				required = apiMeta.GetReqRequired(prefix)
+			}
+		}
+		if !required {
+			continue
+		}
+		for i, seg := range segments {
+			if seg == "N" {
+				segments[i] = "1"
+			}
+		}
+		flags = append(flags, fmt.Sprintf("--%s %s", strings.Join(segments, "."), paramPlaceholder(p)))
+	}
+	sort.Strings(flags)
+
+	example := "  bp " + serviceName + " " + action
+	if len(flags) > 0 {
+		example += " " + strings.Join(flags, " ")
+	}
+	return example
+}
+
+// paramPlaceholder returns "<type>" for p; the element type is used for list
+// items such as Names.N of type array[string].
+func paramPlaceholder(p param) string {
+	typeName := strings.TrimSpace(p.typeName)
+	if strings.HasSuffix(p.key, ".N") && strings.HasPrefix(typeName, "array[") && strings.HasSuffix(typeName, "]") {
+		typeName = typeName[len("array[") : len(typeName)-1]
+	}
+	if typeName == "" {
+		typeName = "value"
+	}
+	return "<" + typeName + ">"
+}
 
-	for i := 0; i < len(params); i++ {
-		params[i] = "  --" + params[i]
+// actionUsageTemplate renders the action help. Required and optional
+// parameters are listed in separate sections, each sorted by name; when the
+// metadata marks nothing as required a single parameter list is shown.
+func actionUsageTemplate(description string, required, optional []string) string {
+	params := "Available Parameters:\n" + formatParamLines(optional)
+	if len(required) > 0 {
+		params = "Required Parameters:\n" + formatParamLines(required)
+		if len(optional) > 0 {
+			params += "\n\nOptional Parameters:\n" + formatParamLines(optional)
+		}
 	}
 
 	description = strings.TrimSpace(description)
@@ -346,13 +484,69 @@ func actionUsageTemplate(description string, params []string) string {
 Examples:
 {{.Example}}{{end}}
 
-Available Parameters:
 %s
 
+Use "{{.CommandPath}} --help-tree" to show the parameters as a nested tree.
+
 Fixed Flags:
   ---profile string    Use a configured profile only for this invocation.
   ---region string     Override the region only for this invocation.
   ---endpoint string   Override the endpoint only for this invocation.
+  ---output string     Output format: json (default), json-compact, table or text.
+  ---paginate          Fetch all pages of a list response.
+  ---protocol string   Request protocol: query or json (default from metadata).
+  ---fields string     Comma-separated columns for table or text output, e.g. InstanceId,Status.
+  ---count             Print only the number of list elements (across all pages with ---paginate).
+  ---jq string         Filter the JSON response with a jq expression, e.g. .Result.Instances[].InstanceId.
+  ---output-template string
+                       Render the response Result with a Go text/template instead of an output format.
+  ---output-file string
+                       Also write the complete result to a file, e.g. to keep JSON while showing a table.
+  ---output-file-format string
+                       Format of ---output-file: json (default), json-compact, table or text.
+  ---post-result string
+                       POST the JSON result (filtered by ---jq if given) to a URL after the call succeeds.
+  ---post-result-header string
+                       Add a 'Key: Value' header to the ---post-result request; repeatable.
+  ---output-raw string Save the raw response body to a file or directory instead of decoding it as JSON.
+  ---created-after string
+                       Keep only list elements created at or after an RFC3339 time, a date or a duration ago (e.g. 7d).
+  ---created-before string
+                       Keep only list elements created before an RFC3339 time, a date or a duration ago.
+  ---time-field string Timestamp field used by ---created-after/---created-before (default CreatedAt, CreationTime, CreateTime or CreatedTime).
+  ---sort-by string    Sort list elements by a field before output, e.g. CreatedAt or Placement.ZoneId.
+  ---reverse           Sort in descending order; requires ---sort-by.
+  ---all-regions       Call a read action in every region listed under "regions" in the config file and merge the results.
+  ---fail-on-partial   Exit with an error when a successful response reports failed items.
+  ---validate-response Warn on stderr when the response Result does not match the API metadata.
+  ---verbose           Print the resolved service, region and endpoint to stderr before each call.
+  ---no-config         Ignore the config file and take credentials only from environment variables.
+  ---credential-source string
+                       Take credentials only from the profile or only from environment variables: profile, env or auto (default).
+  ---insecure-skip-verify
+                       Skip TLS certificate verification for this call; only for test endpoints.
+  ---connect-timeout string
+                       Timeout for connecting to the endpoint, e.g. 5s (default 30s); reading the response is not limited.
+  ---tls-handshake-timeout string
+                       Timeout for the TLS handshake, e.g. 5s (default 10s).
+  ---header string     Add a 'Key: Value' header to the request, e.g. a trace ID; repeatable.
+  ---proxy string      Send this call through a proxy URL, overriding the profile proxy and HTTP(S)_PROXY.
+  ---dump-canonical-request
+                       Print the canonical request and string to sign of each attempt to stderr, to debug signature errors.
+  ---pager             Show the output through $PAGER (default less) when stdout is a terminal.
+  ---config-readonly   Never write the config file, e.g. when refreshing SSO credentials; changes stay in memory.
+  ---error-format string
+                       Print a failed call's error as text (default) or a JSON object with Code, StatusCode and RequestId.
+  ---compact-errors    Print only the top-level context and root cause of a failed call's error; ---verbose shows the full chain.
+
+`, description, params)
+}
 
-`, description, strings.Join(params, "\n"))
+func formatParamLines(params []string) string {
+	lines := append([]string(nil), params...)
+	sort.Strings(lines)
+	for i := range lines {
+		lines[i] = "  --" + lines[i]
+	}
+	return strings.Join(lines, "\n")
 }
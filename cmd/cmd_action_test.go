@@ -123,3 +123,39 @@ func TestIsStringParam(t *testing.T) {
 		})
 	}
 }
+
+func TestActionExampleUsesRequiredParams(t *testing.T) {
+	apiMeta := &ApiMeta{
+		Request: &Meta{
+			MetaTypes: map[string]*MetaType{
+				"ZoneId":      {TypeName: "string", Required: true},
+				"Count":       {TypeName: "integer", Required: true},
+				"Description": {TypeName: "string"},
+				"Placement":   {TypeName: "object"},
+				"Volumes":     {TypeName: "array", Required: true},
+			},
+			ChildMetas: map[string]*Meta{
+				"Placement": {MetaTypes: map[string]*MetaType{"HostId": {TypeName: "string", Required: true}}},
+				"Volumes":   {MetaTypes: map[string]*MetaType{"Size": {TypeName: "integer", Required: true}}},
+			},
+		},
+	}
+	params := []param{
+		{key: "ZoneId", typeName: "string", required: true},
+		{key: "Count", typeName: " integer", required: true},
+		{key: "Description", typeName: "string"},
+		{key: "Placement.HostId", typeName: "string", required: true},
+		{key: "Volumes.N.Size", typeName: "integer", required: true},
+		{key: "Names.N", typeName: "array[string]", required: true},
+	}
+
+	got := actionExample("ecs", "RunInstances", params, apiMeta)
+	want := "  bp ecs RunInstances --Count <integer> --Names.1 <string> --Volumes.1.Size <integer> --ZoneId <string>"
+	if got != want {
+		t.Fatalf("actionExample() = %q, want %q", got, want)
+	}
+
+	if got := actionExample("ecs", "DescribeRegions", nil, apiMeta); got != "  bp ecs DescribeRegions" {
+		t.Fatalf("actionExample() without params = %q", got)
+	}
+}
@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(newAliasCmd())
+}
+
+// expandConfiguredAlias 用配置文件中的别名展开命令行；指定 ---no-config 或 BYTEPLUS_IGNORE_CONFIG=true 时不读取别名。
+func expandConfiguredAlias(args []string) ([]string, error) {
+	cfg := runtimeConfig()
+	if cfg == nil || len(cfg.Aliases) == 0 {
+		return args, nil
+	}
+	if ignore, _ := strconv.ParseBool(strings.TrimSpace(os.Getenv(ignoreConfigEnv))); ignore {
+		return args, nil
+	}
+	for _, a := range args {
+		if a == "---no-config" {
+			return args, nil
+		}
+	}
+	return expandAlias(args, cfg.Aliases)
+}
+
+// expandAlias 在第一个参数是别名时把它替换为别名的展开，其余参数追加在展开之后；
+// 展开结果的第一个参数仍是别名时继续展开，形成循环时报错。内置命令与服务名总是优先于同名别名。
+func expandAlias(args []string, aliases map[string]string) ([]string, error) {
+	var chain []string
+	for len(args) > 0 {
+		name := args[0]
+		expansion, ok := aliases[name]
+		if !ok || isBuiltinCommandName(name) {
+			break
+		}
+		for _, seen := range chain {
+			if seen == name {
+				return nil, fmt.Errorf("alias %q is recursive: %s -> %s", chain[0], strings.Join(chain, " -> "), name)
+			}
+		}
+		chain = append(chain, name)
+		words, err := splitShellWords(expansion)
+		if err != nil {
+			return nil, fmt.Errorf("invalid alias %q: %w", name, err)
+		}
+		if len(words) == 0 {
+			return nil, fmt.Errorf("invalid alias %q: the expansion is empty", name)
+		}
+		args = append(words, args[1:]...)
+	}
+	return args, nil
+}
+
+// isBuiltinCommandName 判断 name 是否为内置命令或服务名，这些名字不能作为别名。
+func isBuiltinCommandName(name string) bool {
+	return isRootCommandName(name) || rootSupport.IsValidSvc(name)
+}
+
+// splitShellWords 按 shell 规则拆分别名展开与 bp shell 的输入行：空白分隔参数，单引号内原样保留，
+// 双引号内与引号外的反斜杠转义下一个字符。
+func splitShellWords(s string) ([]string, error) {
+	var (
+		words   []string
+		current strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+	for _, r := range s {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\\':
+			escaped, inWord = true, true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case unicode.IsSpace(r):
+			if inWord {
+				words = append(words, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash")
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inWord {
+		words = append(words, current.String())
+	}
+	return words, nil
+}
+
+// setAlias 校验并保存别名：别名不能含空白、不能以 - 开头、不能与内置命令或服务同名，展开后不能形成循环。
+func setAlias(name, expansion string) error {
+	if name == "" || strings.HasPrefix(name, "-") || strings.IndexFunc(name, unicode.IsSpace) >= 0 {
+		return fmt.Errorf("invalid alias name %q: it must not be empty, start with - or contain spaces", name)
+	}
+	if isBuiltinCommandName(name) {
+		return fmt.Errorf("%q is a built-in command or service and cannot be used as an alias", name)
+	}
+	cfg := runtimeConfig()
+	if cfg == nil {
+		cfg = &Configure{Profiles: map[string]*Profile{}}
+	}
+	aliases := make(map[string]string, len(cfg.Aliases)+1)
+	for k, v := range cfg.Aliases {
+		aliases[k] = v
+	}
+	aliases[name] = expansion
+	if _, err := expandAlias([]string{name}, aliases); err != nil {
+		return err
+	}
+	cfg.Aliases = aliases
+	if err := WriteConfigToFile(cfg); err != nil {
+		return err
+	}
+	setRuntimeConfig(cfg)
+	return nil
+}
+
+func deleteAlias(name string) error {
+	cfg := runtimeConfig()
+	if cfg == nil {
+		return fmt.Errorf("alias %q not found", name)
+	}
+	if _, ok := cfg.Aliases[name]; !ok {
+		return fmt.Errorf("alias %q not found", name)
+	}
+	delete(cfg.Aliases, name)
+	if err := WriteConfigToFile(cfg); err != nil {
+		return err
+	}
+	setRuntimeConfig(cfg)
+	return nil
+}
+
+func newAliasCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "alias",
+		Short: "Manage command aliases",
+		Long: `Manage command aliases stored in the config file.
+When the first argument of a command line is an alias, it is replaced by the alias
+expansion and the remaining arguments are appended. Built-in commands and service
+names always take precedence over aliases.`,
+		Example: `  # Define an alias
+  bp alias set myrun "ecs RunInstances ---region ap-southeast-1"
+  # Use it; further arguments are appended to the expansion
+  bp myrun --ImageId image-xxx
+  # Show and remove aliases
+  bp alias list
+  bp alias delete myrun`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Usage()
+		},
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "set NAME EXPANSION",
+		Short: "Define or replace an alias; quote the expansion as one argument",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := setAlias(args[0], args[1]); err != nil {
+				return err
+			}
+			fmt.Fprintf(statusWriter(cmd.OutOrStdout()), "Alias %s set.\n", args[0])
+			return nil
+		},
+	}, &cobra.Command{
+		Use:   "list",
+		Short: "List the configured aliases",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var aliases map[string]string
+			if cfg := runtimeConfig(); cfg != nil {
+				aliases = cfg.Aliases
+			}
+			if len(aliases) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "No aliases configured.")
+				return nil
+			}
+			names := make([]string, 0, len(aliases))
+			for name := range aliases {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s = %s\n", name, aliases[name])
+			}
+			return nil
+		},
+	}, &cobra.Command{
+		Use:   "delete NAME",
+		Short: "Remove an alias",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := deleteAlias(args[0]); err != nil {
+				return err
+			}
+			fmt.Fprintf(statusWriter(cmd.OutOrStdout()), "Alias %s deleted.\n", args[0])
+			return nil
+		},
+	})
+	return cmd
+}
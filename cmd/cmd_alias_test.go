@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitShellWords(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want []string
+	}{
+		{
+			in:   `ecs RunInstances  ---region ap-southeast-1 --Name 'my vm' --Tag "a \"b\"" x\ y`,
+			want: []string{"ecs", "RunInstances", "---region", "ap-southeast-1", "--Name", "my vm", "--Tag", `a "b"`, "x y"},
+		},
+		{
+			in:   `ecs RunInstances --body '{"Name": "a b"}' --Tag "x\"y" a\ b`,
+			want: []string{"ecs", "RunInstances", "--body", `{"Name": "a b"}`, "--Tag", `x"y`, "a b"},
+		},
+	} {
+		got, err := splitShellWords(tc.in)
+		if err != nil {
+			t.Fatalf("splitShellWords(%q) error = %v", tc.in, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("splitShellWords(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+	for _, in := range []string{`ecs "RunInstances`, `ecs --body '{"Name"`, `ecs x\`} {
+		if _, err := splitShellWords(in); err == nil {
+			t.Fatalf("splitShellWords(%q) error = nil, want an unterminated quote or escape error", in)
+		}
+	}
+}
+
+func TestExpandAlias(t *testing.T) {
+	aliases := map[string]string{
+		"myrun": "ecs RunInstances ---region ap-southeast-1",
+		"run2":  "myrun --ImageId image-1",
+		"loopa": "loopb x",
+		"loopb": "loopa y",
+		"sso":   "ecs DescribeInstances",
+	}
+
+	got, err := expandAlias([]string{"run2", "--InstanceName", "vm"}, aliases)
+	if err != nil {
+		t.Fatalf("expandAlias error = %v", err)
+	}
+	want := []string{"ecs", "RunInstances", "---region", "ap-southeast-1", "--ImageId", "image-1", "--InstanceName", "vm"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expandAlias = %q, want %q", got, want)
+	}
+
+	if got, _ := expandAlias([]string{"sso", "login"}, aliases); !reflect.DeepEqual(got, []string{"sso", "login"}) {
+		t.Fatalf("built-in command was expanded: %q", got)
+	}
+
+	_, err = expandAlias([]string{"loopa"}, aliases)
+	if err == nil || !strings.Contains(err.Error(), "loopa -> loopb -> loopa") {
+		t.Fatalf("recursive alias error = %v", err)
+	}
+}
+
+func TestAliasCommands(t *testing.T) {
+	dir := withTestConfigDir(t)
+	withTestCtxConfig(t, &Configure{Profiles: map[string]*Profile{}})
+
+	cmd := newAliasCmd()
+	out := &bytes.Buffer{}
+	cmd.SetOut(out)
+	cmd.SetArgs([]string{"set", "myrun", "ecs RunInstances ---region ap-southeast-1"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("alias set error = %v", err)
+	}
+	aliases, _ := readConfigFileAsMap(t, dir)["aliases"].(map[string]interface{})
+	if aliases["myrun"] != "ecs RunInstances ---region ap-southeast-1" {
+		t.Fatalf("config aliases = %v", aliases)
+	}
+
+	got, err := expandConfiguredAlias([]string{"myrun", "--ImageId", "image-1"})
+	if err != nil || got[0] != "ecs" || got[len(got)-1] != "image-1" {
+		t.Fatalf("expandConfiguredAlias = %q, %v", got, err)
+	}
+	if got, _ := expandConfiguredAlias([]string{"myrun", "---no-config"}); got[0] != "myrun" {
+		t.Fatalf("alias expanded with ---no-config: %q", got)
+	}
+
+	for _, args := range [][]string{
+		{"set", "configure", "ecs DescribeInstances"},
+		{"set", "-x", "ecs DescribeInstances"},
+		{"set", "self", "self ecs"},
+		{"delete", "missing"},
+	} {
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err == nil {
+			t.Fatalf("alias %q succeeded, want error", args)
+		}
+	}
+
+	out.Reset()
+	cmd.SetArgs([]string{"list"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("alias list error = %v", err)
+	}
+	if out.String() != "myrun = ecs RunInstances ---region ap-southeast-1\n" {
+		t.Fatalf("alias list output = %q", out.String())
+	}
+
+	cmd.SetArgs([]string{"delete", "myrun"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("alias delete error = %v", err)
+	}
+	if _, ok := readConfigFileAsMap(t, dir)["aliases"]; ok {
+		t.Fatal("aliases still present in the config file after deleting the last one")
+	}
+}
+
+func TestAliasCommandsQuiet(t *testing.T) {
+	withTestConfigDir(t)
+	withTestCtxConfig(t, &Configure{Profiles: map[string]*Profile{}})
+	oldQuiet := quietOutput
+	quietOutput = true
+	t.Cleanup(func() { quietOutput = oldQuiet })
+
+	cmd := newAliasCmd()
+	out := &bytes.Buffer{}
+	cmd.SetOut(out)
+	cmd.SetArgs([]string{"set", "myrun", "ecs RunInstances"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("alias set error = %v", err)
+	}
+	if out.Len() != 0 {
+		t.Fatalf("alias set --quiet printed %q", out.String())
+	}
+
+	cmd.SetArgs([]string{"list"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("alias list error = %v", err)
+	}
+	if out.String() != "myrun = ecs RunInstances\n" {
+		t.Fatalf("alias list --quiet output = %q, want the list", out.String())
+	}
+}
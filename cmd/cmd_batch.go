@@ -0,0 +1,307 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/byteplus-sdk/byteplus-cli/util"
+	"github.com/spf13/cobra"
+)
+
+// batchMaxLineSize 限制 --file 中单行的最大长度，避免超大 input 被 bufio.Scanner 截断。
+const batchMaxLineSize = 16 * 1024 * 1024
+
+func init() {
+	rootCmd.AddCommand(newBatchCmd())
+}
+
+// batchOperation 描述 --file 中的一次 action 调用，每行一个 JSON 对象。
+type batchOperation struct {
+	Service string                 `json:"service"`
+	Action  string                 `json:"action"`
+	Input   map[string]interface{} `json:"input"`
+}
+
+// batchLine 保存解析后的一行；解析失败时 err 非空，执行阶段直接记为该行的错误。
+type batchLine struct {
+	line int
+	op   *batchOperation
+	err  error
+}
+
+type batchOptions struct {
+	concurrency int
+	stopOnError bool
+}
+
+// batchExecutor 执行单个 operation 并返回响应内容。
+type batchExecutor func(op *batchOperation) (interface{}, error)
+
+func newBatchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "batch",
+		Short: "Run multiple actions described in a JSON Lines file",
+		Long: `Run multiple actions described in a JSON Lines file within a single process.
+Each non-empty line is a JSON object: {"service":"ecs","action":"DescribeInstances","input":{...}}.
+Results are printed as a JSON array in file order. Each element has the line number, service,
+action, and either the result or the error of that line.`,
+		Example: `  # Run operations sequentially
+  bp batch --file ops.jsonl
+  # Run up to 8 operations at the same time
+  bp batch --file ops.jsonl --concurrency 8
+  # Stop scheduling new operations after the first failure
+  cat ops.jsonl | bp batch --file - --stop-on-error`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, err := cmd.Flags().GetString("file")
+			if err != nil {
+				return err
+			}
+			if strings.TrimSpace(file) == "" {
+				return fmt.Errorf("--file is required")
+			}
+			concurrency, err := cmd.Flags().GetInt("concurrency")
+			if err != nil {
+				return err
+			}
+			if concurrency < 1 {
+				return fmt.Errorf("--concurrency must be at least 1")
+			}
+			stopOnError, err := cmd.Flags().GetBool("stop-on-error")
+			if err != nil {
+				return err
+			}
+
+			var reader io.Reader = cmd.InOrStdin()
+			if file != "-" {
+				f, err := os.Open(file)
+				if err != nil {
+					return fmt.Errorf("failed to open batch file: %w", err)
+				}
+				defer f.Close()
+				reader = f
+			}
+			lines, err := readBatchOperations(reader)
+			if err != nil {
+				return err
+			}
+
+			return doBatch(ctx, lines, batchOptions{concurrency: concurrency, stopOnError: stopOnError})
+		},
+	}
+
+	cmd.Flags().String("file", "", "JSON Lines file with one operation per line; use - to read from stdin")
+	cmd.Flags().Int("concurrency", 1, "Maximum number of operations executed at the same time")
+	cmd.Flags().Bool("stop-on-error", false, "Stop scheduling new operations after the first failure")
+
+	return cmd
+}
+
+// doBatch 创建一次 SDK client 并复用到所有 operation，凭证刷新只发生一次。
+func doBatch(ctx *Context, lines []batchLine, opts batchOptions) (err error) {
+	debugLog, closeDebugLog, err := prepareDebugLogger(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := closeDebugLog(); err == nil && closeErr != nil {
+			err = closeErr
+		}
+	}()
+
+	sdk, err := NewSimpleClient(ctx)
+	if err != nil {
+		debugLogError(debugLog, "client_init_error", err)
+		return err
+	}
+
+	results := runBatch(lines, opts, func(op *batchOperation) (interface{}, error) {
+		if !rootSupport.IsValidAction(op.Service, op.Action) {
+			return nil, fmt.Errorf("%s.%s is unsupport action", op.Service, op.Action)
+		}
+		info := resolveActionCallInfo(op.Service, op.Action)
+		debugLogActionStart(debugLog, op.Service, op.Action, info.Version, info.Method, info.ContentType)
+		apiMeta := rootSupport.GetApiMeta(op.Service, op.Action)
+		jsonBody := strings.ToLower(info.ContentType) == "application/json"
+		callInput, inputErr := buildBatchCallInput(op.Input, apiMeta, jsonBody)
+		if inputErr != nil {
+			debugLogError(debugLog, "input_build_error", inputErr)
+			return nil, inputErr
+		}
+		out, callErr := sdk.CallSdk(info, callInput)
+		if callErr != nil {
+			return nil, formatActionError(callErr)
+		}
+		return *out, nil
+	})
+
+	if err = util.WriteJson(actionOutputWriter, results, config != nil && config.EnableColor); err != nil {
+		return err
+	}
+	if err = commandContext.Err(); err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, r := range results {
+		if _, ok := r.(map[string]interface{})["error"]; ok {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d batch operations failed", failed, len(lines))
+	}
+	return nil
+}
+
+// buildBatchCallInput 把一行的 input 当作同名的命令行参数，经 buildActionInput 按 API 元数据转换，
+// 参数类型错误时返回与单次调用相同的提示。字符串原样作为参数值，其余值按 JSON 编码，null 视为未传。
+// body 与 cli-input-yaml 是命令行选项而不是 API 参数，出现在 input 中时直接报错，避免批量文件读取任意文件或标准输入。
+func buildBatchCallInput(input map[string]interface{}, apiMeta *ApiMeta, jsonBody bool) (interface{}, error) {
+	flags := make([]*Flag, 0, len(input))
+	for name, v := range input {
+		if name == "body" || name == cliInputYAMLFlag {
+			return nil, fmt.Errorf("input key %q is a CLI option, not an API parameter", name)
+		}
+		if v == nil {
+			continue
+		}
+		f := &Flag{Name: name}
+		if s, ok := v.(string); ok {
+			f.SetValue(s)
+		} else {
+			b, err := json.Marshal(v)
+			if err != nil {
+				return nil, fmt.Errorf("parameter %q: %w", name, err)
+			}
+			f.SetValue(string(b))
+		}
+		flags = append(flags, f)
+	}
+
+	callInput, inputFromBody, err := buildActionInput(flags, apiMeta, jsonBody)
+	if err != nil {
+		return nil, err
+	}
+	if jsonBody && inputFromBody {
+		return callInput, nil
+	}
+	inputMap, _ := callInput.(map[string]interface{})
+	return &inputMap, nil
+}
+
+// readBatchOperations 按行解析 JSON Lines，空行跳过。
+// 单行格式错误不会中断解析，而是作为该行的执行错误返回，保证输出与文件行号一一对应。
+func readBatchOperations(r io.Reader) ([]batchLine, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), batchMaxLineSize)
+
+	var lines []batchLine
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		op := &batchOperation{}
+		if err := json.Unmarshal([]byte(text), op); err != nil {
+			lines = append(lines, batchLine{line: lineNo, err: fmt.Errorf("invalid operation: %w", err)})
+			continue
+		}
+		if strings.TrimSpace(op.Service) == "" || strings.TrimSpace(op.Action) == "" {
+			lines = append(lines, batchLine{line: lineNo, op: op, err: fmt.Errorf("invalid operation: service and action are required")})
+			continue
+		}
+		lines = append(lines, batchLine{line: lineNo, op: op})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read batch file: %w", err)
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("batch file contains no operations")
+	}
+	return lines, nil
+}
+
+// runBatch 以最多 opts.concurrency 个 worker 执行 operation，结果按文件顺序返回。
+// 开启 stopOnError 时，首个失败后不再调度新的 operation；已在执行中的 operation 仍会完成，
+// 未执行的行不会出现在结果中。Ctrl-C 取消 commandContext 后同样不再调度。
+func runBatch(lines []batchLine, opts batchOptions, exec batchExecutor) []interface{} {
+	concurrency := opts.concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]map[string]interface{}, len(lines))
+	var (
+		mu      sync.Mutex
+		stopped bool
+		wg      sync.WaitGroup
+	)
+	jobs := make(chan int)
+
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				// 派发与失败标记之间存在竞争，worker 取到任务后再确认一次是否已停止。
+				mu.Lock()
+				skip := stopped || commandContext.Err() != nil
+				mu.Unlock()
+				if skip {
+					continue
+				}
+				l := lines[i]
+				result := map[string]interface{}{"line": l.line}
+				if l.op != nil {
+					result["service"] = l.op.Service
+					result["action"] = l.op.Action
+				}
+				err := l.err
+				if err == nil {
+					var out interface{}
+					out, err = exec(l.op)
+					if err == nil {
+						result["result"] = out
+					}
+				}
+				if err != nil {
+					result["error"] = err.Error()
+				}
+
+				mu.Lock()
+				results[i] = result
+				if err != nil && opts.stopOnError {
+					stopped = true
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for i := range lines {
+		mu.Lock()
+		stop := stopped || commandContext.Err() != nil
+		mu.Unlock()
+		if stop {
+			break
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	ordered := make([]interface{}, 0, len(results))
+	for _, r := range results {
+		if r != nil {
+			ordered = append(ordered, r)
+		}
+	}
+	return ordered
+}
@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestReadBatchOperationsKeepsLineNumbersAndParseErrors(t *testing.T) {
+	input := strings.Join([]string{
+		`{"service":"ecs","action":"DescribeInstances","input":{"MaxResults":10}}`,
+		``,
+		`not-json`,
+		`{"service":"ecs"}`,
+	}, "\n")
+
+	lines, err := readBatchOperations(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("readBatchOperations returned error: %v", err)
+	}
+	if len(lines) != 3 {
+		t.Fatalf("len(lines) = %d, want 3", len(lines))
+	}
+	if lines[0].line != 1 || lines[0].err != nil || lines[0].op.Input["MaxResults"] != float64(10) {
+		t.Fatalf("unexpected first line: %+v", lines[0])
+	}
+	if lines[1].line != 3 || lines[1].err == nil {
+		t.Fatalf("line 3 should carry a parse error: %+v", lines[1])
+	}
+	if lines[2].line != 4 || lines[2].err == nil || !strings.Contains(lines[2].err.Error(), "service and action are required") {
+		t.Fatalf("line 4 should require action: %+v", lines[2])
+	}
+}
+
+func TestReadBatchOperationsRejectsEmptyFile(t *testing.T) {
+	if _, err := readBatchOperations(strings.NewReader("\n\n")); err == nil {
+		t.Fatal("expected error for empty batch file")
+	}
+}
+
+func batchLinesForTest(n int) []batchLine {
+	lines := make([]batchLine, n)
+	for i := range lines {
+		lines[i] = batchLine{line: i + 1, op: &batchOperation{Service: "ecs", Action: "DescribeInstances"}}
+	}
+	return lines
+}
+
+func TestRunBatchPreservesOrderWithConcurrency(t *testing.T) {
+	lines := batchLinesForTest(20)
+	var calls int32
+	results := runBatch(lines, batchOptions{concurrency: 4}, func(op *batchOperation) (interface{}, error) {
+		n := atomic.AddInt32(&calls, 1)
+		return map[string]interface{}{"call": n}, nil
+	})
+
+	if len(results) != 20 {
+		t.Fatalf("len(results) = %d, want 20", len(results))
+	}
+	for i, r := range results {
+		m := r.(map[string]interface{})
+		if m["line"] != i+1 {
+			t.Fatalf("results[%d].line = %v, want %d", i, m["line"], i+1)
+		}
+		if _, ok := m["result"]; !ok {
+			t.Fatalf("results[%d] missing result: %v", i, m)
+		}
+	}
+}
+
+func TestRunBatchContinuesAfterErrorByDefault(t *testing.T) {
+	lines := batchLinesForTest(3)
+	lines[1].err = errors.New("invalid operation")
+	results := runBatch(lines, batchOptions{concurrency: 1}, func(op *batchOperation) (interface{}, error) {
+		return map[string]interface{}{}, nil
+	})
+
+	if len(results) != 3 {
+		t.Fatalf("len(results) = %d, want 3", len(results))
+	}
+	if results[1].(map[string]interface{})["error"] != "invalid operation" {
+		t.Fatalf("results[1] = %v, want invalid operation error", results[1])
+	}
+}
+
+func TestRunBatchStopOnErrorSkipsRemainingOperations(t *testing.T) {
+	lines := batchLinesForTest(5)
+	var calls int32
+	results := runBatch(lines, batchOptions{concurrency: 1, stopOnError: true}, func(op *batchOperation) (interface{}, error) {
+		if atomic.AddInt32(&calls, 1) == 2 {
+			return nil, errors.New("boom")
+		}
+		return map[string]interface{}{}, nil
+	})
+
+	if len(results) != 2 {
+		t.Fatalf("len(results) = %d, want 2", len(results))
+	}
+	if results[1].(map[string]interface{})["error"] != "boom" {
+		t.Fatalf("results[1] = %v, want boom", results[1])
+	}
+	if calls != 2 {
+		t.Fatalf("calls = %d, want 2", calls)
+	}
+}
+
+func TestRunBatchStopsSchedulingAfterInterrupt(t *testing.T) {
+	oldCtx := commandContext
+	defer func() { commandContext = oldCtx }()
+	c, cancel := context.WithCancel(context.Background())
+	commandContext = c
+
+	lines := batchLinesForTest(5)
+	var calls int32
+	results := runBatch(lines, batchOptions{concurrency: 1}, func(op *batchOperation) (interface{}, error) {
+		if atomic.AddInt32(&calls, 1) == 2 {
+			cancel()
+		}
+		return map[string]interface{}{}, nil
+	})
+
+	if len(results) != 2 || calls != 2 {
+		t.Fatalf("results = %d, calls = %d, want 2 and 2", len(results), calls)
+	}
+}
+
+func TestDoBatchValidatesInputLikeSingleCall(t *testing.T) {
+	defer disableProxyEnvForTest(t)()
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ResponseMetadata":{"RequestId":"req"},"Result":{"Widgets":[]}}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	writeMetaFileForTest(t, dir, "structure/demo/2024-01-01/structure.json",
+		`{"PkgName":"demo","ServiceName":"demo","Version":"2024-01-01"}`)
+	writeMetaFileForTest(t, dir, "metadata/demo/2024-01-01/metadata.json",
+		`{"ListWidgets":{"ApiInfo":{"Method":"POST","ContentType":"application/json"}}}`)
+	writeMetaFileForTest(t, dir, "metatype/demo/2024-01-01/meta_type.json",
+		`{"ListWidgets":{"Request":{"MetaTypes":{"MaxResults":{"TypeName":"integer"}}}}}`)
+	t.Setenv(metaDirEnv, dir)
+	defer delete(serviceMapping, "demo")
+	prevSupport := rootSupport
+	rootSupport = NewRootSupport()
+	defer func() { rootSupport = prevSupport }()
+
+	defer setenvForTest(t, "BYTEPLUS_ACCESS_KEY", "ak-test")()
+	defer setenvForTest(t, "BYTEPLUS_SECRET_KEY", "sk-test")()
+	defer setenvForTest(t, "BYTEPLUS_REGION", "ap-southeast-1")()
+
+	var out bytes.Buffer
+	prevWriter := actionOutputWriter
+	actionOutputWriter = &out
+	defer func() { actionOutputWriter = prevWriter }()
+
+	testCtx := NewContext()
+	if _, err := NewParser([]string{"---endpoint", server.URL}).ReadArgs(testCtx); err != nil {
+		t.Fatalf("ReadArgs() error = %v", err)
+	}
+	lines, err := readBatchOperations(strings.NewReader(strings.Join([]string{
+		`{"service":"demo","action":"ListWidgets","input":{"MaxResults":10}}`,
+		`{"service":"demo","action":"ListWidgets","input":{"MaxResults":"ten"}}`,
+	}, "\n")))
+	if err != nil {
+		t.Fatalf("readBatchOperations() error = %v", err)
+	}
+
+	err = doBatch(testCtx, lines, batchOptions{concurrency: 1})
+	if err == nil || !strings.Contains(err.Error(), "1 of 2 batch operations failed") {
+		t.Fatalf("doBatch() error = %v, want one failed operation", err)
+	}
+	if atomic.LoadInt32(&requests) != 1 {
+		t.Fatalf("requests = %d, want only the valid line sent", requests)
+	}
+	var results []map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &results); err != nil {
+		t.Fatalf("output is not a JSON array: %v\n%s", err, out.String())
+	}
+	if len(results) != 2 || results[0]["result"] == nil {
+		t.Fatalf("results = %v, want the first line to succeed", results)
+	}
+	if want := `parameter "MaxResults": expected integer, got "ten"`; results[1]["error"] != want {
+		t.Fatalf("results[1].error = %v, want %q", results[1]["error"], want)
+	}
+}
+
+func TestBuildBatchCallInputRejectsCliOptions(t *testing.T) {
+	for _, name := range []string{"body", cliInputYAMLFlag} {
+		_, err := buildBatchCallInput(map[string]interface{}{name: "-"}, nil, true)
+		if err == nil || !strings.Contains(err.Error(), "is a CLI option") {
+			t.Fatalf("buildBatchCallInput(%s) error = %v, want CLI option error", name, err)
+		}
+	}
+}
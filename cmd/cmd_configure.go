@@ -18,6 +18,7 @@ package cmd
 
 import (
 	"bufio"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -50,11 +51,15 @@ func init() {
 
 	configureCmd.AddCommand(newConfigureGetCmd())
 	configureCmd.AddCommand(newConfigureListCmd())
+	configureCmd.AddCommand(newConfigureDiffCmd())
 	configureCmd.AddCommand(newConfigureDeleteCmd())
 	configureCmd.AddCommand(newConfigureProfileCmd())
 	configureCmd.AddCommand(newConfigureSetCmd())
+	configureCmd.AddCommand(newConfigureSetRegionCmd())
+	configureCmd.AddCommand(newConfigureSetEndpointCmd())
 	configureCmd.AddCommand(newConfigureSsoSessionCmd())
 	configureCmd.AddCommand(newConfigureSsoCmd())
+	configureCmd.AddCommand(newConfigureWhoamiCmd())
 
 	rootCmd.AddCommand(configureCmd)
 }
@@ -93,6 +98,8 @@ func newConfigureGetCmd() *cobra.Command {
 	cmd.Flags().StringVar(&profileFlags.Name, "profile", "", "target profile name")
 	cmd.Flags().BoolP("help", "h", false, "")
 
+	registerConfigNameCompletions(cmd)
+
 	return cmd
 }
 
@@ -107,6 +114,15 @@ func newConfigureSetCmd() *cobra.Command {
 			if !cmd.Flags().Changed("use-dual-stack") {
 				input.UseDualStack = nil
 			}
+			if !cmd.Flags().Changed("insecure-skip-verify") {
+				input.InsecureSkipVerify = nil
+			}
+			if sso, _ := cmd.Flags().GetBool("sso"); sso {
+				if input.Mode != "" && strings.ToLower(strings.TrimSpace(input.Mode)) != ModeSSO {
+					return fmt.Errorf("--sso cannot be combined with --mode %s", input.Mode)
+				}
+				input.Mode = ModeSSO
+			}
 			return setConfigProfile(&input)
 		},
 		Short: "add new profile, or modify target profile",
@@ -121,7 +137,12 @@ Examples:
   bp configure set --profile test --region ap-southeast-1 --access-key ak --secret-key sk
   bp configure set --profile test-ram --mode ramrolearn --region ap-southeast-1 --access-key ak --secret-key sk --role-name YourRoleName --account-id 2100000000
   bp configure set --profile test-oidc --mode oidc --region ap-southeast-1 --oidc-token-file /path/to/oidc/token --role-trn trn:iam::2100000000:role/YourRoleName
-  bp configure set --profile test-ecs --mode ecsrole --region ap-southeast-1 --role-name YourEcsRoleName`,
+  bp configure set --profile test-ecs --mode ecsrole --region ap-southeast-1 --role-name YourEcsRoleName
+  bp configure set --profile test-sso --sso --sso-session my-sso --account-id 2100000000 --role-name YourRoleName
+  bp configure set --profile test-sso --fallback-roles ReadOnlyRole,AuditRole
+  bp configure set --profile test-broker --region ap-southeast-1 --credential-process "/path/to/broker --account dev"
+  bp configure set --profile test --extra output=table --extra paginate=true
+  bp configure set --profile prod --proxy http://proxy.example.com:3128 --no-proxy .internal.example.com`,
 		DisableFlagsInUseLine: true,
 	}
 
@@ -133,30 +154,110 @@ Examples:
 	cmd.Flags().StringVar(&profileFlags.SecretKey, "secret-key", "", "your secret key(SK)")
 	cmd.Flags().StringVar(&profileFlags.Region, "region", "", "your region")
 	cmd.Flags().StringVar(&profileFlags.Endpoint, "endpoint", "", "endpoint bind with region")
-	cmd.Flags().StringVar(&profileFlags.EndpointResolver, "endpoint-resolver", "", "endpoint resolver (auto-addressing)")
+	cmd.Flags().StringVar(&profileFlags.EndpointResolver, "endpoint-resolver", "", "endpoint resolver: standard, auto or auto-addressing; derives the endpoint from service and region")
 	cmd.Flags().StringVar(&profileFlags.HTTPProxy, "http-proxy", "", "HTTP proxy URL used by the SDK when SSL is disabled")
 	cmd.Flags().StringVar(&profileFlags.HTTPSProxy, "https-proxy", "", "HTTPS proxy URL used by the SDK")
+	cmd.Flags().StringVar(&profileFlags.Proxy, "proxy", "", "proxy URL (http, https or socks5) for every API call of this profile; overrides http-proxy, https-proxy and HTTP(S)_PROXY")
+	cmd.Flags().StringVar(&profileFlags.NoProxy, "no-proxy", "", "comma-separated hosts, domains or CIDRs reached without --proxy; defaults to NO_PROXY")
 	cmd.Flags().StringVar(&profileFlags.SessionToken, "session-token", "", "your session token")
 	cmd.Flags().StringVar(&profileFlags.SsoSessionName, "sso-session", "", "your sso session name")
 	cmd.Flags().StringVar(&profileFlags.AccountId, "account-id", "", "your account id (required for ramrolearn mode)")
 	cmd.Flags().StringVar(&profileFlags.RoleName, "role-name", "", "your role name (required for ramrolearn/ecsrole mode)")
+	cmd.Flags().StringSliceVar(&profileFlags.FallbackRoles, "fallback-roles", nil, "comma-separated SSO roles tried in order when --role-name is no longer assigned; the first that works replaces role-name")
 	cmd.Flags().StringVar(&profileFlags.OidcTokenFile, "oidc-token-file", "", "path to OIDC token file (required for oidc mode)")
 	cmd.Flags().StringVar(&profileFlags.RoleTrn, "role-trn", "", "role TRN (required for oidc mode)")
+	cmd.Flags().StringVar(&profileFlags.CredentialProcess, "credential-process", "", "command that prints credentials as JSON; overrides the mode's credentials")
+	cmd.Flags().StringToStringVar(&profileFlags.Extra, "extra", nil, "default fixed flags for this profile, e.g. output=table,paginate=true; an empty value removes the key")
+	cmd.Flags().IntVar(&profileFlags.StsMinValidity, "sts-min-validity", 0, "minutes of validity SSO credentials must have left before a call; shorter-lived credentials are refreshed first")
 
-	profileFlags.DisableSSL = cmd.Flags().Bool("disable-ssl", false, "disable ssl")
+	cmd.Flags().Bool("sso", false, "shortcut for --mode sso; requires --sso-session, --account-id and --role-name, and credentials are fetched on first use")
+	profileFlags.DisableSSL = cmd.Flags().Bool("disable-ssl", false, "use plaintext HTTP instead of HTTPS; does not affect certificate verification")
 	profileFlags.UseDualStack = cmd.Flags().Bool("use-dual-stack", false, "use dual-stack endpoints")
+	profileFlags.InsecureSkipVerify = cmd.Flags().Bool("insecure-skip-verify", false, "skip TLS certificate verification of HTTPS endpoints; only for test endpoints with self-signed certificates")
+	cmd.Flags().BoolP("help", "h", false, "")
+
+	cmd.MarkFlagRequired("profile")
+
+	registerConfigNameCompletions(cmd)
+
+	return cmd
+}
+
+func newConfigureSetRegionCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use: "set-region",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return updateExistingProfile(&Profile{Name: profileFlags.Name, Region: profileFlags.Region})
+		},
+		Short: "change the region of an existing profile",
+		Long: `Description:
+  change only the region of an existing profile, keeping every other field
+
+Examples:
+  bp configure set-region --profile test --region ap-southeast-1`,
+		DisableFlagsInUseLine: true,
+	}
+
+	cmd.SetUsageTemplate(configureActionUsageTemplate())
+
+	cmd.Flags().StringVar(&profileFlags.Name, "profile", "", "target profile name")
+	cmd.Flags().StringVar(&profileFlags.Region, "region", "", "new region")
+	cmd.Flags().BoolP("help", "h", false, "")
+
+	cmd.MarkFlagRequired("profile")
+	cmd.MarkFlagRequired("region")
+
+	registerConfigNameCompletions(cmd)
+
+	return cmd
+}
+
+func newConfigureSetEndpointCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use: "set-endpoint",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return updateExistingProfile(&Profile{Name: profileFlags.Name, Endpoint: profileFlags.Endpoint})
+		},
+		Short: "change the endpoint of an existing profile",
+		Long: `Description:
+  change only the endpoint of an existing profile, keeping every other field
+
+Examples:
+  bp configure set-endpoint --profile test --endpoint ecs.ap-southeast-1.byteplusapi.com`,
+		DisableFlagsInUseLine: true,
+	}
+
+	cmd.SetUsageTemplate(configureActionUsageTemplate())
+
+	cmd.Flags().StringVar(&profileFlags.Name, "profile", "", "target profile name")
+	cmd.Flags().StringVar(&profileFlags.Endpoint, "endpoint", "", "new endpoint")
 	cmd.Flags().BoolP("help", "h", false, "")
 
 	cmd.MarkFlagRequired("profile")
+	cmd.MarkFlagRequired("endpoint")
+
+	registerConfigNameCompletions(cmd)
 
 	return cmd
 }
 
+// updateExistingProfile 通过 setConfigProfile 更新已有 profile 的单个字段；profile 不存在时报错而不是新建。
+func updateExistingProfile(input *Profile) error {
+	if ctx.config == nil || ctx.config.Profiles[input.Name] == nil {
+		return fmt.Errorf("profile %q not found", input.Name)
+	}
+	return setConfigProfile(input)
+}
+
 // validateProfileMode 校验 profile 的 mode 及其必填参数
 func validateProfileMode(profile *Profile) error {
 	mode := strings.ToLower(strings.TrimSpace(profile.Mode))
 	switch mode {
 	case "", ModeAK:
+		// 配置了外部凭证命令时 AK/SK 由命令提供
+		if profile.CredentialProcess != "" {
+			return nil
+		}
 		if profile.AccessKey == "" {
 			return fmt.Errorf("mode %q requires --access-key", ModeAK)
 		}
@@ -164,7 +265,7 @@ func validateProfileMode(profile *Profile) error {
 			return fmt.Errorf("mode %q requires --secret-key", ModeAK)
 		}
 	case ModeSSO:
-		// sso 模式通过 configure sso 子命令配置，此处不额外校验
+		// sso 模式的 sso-session、账号与角色由 setConfigProfile 中的 validateSsoProfileBinding 校验
 	case ModeConsoleLogin:
 		if profile.LoginSession == "" {
 			return fmt.Errorf("mode %q requires login-session; run 'bp login' first", ModeConsoleLogin)
@@ -218,25 +319,64 @@ func newConfigureListCmd() *cobra.Command {
 	return cmd
 }
 
+func newConfigureDiffCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use: "diff",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profiles, _ := cmd.Flags().GetStringArray("profile")
+			if len(profiles) != 2 {
+				return fmt.Errorf("specify exactly two profiles with --profile A --profile B")
+			}
+			includeSecrets, _ := cmd.Flags().GetBool("include-secrets")
+			return diffConfigProfiles(cmd.OutOrStdout(), profiles[0], profiles[1], includeSecrets)
+		},
+		Short: "compare two profiles field by field",
+		Long: `Description:
+  compare two profiles field by field, e.g. bp configure diff --profile dev --profile prod
+  fields that differ are shown as "-" (first profile) and "+" (second profile) lines
+  access-key, secret-key and session-token are masked unless --include-secrets is set`,
+		DisableFlagsInUseLine: true,
+	}
+
+	cmd.SetUsageTemplate(configureActionUsageTemplate())
+
+	cmd.Flags().StringArray("profile", nil, "profile to compare, given twice")
+	cmd.Flags().Bool("include-secrets", false, "show access-key, secret-key and session-token in full")
+	cmd.Flags().BoolP("help", "h", false, "")
+
+	registerConfigNameCompletions(cmd)
+
+	return cmd
+}
+
 func newConfigureDeleteCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use: "delete",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			profileName := cmd.Flag("profile").Value.String()
+			sessionName := strings.TrimSpace(cmd.Flag("sso-session").Value.String())
+			if (profileName == "") == (sessionName == "") {
+				return fmt.Errorf("specify exactly one of --profile or --sso-session")
+			}
+			if sessionName != "" {
+				return deleteConfigSsoSession(cmd.ErrOrStderr(), sessionName)
+			}
 			return deleteConfigProfile(profileName)
 		},
-		Short: "delete target profile",
+		Short: "delete target profile or sso-session",
 		Long: `Description:
-  delete target profile`,
+  delete target profile, or delete target SSO session with --sso-session.
+  SSO profiles bound to a deleted SSO session are kept and reported.`,
 		DisableFlagsInUseLine: true,
 	}
 
 	cmd.SetUsageTemplate(configureActionUsageTemplate())
 
 	cmd.Flags().StringVar(&profileFlags.Name, "profile", "", "target profile name")
+	cmd.Flags().String("sso-session", "", "target SSO session name")
 	cmd.Flags().BoolP("help", "h", false, "")
 
-	cmd.MarkFlagRequired("profile")
+	registerConfigNameCompletions(cmd)
 
 	return cmd
 }
@@ -261,6 +401,8 @@ func newConfigureProfileCmd() *cobra.Command {
 
 	cmd.MarkFlagRequired("profile")
 
+	registerConfigNameCompletions(cmd)
+
 	return cmd
 }
 
@@ -327,12 +469,16 @@ func newConfigureSsoSessionCmd() *cobra.Command {
 				return err
 			}
 			ssoSessionFlags.RegistrationScopes = scopes
+			ssoSessionFlags.CredentialSource = strings.TrimSpace(ssoSessionFlags.CredentialSource)
+			if ssoSessionFlags.CredentialSource == "" && existingSession != nil {
+				ssoSessionFlags.CredentialSource = existingSession.CredentialSource
+			}
 
 			// 将 SSO 会话落盘到配置文件。
 			if err := setSsoSession(&ssoSessionFlags); err != nil {
 				return err
 			}
-			fmt.Printf("SSO session [%s] configured successfully.\n", ssoSessionFlags.Name)
+			fmt.Fprintf(statusWriter(os.Stdout), "SSO session [%s] configured successfully.\n", ssoSessionFlags.Name)
 			return nil
 		},
 		Short: "add or modify SSO session",
@@ -342,7 +488,8 @@ func newConfigureSsoSessionCmd() *cobra.Command {
       2. if SSO session exist, modify target field
 
 Examples:
-  bp configure sso-session --name my-sso --start-url https://{custom}.byteplusidentity.com/userportal --region ap-southeast-1`,
+  bp configure sso-session --name my-sso --start-url https://{custom}.byteplusidentity.com/userportal --region ap-southeast-1
+  bp configure sso-session --name my-sso --start-url https://{custom}.byteplusidentity.com/userportal --region ap-southeast-1 --credential-source web-identity`,
 		DisableFlagsInUseLine: true,
 	}
 
@@ -352,9 +499,14 @@ Examples:
 	cmd.Flags().StringVar(&ssoSessionFlags.Name, "name", "", "SSO session name")
 	cmd.Flags().StringVar(&ssoSessionFlags.StartURL, "start-url", "", "SSO start URL")
 	cmd.Flags().StringVar(&ssoSessionFlags.Region, "region", "", "SSO region")
+	cmd.Flags().StringVar(&ssoSessionFlags.CredentialSource, "credential-source", "", "how role credentials are obtained (portal, web-identity)")
 	cmd.Flags().StringSliceVar(&ssoSessionFlags.RegistrationScopes, "registration-scopes", nil, "comma-separated SSO registration scopes (cloudidentity:account:access,offline_access)")
 	cmd.Flags().BoolP("help", "h", false, "")
 
+	_ = cmd.RegisterFlagCompletionFunc("name", completeSsoSessionNames)
+
+	cmd.AddCommand(newConfigureSsoSessionDedupeCmd())
+
 	return cmd
 }
 
@@ -465,6 +617,26 @@ func normalizeRegistrationScopes(scopes []string) ([]string, error) {
 	return normalized, nil
 }
 
+// configureSsoResult 是 configure sso --json 输出的配置结果，供脚本获取新 profile 对应的账号与角色。
+type configureSsoResult struct {
+	AccountID string `json:"accountId"`
+	RoleName  string `json:"roleName"`
+	Profile   string `json:"profile"`
+}
+
+func writeConfigureSsoResult(profile *Profile) error {
+	data, err := json.Marshal(configureSsoResult{
+		AccountID: profile.AccountId,
+		RoleName:  profile.RoleName,
+		Profile:   profile.Name,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(ssoCommandResultOut, string(data))
+	return err
+}
+
 // newConfigureSsoCmd 构建 `configure sso` 子命令。
 // 该命令会关联 SSO 会话，执行 SSO 授权流程并最终写入 SSO 类型的 profile 配置。
 func newConfigureSsoCmd() *cobra.Command {
@@ -492,10 +664,19 @@ func newConfigureSsoCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			verbose, err := cmd.Flags().GetBool("verbose")
+			if err != nil {
+				return err
+			}
+			jsonOutput, err := cmd.Flags().GetBool("json")
+			if err != nil {
+				return err
+			}
+			statusOut := ssoStatusOut(jsonOutput)
 
 			// 读取 profile 名称：未输入时允许回车留空，稍后由 SSO 信息回填默认值。
 			if strings.TrimSpace(ssoFlags.Name) == "" {
-				fmt.Print("Enter profile name (press Enter to use default: {sso-role-name}-{sso-account-id}): ")
+				fmt.Fprint(ssoMessageOut(jsonOutput), "Enter profile name (press Enter to use default: {sso-role-name}-{sso-account-id}): ")
 				line, err := readLineAllowEmpty()
 				if err != nil {
 					return err
@@ -510,6 +691,11 @@ func newConfigureSsoCmd() *cobra.Command {
 			if inputProfile := cfg.Profiles[ssoFlags.Name]; inputProfile != nil {
 				profile = inputProfile
 			}
+			// --profile-region 指定业务 API 的 region；未指定时保留 profile 原有 region，
+			// 新 profile 则由 SetProfile 回退为 SSO 登录区域。
+			if strings.TrimSpace(ssoFlags.Region) != "" {
+				profile.Region = strings.TrimSpace(ssoFlags.Region)
+			}
 
 			// Prompt for SSO session name with live fuzzy filtering and allow creating new.
 			var (
@@ -552,13 +738,18 @@ func newConfigureSsoCmd() *cobra.Command {
 				Scopes:         ssoSession.RegistrationScopes,
 				UseDeviceCode:  true, // 目前仅支持设备码登录流程。
 				NoBrowser:      noBrowser,
+				Verbose:        verbose,
+				MessageOut:     ssoMessageOut(jsonOutput),
 			}
 
 			// 执行 SSO 授权流程并落盘 profile 配置。
 			if err := sso.SetProfile(); err != nil {
 				return err
 			}
-			fmt.Printf("SSO profile [%s] configured successfully.\n", profile.Name)
+			fmt.Fprintf(statusOut, "SSO profile [%s] configured successfully.\n", profile.Name)
+			if jsonOutput {
+				return writeConfigureSsoResult(profile)
+			}
 			return nil
 		},
 		Short: "configure SSO type profile",
@@ -574,9 +765,14 @@ func newConfigureSsoCmd() *cobra.Command {
 
 	cmd.Flags().StringVar(&ssoFlags.Name, "profile", "", "profile name")
 	cmd.Flags().StringVar(&ssoFlags.SsoSessionName, "sso-session", "", "SSO session name")
+	cmd.Flags().StringVar(&ssoFlags.Region, "profile-region", "", "Region used by API calls of this profile (defaults to the SSO session region)")
 	cmd.Flags().Bool("no-browser", false, "Do not automatically open the browser during device authorization")
+	cmd.Flags().Bool("verbose", false, "Print polling progress to stderr while waiting for device authorization")
+	cmd.Flags().Bool("json", false, "After configuring, print the selected account, role and profile as a single JSON line to stdout and other messages to stderr")
 	cmd.Flags().BoolP("help", "h", false, "")
 
+	registerConfigNameCompletions(cmd)
+
 	return cmd
 }
 
@@ -689,16 +885,17 @@ Scopes: {{ sessionScopes .Session }}`,
 		FuncMap: buildPromptFuncMap(),
 	}
 
+	size, searchMode := promptSelectSettings()
 	sel := promptui.Select{
 		Label:             "Select or create SSO session (type to filter, Enter to choose)",
 		Items:             choices,
 		Searcher:          searcher,
 		Templates:         templates,
-		StartInSearchMode: true,
-		Size:              10,
+		StartInSearchMode: searchMode,
+		Size:              size,
 	}
 
-	idx, _, err := sel.Run()
+	idx, err := runSelect(sel, sessionOptionLabels(choices))
 	if err != nil {
 		return "", nil, err
 	}
@@ -724,7 +921,7 @@ Scopes: {{ sessionScopes .Session }}`,
 				return nil
 			},
 		}
-		newName, err := newNamePrompt.Run()
+		newName, err := runPrompt(newNamePrompt)
 		if err != nil {
 			return "", nil, err
 		}
@@ -35,6 +35,7 @@ Supports two modes:
 	cmd.Flags().StringVarP(&login.Region, "region", "r", "", "Region (prompts when omitted; empty input defaults to ap-southeast-1)")
 	cmd.Flags().BoolVar(&login.Remote, "remote", false, "Enable cross-device (remote) login mode")
 	cmd.Flags().StringVar(&login.EndpointURL, "endpoint-url", "https://signin.byteplus.com", "Override signin service endpoint URL")
+	registerConfigNameCompletions(cmd)
 
 	return cmd
 }
@@ -72,6 +73,7 @@ Modes:
 	// Register flags.
 	cmd.Flags().StringVarP(&logout.Profile, "profile", "p", "default", "Configuration profile name")
 	cmd.Flags().BoolVar(&logout.All, "all", false, "Log out all profiles and remove all cached login credentials")
+	registerConfigNameCompletions(cmd)
 
 	return cmd
 }
@@ -0,0 +1,288 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/byteplus-sdk/byteplus-cli/util"
+	"github.com/spf13/cobra"
+)
+
+// MetricsFile 保存本地聚合的调用统计，与 config.json 位于同一目录。
+const MetricsFile = "metrics.json"
+
+// metricsWarningOut 为统计写入失败提示的输出目标。
+var metricsWarningOut io.Writer = os.Stderr
+
+// metricsFileMu 串行化同一进程内对统计文件的读改写；多个进程同时写入时可能丢失少量计数。
+var metricsFileMu sync.Mutex
+
+func init() {
+	rootCmd.AddCommand(newMetricsCmd())
+}
+
+// actionMetrics 是单个 service.action 的累计统计，耗时单位为毫秒。
+type actionMetrics struct {
+	Calls    int64  `json:"calls"`
+	Errors   int64  `json:"errors"`
+	TotalMs  int64  `json:"total-ms"`
+	MaxMs    int64  `json:"max-ms"`
+	LastCall string `json:"last-call"`
+}
+
+// metricsData 是统计文件的内容，Since 为第一次记录（或最近一次 reset 后）的时间。
+type metricsData struct {
+	Since   string                    `json:"since"`
+	Actions map[string]*actionMetrics `json:"actions"`
+}
+
+// metricsEnabled 判断是否记录本地统计：只有配置文件中 metrics 为 true 时开启，---no-config 时不记录。
+func metricsEnabled(ctx *Context) bool {
+	return ctx != nil && ctx.config != nil && !ignoreConfigRequested(ctx) && ctx.config.Metrics
+}
+
+func metricsFilePath() (string, error) {
+	dir, err := configFileDirFunc()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, MetricsFile), nil
+}
+
+// recordMetrics 在开启统计时把一次 action 调用计入统计文件。写入失败只在 stderr 提示，不改变命令结果。
+func recordMetrics(ctx *Context, serviceName, action string, started time.Time, callErr error) {
+	if !metricsEnabled(ctx) {
+		return
+	}
+	now := nowFunc()
+	elapsed := now.Sub(started).Milliseconds()
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	err := updateMetrics(func(data *metricsData) {
+		key := serviceName + "." + action
+		m := data.Actions[key]
+		if m == nil {
+			m = &actionMetrics{}
+			data.Actions[key] = m
+		}
+		m.Calls++
+		if callErr != nil {
+			m.Errors++
+		}
+		m.TotalMs += elapsed
+		if elapsed > m.MaxMs {
+			m.MaxMs = elapsed
+		}
+		m.LastCall = now.UTC().Format(time.RFC3339)
+	})
+	if err != nil {
+		fmt.Fprintf(metricsWarningOut, "Warning: failed to record metrics: %v\n", err)
+	}
+}
+
+func updateMetrics(update func(data *metricsData)) error {
+	metricsFileMu.Lock()
+	defer metricsFileMu.Unlock()
+
+	path, err := metricsFilePath()
+	if err != nil {
+		return err
+	}
+	data, err := readMetrics(path)
+	if err != nil {
+		return err
+	}
+	update(data)
+	return writeMetrics(path, data)
+}
+
+// readMetrics 读取统计文件，文件不存在时返回从当前时间开始的空统计。
+func readMetrics(path string) (*metricsData, error) {
+	data := &metricsData{}
+	b, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, data); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+		}
+	}
+	if data.Actions == nil {
+		data.Actions = map[string]*actionMetrics{}
+	}
+	if data.Since == "" {
+		data.Since = nowFunc().UTC().Format(time.RFC3339)
+	}
+	return data, nil
+}
+
+// writeMetrics 先写临时文件再替换，避免中断时留下不完整的统计文件。
+func writeMetrics(path string, data *metricsData) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, configDirPerm()); err != nil {
+		return err
+	}
+	tempFile, err := os.CreateTemp(dir, ".tmp-metrics-*")
+	if err != nil {
+		return err
+	}
+	tempName := tempFile.Name()
+	defer trackTempFile(tempName)()
+	defer func() {
+		_ = tempFile.Close()
+		_ = os.Remove(tempName)
+	}()
+	if _, err := tempFile.Write(b); err != nil {
+		return err
+	}
+	if err := tempFile.Close(); err != nil {
+		return err
+	}
+	return replaceFile(tempName, path, configFilePerm())
+}
+
+// writeMetricsSummary 按调用次数从多到少输出统计表格。
+func writeMetricsSummary(out io.Writer, data *metricsData) error {
+	if len(data.Actions) == 0 {
+		_, err := fmt.Fprintln(out, "No metrics recorded.")
+		return err
+	}
+	keys := make([]string, 0, len(data.Actions))
+	for k := range data.Actions {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := data.Actions[keys[i]], data.Actions[keys[j]]
+		if a.Calls != b.Calls {
+			return a.Calls > b.Calls
+		}
+		return keys[i] < keys[j]
+	})
+
+	if _, err := fmt.Fprintf(out, "Metrics since %s\n\n", data.Since); err != nil {
+		return err
+	}
+	tw := util.NewTableWriter(out, 0)
+	tw.SetColumns([]string{"Action", "Calls", "Errors", "ErrorRate", "AvgMs", "MaxMs", "LastCall"})
+	for _, k := range keys {
+		m := data.Actions[k]
+		var avg int64
+		errorRate := "0%"
+		if m.Calls > 0 {
+			avg = m.TotalMs / m.Calls
+			errorRate = fmt.Sprintf("%.1f%%", float64(m.Errors)*100/float64(m.Calls))
+		}
+		row := map[string]interface{}{
+			"Action":    k,
+			"Calls":     m.Calls,
+			"Errors":    m.Errors,
+			"ErrorRate": errorRate,
+			"AvgMs":     avg,
+			"MaxMs":     m.MaxMs,
+			"LastCall":  m.LastCall,
+		}
+		if err := tw.Write(row); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+func setMetricsEnabled(enabled bool) error {
+	cfg := runtimeConfig()
+	if cfg == nil {
+		cfg = &Configure{Profiles: map[string]*Profile{}}
+	}
+	cfg.Metrics = enabled
+	if err := WriteConfigToFile(cfg); err != nil {
+		return err
+	}
+	setRuntimeConfig(cfg)
+	return nil
+}
+
+func newMetricsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "metrics",
+		Short: "Summarize locally recorded CLI usage metrics",
+		Long: `Summarize locally recorded CLI usage metrics.
+When enabled, each action call adds to per-action counters (calls, errors, latency) in
+~/.byteplus/metrics.json. Metrics are off by default, never leave the machine, and
+record no parameters or credentials.`,
+		Example: `  # Start recording metrics
+  bp metrics enable
+  # Show calls, error rate and latency per action
+  bp metrics
+  # Clear the recorded metrics
+  bp metrics reset`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := metricsFilePath()
+			if err != nil {
+				return err
+			}
+			data, err := readMetrics(path)
+			if err != nil {
+				return err
+			}
+			if cfg := runtimeConfig(); cfg == nil || !cfg.Metrics {
+				fmt.Fprintln(cmd.OutOrStdout(), "Metrics recording is disabled. Run 'bp metrics enable' to start recording.")
+			}
+			return writeMetricsSummary(cmd.OutOrStdout(), data)
+		},
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "enable",
+		Short: "Start recording local usage metrics",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := setMetricsEnabled(true); err != nil {
+				return err
+			}
+			fmt.Fprintln(statusWriter(cmd.OutOrStdout()), "Metrics recording enabled.")
+			return nil
+		},
+	}, &cobra.Command{
+		Use:   "disable",
+		Short: "Stop recording local usage metrics; recorded metrics are kept",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := setMetricsEnabled(false); err != nil {
+				return err
+			}
+			fmt.Fprintln(statusWriter(cmd.OutOrStdout()), "Metrics recording disabled.")
+			return nil
+		},
+	}, &cobra.Command{
+		Use:   "reset",
+		Short: "Delete the recorded metrics",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			metricsFileMu.Lock()
+			defer metricsFileMu.Unlock()
+			path, err := metricsFilePath()
+			if err != nil {
+				return err
+			}
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			fmt.Fprintln(statusWriter(cmd.OutOrStdout()), "Metrics cleared.")
+			return nil
+		},
+	})
+	return cmd
+}
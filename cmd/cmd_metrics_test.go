@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordMetricsAggregatesCalls(t *testing.T) {
+	dir := withTestConfigDir(t)
+	now := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	withFixedNow(t, now)
+
+	testCtx := NewContext()
+	testCtx.SetConfig(&Configure{Profiles: map[string]*Profile{}})
+	recordMetrics(testCtx, "ecs", "DescribeInstances", now.Add(-time.Second), nil)
+	if _, err := os.Stat(filepath.Join(dir, MetricsFile)); !os.IsNotExist(err) {
+		t.Fatalf("metrics recorded while disabled: %v", err)
+	}
+
+	testCtx.SetConfig(&Configure{Profiles: map[string]*Profile{}, Metrics: true})
+	recordMetrics(testCtx, "ecs", "DescribeInstances", now.Add(-100*time.Millisecond), nil)
+	recordMetrics(testCtx, "ecs", "DescribeInstances", now.Add(-300*time.Millisecond), errors.New("boom"))
+	recordMetrics(testCtx, "vpc", "DescribeVpcs", now.Add(-50*time.Millisecond), nil)
+
+	data, err := readMetrics(filepath.Join(dir, MetricsFile))
+	if err != nil {
+		t.Fatalf("readMetrics() error = %v", err)
+	}
+	m := data.Actions["ecs.DescribeInstances"]
+	if m == nil || m.Calls != 2 || m.Errors != 1 || m.TotalMs != 400 || m.MaxMs != 300 || m.LastCall != "2030-01-01T12:00:00Z" {
+		t.Fatalf("ecs.DescribeInstances metrics = %+v", m)
+	}
+
+	var out bytes.Buffer
+	if err := writeMetricsSummary(&out, data); err != nil {
+		t.Fatalf("writeMetricsSummary() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 6 || lines[0] != "Metrics since 2030-01-01T12:00:00Z" {
+		t.Fatalf("summary =\n%s", out.String())
+	}
+	if fields := strings.Fields(lines[4]); strings.Join(fields, " ") != "ecs.DescribeInstances 2 1 50.0% 200 300 2030-01-01T12:00:00Z" {
+		t.Fatalf("first summary row = %q", lines[4])
+	}
+	if !strings.HasPrefix(lines[5], "vpc.DescribeVpcs") {
+		t.Fatalf("second summary row = %q", lines[5])
+	}
+}
+
+func TestMetricsEnableAndReset(t *testing.T) {
+	dir := withTestConfigDir(t)
+	withTestCtxConfig(t, &Configure{Profiles: map[string]*Profile{}})
+
+	cmd := newMetricsCmd()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetArgs([]string{"enable"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("metrics enable error = %v", err)
+	}
+	if got := readConfigFileAsMap(t, dir)["metrics"]; got != true {
+		t.Fatalf("config metrics = %v, want true", got)
+	}
+	if !metricsEnabled(ctx) {
+		t.Fatal("metrics not enabled for the running context")
+	}
+
+	recordMetrics(ctx, "ecs", "DescribeInstances", nowFunc(), nil)
+	cmd.SetArgs([]string{"reset"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("metrics reset error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, MetricsFile)); !os.IsNotExist(err) {
+		t.Fatalf("metrics file still exists after reset: %v", err)
+	}
+}
@@ -18,6 +18,7 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/spf13/cobra"
@@ -34,6 +35,26 @@ var rootCmd = &cobra.Command{
 	SilenceUsage:  true,
 }
 
+// quietOutput 由全局 --quiet/-q 设置：为 true 时不输出成功提示等状态信息，命令结果、交互提示与错误不受影响。
+var quietOutput bool
+
+// statusWriter 返回状态信息的输出目标：--quiet 时丢弃，否则为 out。
+func statusWriter(out io.Writer) io.Writer {
+	if quietOutput {
+		return io.Discard
+	}
+	return out
+}
+
+// guidanceWriter 返回完成交互式登录所需提示（授权链接等）的输出目标。
+// 这些提示丢弃后用户无法完成登录，因此 --quiet 时改写到 stderr，保持 stdout 干净。
+func guidanceWriter() io.Writer {
+	if quietOutput {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
 func initRootCmd() {
 
 	rootCmd.SetHelpCommand(&cobra.Command{
@@ -44,6 +65,25 @@ func initRootCmd() {
 
 	rootCmd.Flags().BoolP("version", "v", false, "Show CLI version")
 
+	rootCmd.PersistentFlags().BoolVarP(&quietOutput, "quiet", "q", false, "Suppress informational messages; results and errors are still printed")
+
+	rootCmd.PersistentFlags().BoolVar(&configReadOnly, "config-readonly", false, "Never write the config file; changes made by this command are kept in memory only")
+
+	// --env-file 与 --override-env 在 Execute 中分发命令前已从参数中取出，这里只用于帮助信息
+	rootCmd.PersistentFlags().String("env-file", "", "Load BYTEPLUS_* defaults from this KEY=VALUE file instead of ./"+defaultEnvFileName)
+
+	rootCmd.PersistentFlags().Bool("override-env", false, "Let the env file override variables that are already set")
+
+	rootCmd.PersistentFlags().StringVar(&connectTimeoutFlag, "connect-timeout", "", "Timeout for connecting to a server, e.g. 5s (default 30s); reading the response is not limited by it")
+
+	rootCmd.PersistentFlags().StringVar(&tlsHandshakeTimeoutFlag, "tls-handshake-timeout", "", "Timeout for the TLS handshake, e.g. 5s (default 10s)")
+
+	rootCmd.PersistentFlags().StringArrayVar(&extraHeaderFlags, "header", nil, "Add a 'Key: Value' header to the sign-in and portal requests; repeatable")
+
+	rootCmd.PersistentFlags().StringVar(&errorFormat, "error-format", errorFormatText, "Format of the error printed on failure: text or json")
+
+	rootCmd.PersistentFlags().BoolVar(&compactErrors, "compact-errors", false, "Print only the top-level context and root cause of an error; --verbose shows the full chain")
+
 	rootCmd.PreRunE = func(cmd *cobra.Command, args []string) error {
 		showVersion, _ := cmd.Flags().GetBool("version")
 		if showVersion {
@@ -81,9 +121,33 @@ func initRootCmd() {
 
 func Execute() {
 	initRootCmd()
-
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
+	args, envFile, explicitEnvFile, overrideEnv, err := extractEnvFileArgs(os.Args[1:])
+	if err == nil {
+		err = loadEnvFile(envFile, explicitEnvFile, overrideEnv)
+	}
+	if err == nil {
+		args, err = expandConfiguredAlias(args)
+	}
+	if err == nil {
+		args, err = resolveServiceAbbreviation(args)
+	}
+	if err != nil {
+		writeCommandError(os.Stderr, err, resolveErrorFormat(), resolveCompactErrors(nil))
+		os.Exit(1)
+	}
+	rootCmd.SetArgs(args)
+	loadActionCmdsForArgs(args)
+
+	stopInterruptHandler := installInterruptHandler()
+	sweepStaleTempFiles(tempFileDirs(), nowFunc())
+	cmd, err := rootCmd.ExecuteContextC(commandContext)
+	stopInterruptHandler()
+	if err != nil {
+		if isInterruptError(err) {
+			reportAborted()
+			os.Exit(interruptExitCode)
+		}
+		writeCommandError(os.Stderr, err, resolveErrorFormat(), resolveCompactErrors(cmd))
 		os.Exit(1)
 	}
 }
@@ -115,6 +179,52 @@ Fixed Flags:
   ---profile string    Use a configured profile only for this invocation.
   ---region string     Override the region only for this invocation.
   ---endpoint string   Override the endpoint only for this invocation.
+  ---output string     Output format: json (default), json-compact, table or text.
+  ---paginate          Fetch all pages of a list response.
+  ---protocol string   Request protocol: query or json (default from metadata).
+  ---fields string     Comma-separated columns for table or text output, e.g. InstanceId,Status.
+  ---count             Print only the number of list elements (across all pages with ---paginate).
+  ---jq string         Filter the JSON response with a jq expression, e.g. .Result.Instances[].InstanceId.
+  ---output-template string
+                       Render the response Result with a Go text/template instead of an output format.
+  ---output-file string
+                       Also write the complete result to a file, e.g. to keep JSON while showing a table.
+  ---output-file-format string
+                       Format of ---output-file: json (default), json-compact, table or text.
+  ---post-result string
+                       POST the JSON result (filtered by ---jq if given) to a URL after the call succeeds.
+  ---post-result-header string
+                       Add a 'Key: Value' header to the ---post-result request; repeatable.
+  ---output-raw string Save the raw response body to a file or directory instead of decoding it as JSON.
+  ---created-after string
+                       Keep only list elements created at or after an RFC3339 time, a date or a duration ago (e.g. 7d).
+  ---created-before string
+                       Keep only list elements created before an RFC3339 time, a date or a duration ago.
+  ---time-field string Timestamp field used by ---created-after/---created-before (default CreatedAt, CreationTime, CreateTime or CreatedTime).
+  ---sort-by string    Sort list elements by a field before output, e.g. CreatedAt or Placement.ZoneId.
+  ---reverse           Sort in descending order; requires ---sort-by.
+  ---all-regions       Call a read action in every region listed under "regions" in the config file and merge the results.
+  ---fail-on-partial   Exit with an error when a successful response reports failed items.
+  ---validate-response Warn on stderr when the response Result does not match the API metadata.
+  ---verbose           Print the resolved service, region and endpoint to stderr before each call.
+  ---no-config         Ignore the config file and take credentials only from environment variables.
+  ---credential-source string
+                       Take credentials only from the profile or only from environment variables: profile, env or auto (default).
+  ---insecure-skip-verify
+                       Skip TLS certificate verification for this call; only for test endpoints.
+  ---connect-timeout string
+                       Timeout for connecting to the endpoint, e.g. 5s (default 30s); reading the response is not limited.
+  ---tls-handshake-timeout string
+                       Timeout for the TLS handshake, e.g. 5s (default 10s).
+  ---header string     Add a 'Key: Value' header to the request, e.g. a trace ID; repeatable.
+  ---proxy string      Send this call through a proxy URL, overriding the profile proxy and HTTP(S)_PROXY.
+  ---dump-canonical-request
+                       Print the canonical request and string to sign of each attempt to stderr, to debug signature errors.
+  ---pager             Show the output through $PAGER (default less) when stdout is a terminal.
+  ---config-readonly   Never write the config file, e.g. when refreshing SSO credentials; changes stay in memory.
+  ---error-format string
+                       Print a failed call's error as text (default) or a JSON object with Code, StatusCode and RequestId.
+  ---compact-errors    Print only the top-level context and root cause of a failed call's error; ---verbose shows the full chain.
 
 Examples:
   bp sts GetCallerIdentity ---profile default ---region ap-southeast-1
@@ -18,6 +18,7 @@ package cmd
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -27,31 +28,33 @@ func init() {
 	generateServiceCommands()
 }
 
+// serviceCmds maps every service command name, including the hidden aliases
+// with "_", to the service it belongs to and the command itself. Action
+// subcommands are only added by loadServiceActionCmds.
+var (
+	serviceCmds       = map[string]*cobra.Command{}
+	serviceCmdSvc     = map[string]string{}
+	serviceCmdsLoaded = map[string]bool{}
+)
+
 func generateServiceCommands() {
-	for svc, actionMeta := range rootSupport.SupportAction {
-		apiMetas := rootSupport.SupportTypes[svc]
+	for _, svc := range rootSupport.GetActionSvcs() {
 		svc := svc
-		validActions := rootSupport.GetAllAction(svc)
 		svcCmd := &cobra.Command{
 			Use:                svc,
 			Short:              formatServiceShort(svc),
 			DisableFlagParsing: true,
 			RunE: func(cmd *cobra.Command, args []string) error {
-				return runServiceCmd(cmd, svc, validActions, args)
+				return runServiceCmd(cmd, svc, rootSupport.GetAllAction(svc), args)
 			},
 		}
 
 		svcCmd.SetUsageTemplate(serviceUsageTemplate())
-		svcCmd.ValidArgs = validActions
-
-		actionCmds := generateActionCmd(svc, actionMeta, apiMetas)
-		for i := 0; i < len(actionCmds); i++ {
-			svcCmd.AddCommand(actionCmds[i])
-		}
-
 		svcCmd.Flags().BoolP("help", "h", false, "")
 
 		rootCmd.AddCommand(svcCmd)
+		serviceCmds[svc] = svcCmd
+		serviceCmdSvc[svc] = svc
 
 		for _, v := range compatible_support_cmd {
 			if strings.ReplaceAll(v, "_", "") == svc {
@@ -60,8 +63,99 @@ func generateServiceCommands() {
 				compatibleCmd.Use = v
 				compatibleCmd.Hidden = true
 				rootCmd.AddCommand(&compatibleCmd)
+				serviceCmds[v] = &compatibleCmd
+				serviceCmdSvc[v] = svc
+			}
+		}
+	}
+}
+
+// resolveServiceAbbreviation expands the service argument when it is a unique
+// prefix of a service command name, so "bp autosc ..." runs "bp autoscaling ...".
+// Exact command names, including non-service commands such as configure, are
+// never rewritten. An ambiguous prefix is an error listing the candidates; an
+// argument that matches nothing is left for cobra to report. In completion
+// mode the word being completed is left alone, since cobra already completes it.
+func resolveServiceAbbreviation(args []string) ([]string, error) {
+	completing := false
+	for i, a := range args {
+		if a == "__complete" || a == "__completeNoDesc" {
+			completing = true
+			continue
+		}
+		if strings.HasPrefix(a, "-") || a == "help" {
+			continue
+		}
+		if completing && i == len(args)-1 {
+			return args, nil
+		}
+		if a == "" || isRootCommandName(a) {
+			return args, nil
+		}
+		var candidates []string
+		for _, svc := range rootSupport.GetAllSvc() {
+			if _, ok := serviceCmds[svc]; ok && strings.HasPrefix(svc, a) {
+				candidates = append(candidates, svc)
 			}
 		}
+		switch len(candidates) {
+		case 0:
+			return args, nil
+		case 1:
+			resolved := append([]string{}, args...)
+			resolved[i] = candidates[0]
+			return resolved, nil
+		default:
+			sort.Strings(candidates)
+			return nil, fmt.Errorf("service %q is ambiguous, it matches: %s", a, strings.Join(candidates, ", "))
+		}
+	}
+	return args, nil
+}
+
+func isRootCommandName(name string) bool {
+	for _, c := range rootCmd.Commands() {
+		if c.Name() == name || c.HasAlias(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadActionCmdsForArgs adds the action subcommands of the service named by
+// the first non-flag argument, so only that service's metadata is parsed.
+// Completion requests name the service after the hidden __complete command.
+func loadActionCmdsForArgs(args []string) {
+	for _, a := range args {
+		if strings.HasPrefix(a, "-") || a == "__complete" || a == "__completeNoDesc" || a == "help" {
+			continue
+		}
+		loadServiceActionCmds(a)
+		return
+	}
+}
+
+// loadServiceActionCmds adds the action subcommands to the service command
+// name and to the other commands of the same service.
+func loadServiceActionCmds(name string) {
+	svc, ok := serviceCmdSvc[name]
+	if !ok || serviceCmdsLoaded[svc] {
+		return
+	}
+	serviceCmdsLoaded[svc] = true
+
+	actionMeta, apiMetas := rootSupport.serviceMeta(svc)
+	validActions := rootSupport.GetAllAction(svc)
+	for cmdName, cmdSvc := range serviceCmdSvc {
+		if cmdSvc != svc {
+			continue
+		}
+		svcCmd := serviceCmds[cmdName]
+		svcCmd.ValidArgs = validActions
+		actionCmds := generateActionCmd(svc, actionMeta, apiMetas)
+		for i := 0; i < len(actionCmds); i++ {
+			svcCmd.AddCommand(actionCmds[i])
+		}
 	}
 }
 
@@ -112,5 +206,51 @@ Fixed Flags:
   ---profile string    Use a configured profile only for this invocation.
   ---region string     Override the region only for this invocation.
   ---endpoint string   Override the endpoint only for this invocation.
+  ---output string     Output format: json (default), json-compact, table or text.
+  ---paginate          Fetch all pages of a list response.
+  ---protocol string   Request protocol: query or json (default from metadata).
+  ---fields string     Comma-separated columns for table or text output, e.g. InstanceId,Status.
+  ---count             Print only the number of list elements (across all pages with ---paginate).
+  ---jq string         Filter the JSON response with a jq expression, e.g. .Result.Instances[].InstanceId.
+  ---output-template string
+                       Render the response Result with a Go text/template instead of an output format.
+  ---output-file string
+                       Also write the complete result to a file, e.g. to keep JSON while showing a table.
+  ---output-file-format string
+                       Format of ---output-file: json (default), json-compact, table or text.
+  ---post-result string
+                       POST the JSON result (filtered by ---jq if given) to a URL after the call succeeds.
+  ---post-result-header string
+                       Add a 'Key: Value' header to the ---post-result request; repeatable.
+  ---output-raw string Save the raw response body to a file or directory instead of decoding it as JSON.
+  ---created-after string
+                       Keep only list elements created at or after an RFC3339 time, a date or a duration ago (e.g. 7d).
+  ---created-before string
+                       Keep only list elements created before an RFC3339 time, a date or a duration ago.
+  ---time-field string Timestamp field used by ---created-after/---created-before (default CreatedAt, CreationTime, CreateTime or CreatedTime).
+  ---sort-by string    Sort list elements by a field before output, e.g. CreatedAt or Placement.ZoneId.
+  ---reverse           Sort in descending order; requires ---sort-by.
+  ---all-regions       Call a read action in every region listed under "regions" in the config file and merge the results.
+  ---fail-on-partial   Exit with an error when a successful response reports failed items.
+  ---validate-response Warn on stderr when the response Result does not match the API metadata.
+  ---verbose           Print the resolved service, region and endpoint to stderr before each call.
+  ---no-config         Ignore the config file and take credentials only from environment variables.
+  ---credential-source string
+                       Take credentials only from the profile or only from environment variables: profile, env or auto (default).
+  ---insecure-skip-verify
+                       Skip TLS certificate verification for this call; only for test endpoints.
+  ---connect-timeout string
+                       Timeout for connecting to the endpoint, e.g. 5s (default 30s); reading the response is not limited.
+  ---tls-handshake-timeout string
+                       Timeout for the TLS handshake, e.g. 5s (default 10s).
+  ---header string     Add a 'Key: Value' header to the request, e.g. a trace ID; repeatable.
+  ---proxy string      Send this call through a proxy URL, overriding the profile proxy and HTTP(S)_PROXY.
+  ---dump-canonical-request
+                       Print the canonical request and string to sign of each attempt to stderr, to debug signature errors.
+  ---pager             Show the output through $PAGER (default less) when stdout is a terminal.
+  ---config-readonly   Never write the config file, e.g. when refreshing SSO credentials; changes stay in memory.
+  ---error-format string
+                       Print a failed call's error as text (default) or a JSON object with Code, StatusCode and RequestId.
+  ---compact-errors    Print only the top-level context and root cause of a failed call's error; ---verbose shows the full chain.
 `
 }
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// shellPrompt 是 bp shell 每行输入前的提示符，写到 stderr，stdout 只保留命令结果。
+const shellPrompt = "bp> "
+
+func init() {
+	rootCmd.AddCommand(newShellCmd())
+}
+
+// shellSession 保存 bp shell 中通过 :profile、:region、:output 设置的默认值，
+// 对之后的每条命令生效，命令行中显式给出的同名 ---flag 优先。
+type shellSession struct {
+	profile string
+	region  string
+	output  string
+}
+
+func newShellCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "shell",
+		Short: "Start an interactive shell that runs service actions",
+		Long: `Start an interactive shell. Each line is run as "<service> <action> [--params] [---flags]"
+without the leading "bp"; the config file is loaded once and kept for the whole session.
+Meta-commands change the defaults of later commands:
+  :profile [name]   Use a configured profile; without a name, print the current one
+  :region [region]  Override the region; without a region, print the current one
+  :output [format]  Output format: json, json-compact, table or text
+  :quit             Leave the shell (also exit or Ctrl-D)`,
+		Example: `  bp shell
+  bp> :region ap-southeast-1
+  bp> ecs DescribeInstances --MaxResults 10 ---output table`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runShell(cmd.InOrStdin(), cmd.ErrOrStderr(), &shellSession{}, executeShellLine)
+		},
+	}
+	return cmd
+}
+
+// runShell 逐行读取 in 并交给 exec 执行，单条命令失败只输出错误，不结束会话。
+// 读到 EOF、:quit/exit 或 Ctrl-C 取消 commandContext 后返回。
+func runShell(in io.Reader, errOut io.Writer, session *shellSession, exec func(session *shellSession, args []string) error) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), batchMaxLineSize)
+	for {
+		fmt.Fprint(errOut, shellPrompt)
+		if !scanner.Scan() {
+			fmt.Fprintln(errOut)
+			return scanner.Err()
+		}
+		if err := commandContext.Err(); err != nil {
+			return err
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if line == "exit" || line == "quit" || line == ":quit" || line == ":q" {
+			return nil
+		}
+		if strings.HasPrefix(line, ":") {
+			if err := session.meta(errOut, line); err != nil {
+				fmt.Fprintln(errOut, err)
+			}
+			continue
+		}
+		args, err := splitShellWords(line)
+		if err == nil {
+			err = exec(session, args)
+		}
+		if err != nil {
+			if isInterruptError(err) {
+				return err
+			}
+			fmt.Fprintln(errOut, err)
+		}
+	}
+}
+
+// meta 处理以 ":" 开头的会话命令；不带值时输出当前设置。
+func (s *shellSession) meta(out io.Writer, line string) error {
+	fields := strings.Fields(line)
+	name := strings.TrimPrefix(fields[0], ":")
+	var target *string
+	switch name {
+	case "profile":
+		target = &s.profile
+	case "region":
+		target = &s.region
+	case "output":
+		target = &s.output
+	default:
+		return fmt.Errorf("unknown meta-command :%s, supported: :profile, :region, :output, :quit", name)
+	}
+	if len(fields) == 1 {
+		value := *target
+		if value == "" {
+			value = "(default)"
+		}
+		fmt.Fprintf(out, "%s: %s\n", name, value)
+		return nil
+	}
+	if len(fields) > 2 {
+		return fmt.Errorf(":%s takes a single value", name)
+	}
+	value := fields[1]
+	switch name {
+	case "profile":
+		if cfg := runtimeConfig(); cfg == nil || cfg.Profiles[value] == nil {
+			return fmt.Errorf("profile %q is not configured", value)
+		}
+	case "output":
+		value = strings.ToLower(value)
+		switch value {
+		case outputFormatJSON, outputFormatJSONCompact, outputFormatTable, outputFormatText:
+		default:
+			return fmt.Errorf(":output %q is not supported, supported values: %s", fields[1], supportedOutputFormatsMessage)
+		}
+	}
+	*target = value
+	return nil
+}
+
+// withDefaults 在 args 末尾补上会话默认的 ---profile、---region、---output，已显式指定的不覆盖。
+func (s *shellSession) withDefaults(args []string) []string {
+	result := append([]string{}, args...)
+	for _, d := range []struct{ name, value string }{{"profile", s.profile}, {"region", s.region}, {"output", s.output}} {
+		if d.value == "" || containsArg(args, "---"+d.name) {
+			continue
+		}
+		result = append(result, "---"+d.name, d.value)
+	}
+	return result
+}
+
+func containsArg(args []string, arg string) bool {
+	for _, a := range args {
+		if a == arg {
+			return true
+		}
+	}
+	return false
+}
+
+// executeShellLine 复用 bp 的命令分发执行一行输入。每条命令使用新的 Context，
+// 避免上一条命令的 flag 残留，但共享已加载的 config。只允许服务命令，
+// configure 等普通命令的 flag 在 cobra 中会跨次执行保留，不适合在同一进程内重复执行。
+func executeShellLine(session *shellSession, args []string) error {
+	args, err := expandConfiguredAlias(args)
+	if err == nil {
+		args, err = resolveServiceAbbreviation(args)
+	}
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	if _, ok := serviceCmdSvc[args[0]]; !ok {
+		return fmt.Errorf("%q is not a service; shell lines must be \"<service> <action> [params]\"", args[0])
+	}
+	loadActionCmdsForArgs(args)
+
+	ctx = NewContext()
+	ctx.SetConfig(config)
+	rootCmd.SetArgs(session.withDefaults(args))
+	return rootCmd.ExecuteContext(commandContext)
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRunShellAppliesMetaCommandsToLaterLines(t *testing.T) {
+	withTestCtxConfig(t, &Configure{Profiles: map[string]*Profile{"dev": {Name: "dev"}}})
+
+	input := strings.Join([]string{
+		":profile dev",
+		":output TABLE",
+		":profile missing",
+		"ecs DescribeInstances ---output json",
+		"vpc DescribeVpcs",
+		":quit",
+		"ecs NotRun",
+	}, "\n")
+	var calls [][]string
+	var errOut strings.Builder
+	exec := func(session *shellSession, args []string) error {
+		calls = append(calls, session.withDefaults(args))
+		if args[0] == "vpc" {
+			return errors.New("call failed")
+		}
+		return nil
+	}
+	if err := runShell(strings.NewReader(input), &errOut, &shellSession{}, exec); err != nil {
+		t.Fatalf("runShell returned error: %v", err)
+	}
+
+	want := [][]string{
+		{"ecs", "DescribeInstances", "---output", "json", "---profile", "dev"},
+		{"vpc", "DescribeVpcs", "---profile", "dev", "---output", "table"},
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("calls = %q, want %q", calls, want)
+	}
+	if !strings.Contains(errOut.String(), `profile "missing" is not configured`) || !strings.Contains(errOut.String(), "call failed") {
+		t.Fatalf("stderr = %q, want the meta-command and call errors", errOut.String())
+	}
+}
@@ -1,10 +1,15 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/url"
+	"os"
 	"sort"
 	"strings"
 
+	"github.com/byteplus-sdk/byteplus-cli/util"
 	"github.com/manifoldco/promptui"
 	"github.com/spf13/cobra"
 )
@@ -14,6 +19,12 @@ func init() {
 
 	ssoCmd.AddCommand(newSsoLoginCmd())
 	ssoCmd.AddCommand(newSsoLogoutCmd())
+	ssoCmd.AddCommand(newSsoDoctorCmd())
+	ssoCmd.AddCommand(newSsoListAccountsCmd())
+	ssoCmd.AddCommand(newSsoListRolesCmd())
+	ssoCmd.AddCommand(newSsoListAssignmentsCmd())
+	ssoCmd.AddCommand(newSsoCacheCmd())
+	ssoCmd.AddCommand(newSsoServeCmd())
 
 	rootCmd.AddCommand(ssoCmd)
 }
@@ -35,13 +46,49 @@ func newSsoLoginCmd() *cobra.Command {
 		Use:   "login",
 		Short: "Perform SSO login operations",
 		Long: `Login via SSO, obtain the access token and store it in the cache.
-This command requires a configured profile, and the profile must be associated with a valid SSO session.
+This command uses a configured SSO profile or sso-session. With --start-url alone it logs in once
+without saving an sso-session, caching the token under a name derived from the start URL host.
 After a successful login, the system stores the access token for subsequent operations.`,
 		Example: `  # Login to SSO using the specified profile
   bp sso login --profile my-sso-profile
   # Login to SSO using the specified sso-session
-  bp sso login --sso-session my-sso-session`,
-		RunE: func(cmd *cobra.Command, args []string) error {
+  bp sso login --sso-session my-sso-session
+  # Create the sso-session on first login
+  bp sso login --sso-session my-sso-session --start-url https://{custom}.byteplusidentity.com/userportal --region ap-southeast-1
+  # Login once without saving an sso-session
+  bp sso login --start-url https://{custom}.byteplusidentity.com/userportal --region ap-southeast-1
+  # Login to SSO using the profile selected for the current shell
+  BYTEPLUS_PROFILE=my-sso-profile bp sso login
+  # Print a machine-readable result for automation
+  bp sso login --sso-session my-sso-session --json
+  # Authorize again even though the cached token is still valid
+  bp sso login --sso-session my-sso-session --reauth`,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			jsonOutput, err := cmd.Flags().GetBool("json")
+			if err != nil {
+				return err
+			}
+			var sso *Sso
+			var activeSessionName string
+			statusOut := ssoStatusOut(jsonOutput)
+			if jsonOutput {
+				defer func() {
+					session := activeSessionName
+					if session == "" {
+						session = strings.TrimSpace(cmd.Flag("sso-session").Value.String())
+					}
+					result := newSsoCommandResult(session, err)
+					if err == nil && sso != nil {
+						if token := sso.LoginToken(); token != nil {
+							result.ExpiresAt = token.ExpiresAt
+						}
+					}
+					if writeErr := writeSsoCommandResult(result); err == nil {
+						err = writeErr
+					}
+				}()
+			}
+
 			cfg := ctx.config
 			if cfg == nil {
 				return fmt.Errorf("the configuration file cannot be loaded")
@@ -54,9 +101,27 @@ After a successful login, the system stores the access token for subsequent oper
 			if err != nil {
 				return err
 			}
+			verbose, err := cmd.Flags().GetBool("verbose")
+			if err != nil {
+				return err
+			}
+			reauth, err := cmd.Flags().GetBool("reauth")
+			if err != nil {
+				return err
+			}
 
-			var sso *Sso
-			var activeSessionName string
+			startURL := strings.TrimSpace(cmd.Flag("start-url").Value.String())
+			region := strings.TrimSpace(cmd.Flag("region").Value.String())
+			if (startURL != "" || region != "") && profileName != "" {
+				return fmt.Errorf("--start-url and --region cannot be used together with --profile")
+			}
+			if region != "" && startURL == "" && ssoSessionName == "" {
+				return fmt.Errorf("--region requires --start-url or --sso-session")
+			}
+
+			if profileName == "" && ssoSessionName == "" && startURL == "" {
+				profileName = ssoProfileNameFromEnv(cfg)
+			}
 
 			if profileName != "" {
 				profile, ok := cfg.Profiles[profileName]
@@ -71,18 +136,40 @@ After a successful login, the system stores the access token for subsequent oper
 					return fmt.Errorf("the specified profile does not have sso-session configured")
 				}
 
+				// 登录区域由 Login 从 sso-session 中读取，不使用 profile 的 API region。
 				sso = &Sso{
 					Profile:        profile,
 					SsoSessionName: profile.SsoSessionName,
-					Region:         profile.Region,
 					UseDeviceCode:  useDeviceCode,
 					NoBrowser:      noBrowser,
 				}
 				activeSessionName = profile.SsoSessionName
+			} else if ssoSessionName == "" && startURL != "" {
+				// 未指定 sso-session 时只做一次性登录：不写配置文件，token 按派生的 session 名缓存
+				if region == "" {
+					region = defaultSsoRegion
+				}
+				sso = &Sso{
+					SsoSessionName: transientSsoSessionName(startURL),
+					StartURL:       startURL,
+					Region:         region,
+					Transient:      true,
+					UseDeviceCode:  useDeviceCode,
+					NoBrowser:      noBrowser,
+				}
+				activeSessionName = sso.SsoSessionName
+				fmt.Fprintf(statusOut, "Logging in without a configured sso-session; the token is cached as sso-session [%s].\n", activeSessionName)
 			} else if ssoSessionName != "" {
 				ssoSession, ok := cfg.SsoSession[ssoSessionName]
 				if !ok {
-					return fmt.Errorf("the specified sso-session was not found: %s", ssoSessionName)
+					if startURL == "" {
+						return fmt.Errorf("the specified sso-session was not found: %s; pass --start-url and --region to create it", ssoSessionName)
+					}
+					if ssoSession, err = createSsoSessionForLogin(statusOut, ssoSessionName, startURL, region); err != nil {
+						return err
+					}
+				} else if startURL != "" || region != "" {
+					return fmt.Errorf("the sso-session %s already exists; --start-url and --region only apply when creating a new sso-session, use 'bp configure sso-session' to modify it", ssoSessionName)
 				}
 				if ssoSession == nil {
 					return fmt.Errorf("the specified sso-session is invalid: %s", ssoSessionName)
@@ -135,17 +222,24 @@ After a successful login, the system stores the access token for subsequent oper
 				}
 			}
 
+			sso.Verbose = verbose
+			sso.Reauth = reauth
+			sso.MessageOut = ssoMessageOut(jsonOutput)
 			if err := sso.Login(); err != nil {
 				if activeSessionName != "" {
-					fmt.Printf("login failed for sso-session [%s]: %v\n", activeSessionName, err)
+					fmt.Fprintf(statusOut, "login failed for sso-session [%s]: %v\n", activeSessionName, err)
 				}
 				return err
 			}
 
+			if token := sso.ReusedLoginToken(); token != nil {
+				fmt.Fprintf(statusOut, "already logged in for sso-session [%s] (token valid until %s)\n", sso.SsoSessionName, token.ExpiresAt)
+				return nil
+			}
 			if activeSessionName != "" {
-				fmt.Printf("login successfully for sso-session [%s]\n", activeSessionName)
+				fmt.Fprintf(statusOut, "login successfully for sso-session [%s]\n", activeSessionName)
 			} else {
-				fmt.Println("login successfully")
+				fmt.Fprintln(statusOut, "login successfully")
 			}
 			return nil
 		},
@@ -153,13 +247,69 @@ After a successful login, the system stores the access token for subsequent oper
 
 	ssoLoginCmd.Flags().String("profile", "", "Specify the name of the configuration file to be used")
 	ssoLoginCmd.Flags().String("sso-session", "", "Specify the SSO session to use when no profile is provided")
+	ssoLoginCmd.Flags().String("start-url", "", "SSO start URL used to create the --sso-session if it does not exist, or to log in without an sso-session")
+	ssoLoginCmd.Flags().String("region", "", "SSO region used together with --start-url (default "+defaultSsoRegion+")")
 	ssoLoginCmd.Flags().Bool("no-browser", false, "Do not automatically open the browser during device authorization")
+	ssoLoginCmd.Flags().Bool("verbose", false, "Print polling progress to stderr while waiting for device authorization")
+	ssoLoginCmd.Flags().Bool("reauth", false, "Always run device authorization, ignoring a valid cached token and the refresh token")
+	ssoLoginCmd.Flags().Bool("json", false, "Print the result as a single JSON line to stdout and all other messages to stderr")
 
 	ssoLoginCmd.SetUsageTemplate(ssoUsageTemplate())
+	registerConfigNameCompletions(ssoLoginCmd)
 
 	return ssoLoginCmd
 }
 
+// createSsoSessionForLogin saves a new sso-session from the login flags so the
+// first login does not require a separate 'bp configure sso-session' run.
+func createSsoSessionForLogin(out io.Writer, name, startURL, region string) (*SsoSession, error) {
+	if region == "" {
+		region = defaultSsoRegion
+	}
+	session := &SsoSession{
+		Name:     name,
+		StartURL: startURL,
+		Region:   region,
+	}
+	if err := setSsoSession(session); err != nil {
+		return nil, fmt.Errorf("failed to create sso-session %s: %w", name, err)
+	}
+	fmt.Fprintf(out, "SSO session [%s] created.\n", name)
+	return ctx.config.SsoSession[name], nil
+}
+
+// transientSsoSessionName derives the session name that caches the token of a
+// login without an sso-session: the first label of the start URL host, so
+// https://acme.byteplusidentity.com/userportal is cached as "acme". Creating an
+// sso-session with that name, start URL and region later reuses the token.
+func transientSsoSessionName(startURL string) string {
+	if u, err := url.Parse(startURL); err == nil {
+		if label := strings.SplitN(u.Hostname(), ".", 2)[0]; label != "" {
+			return label
+		}
+	}
+	return "default"
+}
+
+// ssoProfileNameFromEnv returns the profile selected by BYTEPLUS_PROFILE when
+// it is an SSO profile bound to an sso-session, so that sso login/logout act on
+// the same profile as service commands in the current shell. Non-SSO profiles
+// are ignored and the commands fall back to sso-session selection.
+func ssoProfileNameFromEnv(cfg *Configure) string {
+	name, _ := envProfileName()
+	if name == "" || cfg == nil {
+		return ""
+	}
+	profile := cfg.Profiles[name]
+	if profile == nil || strings.ToLower(strings.TrimSpace(profile.Mode)) != ModeSSO {
+		return ""
+	}
+	if strings.TrimSpace(profile.SsoSessionName) == "" {
+		return ""
+	}
+	return name
+}
+
 func selectExistingSession(options []sessionOption) (string, *SsoSession, error) {
 	if len(options) == 0 {
 		return "", nil, fmt.Errorf("no sso-session configured")
@@ -196,16 +346,17 @@ Scopes: {{ sessionScopes .Session }}`,
 		FuncMap: buildPromptFuncMap(),
 	}
 
+	size, searchMode := promptSelectSettings()
 	sel := promptui.Select{
 		Label:             "Select SSO session (type to filter, Enter to choose)",
 		Items:             options,
 		Searcher:          searcher,
 		Templates:         templates,
-		StartInSearchMode: true,
-		Size:              10,
+		StartInSearchMode: searchMode,
+		Size:              size,
 	}
 
-	idx, _, err := sel.Run()
+	idx, err := runSelect(sel, sessionOptionLabels(options))
 	if err != nil {
 		return "", nil, err
 	}
@@ -265,16 +416,17 @@ Scopes: {{ sessionScopes .Session }}`,
 		}(),
 	}
 
+	size, searchMode := promptSelectSettings()
 	sel := promptui.Select{
 		Label:             "Select SSO session to logout (type to filter, Enter to choose)",
 		Items:             choices,
 		Searcher:          searcher,
 		Templates:         templates,
-		StartInSearchMode: true,
-		Size:              10,
+		StartInSearchMode: searchMode,
+		Size:              size,
 	}
 
-	idx, _, err := sel.Run()
+	idx, err := runSelect(sel, sessionOptionLabels(choices))
 	if err != nil {
 		return "", nil, false, err
 	}
@@ -286,6 +438,10 @@ Scopes: {{ sessionScopes .Session }}`,
 	return chosen.Name, chosen.Session, false, nil
 }
 
+// ssoLogoutConcurrency limits how many sessions logoutAllSessions revokes at
+// the same time.
+const ssoLogoutConcurrency = 4
+
 func logoutAllSessions(cfg *Configure) error {
 	if cfg == nil {
 		return fmt.Errorf("the configuration file cannot be loaded")
@@ -297,19 +453,27 @@ func logoutAllSessions(cfg *Configure) error {
 	}
 	sort.Strings(sessionNames)
 
-	var failures []string
-	for _, name := range sessionNames {
-		session := cfg.SsoSession[name]
+	opts := util.BoundedOptions{Concurrency: ssoLogoutConcurrency, Label: "sso logout"}
+	if len(sessionNames) > 1 && util.IsTerminal(os.Stderr) {
+		opts.Progress = os.Stderr
+	}
+	results := util.RunBounded(len(sessionNames), opts, func(i int) (interface{}, error) {
+		session := cfg.SsoSession[sessionNames[i]]
 		if session == nil {
-			continue
+			return nil, nil
 		}
 		sso := &Sso{
-			SsoSessionName: name,
+			SsoSessionName: sessionNames[i],
 			StartURL:       session.StartURL,
 			Region:         session.Region,
 		}
-		if err := sso.Logout(); err != nil {
-			failures = append(failures, fmt.Sprintf("%s: %v", name, err))
+		return nil, sso.Logout()
+	})
+
+	var failures []string
+	for _, r := range results {
+		if r.Err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", sessionNames[r.Index], r.Err))
 		}
 	}
 	if len(failures) > 0 {
@@ -327,20 +491,42 @@ func newSsoLogoutCmd() *cobra.Command {
 		Example: `  # Logout SSO by profile
   bp sso logout --profile my-sso-profile
   # Logout SSO by sso-session
-  bp sso logout --sso-session my-sso-session`,
-		RunE: func(cmd *cobra.Command, args []string) error {
+  bp sso logout --sso-session my-sso-session
+  # Print a machine-readable result for automation
+  bp sso logout --sso-session my-sso-session --json`,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			jsonOutput, err := cmd.Flags().GetBool("json")
+			if err != nil {
+				return err
+			}
+			var loggedOut []string
+			statusOut := ssoStatusOut(jsonOutput)
+			if jsonOutput {
+				defer func() {
+					if writeErr := writeSsoCommandResult(newSsoCommandResult(strings.Join(loggedOut, ","), err)); err == nil {
+						err = writeErr
+					}
+				}()
+			}
+
 			cfg := ctx.config
 			if cfg == nil {
 				return fmt.Errorf("the configuration file cannot be loaded")
 			}
 
 			ssoSessionName := strings.TrimSpace(cmd.Flag("sso-session").Value.String())
+			if ssoSessionName == "" {
+				if name := ssoProfileNameFromEnv(cfg); name != "" {
+					ssoSessionName = cfg.Profiles[name].SsoSessionName
+				}
+			}
 
 			if ssoSessionName != "" {
 				session, ok := cfg.SsoSession[ssoSessionName]
 				if !ok {
 					return fmt.Errorf("the specified sso-session was not found: %s", ssoSessionName)
 				}
+				loggedOut = []string{ssoSessionName}
 				sso := &Sso{
 					SsoSessionName: ssoSessionName,
 					StartURL:       session.StartURL,
@@ -349,7 +535,7 @@ func newSsoLogoutCmd() *cobra.Command {
 				if err := sso.Logout(); err != nil {
 					return err
 				}
-				fmt.Println("logout successfully")
+				fmt.Fprintln(statusOut, "logout successfully")
 				return nil
 			}
 
@@ -361,6 +547,7 @@ func newSsoLogoutCmd() *cobra.Command {
 					if session == nil {
 						return fmt.Errorf("the specified sso-session is invalid: %s", name)
 					}
+					loggedOut = []string{name}
 					sso := &Sso{
 						SsoSessionName: name,
 						StartURL:       session.StartURL,
@@ -369,7 +556,7 @@ func newSsoLogoutCmd() *cobra.Command {
 					if err := sso.Logout(); err != nil {
 						return err
 					}
-					fmt.Println("logout successfully")
+					fmt.Fprintln(statusOut, "logout successfully")
 					return nil
 				}
 			}
@@ -380,16 +567,20 @@ func newSsoLogoutCmd() *cobra.Command {
 				return err
 			}
 			if logoutAll {
+				for _, option := range options {
+					loggedOut = append(loggedOut, option.Name)
+				}
 				if err := logoutAllSessions(cfg); err != nil {
 					return err
 				}
-				fmt.Println("logout successfully")
+				fmt.Fprintln(statusOut, "logout successfully")
 				return nil
 			}
 			if selectedSession == nil {
 				return fmt.Errorf("the specified sso-session is invalid: %s", selectedName)
 			}
 
+			loggedOut = []string{selectedName}
 			sso := &Sso{
 				SsoSessionName: selectedName,
 				StartURL:       selectedSession.StartURL,
@@ -398,18 +589,68 @@ func newSsoLogoutCmd() *cobra.Command {
 			if err := sso.Logout(); err != nil {
 				return err
 			}
-			fmt.Println("logout successfully")
+			fmt.Fprintln(statusOut, "logout successfully")
 			return nil
 		},
 	}
 
 	ssoLogoutCmd.Flags().String("sso-session", "", "Specify the SSO session to log out")
+	ssoLogoutCmd.Flags().Bool("json", false, "Print the result as a single JSON line to stdout and all other messages to stderr")
 
 	ssoLogoutCmd.SetUsageTemplate(ssoUsageTemplate())
+	registerConfigNameCompletions(ssoLogoutCmd)
 
 	return ssoLogoutCmd
 }
 
+// ssoCommandResultOut is where --json writes the result line of sso login/logout
+// and configure sso.
+var ssoCommandResultOut io.Writer = os.Stdout
+
+// ssoCommandResult is the single-line JSON result printed by sso login/logout --json.
+type ssoCommandResult struct {
+	Session   string `json:"session"`
+	Status    string `json:"status"`
+	ExpiresAt string `json:"expiresAt,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+func newSsoCommandResult(session string, err error) ssoCommandResult {
+	if err != nil {
+		return ssoCommandResult{Session: session, Status: "failed", Error: err.Error()}
+	}
+	return ssoCommandResult{Session: session, Status: "success"}
+}
+
+func writeSsoCommandResult(result ssoCommandResult) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(ssoCommandResultOut, string(data))
+	return err
+}
+
+// ssoStatusOut returns where human-readable progress goes: stdout normally,
+// stderr with --json so stdout carries only the result line, and nowhere
+// with --quiet.
+func ssoStatusOut(jsonOutput bool) io.Writer {
+	if jsonOutput {
+		return statusWriter(os.Stderr)
+	}
+	return statusWriter(os.Stdout)
+}
+
+// ssoMessageOut returns where device authorization instructions go. The
+// user needs them to finish logging in, so --quiet moves them to stderr
+// like --json does instead of dropping them.
+func ssoMessageOut(jsonOutput bool) io.Writer {
+	if jsonOutput {
+		return os.Stderr
+	}
+	return guidanceWriter()
+}
+
 func ssoUsageTemplate() string {
 	return `Usage:{{if .Runnable}}
   {{.UseLine}}{{end}}{{if .HasAvailableSubCommands}}
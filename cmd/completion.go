@@ -18,6 +18,8 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -94,3 +96,55 @@ func init() {
 	// is called directly, e.g.:
 	// completionCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 }
+
+// registerConfigNameCompletions completes the --profile and --sso-session
+// flags of cmd, when present, with the names found in the loaded config.
+func registerConfigNameCompletions(cmd *cobra.Command) {
+	if cmd.Flags().Lookup("profile") != nil {
+		_ = cmd.RegisterFlagCompletionFunc("profile", completeProfileNames)
+	}
+	if cmd.Flags().Lookup("sso-session") != nil {
+		_ = cmd.RegisterFlagCompletionFunc("sso-session", completeSsoSessionNames)
+	}
+}
+
+func completeProfileNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var names []string
+	if ctx != nil && ctx.config != nil {
+		for name := range ctx.config.Profiles {
+			names = append(names, name)
+		}
+	}
+	return filterCompletions(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+func completeSsoSessionNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var names []string
+	if ctx != nil && ctx.config != nil {
+		for name := range ctx.config.SsoSession {
+			names = append(names, name)
+		}
+	}
+	return filterCompletions(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeActionArgs completes the value of ---profile for action commands.
+// Action commands disable flag parsing, so cobra hands the raw arguments here
+// instead of invoking a flag completion function.
+func completeActionArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 && args[len(args)-1] == "---profile" {
+		return completeProfileNames(cmd, args, toComplete)
+	}
+	return nil, cobra.ShellCompDirectiveDefault
+}
+
+func filterCompletions(names []string, toComplete string) []string {
+	var matched []string
+	for _, name := range names {
+		if strings.HasPrefix(name, toComplete) {
+			matched = append(matched, name)
+		}
+	}
+	sort.Strings(matched)
+	return matched
+}
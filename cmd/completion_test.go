@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func completionTestConfig() *Configure {
+	return &Configure{
+		Profiles: map[string]*Profile{
+			"prod":    {Name: "prod"},
+			"staging": {Name: "staging"},
+			"preview": {Name: "preview"},
+		},
+		SsoSession: map[string]*SsoSession{
+			"corp": {Name: "corp"},
+			"lab":  {Name: "lab"},
+		},
+	}
+}
+
+func TestCompleteProfileNamesFiltersByPrefix(t *testing.T) {
+	withTestCtxConfig(t, completionTestConfig())
+
+	got, directive := completeProfileNames(nil, nil, "pr")
+	if want := []string{"preview", "prod"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("completeProfileNames() = %v, want %v", got, want)
+	}
+	if directive != cobra.ShellCompDirectiveNoFileComp {
+		t.Fatalf("directive = %v, want NoFileComp", directive)
+	}
+}
+
+func TestCompleteSsoSessionNames(t *testing.T) {
+	withTestCtxConfig(t, completionTestConfig())
+
+	got, _ := completeSsoSessionNames(nil, nil, "")
+	if want := []string{"corp", "lab"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("completeSsoSessionNames() = %v, want %v", got, want)
+	}
+}
+
+func TestCompleteActionArgsCompletesFixedProfileFlag(t *testing.T) {
+	withTestCtxConfig(t, completionTestConfig())
+
+	got, _ := completeActionArgs(nil, []string{"--InstanceId", "i-1", "---profile"}, "st")
+	if want := []string{"staging"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("completeActionArgs() = %v, want %v", got, want)
+	}
+	if got, _ := completeActionArgs(nil, []string{"--InstanceId"}, ""); got != nil {
+		t.Fatalf("completeActionArgs() for API parameter = %v, want nil", got)
+	}
+}
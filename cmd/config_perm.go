@@ -0,0 +1,76 @@
+/*
+ * // Copyright (c) 2024 Bytedance Ltd. and/or its affiliates
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at
+ * //
+ * //	http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// configFilePermEnv 指定配置文件与凭证缓存文件的权限（八进制，如 0640），
+// 用于同组服务账号需要读取配置的共享部署。
+const configFilePermEnv = "BYTEPLUS_CONFIG_PERM"
+
+const (
+	defaultConfigFilePerm os.FileMode = 0600
+	// maxConfigFilePerm 是允许的最宽松权限：最多向同组和其他用户开放只读。
+	maxConfigFilePerm os.FileMode = 0644
+)
+
+var configFilePermWarnOnce sync.Once
+
+// configFilePerm 返回写入配置和缓存文件时使用的权限。
+// 取值无法解析、比 0644 宽松或去掉了属主读写权限时，打印一次警告并回退到 0600。
+func configFilePerm() os.FileMode {
+	value := strings.TrimSpace(os.Getenv(configFilePermEnv))
+	if value == "" {
+		return defaultConfigFilePerm
+	}
+	perm, err := parseConfigFilePerm(value)
+	if err != nil {
+		configFilePermWarnOnce.Do(func() {
+			fmt.Fprintf(os.Stderr, "Warning: ignoring %s: %v, using %04o\n", configFilePermEnv, err, defaultConfigFilePerm)
+		})
+		return defaultConfigFilePerm
+	}
+	return perm
+}
+
+func parseConfigFilePerm(value string) (os.FileMode, error) {
+	n, err := strconv.ParseUint(value, 8, 32)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not an octal file mode", value)
+	}
+	perm := os.FileMode(n)
+	if perm&^maxConfigFilePerm != 0 {
+		return 0, fmt.Errorf("%04o is more permissive than %04o", perm, maxConfigFilePerm)
+	}
+	if perm&0600 != 0600 {
+		return 0, fmt.Errorf("%04o must keep owner read and write", perm)
+	}
+	return perm, nil
+}
+
+// configDirPerm 返回与 configFilePerm 对应的目录权限：文件对同组或其他用户可读时，
+// 目录同样对其开放读取和进入，否则文件权限放宽也无法生效。
+func configDirPerm() os.FileMode {
+	readable := configFilePerm() & 0044
+	return 0700 | readable | readable>>2
+}
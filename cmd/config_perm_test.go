@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestParseConfigFilePerm(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    os.FileMode
+		wantErr bool
+	}{
+		{value: "0600", want: 0600},
+		{value: "640", want: 0640},
+		{value: "0644", want: 0644},
+		{value: "0660", wantErr: true},
+		{value: "0755", wantErr: true},
+		{value: "0400", wantErr: true},
+		{value: "rw-r-----", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseConfigFilePerm(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseConfigFilePerm(%q) = %04o, want error", tt.value, got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("parseConfigFilePerm(%q) = %04o, %v, want %04o", tt.value, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigPermFallsBackToDefault(t *testing.T) {
+	t.Setenv(configFilePermEnv, "0666")
+	if got := configFilePerm(); got != defaultConfigFilePerm {
+		t.Fatalf("configFilePerm() = %04o, want %04o", got, defaultConfigFilePerm)
+	}
+	if got := configDirPerm(); got != 0700 {
+		t.Fatalf("configDirPerm() = %04o, want 0700", got)
+	}
+}
+
+func TestWriteConfigToFileUsesConfiguredPerm(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not enforced on windows")
+	}
+	dir := withTestConfigDir(t)
+	t.Setenv(configFilePermEnv, "0640")
+
+	if err := WriteConfigToFile(&Configure{Profiles: map[string]*Profile{}}); err != nil {
+		t.Fatalf("WriteConfigToFile() error = %v", err)
+	}
+	info, err := os.Stat(filepath.Join(dir, ConfigFile))
+	if err != nil {
+		t.Fatalf("stat config: %v", err)
+	}
+	if got := info.Mode().Perm(); got != 0640 {
+		t.Fatalf("config file mode = %04o, want 0640", got)
+	}
+	info, err = os.Stat(dir)
+	if err != nil {
+		t.Fatalf("stat config dir: %v", err)
+	}
+	if got := info.Mode().Perm(); got != 0750 {
+		t.Fatalf("config dir mode = %04o, want 0750", got)
+	}
+}
@@ -21,10 +21,15 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/byteplus-sdk/byteplus-cli/util"
 )
@@ -48,33 +53,92 @@ const (
 	ConfigFile = "config.json"
 )
 
+// SSO 会话获取角色凭证的方式
+const (
+	SsoCredentialSourcePortal      = "portal"
+	SsoCredentialSourceWebIdentity = "web-identity"
+)
+
 type Configure struct {
 	Current     string                 `json:"current"`
 	Profiles    map[string]*Profile    `json:"profiles"`
 	EnableColor bool                   `json:"enableColor"`
 	SsoSession  map[string]*SsoSession `json:"sso-session"`
+	// PromptListSize 与 PromptSearchMode 调整交互式选择列表（SSO session、账号、角色），未配置时使用默认值。
+	PromptListSize   int   `json:"prompt-list-size,omitempty"`
+	PromptSearchMode *bool `json:"prompt-search-mode,omitempty"`
+	// Regions 为 ---all-regions 依次调用的区域列表。
+	Regions []string `json:"regions,omitempty"`
+	// ProfileTTLDays 大于 0 时，使用 AK 超过该天数的 ak 模式 profile 会在 stderr 提示轮换，默认不检查。
+	ProfileTTLDays int `json:"profile-ttl-days,omitempty"`
+	// SoftErrorPaths 替换检测 HTTP 200 响应中部分失败的默认 key 路径，见 soft_errors.go。
+	SoftErrorPaths []string `json:"soft-error-paths,omitempty"`
+	// StsMinValidity 为 SSO 凭证至少需要剩余的有效分钟数，不足时调用前提前刷新；profile 可单独覆盖。
+	StsMinValidity int `json:"sts-min-validity,omitempty"`
+	// AuditLog 为审计日志路径，设置后每次调用 action 追加一行不含参数值的 JSON 记录。
+	AuditLog string `json:"audit-log,omitempty"`
+	// Metrics 为 true 时在本地聚合每个 action 的调用次数、失败次数与耗时，见 bp metrics。
+	Metrics bool `json:"metrics,omitempty"`
+	// Aliases 为用户定义的命令别名，键为别名，值为展开后的参数（按 shell 规则拆分），见 bp alias。
+	Aliases map[string]string `json:"aliases,omitempty"`
+	// MaxIdleConnsPerHost 与 MaxConnsPerHost 调整 API 调用使用的连接池，未配置时见 sdk_transport.go 中的默认值。
+	MaxIdleConnsPerHost int `json:"max-idle-conns-per-host,omitempty"`
+	MaxConnsPerHost     int `json:"max-conns-per-host,omitempty"`
+}
+
+const defaultPromptListSize = 10
+
+// promptSelectSettings 返回交互式选择列表显示的行数，以及是否以搜索模式启动。
+func promptSelectSettings() (int, bool) {
+	size, searchMode := defaultPromptListSize, true
+	if config != nil {
+		if config.PromptListSize > 0 {
+			size = config.PromptListSize
+		}
+		if config.PromptSearchMode != nil {
+			searchMode = *config.PromptSearchMode
+		}
+	}
+	return size, searchMode
 }
 
 type Profile struct {
-	Name             string `json:"name"`
-	Mode             string `json:"mode"`
-	AccessKey        string `json:"access-key"`
-	SecretKey        string `json:"secret-key"`
-	Region           string `json:"region"`
-	Endpoint         string `json:"endpoint"`
-	EndpointResolver string `json:"endpoint-resolver,omitempty"`
-	HTTPProxy        string `json:"http-proxy,omitempty"`
-	HTTPSProxy       string `json:"https-proxy,omitempty"`
-	UseDualStack     *bool  `json:"use-dual-stack,omitempty"`
-	SessionToken     string `json:"session-token"`
-	DisableSSL       *bool  `json:"disable-ssl"`
-	SsoSessionName   string `json:"sso-session-name,omitempty"`
-	AccountId        string `json:"account-id"`
-	RoleName         string `json:"role-name"`
-	StsExpiration    int64  `json:"sts-expiration"`
-	OidcTokenFile    string `json:"oidc-token-file,omitempty"`
-	RoleTrn          string `json:"role-trn,omitempty"`
-	LoginSession     string `json:"login-session,omitempty"`
+	Name             string            `json:"name"`
+	Mode             string            `json:"mode"`
+	AccessKey        string            `json:"access-key"`
+	SecretKey        string            `json:"secret-key"`
+	Region           string            `json:"region"`
+	Endpoint         string            `json:"endpoint"`
+	Endpoints        map[string]string `json:"endpoints,omitempty"`
+	EndpointResolver string            `json:"endpoint-resolver,omitempty"`
+	HTTPProxy        string            `json:"http-proxy,omitempty"`
+	HTTPSProxy       string            `json:"https-proxy,omitempty"`
+	Proxy            string            `json:"proxy,omitempty"`
+	NoProxy          string            `json:"no-proxy,omitempty"`
+	UseDualStack     *bool             `json:"use-dual-stack,omitempty"`
+	SessionToken     string            `json:"session-token"`
+	DisableSSL       *bool             `json:"disable-ssl"`
+	SsoSessionName   string            `json:"sso-session-name,omitempty"`
+	AccountId        string            `json:"account-id"`
+	RoleName         string            `json:"role-name"`
+	StsExpiration    int64             `json:"sts-expiration"`
+	OidcTokenFile    string            `json:"oidc-token-file,omitempty"`
+	RoleTrn          string            `json:"role-trn,omitempty"`
+	LoginSession     string            `json:"login-session,omitempty"`
+	// CredentialProcess 为外部凭证命令，设置后优先于 mode 使用其输出的凭证。
+	CredentialProcess string `json:"credential-process,omitempty"`
+	// CreatedAt 为当前 AK 的写入时间（Unix 秒），新建 profile 或更换 access-key 时更新，用于 profile-ttl-days 检查。
+	CreatedAt int64 `json:"created-at,omitempty"`
+	// Extra 为该 profile 生效时固定 flag 的默认值，键为不带 --- 的 flag 名（如 output、region），
+	// 命令行显式传入的固定 flag 优先。
+	Extra map[string]string `json:"extra,omitempty"`
+	// StsMinValidity 覆盖全局 sts-min-validity，单位为分钟。
+	StsMinValidity int `json:"sts-min-validity,omitempty"`
+	// FallbackRoles 为 SSO profile 的备用角色，role-name 不再分配给当前用户时按顺序尝试，改用第一个可用的角色。
+	FallbackRoles []string `json:"fallback-roles,omitempty"`
+	// InsecureSkipVerify 为 true 时 HTTPS 请求不校验服务端证书，仅用于自签名证书的测试 endpoint；
+	// 与 DisableSSL（改用明文 HTTP）是两个独立的设置。
+	InsecureSkipVerify *bool `json:"insecure-skip-verify,omitempty"`
 }
 
 type SsoSession struct {
@@ -82,6 +146,8 @@ type SsoSession struct {
 	StartURL           string   `json:"start-url"`
 	Region             string   `json:"region"`
 	RegistrationScopes []string `json:"registration-scopes,omitempty"`
+	// CredentialSource 为 web-identity 时通过 STS 以 SSO token 扮演角色，为空或 portal 时走 Portal 接口。
+	CredentialSource string `json:"credential-source,omitempty"`
 }
 
 // LoadConfig from CONFIG_FILE_DIR(default ~/.byteplus)
@@ -94,19 +160,19 @@ func LoadConfig() *Configure {
 		return nil
 	}
 
-	if err := os.MkdirAll(configFileDir, 0700); err != nil {
+	if err := os.MkdirAll(configFileDir, configDirPerm()); err != nil {
 		return nil
 	}
-	_ = os.Chmod(configFileDir, 0700)
+	_ = os.Chmod(configFileDir, configDirPerm())
 
 	configFilePath := filepath.Join(configFileDir, ConfigFile)
-	file, err := os.OpenFile(configFilePath, os.O_CREATE|os.O_RDWR, 0600)
+	file, err := os.OpenFile(configFilePath, os.O_CREATE|os.O_RDWR, configFilePerm())
 	if err != nil {
 		fmt.Println(err)
 		return nil
 	}
 	defer file.Close()
-	_ = file.Chmod(0600)
+	_ = file.Chmod(configFilePerm())
 
 	fileContent, err := ioutil.ReadAll(file)
 	if err != nil {
@@ -138,8 +204,42 @@ func setRuntimeConfig(cfg *Configure) {
 	config = cfg
 }
 
+// configReadOnly 由全局 --config-readonly 设置：为 true 时配置修改只保留在内存中，不写配置文件。
+var configReadOnly bool
+
+// configReadOnlyEnv 为 true 时等同于 --config-readonly，适用于只读文件系统或共享配置。
+const configReadOnlyEnv = "BYTEPLUS_CONFIG_READONLY"
+
+// configReadOnlyWarningOut 为跳过写配置文件时提示的输出目标。
+var configReadOnlyWarningOut io.Writer = os.Stderr
+
+// configReadOnlyWarned 保证同一进程内只提示一次。
+var configReadOnlyWarned sync.Once
+
+// configReadOnlyRequested 判断本次调用是否禁止写配置文件：--config-readonly、---config-readonly 或 BYTEPLUS_CONFIG_READONLY=true。
+func configReadOnlyRequested() bool {
+	if configReadOnly {
+		return true
+	}
+	if ctx != nil && ctx.fixedFlags != nil {
+		if f := ctx.fixedFlags.GetByName("config-readonly"); f != nil && f.GetValue() == "true" {
+			return true
+		}
+	}
+	value, _ := strconv.ParseBool(strings.TrimSpace(os.Getenv(configReadOnlyEnv)))
+	return value
+}
+
 // WriteConfigToFile store config
 func WriteConfigToFile(config *Configure) error {
+	// 只读模式下修改已在内存中生效，本次调用照常使用，只是不落盘。
+	if configReadOnlyRequested() {
+		configReadOnlyWarned.Do(func() {
+			fmt.Fprintln(configReadOnlyWarningOut, "Warning: the config file is read-only for this invocation; changes are kept in memory and not saved")
+		})
+		return nil
+	}
+
 	configFileMu.Lock()
 	defer configFileMu.Unlock()
 
@@ -148,10 +248,10 @@ func WriteConfigToFile(config *Configure) error {
 		return err
 	}
 
-	if err := os.MkdirAll(configFileDir, 0700); err != nil {
+	if err := os.MkdirAll(configFileDir, configDirPerm()); err != nil {
 		return err
 	}
-	_ = os.Chmod(configFileDir, 0700)
+	_ = os.Chmod(configFileDir, configDirPerm())
 
 	targetPath := filepath.Join(configFileDir, ConfigFile)
 
@@ -161,11 +261,12 @@ func WriteConfigToFile(config *Configure) error {
 		return err
 	}
 	tempName := tempFile.Name()
+	defer trackTempFile(tempName)()
 	defer func() {
 		_ = tempFile.Close()
 		_ = os.Remove(tempName)
 	}()
-	_ = tempFile.Chmod(0600)
+	_ = tempFile.Chmod(configFilePerm())
 
 	data, err := marshalConfig(config)
 	if err != nil {
@@ -174,17 +275,17 @@ func WriteConfigToFile(config *Configure) error {
 	if _, err := tempFile.Write(data); err != nil {
 		return err
 	}
+	if err := tempFile.Sync(); err != nil {
+		return err
+	}
 	if err := tempFile.Close(); err != nil {
 		return err
 	}
 
-	if err := os.Rename(tempName, targetPath); err != nil {
-		_ = os.Remove(targetPath)
-		if err2 := os.Rename(tempName, targetPath); err2 != nil {
-			return err2
-		}
+	if err := replaceFile(tempName, targetPath, configFilePerm()); err != nil {
+		return err
 	}
-	_ = os.Chmod(targetPath, 0600)
+	_ = os.Chmod(targetPath, configFilePerm())
 	return nil
 }
 
@@ -245,10 +346,43 @@ func setConfigProfile(profile *Profile) error {
 		*currentProfile.UseDualStack = false
 	}
 
+	for k, v := range profile.Extra {
+		if strings.TrimSpace(v) == "" {
+			continue
+		}
+		if err := validateProfileExtraEntry(k, v); err != nil {
+			return err
+		}
+	}
+	if _, err := useStandardEndpointResolver(profile.EndpointResolver); err != nil {
+		return err
+	}
+	if profile.Proxy != "" {
+		if _, err := parseProxyURL(profile.Proxy); err != nil {
+			return err
+		}
+	}
 	nextProfile := mergeProfile(currentProfile, profile)
 	if err := validateProfileMode(nextProfile); err != nil {
 		return err
 	}
+	if strings.ToLower(strings.TrimSpace(nextProfile.Mode)) == ModeSSO {
+		if err := validateSsoProfileBinding(cfg, nextProfile); err != nil {
+			return err
+		}
+		// 新建或改绑账号、角色、sso-session 后，旧的角色凭证不再属于该 profile；清除后由 EnsureValidStsToken 在首次调用时获取
+		if !exist || ssoProfileBindingChanged(currentProfile, nextProfile) {
+			clearSsoProfileTemporaryCredentials(nextProfile)
+		}
+	}
+	if !exist || (profile.AccessKey != "" && profile.AccessKey != currentProfile.AccessKey) {
+		nextProfile.CreatedAt = nowFunc().Unix()
+		// 更换 ak 模式的 access key 时，旧的 STS token 属于旧的密钥，除非同时传入新的 session-token 否则一并清除
+		if exist && profile.SessionToken == "" && debugCredentialMode(nextProfile) == ModeAK {
+			nextProfile.SessionToken = ""
+			nextProfile.StsExpiration = 0
+		}
+	}
 
 	cfg.Profiles[nextProfile.Name] = nextProfile
 	cfg.Current = nextProfile.Name
@@ -290,6 +424,12 @@ func mergeProfile(base *Profile, input *Profile) *Profile {
 	if input.HTTPSProxy != "" {
 		merged.HTTPSProxy = input.HTTPSProxy
 	}
+	if input.Proxy != "" {
+		merged.Proxy = input.Proxy
+	}
+	if input.NoProxy != "" {
+		merged.NoProxy = input.NoProxy
+	}
 	if input.SessionToken != "" {
 		merged.SessionToken = input.SessionToken
 	}
@@ -305,6 +445,12 @@ func mergeProfile(base *Profile, input *Profile) *Profile {
 		}
 		*merged.UseDualStack = *input.UseDualStack
 	}
+	if input.InsecureSkipVerify != nil {
+		if merged.InsecureSkipVerify == nil {
+			merged.InsecureSkipVerify = new(bool)
+		}
+		*merged.InsecureSkipVerify = *input.InsecureSkipVerify
+	}
 	if input.SsoSessionName != "" {
 		merged.SsoSessionName = input.SsoSessionName
 	}
@@ -314,15 +460,27 @@ func mergeProfile(base *Profile, input *Profile) *Profile {
 	if input.RoleName != "" {
 		merged.RoleName = input.RoleName
 	}
+	if len(input.FallbackRoles) > 0 {
+		merged.FallbackRoles = append([]string(nil), input.FallbackRoles...)
+	}
 	if input.OidcTokenFile != "" {
 		merged.OidcTokenFile = input.OidcTokenFile
 	}
 	if input.RoleTrn != "" {
 		merged.RoleTrn = input.RoleTrn
 	}
+	if input.CredentialProcess != "" {
+		merged.CredentialProcess = input.CredentialProcess
+	}
 	if input.Mode != "" {
 		merged.Mode = input.Mode
 	}
+	if len(input.Extra) > 0 {
+		merged.Extra = mergeProfileExtra(merged.Extra, input.Extra)
+	}
+	if input.StsMinValidity > 0 {
+		merged.StsMinValidity = input.StsMinValidity
+	}
 	// 仅新建 profile 时默认 mode 为 ak，修改已有 profile 时保留原 mode
 	if base == nil && merged.Mode == "" {
 		merged.Mode = ModeAK
@@ -345,6 +503,25 @@ func cloneProfile(profile *Profile) *Profile {
 		clone.UseDualStack = new(bool)
 		*clone.UseDualStack = *profile.UseDualStack
 	}
+	if profile.InsecureSkipVerify != nil {
+		clone.InsecureSkipVerify = new(bool)
+		*clone.InsecureSkipVerify = *profile.InsecureSkipVerify
+	}
+	if profile.Endpoints != nil {
+		clone.Endpoints = make(map[string]string, len(profile.Endpoints))
+		for svc, endpoint := range profile.Endpoints {
+			clone.Endpoints[svc] = endpoint
+		}
+	}
+	if profile.FallbackRoles != nil {
+		clone.FallbackRoles = append([]string(nil), profile.FallbackRoles...)
+	}
+	if profile.Extra != nil {
+		clone.Extra = make(map[string]string, len(profile.Extra))
+		for k, v := range profile.Extra {
+			clone.Extra[k] = v
+		}
+	}
 	return &clone
 }
 
@@ -357,12 +534,12 @@ func getConfigProfile(profileName string) error {
 
 	// if config not exist, return
 	if cfg = ctx.config; cfg == nil {
-		fmt.Println("no profile created")
+		fmt.Fprintln(statusWriter(os.Stdout), "no profile created")
 		return nil
 	}
 
 	if profileName == "" {
-		fmt.Printf("no profile name specified, show current profile: [%v]\n", cfg.Current)
+		fmt.Fprintf(statusWriter(os.Stdout), "no profile name specified, show current profile: [%v]\n", cfg.Current)
 		profileName = cfg.Current
 	}
 
@@ -386,11 +563,11 @@ func listConfigProfiles() error {
 
 	// if config not exist, return
 	if cfg = ctx.config; cfg == nil {
-		fmt.Println("no profile created")
+		fmt.Fprintln(statusWriter(os.Stdout), "no profile created")
 		return nil
 	}
 
-	fmt.Printf("*** current profile: %v ***\n", ctx.config.Current)
+	fmt.Fprintf(statusWriter(os.Stdout), "*** current profile: %v ***\n", ctx.config.Current)
 	for _, profile := range ctx.config.Profiles {
 		util.ShowJson(profile.ToMap(), config.EnableColor)
 	}
@@ -417,13 +594,84 @@ func deleteConfigProfile(profileName string) error {
 	delete(cfg.Profiles, profileName)
 	if profileName == cfg.Current {
 		cfg.SetRandomCurrentProfile()
-		fmt.Printf("delete current profile, set new current profile to [%v]\n", cfg.Current)
+		fmt.Fprintf(statusWriter(os.Stdout), "delete current profile, set new current profile to [%v]\n", cfg.Current)
 	}
 
 	// 写入配置文件，完成持久化。
 	return WriteConfigToFile(cfg)
 }
 
+// deleteConfigSsoSession 删除 sso-session。仍绑定该 session 的 SSO profile 不会被删除，
+// 但之后刷新凭证会失败，因此逐个提示。
+func deleteConfigSsoSession(out io.Writer, sessionName string) error {
+	cfg := ctx.config
+	if cfg == nil {
+		return fmt.Errorf("sso-session %v not found", sessionName)
+	}
+	if _, exist := cfg.SsoSession[sessionName]; !exist {
+		return fmt.Errorf("sso-session %v not found", sessionName)
+	}
+
+	delete(cfg.SsoSession, sessionName)
+	if err := WriteConfigToFile(cfg); err != nil {
+		return err
+	}
+	for _, name := range profilesUsingSsoSession(cfg, sessionName) {
+		fmt.Fprintf(out, "Warning: profile %q still references the deleted sso-session %q; run 'bp configure sso --profile %s' to bind it to another sso-session\n", name, sessionName, name)
+	}
+	return nil
+}
+
+// profilesUsingSsoSession 返回绑定到 sessionName 的 SSO profile 名，按名称排序。
+func profilesUsingSsoSession(cfg *Configure, sessionName string) []string {
+	var names []string
+	for name, profile := range cfg.Profiles {
+		if profile != nil && strings.ToLower(strings.TrimSpace(profile.Mode)) == ModeSSO && profile.SsoSessionName == sessionName {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// danglingSsoSessionWarningOut 为 SSO profile 引用不存在的 sso-session 时提示的输出目标。
+var danglingSsoSessionWarningOut io.Writer = os.Stderr
+
+// missingSsoSessionError 在 SSO profile 绑定的 sso-session 不存在时返回带修复建议的错误，否则返回 nil。
+// validateSsoProfileBinding 校验 sso 模式 profile 绑定的 sso-session 已存在，且账号与角色已指定，
+// 使 configure set 无需设备码授权即可创建可用的 SSO profile。
+func validateSsoProfileBinding(cfg *Configure, profile *Profile) error {
+	if profile.SsoSessionName == "" {
+		return fmt.Errorf("mode %q requires --sso-session", ModeSSO)
+	}
+	if profile.AccountId == "" {
+		return fmt.Errorf("mode %q requires --account-id", ModeSSO)
+	}
+	if profile.RoleName == "" {
+		return fmt.Errorf("mode %q requires --role-name", ModeSSO)
+	}
+	return missingSsoSessionError(cfg, profile)
+}
+
+// ssoProfileBindingChanged 判断 profile 的 mode、sso-session、账号或角色是否发生变化。
+func ssoProfileBindingChanged(before, after *Profile) bool {
+	return strings.ToLower(strings.TrimSpace(before.Mode)) != ModeSSO ||
+		before.SsoSessionName != after.SsoSessionName ||
+		before.AccountId != after.AccountId ||
+		before.RoleName != after.RoleName
+}
+
+func missingSsoSessionError(cfg *Configure, profile *Profile) error {
+	if cfg == nil || profile == nil || strings.ToLower(strings.TrimSpace(profile.Mode)) != ModeSSO || profile.SsoSessionName == "" {
+		return nil
+	}
+	if _, ok := cfg.SsoSession[profile.SsoSessionName]; ok {
+		return nil
+	}
+	return fmt.Errorf("profile %q references sso-session %q, which does not exist in the configuration file; run 'bp configure sso --profile %s' to bind it to an existing sso-session, or recreate the session with 'bp configure sso-session --name %s'",
+		profile.Name, profile.SsoSessionName, profile.Name, profile.SsoSessionName)
+}
+
 func changeConfigProfile(profileName string) error {
 	var (
 		exist bool
@@ -451,6 +699,41 @@ func changeConfigProfile(profileName string) error {
 	return WriteConfigToFile(cfg)
 }
 
+// staleProfileWarningOut 为 AK 超期提示的输出目标。
+var staleProfileWarningOut io.Writer = os.Stderr
+
+// warnStaleAKProfile 在配置了 profile-ttl-days 且 ak 模式 profile 的 AK 已超过该天数时打印提示，不影响调用。
+// 没有 created-at 的旧 profile 无法判断时长，不提示。
+func warnStaleAKProfile(cfg *Configure, profile *Profile) {
+	if cfg == nil || cfg.ProfileTTLDays <= 0 || profile == nil || profile.CreatedAt <= 0 {
+		return
+	}
+	mode := strings.ToLower(strings.TrimSpace(profile.Mode))
+	if (mode != "" && mode != ModeAK) || strings.TrimSpace(profile.CredentialProcess) != "" {
+		return
+	}
+	days := int(nowFunc().Sub(time.Unix(profile.CreatedAt, 0)).Hours() / 24)
+	if days < cfg.ProfileTTLDays {
+		return
+	}
+	fmt.Fprintf(staleProfileWarningOut, "Warning: the access key of profile %q was set %d days ago (profile-ttl-days is %d); consider rotating it with 'bp configure set --profile %s --access-key AK --secret-key SK' or switching to SSO\n",
+		profile.Name, days, cfg.ProfileTTLDays, profile.Name)
+}
+
+// staleAKSessionToken 判断 ak 模式 profile 的 session-token 是否已随 sts-expiration 过期，返回过期时间。
+// 从 SSO 等模式切换为 ak 后，遗留的 STS token 会让 SDK 带着过期 token 签名；
+// 没有 sts-expiration 或尚未过期的 token 视为有意配置的临时凭证，照常使用。
+func staleAKSessionToken(profile *Profile) (time.Time, bool) {
+	if profile == nil || strings.TrimSpace(profile.SessionToken) == "" || profile.StsExpiration <= 0 {
+		return time.Time{}, false
+	}
+	if mode := strings.ToLower(strings.TrimSpace(profile.Mode)); mode != "" && mode != ModeAK {
+		return time.Time{}, false
+	}
+	expiredAt := util.UnixTimestampToTime(profile.StsExpiration)
+	return expiredAt, !nowFunc().Before(expiredAt)
+}
+
 func (p *Profile) ToMap() map[string]interface{} {
 	data, _ := json.Marshal(p)
 	m := make(map[string]interface{})
@@ -459,6 +742,104 @@ func (p *Profile) ToMap() map[string]interface{} {
 	return m
 }
 
+// profileSecretFields 为 profile 中的密钥字段，configure diff 默认只显示其末尾 4 位。
+var profileSecretFields = map[string]struct{}{
+	"access-key":    {},
+	"secret-key":    {},
+	"session-token": {},
+}
+
+// diffConfigProfiles 逐字段比较两个 profile：相同的字段以两个空格开头，不同的字段先输出 "-" 行（第一个 profile）
+// 再输出 "+" 行（第二个 profile）。endpoints、extra 等对象字段按 "endpoints.ecs" 展开后比较。
+func diffConfigProfiles(out io.Writer, nameA, nameB string, includeSecrets bool) error {
+	cfg := ctx.config
+	if cfg == nil {
+		return fmt.Errorf("configuration profile %v not found", nameA)
+	}
+	var fields [2]map[string]string
+	for i, name := range []string{nameA, nameB} {
+		profile, ok := cfg.Profiles[name]
+		if !ok || profile == nil {
+			return fmt.Errorf("configuration profile %v not found", name)
+		}
+		fields[i] = flattenProfileFields(profile.ToMap())
+	}
+
+	keys := make([]string, 0, len(fields[0]))
+	for k := range fields[0] {
+		keys = append(keys, k)
+	}
+	for k := range fields[1] {
+		if _, ok := fields[0][k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	fmt.Fprintf(out, "--- %s\n+++ %s\n", nameA, nameB)
+	differences := 0
+	for _, k := range keys {
+		if k == "name" {
+			continue
+		}
+		a, inA := fields[0][k]
+		b, inB := fields[1][k]
+		if inA == inB && a == b {
+			fmt.Fprintf(out, "  %s: %s\n", k, displayProfileField(k, a, includeSecrets))
+			continue
+		}
+		differences++
+		if inA {
+			fmt.Fprintf(out, "- %s: %s\n", k, displayProfileField(k, a, includeSecrets))
+		}
+		if inB {
+			fmt.Fprintf(out, "+ %s: %s\n", k, displayProfileField(k, b, includeSecrets))
+		}
+	}
+	if differences == 0 {
+		fmt.Fprintf(out, "profiles %s and %s are identical\n", nameA, nameB)
+	} else {
+		fmt.Fprintf(out, "%d field(s) differ\n", differences)
+	}
+	return nil
+}
+
+// flattenProfileFields 把 ToMap 的结果转换为字段路径到显示值的映射，空值字段省略。
+func flattenProfileFields(m map[string]interface{}) map[string]string {
+	flat := make(map[string]string, len(m))
+	for k, v := range m {
+		switch value := v.(type) {
+		case nil:
+		case map[string]interface{}:
+			for sub, subValue := range value {
+				flat[k+"."+sub] = fmt.Sprint(subValue)
+			}
+		case string:
+			if value != "" {
+				flat[k] = value
+			}
+		case float64:
+			if value != 0 {
+				flat[k] = strconv.FormatFloat(value, 'f', -1, 64)
+			}
+		default:
+			b, _ := json.Marshal(value)
+			flat[k] = string(b)
+		}
+	}
+	return flat
+}
+
+func displayProfileField(key, value string, includeSecrets bool) string {
+	if _, secret := profileSecretFields[key]; !secret || includeSecrets {
+		return value
+	}
+	if len(value) <= 8 {
+		return "****"
+	}
+	return "****" + value[len(value)-4:]
+}
+
 func (p *Profile) String() string {
 	b, _ := json.MarshalIndent(p, "", "    ")
 	return string(b)
@@ -474,6 +855,11 @@ func setSsoSession(session *SsoSession) error {
 	if err != nil {
 		return err
 	}
+	switch session.CredentialSource {
+	case "", SsoCredentialSourcePortal, SsoCredentialSourceWebIdentity:
+	default:
+		return fmt.Errorf("unsupported credential source %q, supported values: %s, %s", session.CredentialSource, SsoCredentialSourcePortal, SsoCredentialSourceWebIdentity)
+	}
 
 	// 若配置为空则初始化基础结构。
 	if cfg = ctx.config; cfg == nil {
@@ -494,6 +880,7 @@ func setSsoSession(session *SsoSession) error {
 		StartURL:           session.StartURL,
 		Region:             session.Region,
 		RegistrationScopes: scopes,
+		CredentialSource:   session.CredentialSource,
 	}
 
 	// 写入内存配置并提示成功。
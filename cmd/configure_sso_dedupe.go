@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// ssoSessionDuplicates 是 start-url、region、scopes 与 credential-source 都相同的一组 sso-session。
+// Keep 是合并后保留的 session，Merge 中的 session 被删除，绑定它们的 profile 改为绑定 Keep。
+type ssoSessionDuplicates struct {
+	Keep     string
+	Merge    []string
+	StartURL string
+	Region   string
+}
+
+func newConfigureSsoSessionDedupeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dedupe",
+		Short: "find and merge SSO sessions with the same start URL, region and scopes",
+		Long: `Description:
+  report sso-sessions whose start URL, region, registration scopes and
+  credential source are all the same, and offer to merge each group: the
+  session bound to the most profiles is kept (or the one named by --keep),
+  profiles bound to the others are rebound to it and the others are deleted`,
+		Example: `  bp configure sso-session dedupe
+  bp configure sso-session dedupe --keep my-sso --yes`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			yes, err := cmd.Flags().GetBool("yes")
+			if err != nil {
+				return err
+			}
+			keep := strings.TrimSpace(cmd.Flag("keep").Value.String())
+			return dedupeSsoSessions(ctx.config, keep, yes, os.Stdin, cmd.OutOrStdout())
+		},
+		DisableFlagsInUseLine: true,
+	}
+
+	cmd.SetUsageTemplate(configureActionUsageTemplate())
+
+	cmd.Flags().String("keep", "", "SSO session to keep when it is one of a group of duplicates")
+	cmd.Flags().BoolP("yes", "y", false, "merge every group without asking")
+	cmd.Flags().BoolP("help", "h", false, "")
+
+	_ = cmd.RegisterFlagCompletionFunc("keep", completeSsoSessionNames)
+
+	return cmd
+}
+
+// dedupeSsoSessions 报告重复的 sso-session，并在确认后（yes 为 true 时不询问）合并每一组，最后一次性写入配置文件。
+func dedupeSsoSessions(cfg *Configure, keep string, yes bool, in io.Reader, out io.Writer) error {
+	if cfg == nil {
+		return fmt.Errorf("the configuration file cannot be loaded")
+	}
+	if keep != "" {
+		if _, ok := cfg.SsoSession[keep]; !ok {
+			return fmt.Errorf("sso-session %v not found", keep)
+		}
+	}
+	groups := findDuplicateSsoSessions(cfg, keep)
+	if len(groups) == 0 {
+		fmt.Fprintln(out, "No duplicate sso-sessions found.")
+		return nil
+	}
+
+	reader := bufio.NewReader(in)
+	merged := 0
+	for _, group := range groups {
+		fmt.Fprintf(out, "Duplicate sso-sessions for %s (region %s):\n", group.StartURL, group.Region)
+		fmt.Fprintf(out, "  keep   %s%s\n", group.Keep, describeSsoSessionProfiles(cfg, group.Keep))
+		for _, name := range group.Merge {
+			fmt.Fprintf(out, "  merge  %s%s\n", name, describeSsoSessionProfiles(cfg, name))
+		}
+		if !yes {
+			fmt.Fprintf(out, "Merge into %s? [y/N]: ", group.Keep)
+			answer, err := reader.ReadString('\n')
+			if err != nil && err != io.EOF {
+				return err
+			}
+			if err == io.EOF {
+				fmt.Fprintln(out)
+			}
+			answer = strings.ToLower(strings.TrimSpace(answer))
+			if answer != "y" && answer != "yes" {
+				continue
+			}
+		}
+		mergeSsoSessions(cfg, group)
+		merged += len(group.Merge)
+	}
+	if merged == 0 {
+		return nil
+	}
+	if err := WriteConfigToFile(cfg); err != nil {
+		return err
+	}
+	fmt.Fprintf(statusWriter(out), "Merged %d sso-session(s). Run 'bp sso login' for a kept session that is not logged in yet.\n", merged)
+	return nil
+}
+
+// findDuplicateSsoSessions 按 ssoSessionDedupeKey 分组，返回包含多个 session 的组，按保留的 session 名排序。
+// 保留 keep（属于该组时），否则保留绑定 profile 最多的 session，数量相同时取名称最小者。
+func findDuplicateSsoSessions(cfg *Configure, keep string) []ssoSessionDuplicates {
+	byKey := map[string][]string{}
+	for name, session := range cfg.SsoSession {
+		if session == nil {
+			continue
+		}
+		key := ssoSessionDedupeKey(session)
+		byKey[key] = append(byKey[key], name)
+	}
+
+	var groups []ssoSessionDuplicates
+	for _, names := range byKey {
+		if len(names) < 2 {
+			continue
+		}
+		sort.Slice(names, func(i, j int) bool {
+			if names[i] == keep || names[j] == keep {
+				return names[i] == keep
+			}
+			ci, cj := len(profilesUsingSsoSession(cfg, names[i])), len(profilesUsingSsoSession(cfg, names[j]))
+			if ci != cj {
+				return ci > cj
+			}
+			return names[i] < names[j]
+		})
+		kept := cfg.SsoSession[names[0]]
+		groups = append(groups, ssoSessionDuplicates{
+			Keep:     names[0],
+			Merge:    names[1:],
+			StartURL: kept.StartURL,
+			Region:   kept.Region,
+		})
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Keep < groups[j].Keep })
+	return groups
+}
+
+// ssoSessionDedupeKey 规范化判断重复所用的字段：start URL 忽略首尾空白、结尾的 "/" 以及 scheme 和 host 的大小写，
+// scopes 忽略顺序与重复项，未设置的 scopes 与 credential-source 视同默认值。
+func ssoSessionDedupeKey(session *SsoSession) string {
+	startURL := strings.TrimRight(strings.TrimSpace(session.StartURL), "/")
+	if u, err := url.Parse(startURL); err == nil && u.Host != "" {
+		u.Scheme = strings.ToLower(u.Scheme)
+		u.Host = strings.ToLower(u.Host)
+		startURL = u.String()
+	}
+	scopes, err := normalizeRegistrationScopes(session.RegistrationScopes)
+	if err != nil {
+		scopes = session.RegistrationScopes
+	}
+	scopes = append([]string(nil), scopes...)
+	sort.Strings(scopes)
+	credentialSource := strings.TrimSpace(session.CredentialSource)
+	if credentialSource == "" {
+		credentialSource = SsoCredentialSourcePortal
+	}
+	return strings.Join([]string{
+		startURL,
+		strings.ToLower(strings.TrimSpace(session.Region)),
+		strings.Join(scopes, ","),
+		credentialSource,
+	}, "\n")
+}
+
+// mergeSsoSessions 把绑定 group.Merge 中 session 的 profile 改为绑定 group.Keep，并删除这些 session。
+// profile 的账号、角色与已获取的 STS 凭证保持不变。
+func mergeSsoSessions(cfg *Configure, group ssoSessionDuplicates) {
+	for _, name := range group.Merge {
+		for _, profileName := range profilesUsingSsoSession(cfg, name) {
+			cfg.Profiles[profileName].SsoSessionName = group.Keep
+		}
+		delete(cfg.SsoSession, name)
+	}
+}
+
+func describeSsoSessionProfiles(cfg *Configure, sessionName string) string {
+	profiles := profilesUsingSsoSession(cfg, sessionName)
+	if len(profiles) == 0 {
+		return " (no profiles)"
+	}
+	return fmt.Sprintf(" (profiles: %s)", strings.Join(profiles, ", "))
+}
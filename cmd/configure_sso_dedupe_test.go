@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func newDedupeTestConfig() *Configure {
+	return &Configure{
+		Profiles: map[string]*Profile{
+			"dev":  {Name: "dev", Mode: ModeSSO, SsoSessionName: "corp", AccountId: "1", RoleName: "dev"},
+			"prod": {Name: "prod", Mode: ModeSSO, SsoSessionName: "corp-2", AccountId: "2", RoleName: "admin"},
+			"ops":  {Name: "ops", Mode: ModeSSO, SsoSessionName: "corp-2", AccountId: "2", RoleName: "ops"},
+			"ak":   {Name: "ak", Mode: ModeAK, AccessKey: "ak", SecretKey: "sk"},
+		},
+		SsoSession: map[string]*SsoSession{
+			"corp": {Name: "corp", StartURL: "https://corp.byteplusidentity.com/userportal", Region: "ap-southeast-1"},
+			"corp-2": {Name: "corp-2", StartURL: "HTTPS://Corp.byteplusidentity.com/userportal/", Region: "ap-southeast-1",
+				RegistrationScopes: []string{"offline_access", "cloudidentity:account:access"}},
+			"corp-wi": {Name: "corp-wi", StartURL: "https://corp.byteplusidentity.com/userportal", Region: "ap-southeast-1",
+				CredentialSource: SsoCredentialSourceWebIdentity},
+			"other": {Name: "other", StartURL: "https://other.byteplusidentity.com/userportal", Region: "ap-southeast-1"},
+		},
+	}
+}
+
+func TestFindDuplicateSsoSessions(t *testing.T) {
+	cfg := newDedupeTestConfig()
+	groups := findDuplicateSsoSessions(cfg, "")
+	if len(groups) != 1 {
+		t.Fatalf("groups = %+v, want one group", groups)
+	}
+	// corp-2 绑定的 profile 更多，作为保留的 session
+	if groups[0].Keep != "corp-2" || strings.Join(groups[0].Merge, ",") != "corp" {
+		t.Fatalf("group = %+v, want corp merged into corp-2", groups[0])
+	}
+
+	groups = findDuplicateSsoSessions(cfg, "corp")
+	if len(groups) != 1 || groups[0].Keep != "corp" || strings.Join(groups[0].Merge, ",") != "corp-2" {
+		t.Fatalf("groups with keep = %+v, want corp-2 merged into corp", groups)
+	}
+}
+
+func TestDedupeSsoSessionsMergesOnConfirmation(t *testing.T) {
+	withTestConfigDir(t)
+
+	cfg := newDedupeTestConfig()
+	var out bytes.Buffer
+	if err := dedupeSsoSessions(cfg, "", false, strings.NewReader("n\n"), &out); err != nil {
+		t.Fatalf("dedupeSsoSessions() error = %v", err)
+	}
+	if _, ok := cfg.SsoSession["corp"]; !ok || cfg.Profiles["dev"].SsoSessionName != "corp" {
+		t.Fatal("declined merge changed the config")
+	}
+	if !strings.Contains(out.String(), "merge  corp (profiles: dev)") {
+		t.Fatalf("report = %q", out.String())
+	}
+
+	out.Reset()
+	if err := dedupeSsoSessions(cfg, "", false, strings.NewReader("y\n"), &out); err != nil {
+		t.Fatalf("dedupeSsoSessions() error = %v", err)
+	}
+	if _, ok := cfg.SsoSession["corp"]; ok {
+		t.Fatal("merged sso-session corp was not deleted")
+	}
+	if cfg.Profiles["dev"].SsoSessionName != "corp-2" || cfg.Profiles["dev"].AccountId != "1" {
+		t.Fatalf("dev profile = %+v, want it rebound to corp-2", cfg.Profiles["dev"])
+	}
+	if _, ok := cfg.SsoSession["corp-wi"]; !ok {
+		t.Fatal("session with another credential source was merged")
+	}
+
+	out.Reset()
+	if err := dedupeSsoSessions(cfg, "", true, strings.NewReader(""), &out); err != nil {
+		t.Fatalf("dedupeSsoSessions() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "No duplicate sso-sessions found.") {
+		t.Fatalf("second run output = %q", out.String())
+	}
+}
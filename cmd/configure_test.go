@@ -1,11 +1,16 @@
 package cmd
 
 import (
+	"bytes"
 	"encoding/json"
+	"net/http"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/byteplus-sdk/byteplus-go-sdk-v2/byteplus/credentials/clicreds"
 )
@@ -137,6 +142,42 @@ func TestConfigureSetSupportsOIDCModeFields(t *testing.T) {
 	}
 }
 
+func TestConfigureSsoSessionKeepsCredentialSource(t *testing.T) {
+	dir := withTestConfigDir(t)
+	withTestCtxConfig(t, &Configure{Profiles: map[string]*Profile{}, SsoSession: map[string]*SsoSession{}})
+	oldFlags := ssoSessionFlags
+	t.Cleanup(func() { ssoSessionFlags = oldFlags })
+
+	run := func(extra ...string) error {
+		ssoSessionFlags = SsoSession{}
+		cmd := newConfigureSsoSessionCmd()
+		cmd.SetArgs(append([]string{
+			"--name", "my-sso",
+			"--start-url", "https://example.byteplusidentity.com/userportal",
+			"--region", "ap-southeast-1",
+			"--registration-scopes", "cloudidentity:account:access",
+		}, extra...))
+		return cmd.Execute()
+	}
+
+	if err := run("--credential-source", "web-identity"); err != nil {
+		t.Fatalf("configure sso-session returned error: %v", err)
+	}
+	// 未传 --credential-source 时沿用已有会话的取值
+	if err := run(); err != nil {
+		t.Fatalf("configure sso-session returned error: %v", err)
+	}
+	raw := readConfigFileAsMap(t, dir)
+	session := raw["sso-session"].(map[string]interface{})["my-sso"].(map[string]interface{})
+	if session["credential-source"] != "web-identity" {
+		t.Fatalf("credential-source = %v, want web-identity", session["credential-source"])
+	}
+
+	if err := run("--credential-source", "saml"); err == nil || !strings.Contains(err.Error(), "unsupported credential source") {
+		t.Fatalf("configure sso-session error = %v, want unsupported credential source", err)
+	}
+}
+
 func TestConfigureSetSupportsRamRoleArnModeFields(t *testing.T) {
 	dir := withTestConfigDir(t)
 	resetProfileFlagsForTest(t)
@@ -170,6 +211,49 @@ func TestConfigureSetSupportsRamRoleArnModeFields(t *testing.T) {
 	}
 }
 
+func TestConfigureSetSsoCreatesBoundProfileWithoutLogin(t *testing.T) {
+	dir := withTestConfigDir(t)
+	resetProfileFlagsForTest(t)
+	withTestCtxConfig(t, &Configure{
+		Profiles: map[string]*Profile{
+			"dev": {Name: "dev", Mode: ModeAK, AccessKey: "old-ak", SecretKey: "old-sk", SessionToken: "old-token"},
+		},
+		SsoSession: map[string]*SsoSession{"my-sso": {Name: "my-sso", StartURL: "https://example.com/userportal", Region: "ap-southeast-1"}},
+	})
+
+	run := func(args ...string) error {
+		resetProfileFlagsForTest(t)
+		setCmd := newConfigureSetCmd()
+		setCmd.SetArgs(args)
+		return setCmd.Execute()
+	}
+
+	if err := run("--profile", "dev", "--sso", "--sso-session", "my-sso", "--account-id", "2100000000", "--role-name", "AdminRole"); err != nil {
+		t.Fatalf("configure set --sso returned error: %v", err)
+	}
+	profile := readConfigFileAsMap(t, dir)["profiles"].(map[string]interface{})["dev"].(map[string]interface{})
+	if profile["mode"] != ModeSSO || profile["sso-session-name"] != "my-sso" || profile["account-id"] != "2100000000" || profile["role-name"] != "AdminRole" {
+		t.Fatalf("profile = %#v, want an sso profile bound to my-sso", profile)
+	}
+	if got := ctx.config.Profiles["dev"]; got.AccessKey != "" || got.SessionToken != "" {
+		t.Fatalf("stale credentials kept after switching to sso: %#v", got)
+	}
+
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--profile", "p", "--sso", "--account-id", "1", "--role-name", "r"}, "requires --sso-session"},
+		{[]string{"--profile", "p", "--mode", "sso", "--sso-session", "my-sso", "--role-name", "r"}, "requires --account-id"},
+		{[]string{"--profile", "p", "--sso", "--sso-session", "missing", "--account-id", "1", "--role-name", "r"}, "does not exist"},
+		{[]string{"--profile", "p", "--sso", "--mode", "ak"}, "cannot be combined"},
+	} {
+		if err := run(tc.args...); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("configure set %v error = %v, want %q", tc.args, err, tc.want)
+		}
+	}
+}
+
 func TestConfigureSetSupportsEcsRoleModeFields(t *testing.T) {
 	dir := withTestConfigDir(t)
 	resetProfileFlagsForTest(t)
@@ -375,6 +459,10 @@ func TestValidateProfileModeAcceptsValidModes(t *testing.T) {
 			name:    "ecsrole",
 			profile: &Profile{Name: "p", Mode: ModeEcsRole, RoleName: "role"},
 		},
+		{
+			name:    "credential process without keys",
+			profile: &Profile{Name: "p", Mode: ModeAK, CredentialProcess: "/usr/local/bin/broker"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -551,6 +639,109 @@ func TestNewSimpleClientProfileOverrideValid(t *testing.T) {
 	}
 }
 
+func envProfileTestConfig() *Configure {
+	falseVal := false
+	return &Configure{
+		Current: "default",
+		Profiles: map[string]*Profile{
+			"default": {
+				Name:       "default",
+				Mode:       ModeAK,
+				AccessKey:  "default-ak",
+				SecretKey:  "default-sk",
+				Region:     "ap-southeast-1",
+				DisableSSL: &falseVal,
+			},
+			"prod": {
+				Name:       "prod",
+				Mode:       ModeAK,
+				AccessKey:  "prod-ak",
+				SecretKey:  "prod-sk",
+				Region:     "cn-beijing",
+				DisableSSL: &falseVal,
+			},
+			"staging": {
+				Name:       "staging",
+				Mode:       ModeAK,
+				AccessKey:  "staging-ak",
+				SecretKey:  "staging-sk",
+				Region:     "ap-southeast-3",
+				DisableSSL: &falseVal,
+			},
+		},
+	}
+}
+
+func TestNewSimpleClientEnvProfileOverridesCurrent(t *testing.T) {
+	t.Setenv("BYTEPLUS_PROFILE", "prod")
+	t.Setenv("BYTEPLUS_CLI_PROFILE", "")
+
+	testCtx := NewContext()
+	testCtx.SetConfig(envProfileTestConfig())
+
+	client, err := NewSimpleClient(testCtx)
+	if err != nil {
+		t.Fatalf("NewSimpleClient returned error: %v", err)
+	}
+	if client.Config.Region == nil || *client.Config.Region != "cn-beijing" {
+		t.Fatalf("region = %v, want cn-beijing from BYTEPLUS_PROFILE", client.Config.Region)
+	}
+}
+
+func TestNewSimpleClientProfileFlagOverridesEnvProfile(t *testing.T) {
+	t.Setenv("BYTEPLUS_PROFILE", "prod")
+	t.Setenv("BYTEPLUS_CLI_PROFILE", "")
+
+	testCtx := NewContext()
+	testCtx.SetConfig(envProfileTestConfig())
+	flag, _ := testCtx.fixedFlags.AddByName("profile")
+	flag.SetValue("staging")
+
+	client, err := NewSimpleClient(testCtx)
+	if err != nil {
+		t.Fatalf("NewSimpleClient returned error: %v", err)
+	}
+	if client.Config.Region == nil || *client.Config.Region != "ap-southeast-3" {
+		t.Fatalf("region = %v, want ap-southeast-3 from ---profile", client.Config.Region)
+	}
+}
+
+func TestNewSimpleClientEnvProfileNotFound(t *testing.T) {
+	t.Setenv("BYTEPLUS_PROFILE", "missing")
+	t.Setenv("BYTEPLUS_CLI_PROFILE", "")
+
+	testCtx := NewContext()
+	testCtx.SetConfig(envProfileTestConfig())
+
+	_, err := NewSimpleClient(testCtx)
+	if err == nil {
+		t.Fatal("expected error for missing BYTEPLUS_PROFILE profile")
+	}
+	if !strings.Contains(err.Error(), "BYTEPLUS_PROFILE") {
+		t.Fatalf("error = %q, want env variable name", err.Error())
+	}
+}
+
+func TestDefaultProfileNameWithSourcePrefersEnv(t *testing.T) {
+	cfg := &Configure{Current: "default"}
+
+	t.Setenv("BYTEPLUS_PROFILE", "")
+	t.Setenv("BYTEPLUS_CLI_PROFILE", "")
+	if name, source := defaultProfileNameWithSource(cfg); name != "default" || source != "current" {
+		t.Fatalf("got (%q, %q), want (default, current)", name, source)
+	}
+
+	t.Setenv("BYTEPLUS_CLI_PROFILE", "legacy")
+	if name, source := defaultProfileNameWithSource(cfg); name != "legacy" || source != "env:BYTEPLUS_CLI_PROFILE" {
+		t.Fatalf("got (%q, %q), want legacy from BYTEPLUS_CLI_PROFILE", name, source)
+	}
+
+	t.Setenv("BYTEPLUS_PROFILE", "prod")
+	if name, source := defaultProfileNameWithSource(cfg); name != "prod" || source != "env:BYTEPLUS_PROFILE" {
+		t.Fatalf("got (%q, %q), want prod from BYTEPLUS_PROFILE", name, source)
+	}
+}
+
 func TestNewSimpleClientRegionOverride(t *testing.T) {
 	falseVal := false
 	testCtx := NewContext()
@@ -592,6 +783,7 @@ func TestNewSimpleClientEndpointAutoAddressingUsesStandardResolver(t *testing.T)
 				SecretKey:  "sk",
 				Region:     "ap-southeast-1",
 				Endpoint:   "auto-addressing",
+				Endpoints:  map[string]string{"ecs": "ecs.internal.example.com"},
 				DisableSSL: &falseVal,
 			},
 		},
@@ -607,6 +799,148 @@ func TestNewSimpleClientEndpointAutoAddressingUsesStandardResolver(t *testing.T)
 	if client.Config.EndpointResolver == nil {
 		t.Fatal("EndpointResolver should be set when endpoint=auto-addressing")
 	}
+	if got := client.serviceEndpoint("ecs"); got != "" {
+		t.Fatalf("ecs endpoint = %q, want endpoints.ecs ignored when endpoint=auto-addressing", got)
+	}
+}
+
+func TestNewSimpleClientEndpointResolverValues(t *testing.T) {
+	for _, tc := range []struct {
+		resolver string
+		wantErr  bool
+	}{
+		{resolver: "auto"},
+		{resolver: "Auto-Addressing"},
+		{resolver: "standard"},
+		{resolver: "automatic", wantErr: true},
+	} {
+		falseVal := false
+		testCtx := NewContext()
+		testCtx.SetConfig(&Configure{
+			Current: "default",
+			Profiles: map[string]*Profile{
+				"default": {
+					Name:             "default",
+					Mode:             ModeAK,
+					AccessKey:        "ak",
+					SecretKey:        "sk",
+					Region:           "ap-southeast-1",
+					Endpoint:         "open.byteplusapi.com",
+					Endpoints:        map[string]string{"ecs": "ecs.internal.example.com"},
+					EndpointResolver: tc.resolver,
+					DisableSSL:       &falseVal,
+				},
+			},
+		})
+
+		client, err := NewSimpleClient(testCtx)
+		if tc.wantErr {
+			if err == nil || !strings.Contains(err.Error(), "supported values") {
+				t.Fatalf("endpoint-resolver %q: error = %v, want unsupported value error", tc.resolver, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("endpoint-resolver %q: NewSimpleClient returned error: %v", tc.resolver, err)
+		}
+		if client.Config.EndpointResolver == nil || client.Config.Endpoint != nil {
+			t.Fatalf("endpoint-resolver %q: resolver = %v, endpoint = %v", tc.resolver, client.Config.EndpointResolver, client.Config.Endpoint)
+		}
+		if got := client.serviceEndpoint("ecs"); got != "" {
+			t.Fatalf("endpoint-resolver %q: ecs endpoint = %q, want the standard resolver to ignore endpoints.ecs", tc.resolver, got)
+		}
+	}
+}
+
+func TestNewSimpleClientInsecureSkipVerify(t *testing.T) {
+	insecure := func(client *SdkClient) bool {
+		if client.Config.HTTPClient == nil {
+			return false
+		}
+		transport, ok := client.Config.HTTPClient.Transport.(*http.Transport)
+		return ok && transport.TLSClientConfig != nil && transport.TLSClientConfig.InsecureSkipVerify
+	}
+	newCtx := func(profileValue *bool) *Context {
+		falseVal := false
+		testCtx := NewContext()
+		testCtx.SetConfig(&Configure{
+			Current: "default",
+			Profiles: map[string]*Profile{
+				"default": {
+					Name:               "default",
+					Mode:               ModeAK,
+					AccessKey:          "ak",
+					SecretKey:          "sk",
+					Region:             "ap-southeast-1",
+					DisableSSL:         &falseVal,
+					InsecureSkipVerify: profileValue,
+				},
+			},
+		})
+		return testCtx
+	}
+
+	client, err := NewSimpleClient(newCtx(nil))
+	if err != nil {
+		t.Fatalf("NewSimpleClient returned error: %v", err)
+	}
+	if insecure(client) {
+		t.Fatal("certificate verification skipped without insecure-skip-verify")
+	}
+
+	trueVal := true
+	client, err = NewSimpleClient(newCtx(&trueVal))
+	if err != nil {
+		t.Fatalf("NewSimpleClient returned error: %v", err)
+	}
+	if !insecure(client) || *client.Config.DisableSSL {
+		t.Fatal("profile insecure-skip-verify should skip certificate verification and keep https")
+	}
+
+	testCtx := newCtx(nil)
+	flag, _ := testCtx.fixedFlags.AddByName("insecure-skip-verify")
+	flag.SetValue("true")
+	client, err = NewSimpleClient(testCtx)
+	if err != nil {
+		t.Fatalf("NewSimpleClient returned error: %v", err)
+	}
+	if !insecure(client) {
+		t.Fatal("---insecure-skip-verify should skip certificate verification")
+	}
+}
+
+func TestNewSimpleClientTransportPoolSettings(t *testing.T) {
+	newCtx := func(cfg *Configure) *Context {
+		falseVal := false
+		cfg.Current = "default"
+		cfg.Profiles = map[string]*Profile{
+			"default": {Name: "default", Mode: ModeAK, AccessKey: "ak", SecretKey: "sk", Region: "ap-southeast-1", DisableSSL: &falseVal},
+		}
+		testCtx := NewContext()
+		testCtx.SetConfig(cfg)
+		return testCtx
+	}
+	transportOf := func(cfg *Configure) *http.Transport {
+		client, err := NewSimpleClient(newCtx(cfg))
+		if err != nil {
+			t.Fatalf("NewSimpleClient returned error: %v", err)
+		}
+		transport, ok := client.Config.HTTPClient.Transport.(*http.Transport)
+		if !ok {
+			t.Fatalf("transport = %T, want *http.Transport", client.Config.HTTPClient.Transport)
+		}
+		return transport
+	}
+
+	if got := transportOf(&Configure{}); got.MaxIdleConnsPerHost != defaultMaxIdleConnsPerHost || got.MaxConnsPerHost != 0 {
+		t.Fatalf("default pool = %d idle / %d max per host", got.MaxIdleConnsPerHost, got.MaxConnsPerHost)
+	}
+	if got := transportOf(&Configure{MaxIdleConnsPerHost: 200}); got.MaxIdleConnsPerHost != 200 || got.MaxIdleConns < 200 {
+		t.Fatalf("configured pool = %d idle per host / %d idle total", got.MaxIdleConnsPerHost, got.MaxIdleConns)
+	}
+	if got := transportOf(&Configure{MaxConnsPerHost: 8}); got.MaxIdleConnsPerHost != 8 || got.MaxConnsPerHost != 8 {
+		t.Fatalf("capped pool = %d idle / %d max per host, want 8 / 8", got.MaxIdleConnsPerHost, got.MaxConnsPerHost)
+	}
 }
 
 func TestNewSimpleClientRegionOverrideFixesEmptyProfileRegion(t *testing.T) {
@@ -636,6 +970,34 @@ func TestNewSimpleClientRegionOverrideFixesEmptyProfileRegion(t *testing.T) {
 	}
 }
 
+func TestNewSimpleClientSsoProfileFallsBackToSessionRegion(t *testing.T) {
+	t.Setenv("BYTEPLUS_REGION", "env-region")
+	testCtx := NewContext()
+	testCtx.SetConfig(&Configure{
+		Current: "sso-dev",
+		Profiles: map[string]*Profile{
+			"sso-dev": {
+				Name:           "sso-dev",
+				Mode:           ModeSSO,
+				SsoSessionName: "my-sso",
+				SessionToken:   "token",
+				StsExpiration:  time.Now().Add(time.Hour).Unix(),
+			},
+		},
+		SsoSession: map[string]*SsoSession{
+			"my-sso": {Name: "my-sso", StartURL: "https://example.com/userportal", Region: "ap-southeast-3"},
+		},
+	})
+
+	client, err := NewSimpleClient(testCtx)
+	if err != nil {
+		t.Fatalf("NewSimpleClient returned error: %v", err)
+	}
+	if client.Config.Region == nil || *client.Config.Region != "ap-southeast-3" {
+		t.Fatalf("region = %v, want the sso-session region ap-southeast-3", client.Config.Region)
+	}
+}
+
 func TestNewSimpleClientRegionOverrideFixesEmptyEnvRegion(t *testing.T) {
 	t.Setenv("BYTEPLUS_DISABLE_DEFAULT_CREDENTIALS", "")
 	t.Setenv("BYTEPLUS_ACCESS_KEY", "env-ak")
@@ -713,6 +1075,64 @@ func TestNewSimpleClientDisableDefaultCredentials(t *testing.T) {
 	}
 }
 
+func TestNewSimpleClientNoConfigIgnoresProfiles(t *testing.T) {
+	t.Setenv(ignoreConfigEnv, "")
+	t.Setenv("BYTEPLUS_PROFILE", "")
+	t.Setenv("BYTEPLUS_CLI_PROFILE", "")
+	t.Setenv("BYTEPLUS_ACCESS_KEY", "env-ak")
+	t.Setenv("BYTEPLUS_SECRET_KEY", "env-sk")
+	t.Setenv("BYTEPLUS_REGION", "ap-southeast-1")
+
+	testCtx := NewContext()
+	testCtx.SetConfig(&Configure{
+		Current: "stale",
+		Profiles: map[string]*Profile{
+			"stale": {Name: "stale", Mode: ModeAK, AccessKey: "old-ak", SecretKey: "old-sk", Region: "ap-southeast-3"},
+		},
+	})
+	parser := NewParser([]string{"---no-config"})
+	if _, err := parser.ReadArgs(testCtx); err != nil {
+		t.Fatalf("ReadArgs() error = %v", err)
+	}
+
+	client, err := NewSimpleClient(testCtx)
+	if err != nil {
+		t.Fatalf("NewSimpleClient returned error: %v", err)
+	}
+	if client.Config.Region == nil || *client.Config.Region != "ap-southeast-1" {
+		t.Fatalf("region = %v, want ap-southeast-1 from the environment", client.Config.Region)
+	}
+
+	flag, _ := testCtx.fixedFlags.AddByName("profile")
+	flag.SetValue("stale")
+	if _, err := NewSimpleClient(testCtx); err == nil || !strings.Contains(err.Error(), "---profile cannot be used") {
+		t.Fatalf("NewSimpleClient error = %v, want ---profile conflict", err)
+	}
+}
+
+func TestNewSimpleClientIgnoreConfigEnvRequiresEnvCredentials(t *testing.T) {
+	t.Setenv(ignoreConfigEnv, "true")
+	t.Setenv("BYTEPLUS_ACCESS_KEY", "")
+	t.Setenv("BYTEPLUS_ACCESS_KEY_ID", "")
+	t.Setenv("BYTEPLUS_SECRET_KEY", "")
+	t.Setenv("BYTEPLUS_SECRET_ACCESS_KEY", "")
+	t.Setenv("BYTEPLUS_REGION", "")
+
+	testCtx := NewContext()
+	testCtx.SetConfig(&Configure{
+		Current:  "dev",
+		Profiles: map[string]*Profile{"dev": {Name: "dev", Mode: ModeAK, AccessKey: "ak", SecretKey: "sk", Region: "ap-southeast-1"}},
+	})
+
+	_, err := NewSimpleClient(testCtx)
+	if err == nil {
+		t.Fatal("expected error when environment credentials are missing")
+	}
+	if !strings.Contains(err.Error(), "BYTEPLUS_ACCESS_KEY, BYTEPLUS_SECRET_KEY, BYTEPLUS_REGION") {
+		t.Fatalf("error = %q, want every missing variable listed", err.Error())
+	}
+}
+
 func TestNewSimpleClientRequiresRegion(t *testing.T) {
 	t.Setenv("BYTEPLUS_DISABLE_DEFAULT_CREDENTIALS", "")
 	t.Setenv("BYTEPLUS_ACCESS_KEY", "env-ak")
@@ -830,3 +1250,406 @@ func TestCliProviderContractConsoleLoginModeRecognized(t *testing.T) {
 		t.Fatalf("expected console-login provider cache error, got: %v", err)
 	}
 }
+
+func TestNewSimpleClientAppliesServiceEndpoint(t *testing.T) {
+	t.Setenv("BYTEPLUS_PROFILE", "")
+	t.Setenv("BYTEPLUS_CLI_PROFILE", "")
+
+	cfg := envProfileTestConfig()
+	cfg.Profiles["default"].Endpoint = "open.byteplusapi.com"
+	cfg.Profiles["default"].Endpoints = map[string]string{"ecs": "ecs.internal.example.com"}
+	testCtx := NewContext()
+	testCtx.SetConfig(cfg)
+
+	client, err := NewSimpleClient(testCtx)
+	if err != nil {
+		t.Fatalf("NewSimpleClient returned error: %v", err)
+	}
+	if got := client.serviceEndpoint("ecs"); got != "ecs.internal.example.com" {
+		t.Fatalf("ecs endpoint = %q, want ecs.internal.example.com", got)
+	}
+	if got := client.serviceEndpoint("vpc"); got != "" {
+		t.Fatalf("vpc endpoint = %q, want fallback to global endpoint", got)
+	}
+	if client.Config.Endpoint == nil || *client.Config.Endpoint != "open.byteplusapi.com" {
+		t.Fatalf("global endpoint = %v, want open.byteplusapi.com", client.Config.Endpoint)
+	}
+}
+
+func TestServiceEndpointMatchesMappedServiceName(t *testing.T) {
+	SetServiceMapping("rds_mysql_v2", "rds_mysql")
+	defer delete(serviceMapping, "rds_mysql_v2")
+
+	client := &SdkClient{ServiceEndpoints: map[string]string{"rds_mysql_v2": " rds.internal.example.com "}}
+	if got := client.serviceEndpoint("rds_mysql"); got != "rds.internal.example.com" {
+		t.Fatalf("rds_mysql endpoint = %q, want rds.internal.example.com", got)
+	}
+}
+
+func TestNewSimpleClientEndpointFlagOverridesServiceEndpoints(t *testing.T) {
+	t.Setenv("BYTEPLUS_PROFILE", "")
+	t.Setenv("BYTEPLUS_CLI_PROFILE", "")
+
+	cfg := envProfileTestConfig()
+	cfg.Profiles["default"].Endpoints = map[string]string{"ecs": "ecs.internal.example.com"}
+	testCtx := NewContext()
+	testCtx.SetConfig(cfg)
+	flag, _ := testCtx.fixedFlags.AddByName("endpoint")
+	flag.SetValue("ecs.byteplusapi.com")
+
+	client, err := NewSimpleClient(testCtx)
+	if err != nil {
+		t.Fatalf("NewSimpleClient returned error: %v", err)
+	}
+	if got := client.serviceEndpoint("ecs"); got != "" {
+		t.Fatalf("ecs endpoint = %q, want ---endpoint to take precedence", got)
+	}
+}
+
+func TestCloneProfileCopiesEndpoints(t *testing.T) {
+	profile := &Profile{Name: "default", Endpoints: map[string]string{"ecs": "ecs.internal.example.com"}}
+	clone := cloneProfile(profile)
+	clone.Endpoints["ecs"] = "changed"
+	if profile.Endpoints["ecs"] != "ecs.internal.example.com" {
+		t.Fatalf("cloneProfile shared the Endpoints map with the original profile")
+	}
+}
+
+func TestPromptSelectSettings(t *testing.T) {
+	withTestCtxConfig(t, &Configure{Profiles: map[string]*Profile{}})
+	if size, searchMode := promptSelectSettings(); size != 10 || !searchMode {
+		t.Fatalf("promptSelectSettings() = (%d, %t), want defaults (10, true)", size, searchMode)
+	}
+
+	searchMode := false
+	withTestCtxConfig(t, &Configure{PromptListSize: 25, PromptSearchMode: &searchMode})
+	if size, gotSearch := promptSelectSettings(); size != 25 || gotSearch {
+		t.Fatalf("promptSelectSettings() = (%d, %t), want (25, false)", size, gotSearch)
+	}
+}
+
+func TestConfigureSetMergesProfileExtra(t *testing.T) {
+	dir := withTestConfigDir(t)
+	resetProfileFlagsForTest(t)
+	withTestCtxConfig(t, &Configure{Profiles: map[string]*Profile{}})
+
+	run := func(args ...string) error {
+		setCmd := newConfigureSetCmd()
+		setCmd.SetArgs(append([]string{"--profile", "dev", "--access-key", "ak", "--secret-key", "sk"}, args...))
+		return setCmd.Execute()
+	}
+	if err := run("--extra", "---output=table,paginate=true"); err != nil {
+		t.Fatalf("configure set returned error: %v", err)
+	}
+	// 再次设置时只合并传入的键，值为空的键被删除
+	if err := run("--extra", "region=ap-southeast-1", "--extra", "paginate="); err != nil {
+		t.Fatalf("configure set returned error: %v", err)
+	}
+	raw := readConfigFileAsMap(t, dir)
+	extra := raw["profiles"].(map[string]interface{})["dev"].(map[string]interface{})["extra"]
+	want := map[string]interface{}{"output": "table", "region": "ap-southeast-1"}
+	if !reflect.DeepEqual(extra, want) {
+		t.Fatalf("extra = %#v, want %#v", extra, want)
+	}
+
+	for _, bad := range []string{"debug=true", "profile=other", "count=yes"} {
+		if err := run("--extra", bad); err == nil {
+			t.Fatalf("configure set --extra %s error = nil, want error", bad)
+		}
+	}
+}
+
+func TestApplyProfileExtraKeepsExplicitFlags(t *testing.T) {
+	withTestCtxConfig(t, &Configure{
+		Current: "dev",
+		Profiles: map[string]*Profile{
+			"dev": {Name: "dev", Extra: map[string]string{"output": "table", "region": "ap-southeast-1", "paginate": "true", "count": "false"}},
+			"ops": {Name: "ops", Extra: map[string]string{"output": "text"}},
+		},
+	})
+
+	c := NewContext()
+	c.SetConfig(ctx.config)
+	if _, err := NewParser([]string{"---region", "cn-beijing"}).ReadArgs(c); err != nil {
+		t.Fatalf("ReadArgs() error = %v", err)
+	}
+	if err := applyProfileExtra(c); err != nil {
+		t.Fatalf("applyProfileExtra() error = %v", err)
+	}
+	got := map[string]string{}
+	for _, f := range c.fixedFlags.GetFlags() {
+		got[f.Name] = f.GetValue()
+	}
+	want := map[string]string{"region": "cn-beijing", "output": "table", "paginate": "true"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("fixed flags = %#v, want %#v", got, want)
+	}
+
+	c = NewContext()
+	c.SetConfig(ctx.config)
+	if _, err := NewParser([]string{"---profile", "ops"}).ReadArgs(c); err != nil {
+		t.Fatalf("ReadArgs() error = %v", err)
+	}
+	if err := applyProfileExtra(c); err != nil {
+		t.Fatalf("applyProfileExtra() error = %v", err)
+	}
+	if f := c.fixedFlags.GetByName("output"); f == nil || f.GetValue() != "text" {
+		t.Fatalf("output = %v, want text from ---profile ops", f)
+	}
+}
+
+func TestConfigureSetRegionUpdatesOnlyRegion(t *testing.T) {
+	dir := withTestConfigDir(t)
+	resetProfileFlagsForTest(t)
+	withTestCtxConfig(t, &Configure{Profiles: map[string]*Profile{
+		"prod": {Name: "prod", Mode: ModeAK, AccessKey: "ak", SecretKey: "sk", Region: "cn-beijing", Endpoint: "open.byteplusapi.com"},
+	}})
+
+	regionCmd := newConfigureSetRegionCmd()
+	regionCmd.SetArgs([]string{"--profile", "prod", "--region", "ap-southeast-1"})
+	if err := regionCmd.Execute(); err != nil {
+		t.Fatalf("configure set-region returned error: %v", err)
+	}
+	endpointCmd := newConfigureSetEndpointCmd()
+	endpointCmd.SetArgs([]string{"--profile", "prod", "--endpoint", "ecs.ap-southeast-1.byteplusapi.com"})
+	if err := endpointCmd.Execute(); err != nil {
+		t.Fatalf("configure set-endpoint returned error: %v", err)
+	}
+
+	profile := readConfigFileAsMap(t, dir)["profiles"].(map[string]interface{})["prod"].(map[string]interface{})
+	if profile["region"] != "ap-southeast-1" || profile["endpoint"] != "ecs.ap-southeast-1.byteplusapi.com" {
+		t.Fatalf("region/endpoint = %v/%v", profile["region"], profile["endpoint"])
+	}
+	if profile["access-key"] != "ak" || profile["secret-key"] != "sk" {
+		t.Fatalf("credentials were not preserved: %v", profile)
+	}
+
+	missingCmd := newConfigureSetRegionCmd()
+	missingCmd.SetArgs([]string{"--profile", "missing", "--region", "ap-southeast-1"})
+	if err := missingCmd.Execute(); err == nil || !strings.Contains(err.Error(), `profile "missing" not found`) {
+		t.Fatalf("configure set-region error = %v, want profile not found", err)
+	}
+}
+
+func TestSetConfigProfileTracksAccessKeyAge(t *testing.T) {
+	withTestConfigDir(t)
+	withTestCtxConfig(t, &Configure{Profiles: map[string]*Profile{}})
+	created := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	withFixedNow(t, created)
+
+	if err := setConfigProfile(&Profile{Name: "dev", AccessKey: "ak", SecretKey: "sk"}); err != nil {
+		t.Fatalf("setConfigProfile() error = %v", err)
+	}
+	if got := ctx.config.Profiles["dev"].CreatedAt; got != created.Unix() {
+		t.Fatalf("created-at = %d, want %d", got, created.Unix())
+	}
+
+	withFixedNow(t, created.Add(24*time.Hour))
+	if err := setConfigProfile(&Profile{Name: "dev", Region: "ap-southeast-1"}); err != nil {
+		t.Fatalf("setConfigProfile() error = %v", err)
+	}
+	if got := ctx.config.Profiles["dev"].CreatedAt; got != created.Unix() {
+		t.Fatalf("created-at changed without a new access key: %d", got)
+	}
+
+	rotated := created.Add(48 * time.Hour)
+	withFixedNow(t, rotated)
+	if err := setConfigProfile(&Profile{Name: "dev", AccessKey: "ak-2", SecretKey: "sk-2"}); err != nil {
+		t.Fatalf("setConfigProfile() error = %v", err)
+	}
+	if got := ctx.config.Profiles["dev"].CreatedAt; got != rotated.Unix() {
+		t.Fatalf("created-at = %d after rotation, want %d", got, rotated.Unix())
+	}
+}
+
+func TestWarnStaleAKProfile(t *testing.T) {
+	now := time.Date(2030, 6, 1, 0, 0, 0, 0, time.UTC)
+	withFixedNow(t, now)
+	var out bytes.Buffer
+	oldOut := staleProfileWarningOut
+	staleProfileWarningOut = &out
+	t.Cleanup(func() { staleProfileWarningOut = oldOut })
+
+	old := &Profile{Name: "dev", Mode: ModeAK, CreatedAt: now.Add(-100 * 24 * time.Hour).Unix()}
+	cases := []struct {
+		name    string
+		cfg     *Configure
+		profile *Profile
+		warn    bool
+	}{
+		{"disabled", &Configure{}, old, false},
+		{"stale", &Configure{ProfileTTLDays: 90}, old, true},
+		{"fresh", &Configure{ProfileTTLDays: 120}, old, false},
+		{"unknown age", &Configure{ProfileTTLDays: 90}, &Profile{Name: "legacy", Mode: ModeAK}, false},
+		{"sso", &Configure{ProfileTTLDays: 90}, &Profile{Name: "sso", Mode: ModeSSO, CreatedAt: old.CreatedAt}, false},
+	}
+	for _, tc := range cases {
+		out.Reset()
+		warnStaleAKProfile(tc.cfg, tc.profile)
+		if got := out.Len() > 0; got != tc.warn {
+			t.Fatalf("%s: warned = %v (%q), want %v", tc.name, got, out.String(), tc.warn)
+		}
+	}
+	warnStaleAKProfile(&Configure{ProfileTTLDays: 90}, old)
+	if !strings.Contains(out.String(), `profile "dev" was set 100 days ago`) {
+		t.Fatalf("warning = %q", out.String())
+	}
+}
+
+func TestConfigureDeleteSsoSessionWarnsAboutBoundProfiles(t *testing.T) {
+	dir := withTestConfigDir(t)
+	withTestCtxConfig(t, &Configure{
+		Profiles: map[string]*Profile{
+			"dev":   {Name: "dev", Mode: ModeSSO, SsoSessionName: "corp"},
+			"admin": {Name: "admin", Mode: ModeSSO, SsoSessionName: "corp"},
+			"other": {Name: "other", Mode: ModeSSO, SsoSessionName: "lab"},
+		},
+		SsoSession: map[string]*SsoSession{
+			"corp": {Name: "corp", StartURL: "https://corp.byteplusidentity.com/userportal", Region: "ap-southeast-1"},
+			"lab":  {Name: "lab", StartURL: "https://lab.byteplusidentity.com/userportal", Region: "ap-southeast-1"},
+		},
+	})
+
+	cmd := newConfigureDeleteCmd()
+	var stderr bytes.Buffer
+	cmd.SetErr(&stderr)
+	cmd.SetArgs([]string{"--sso-session", "corp"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("configure delete --sso-session error = %v", err)
+	}
+
+	sessions, _ := readConfigFileAsMap(t, dir)["sso-session"].(map[string]interface{})
+	if _, ok := sessions["corp"]; ok || sessions["lab"] == nil {
+		t.Fatalf("saved sso-sessions = %#v, want only lab", sessions)
+	}
+	lines := strings.Split(strings.TrimSpace(stderr.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `profile "admin"`) || !strings.Contains(lines[1], `profile "dev"`) {
+		t.Fatalf("warnings =\n%s", stderr.String())
+	}
+
+	cmd = newConfigureDeleteCmd()
+	cmd.SetArgs([]string{"--sso-session", "missing"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "sso-session missing not found") {
+		t.Fatalf("delete of a missing sso-session error = %v", err)
+	}
+	cmd = newConfigureDeleteCmd()
+	cmd.SetArgs([]string{})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "exactly one of --profile or --sso-session") {
+		t.Fatalf("delete without a target error = %v", err)
+	}
+}
+
+func TestSsoProfileWithMissingSessionReportsFix(t *testing.T) {
+	withTestConfigDir(t)
+	profile := &Profile{Name: "dev", Mode: ModeSSO, SsoSessionName: "corp", AccountId: "1", RoleName: "r"}
+	cfg := &Configure{Profiles: map[string]*Profile{"dev": profile}, SsoSession: map[string]*SsoSession{}}
+	withTestCtxConfig(t, cfg)
+
+	err := (&Sso{Profile: profile}).EnsureValidStsToken(ctx)
+	if err == nil || !strings.Contains(err.Error(), `profile "dev" references sso-session "corp"`) || !strings.Contains(err.Error(), "bp configure sso --profile dev") {
+		t.Fatalf("EnsureValidStsToken() error = %v, want missing sso-session hint", err)
+	}
+
+	var warnings bytes.Buffer
+	old := danglingSsoSessionWarningOut
+	danglingSsoSessionWarningOut = &warnings
+	defer func() { danglingSsoSessionWarningOut = old }()
+	profile.AccessKey, profile.SecretKey, profile.SessionToken = "ak", "sk", "token"
+	profile.StsExpiration = time.Now().Add(time.Hour).Unix()
+	if _, err := resolveCredentials(ctx, clientOverrides{Profile: "dev"}); err != nil {
+		t.Fatalf("resolveCredentials() with valid cached STS error = %v", err)
+	}
+	if !strings.Contains(warnings.String(), `Warning: profile "dev" references sso-session "corp"`) {
+		t.Fatalf("warning = %q", warnings.String())
+	}
+}
+
+func TestConfigureDiffComparesProfiles(t *testing.T) {
+	withTestConfigDir(t)
+	withTestCtxConfig(t, &Configure{
+		Current: "dev",
+		Profiles: map[string]*Profile{
+			"dev":  {Name: "dev", Mode: ModeAK, AccessKey: "AKLTdev0000001111", SecretKey: "same-secret-value", Region: "ap-southeast-1", Endpoints: map[string]string{"ecs": "ecs.dev.example.com"}},
+			"prod": {Name: "prod", Mode: ModeAK, AccessKey: "AKLTprod000002222", SecretKey: "same-secret-value", Region: "ap-southeast-3"},
+		},
+	})
+
+	run := func(args ...string) (string, error) {
+		cmd := newConfigureDiffCmd()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	out, err := run("--profile", "dev", "--profile", "prod")
+	if err != nil {
+		t.Fatalf("configure diff error = %v", err)
+	}
+	for _, want := range []string{
+		"--- dev\n+++ prod\n",
+		"- access-key: ****1111\n+ access-key: ****2222\n",
+		"- endpoints.ecs: ecs.dev.example.com\n",
+		"  mode: ak\n",
+		"- region: ap-southeast-1\n+ region: ap-southeast-3\n",
+		"  secret-key: ****alue\n",
+		"3 field(s) differ\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("configure diff output = %q, want %q", out, want)
+		}
+	}
+	if strings.Contains(out, "same-secret-value") || strings.Contains(out, "name:") {
+		t.Fatalf("configure diff output = %q, want secrets masked and name skipped", out)
+	}
+
+	if out, err = run("--profile", "dev", "--profile", "prod", "--include-secrets"); err != nil || !strings.Contains(out, "- access-key: AKLTdev0000001111\n") {
+		t.Fatalf("configure diff --include-secrets = %q, %v", out, err)
+	}
+	if out, err = run("--profile", "dev", "--profile", "dev"); err != nil || !strings.Contains(out, "profiles dev and dev are identical\n") {
+		t.Fatalf("configure diff of one profile = %q, %v", out, err)
+	}
+	if _, err = run("--profile", "dev"); err == nil || !strings.Contains(err.Error(), "exactly two profiles") {
+		t.Fatalf("configure diff with one profile error = %v", err)
+	}
+	if _, err = run("--profile", "dev", "--profile", "missing"); err == nil || !strings.Contains(err.Error(), "missing not found") {
+		t.Fatalf("configure diff with missing profile error = %v", err)
+	}
+}
+
+func TestChangeConfigProfileReadOnlyKeepsChangeInMemory(t *testing.T) {
+	dir := withTestConfigDir(t)
+	cfg := &Configure{
+		Current: "default",
+		Profiles: map[string]*Profile{
+			"default": {Name: "default", Mode: ModeAK},
+			"test":    {Name: "test", Mode: ModeAK},
+		},
+	}
+	withTestCtxConfig(t, cfg)
+	t.Setenv(configReadOnlyEnv, "true")
+	var warning bytes.Buffer
+	oldOut := configReadOnlyWarningOut
+	configReadOnlyWarningOut, configReadOnlyWarned = &warning, sync.Once{}
+	t.Cleanup(func() {
+		configReadOnlyWarningOut, configReadOnlyWarned = oldOut, sync.Once{}
+	})
+
+	if err := changeConfigProfile("test"); err != nil {
+		t.Fatalf("changeConfigProfile() error = %v", err)
+	}
+	if err := WriteConfigToFile(cfg); err != nil {
+		t.Fatalf("WriteConfigToFile() error = %v", err)
+	}
+
+	if cfg.Current != "test" {
+		t.Fatalf("Current = %q, want test", cfg.Current)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ConfigFile)); !os.IsNotExist(err) {
+		t.Fatalf("config file stat error = %v, want not exist", err)
+	}
+	if got := strings.Count(warning.String(), "read-only"); got != 1 {
+		t.Fatalf("warning printed %d times, want once: %q", got, warning.String())
+	}
+}
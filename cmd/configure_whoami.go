@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/byteplus-sdk/byteplus-cli/util"
+	"github.com/spf13/cobra"
+)
+
+// whoamiStsVersion 是元数据中没有 sts 时 GetCallerIdentity 使用的 API 版本。
+const whoamiStsVersion = "2018-01-01"
+
+// whoamiIdentityColumns 是 GetCallerIdentity 返回的身份字段，表格输出时排在前面；
+// 其余返回字段按名称排在其后，最后是凭证来源。
+var whoamiIdentityColumns = []string{"AccountId", "IdentityType", "IdentityId", "Trn"}
+
+var whoamiSourceColumns = []string{"Profile", "ProfileSource", "CredentialSource", "Region"}
+
+func newConfigureWhoamiCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "whoami",
+		Short: "show the identity the CLI acts as and where its credentials come from",
+		Long: `Description:
+  resolve credentials with the same precedence as service commands (refreshing
+  SSO and Console Login credentials when needed), call sts GetCallerIdentity and
+  print the account ID, identity type, identity ID and TRN, together with the
+  profile, credential source and region that were used`,
+		Example: `  bp configure whoami
+  bp configure whoami --profile dev --output table`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format := strings.ToLower(strings.TrimSpace(cmd.Flag("output").Value.String()))
+			if format != outputFormatJSON && format != outputFormatTable {
+				return fmt.Errorf("unsupported --output %q, supported: json, table", format)
+			}
+			result, err := whoami(whoamiContext(ctx, cmd.Flag("profile").Value.String(), cmd.Flag("region").Value.String()))
+			if err != nil {
+				return err
+			}
+			if format == outputFormatTable {
+				return writeWhoamiTable(cmd.OutOrStdout(), result)
+			}
+			util.ShowJson(result, config != nil && config.EnableColor)
+			return nil
+		},
+		DisableFlagsInUseLine: true,
+	}
+
+	cmd.SetUsageTemplate(configureActionUsageTemplate())
+
+	cmd.Flags().String("profile", "", "profile to resolve; defaults to the profile service commands would use")
+	cmd.Flags().String("region", "", "region of the sts endpoint; defaults to the profile or BYTEPLUS_REGION")
+	cmd.Flags().String("output", outputFormatJSON, "output format: json or table")
+	cmd.Flags().BoolP("help", "h", false, "")
+
+	registerConfigNameCompletions(cmd)
+
+	return cmd
+}
+
+// whoamiContext 复制 c，以 --profile、--region 作为本次解析使用的 ---profile、---region。
+func whoamiContext(c *Context, profile, region string) *Context {
+	clone := *c
+	clone.fixedFlags = NewFlagSet()
+	if strings.TrimSpace(profile) != "" {
+		clone.fixedFlags.AddFlag(&Flag{Name: "profile", value: profile})
+	}
+	if strings.TrimSpace(region) != "" {
+		clone.fixedFlags.AddFlag(&Flag{Name: "region", value: region})
+	}
+	return &clone
+}
+
+// whoami 按服务命令的优先级创建 client 并调用 GetCallerIdentity，返回 Result 中的字段与凭证来源。
+func whoami(c *Context) (map[string]interface{}, error) {
+	sdk, err := NewSimpleClient(c)
+	if err != nil {
+		return nil, err
+	}
+	info := resolveActionCallInfo("sts", "GetCallerIdentity")
+	if info.Version == "" {
+		info.Version = whoamiStsVersion
+	}
+	out, err := sdk.CallSdk(info, nil)
+	if err != nil {
+		return nil, formatActionError(err)
+	}
+	identity, ok := (*out)["Result"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("GetCallerIdentity returned no Result")
+	}
+
+	result := make(map[string]interface{}, len(identity)+len(whoamiSourceColumns))
+	for k, v := range identity {
+		result[k] = v
+	}
+	r := sdk.resolved
+	profile := r.ProfileName
+	if r.Profile == nil {
+		profile = ""
+	}
+	result["Profile"] = profile
+	result["ProfileSource"] = r.ProfileSource
+	result["CredentialSource"] = r.CredentialSource
+	result["Region"] = r.Region
+	return result, nil
+}
+
+func writeWhoamiTable(out io.Writer, result map[string]interface{}) error {
+	known := map[string]struct{}{}
+	for _, col := range append(append([]string{}, whoamiIdentityColumns...), whoamiSourceColumns...) {
+		known[col] = struct{}{}
+	}
+	var extra []string
+	for k := range result {
+		if _, ok := known[k]; !ok {
+			extra = append(extra, k)
+		}
+	}
+	sort.Strings(extra)
+
+	var columns []string
+	for _, col := range whoamiIdentityColumns {
+		if _, ok := result[col]; ok {
+			columns = append(columns, col)
+		}
+	}
+	columns = append(columns, extra...)
+	columns = append(columns, whoamiSourceColumns...)
+
+	table := util.NewTableWriter(out, 0)
+	table.SetColumns(columns)
+	if err := table.Write(result); err != nil {
+		return err
+	}
+	return table.Flush()
+}
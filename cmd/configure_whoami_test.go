@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWhoamiReportsIdentityAndCredentialSource(t *testing.T) {
+	defer disableProxyEnvForTest(t)()
+
+	var action string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		action = r.URL.Query().Get("Action")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ResponseMetadata":{"RequestId":"req"},"Result":{"AccountId":"2100000001","IdentityType":"User","IdentityId":"42","Trn":"trn:iam::2100000001:user/dev"}}`))
+	}))
+	defer server.Close()
+
+	// SDK 的 CliProvider 从 BYTEPLUS_CLI_CONFIG_FILE 读取凭证，指向测试目录以免读到用户的配置
+	dir := withTestConfigDir(t)
+	t.Cleanup(setenvForTest(t, "BYTEPLUS_CLI_CONFIG_FILE", filepath.Join(dir, ConfigFile)))
+	cfg := &Configure{Current: "default", Profiles: map[string]*Profile{
+		"default": {Name: "default", Mode: ModeAK, AccessKey: "ak", SecretKey: "sk", Region: "ap-southeast-1", Endpoint: server.URL},
+		"dev":     {Name: "dev", Mode: ModeAK, AccessKey: "ak", SecretKey: "sk", Region: "ap-southeast-1", Endpoint: server.URL},
+	}}
+	if err := WriteConfigToFile(cfg); err != nil {
+		t.Fatalf("WriteConfigToFile() error = %v", err)
+	}
+	withTestCtxConfig(t, cfg)
+
+	result, err := whoami(whoamiContext(ctx, "dev", "ap-southeast-3"))
+	if err != nil {
+		t.Fatalf("whoami() error = %v", err)
+	}
+	if action != "GetCallerIdentity" {
+		t.Fatalf("Action = %q, want GetCallerIdentity", action)
+	}
+	want := map[string]interface{}{
+		"AccountId":        "2100000001",
+		"Trn":              "trn:iam::2100000001:user/dev",
+		"Profile":          "dev",
+		"ProfileSource":    sourceFlag,
+		"CredentialSource": "profile:" + ModeAK,
+		"Region":           "ap-southeast-3",
+	}
+	for k, v := range want {
+		if result[k] != v {
+			t.Fatalf("%s = %v, want %v", k, result[k], v)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := writeWhoamiTable(&out, result); err != nil {
+		t.Fatalf("writeWhoamiTable() error = %v", err)
+	}
+	header := strings.Fields(strings.SplitN(out.String(), "\n", 2)[0])
+	if strings.Join(header, ",") != "AccountId,IdentityType,IdentityId,Trn,Profile,ProfileSource,CredentialSource,Region" {
+		t.Fatalf("table header = %v", header)
+	}
+}
@@ -189,11 +189,12 @@ func (cl *ConsoleLogin) Login() error {
 	setRuntimeConfig(cfg)
 
 	// 12. Print success message.
-	fmt.Println("\nSuccessfully logged in!")
-	fmt.Printf("Credentials cached for profile: %s\n", cl.Profile)
+	out := statusWriter(os.Stdout)
+	fmt.Fprintln(out, "\nSuccessfully logged in!")
+	fmt.Fprintf(out, "Credentials cached for profile: %s\n", cl.Profile)
 	issuedAt, _ := time.Parse(time.RFC3339, cache.IssuedAt)
 	expiresAt := issuedAt.Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
-	fmt.Printf("STS credentials expire at: %s\n", expiresAt.Local().Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(out, "STS credentials expire at: %s\n", expiresAt.Local().Format("2006-01-02 15:04:05"))
 	return nil
 }
 
@@ -286,9 +287,10 @@ func (cl *ConsoleLogin) localAuthorize(
 		RedirectURI:         redirectURI,
 	})
 
-	fmt.Println("Attempting to automatically open the login page in your default browser.")
-	fmt.Println("If the browser does not open, open the following URL:")
-	fmt.Println(authorizeURL)
+	guidance := guidanceWriter()
+	fmt.Fprintln(guidance, "Attempting to automatically open the login page in your default browser.")
+	fmt.Fprintln(guidance, "If the browser does not open, open the following URL:")
+	fmt.Fprintln(guidance, authorizeURL)
 
 	// Best-effort browser open.
 	_ = util.OpenBrowser(authorizeURL)
@@ -342,14 +344,15 @@ func (cl *ConsoleLogin) remoteAuthorize(
 		RedirectURI:         redirectURI,
 	})
 
-	fmt.Println("Open the following URL in a browser on any device:")
-	fmt.Println()
-	fmt.Println(authorizeURL)
-	fmt.Println()
-	fmt.Println("After completing login, enter the authorization code shown in the browser:")
+	guidance := guidanceWriter()
+	fmt.Fprintln(guidance, "Open the following URL in a browser on any device:")
+	fmt.Fprintln(guidance)
+	fmt.Fprintln(guidance, authorizeURL)
+	fmt.Fprintln(guidance)
+	fmt.Fprintln(guidance, "After completing login, enter the authorization code shown in the browser:")
 
 	reader := bufio.NewReader(os.Stdin)
-	fmt.Print("Authorization code: ")
+	fmt.Fprint(guidance, "Authorization code: ")
 	rawInput, err := reader.ReadString('\n')
 	if err != nil {
 		return "", "", fmt.Errorf("reading authorization code from stdin: %w", err)
@@ -398,7 +401,7 @@ func (cl *ConsoleLogin) remoteAuthorize(
 
 func getLoginCacheDir() (string, error) {
 	if customCacheDir := os.Getenv(loginCacheDirectoryEnv); customCacheDir != "" {
-		if err := os.MkdirAll(customCacheDir, 0700); err != nil {
+		if err := os.MkdirAll(customCacheDir, configDirPerm()); err != nil {
 			return "", fmt.Errorf("creating custom cache directory %s: %w", customCacheDir, err)
 		}
 		return customCacheDir, nil
@@ -409,7 +412,7 @@ func getLoginCacheDir() (string, error) {
 		return "", fmt.Errorf("getting config directory: %w", err)
 	}
 	cacheDir := filepath.Join(configDir, "login", "cache")
-	if err := os.MkdirAll(cacheDir, 0700); err != nil {
+	if err := os.MkdirAll(cacheDir, configDirPerm()); err != nil {
 		return "", fmt.Errorf("creating cache directory %s: %w", cacheDir, err)
 	}
 	return cacheDir, nil
@@ -443,6 +446,7 @@ func writeLoginCache(cache *LoginTokenCache) (retErr error) {
 		return fmt.Errorf("creating temp file: %w", err)
 	}
 	tmpName := tmpFile.Name()
+	defer trackTempFile(tmpName)()
 	closed := false
 	defer func() {
 		if retErr != nil {
@@ -460,7 +464,7 @@ func writeLoginCache(cache *LoginTokenCache) (retErr error) {
 		return fmt.Errorf("closing temp cache file: %w", err)
 	}
 	closed = true
-	if err := os.Chmod(tmpName, 0600); err != nil {
+	if err := os.Chmod(tmpName, configFilePerm()); err != nil {
 		return fmt.Errorf("setting cache file permissions: %w", err)
 	}
 	if err := os.Rename(tmpName, cachePath); err != nil {
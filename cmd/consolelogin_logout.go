@@ -50,7 +50,7 @@ func (cl *ConsoleLogout) logoutSingleProfile() error {
 	}
 
 	if profile.LoginSession == "" {
-		fmt.Printf("Profile %q does not have an active login session. Nothing to do.\n", profileName)
+		fmt.Fprintf(statusWriter(os.Stdout), "Profile %q does not have an active login session. Nothing to do.\n", profileName)
 		return nil
 	}
 
@@ -66,7 +66,7 @@ func (cl *ConsoleLogout) logoutSingleProfile() error {
 	}
 	setRuntimeConfig(cfg)
 
-	fmt.Printf("Successfully logged out of profile %q.\n", profileName)
+	fmt.Fprintf(statusWriter(os.Stdout), "Successfully logged out of profile %q.\n", profileName)
 	printPostLogoutHint()
 	return nil
 }
@@ -74,7 +74,7 @@ func (cl *ConsoleLogout) logoutSingleProfile() error {
 func (cl *ConsoleLogout) logoutAll() error {
 	cfg := runtimeConfig()
 	if cfg == nil || cfg.Profiles == nil {
-		fmt.Println("No configuration found; nothing to log out.")
+		fmt.Fprintln(statusWriter(os.Stdout), "No configuration found; nothing to log out.")
 		return nil
 	}
 
@@ -96,7 +96,7 @@ func (cl *ConsoleLogout) logoutAll() error {
 
 		profile.LoginSession = ""
 		deletedCount++
-		fmt.Printf("  Logged out profile %q\n", name)
+		fmt.Fprintf(statusWriter(os.Stdout), "  Logged out profile %q\n", name)
 	}
 
 	if err := WriteConfigToFile(cfg); err != nil {
@@ -106,10 +106,10 @@ func (cl *ConsoleLogout) logoutAll() error {
 	}
 
 	if deletedCount > 0 {
-		fmt.Printf("\nSuccessfully logged out %d console-login profile(s).\n", deletedCount)
+		fmt.Fprintf(statusWriter(os.Stdout), "\nSuccessfully logged out %d console-login profile(s).\n", deletedCount)
 		printPostLogoutHint()
 	} else {
-		fmt.Println("No console-login profiles with active sessions found. Nothing to do.")
+		fmt.Fprintln(statusWriter(os.Stdout), "No console-login profiles with active sessions found. Nothing to do.")
 	}
 
 	return firstErr
@@ -128,8 +128,9 @@ func removeLoginCache(loginSession string) error {
 }
 
 func printPostLogoutHint() {
-	fmt.Println()
-	fmt.Println("Note: Local cache has been removed for future CLI sessions.")
-	fmt.Println("Already-running tools that loaded temporary STS credentials before logout")
-	fmt.Println("may continue to use them until those credentials expire.")
+	out := statusWriter(os.Stdout)
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Note: Local cache has been removed for future CLI sessions.")
+	fmt.Fprintln(out, "Already-running tools that loaded temporary STS credentials before logout")
+	fmt.Fprintln(out, "may continue to use them until those credentials expire.")
 }
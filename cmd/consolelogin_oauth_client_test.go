@@ -3,8 +3,10 @@ package cmd
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync/atomic"
 	"testing"
 )
@@ -85,4 +87,44 @@ func TestConsoleOAuthClientExchangeTokenDoesNotRetryNonRetryableErrors(t *testin
 	if got := atomic.LoadInt32(&attempts); got != 1 {
 		t.Fatalf("attempts = %d, want 1", got)
 	}
+	if strings.Contains(err.Error(), "attempts") {
+		t.Fatalf("error = %q, want no attempt count for an error that was not retried", err)
+	}
+}
+
+func TestConsoleOAuthClientExchangeTokenReportsAttemptsAfterRetries(t *testing.T) {
+	var attempts int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"error":"temporarily_unavailable","error_description":"busy"}`))
+	}))
+	defer server.Close()
+
+	client := NewConsoleOAuthClient(&ConsoleOAuthClientConfig{
+		EndpointURL: server.URL,
+		HTTPClient:  server.Client(),
+	})
+
+	_, err := client.ExchangeToken(context.Background(), &ConsoleTokenRequest{
+		GrantType:    "refresh_token",
+		RefreshToken: "refresh-old",
+		ClientID:     ConsoleClientIDSameDevice,
+		Scope:        scopeAllAll,
+	})
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if got := atomic.LoadInt32(&attempts); got != consoleTokenRetryAttempts {
+		t.Fatalf("attempts = %d, want %d", got, consoleTokenRetryAttempts)
+	}
+	if !strings.Contains(err.Error(), "(after 3 attempts)") {
+		t.Fatalf("error = %q, want attempt count", err)
+	}
+	var apiErr *ConsoleOAuthAPIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("errors.As(*ConsoleOAuthAPIError) failed for %v", err)
+	}
 }
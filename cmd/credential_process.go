@@ -0,0 +1,163 @@
+/*
+ * // Copyright (c) 2024 Bytedance Ltd. and/or its affiliates
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at
+ * //
+ * //	http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// credentialProcessMinRemaining 是复用缓存凭证时要求的最短剩余有效期，与 SSO 角色凭证保持一致。
+const credentialProcessMinRemaining = roleCredentialsMinRemaining
+
+// credentialProcessOutput 是外部凭证命令在 stdout 输出的 JSON。
+// Expiration 为 RFC3339 时间，为空表示凭证长期有效（此时不缓存，每次调用都重新执行命令）。
+type credentialProcessOutput struct {
+	AccessKeyId     string `json:"AccessKeyId"`
+	SecretAccessKey string `json:"SecretAccessKey"`
+	SessionToken    string `json:"SessionToken,omitempty"`
+	Expiration      string `json:"Expiration,omitempty"`
+}
+
+// credentialProcessCache 按命令内容缓存凭证，命令变化后旧缓存自然失效。
+type credentialProcessCache struct {
+	Command     string                  `json:"command"`
+	Credentials credentialProcessOutput `json:"credentials"`
+}
+
+// runCredentialProcess 是执行外部命令的注入点，测试中替换以避免依赖 shell。
+var runCredentialProcess = func(command string) ([]byte, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(commandContext, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(commandContext, "sh", "-c", command)
+	}
+	var stdout bytes.Buffer
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = &stdout
+	// stderr 直接透传，凭证代理可以借此提示用户完成交互式认证
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
+
+// resolveCredentialProcess 返回 profile 外部凭证命令给出的凭证，优先使用未过期的缓存。
+func resolveCredentialProcess(profile *Profile) (*credentialProcessOutput, error) {
+	command := strings.TrimSpace(profile.CredentialProcess)
+	if cached := readCachedCredentialProcess(command); cached != nil {
+		return cached, nil
+	}
+
+	out, err := runCredentialProcess(command)
+	if err != nil {
+		return nil, fmt.Errorf("credential process for profile %q failed: %w", profile.Name, err)
+	}
+	creds, err := parseCredentialProcessOutput(out)
+	if err != nil {
+		return nil, fmt.Errorf("credential process for profile %q returned invalid output: %w", profile.Name, err)
+	}
+	storeCredentialProcess(command, creds)
+	return creds, nil
+}
+
+func parseCredentialProcessOutput(out []byte) (*credentialProcessOutput, error) {
+	var creds credentialProcessOutput
+	if err := json.Unmarshal(bytes.TrimSpace(out), &creds); err != nil {
+		return nil, err
+	}
+	if creds.AccessKeyId == "" || creds.SecretAccessKey == "" {
+		return nil, fmt.Errorf("AccessKeyId and SecretAccessKey are required")
+	}
+	if creds.Expiration != "" {
+		expiration, err := time.Parse(time.RFC3339, creds.Expiration)
+		if err != nil {
+			return nil, fmt.Errorf("Expiration must be an RFC3339 timestamp: %w", err)
+		}
+		if !nowFunc().Before(expiration) {
+			return nil, fmt.Errorf("credentials expired at %s", creds.Expiration)
+		}
+	}
+	return &creds, nil
+}
+
+func getCredentialProcessCacheFile(command string) (string, error) {
+	configDir, err := configFileDirFunc()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "credential-process", fmt.Sprintf("%x.json", sha1.Sum([]byte(command)))), nil
+}
+
+// readCachedCredentialProcess 返回同一命令仍在有效期内的缓存凭证；缓存不存在、损坏或即将过期时返回 nil。
+func readCachedCredentialProcess(command string) *credentialProcessOutput {
+	filePath, err := getCredentialProcessCacheFile(command)
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil
+	}
+	var cached credentialProcessCache
+	if err := json.Unmarshal(data, &cached); err != nil {
+		_ = os.Remove(filePath)
+		return nil
+	}
+	if cached.Command != command {
+		return nil
+	}
+	creds := cached.Credentials
+	expiration, err := time.Parse(time.RFC3339, creds.Expiration)
+	if err != nil || creds.AccessKeyId == "" || creds.SecretAccessKey == "" {
+		return nil
+	}
+	if !nowFunc().Add(credentialProcessMinRemaining).Before(expiration) {
+		return nil
+	}
+	return &creds
+}
+
+// storeCredentialProcess 缓存带有效期的凭证，写入失败不影响本次调用。
+func storeCredentialProcess(command string, creds *credentialProcessOutput) {
+	if creds == nil || creds.Expiration == "" {
+		return
+	}
+	filePath, err := getCredentialProcessCacheFile(command)
+	if err != nil {
+		return
+	}
+	cacheDir := filepath.Dir(filePath)
+	if err := os.MkdirAll(cacheDir, configDirPerm()); err != nil {
+		return
+	}
+	_ = os.Chmod(cacheDir, configDirPerm())
+	_ = writeJSONFileAtomic(filePath, configFilePerm(), &credentialProcessCache{
+		Command:     command,
+		Credentials: *creds,
+	})
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func withFakeCredentialProcess(t *testing.T, outputs ...string) *int {
+	t.Helper()
+	calls := 0
+	oldRun := runCredentialProcess
+	runCredentialProcess = func(command string) ([]byte, error) {
+		if calls >= len(outputs) {
+			return nil, errors.New("unexpected credential process call")
+		}
+		out := outputs[calls]
+		calls++
+		return []byte(out), nil
+	}
+	t.Cleanup(func() { runCredentialProcess = oldRun })
+	return &calls
+}
+
+func TestResolveCredentialProcessCachesUntilExpiration(t *testing.T) {
+	withTestConfigDir(t)
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	withFixedNow(t, now)
+	calls := withFakeCredentialProcess(t,
+		`{"AccessKeyId":"ak-1","SecretAccessKey":"sk-1","SessionToken":"token-1","Expiration":"2026-01-02T04:04:05Z"}`,
+		`{"AccessKeyId":"ak-2","SecretAccessKey":"sk-2","SessionToken":"token-2","Expiration":"2026-01-02T05:04:05Z"}`,
+	)
+	profile := &Profile{Name: "broker", CredentialProcess: "broker --account dev"}
+
+	creds, err := resolveCredentialProcess(profile)
+	if err != nil {
+		t.Fatalf("resolveCredentialProcess returned error: %v", err)
+	}
+	if creds.AccessKeyId != "ak-1" || creds.SessionToken != "token-1" {
+		t.Fatalf("unexpected credentials: %+v", creds)
+	}
+
+	creds, err = resolveCredentialProcess(profile)
+	if err != nil {
+		t.Fatalf("resolveCredentialProcess returned error: %v", err)
+	}
+	if *calls != 1 || creds.AccessKeyId != "ak-1" {
+		t.Fatalf("expected cached credentials, calls=%d creds=%+v", *calls, creds)
+	}
+
+	withFixedNow(t, now.Add(58*time.Minute))
+	creds, err = resolveCredentialProcess(profile)
+	if err != nil {
+		t.Fatalf("resolveCredentialProcess returned error: %v", err)
+	}
+	if *calls != 2 || creds.AccessKeyId != "ak-2" {
+		t.Fatalf("expected refreshed credentials near expiry, calls=%d creds=%+v", *calls, creds)
+	}
+}
+
+func TestResolveCredentialProcessDoesNotCacheWithoutExpiration(t *testing.T) {
+	withTestConfigDir(t)
+	calls := withFakeCredentialProcess(t,
+		`{"AccessKeyId":"ak-1","SecretAccessKey":"sk-1"}`,
+		`{"AccessKeyId":"ak-2","SecretAccessKey":"sk-2"}`,
+	)
+	profile := &Profile{Name: "broker", CredentialProcess: "broker"}
+
+	for _, want := range []string{"ak-1", "ak-2"} {
+		creds, err := resolveCredentialProcess(profile)
+		if err != nil {
+			t.Fatalf("resolveCredentialProcess returned error: %v", err)
+		}
+		if creds.AccessKeyId != want {
+			t.Fatalf("AccessKeyId = %q, want %q", creds.AccessKeyId, want)
+		}
+	}
+	if *calls != 2 {
+		t.Fatalf("calls = %d, want 2", *calls)
+	}
+}
+
+func TestResolveCredentialProcessRejectsInvalidOutput(t *testing.T) {
+	withTestConfigDir(t)
+	withFixedNow(t, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	tests := []struct {
+		name    string
+		output  string
+		wantErr string
+	}{
+		{name: "not json", output: "ak sk", wantErr: "invalid output"},
+		{name: "missing secret", output: `{"AccessKeyId":"ak"}`, wantErr: "SecretAccessKey are required"},
+		{name: "bad expiration", output: `{"AccessKeyId":"ak","SecretAccessKey":"sk","Expiration":"tomorrow"}`, wantErr: "RFC3339"},
+		{name: "expired", output: `{"AccessKeyId":"ak","SecretAccessKey":"sk","Expiration":"2026-01-01T00:00:00Z"}`, wantErr: "expired"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withFakeCredentialProcess(t, tt.output)
+			_, err := resolveCredentialProcess(&Profile{Name: "broker", CredentialProcess: "broker " + tt.name})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunCredentialProcessReturnsStdout(t *testing.T) {
+	out, err := runCredentialProcess(`echo '{"AccessKeyId":"ak","SecretAccessKey":"sk"}'`)
+	if err != nil {
+		t.Fatalf("runCredentialProcess returned error: %v", err)
+	}
+	creds, err := parseCredentialProcessOutput(out)
+	if err != nil || creds.AccessKeyId != "ak" {
+		t.Fatalf("parseCredentialProcessOutput = %+v, %v", creds, err)
+	}
+}
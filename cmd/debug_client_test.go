@@ -173,3 +173,67 @@ func disableProxyEnvForTest(t *testing.T) func() {
 		}
 	}
 }
+
+func TestCallSdkPrintsResolvedEndpointWhenVerbose(t *testing.T) {
+	defer disableProxyEnvForTest(t)()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ResponseMetadata":{"RequestId":"req-verbose"},"Result":{"Ok":true}}`))
+	}))
+	defer server.Close()
+
+	defer setenvForTest(t, "BYTEPLUS_ACCESS_KEY", "ak-test")()
+	defer setenvForTest(t, "BYTEPLUS_SECRET_KEY", "sk-test")()
+	defer setenvForTest(t, "BYTEPLUS_REGION", "ap-southeast-1")()
+
+	var out bytes.Buffer
+	prevOutput := verboseOutput
+	verboseOutput = &out
+	defer func() { verboseOutput = prevOutput }()
+
+	ctx := NewContext()
+	parser := NewParser([]string{"---endpoint", server.URL, "---verbose"})
+	if _, err := parser.ReadArgs(ctx); err != nil {
+		t.Fatalf("ReadArgs() error = %v", err)
+	}
+	sdk, err := NewSimpleClient(ctx)
+	if err != nil {
+		t.Fatalf("NewSimpleClient returned error: %v", err)
+	}
+	if _, err := sdk.CallSdk(SdkClientInfo{
+		ServiceName: "ecs",
+		Action:      "DescribeInstances",
+		Version:     "2020-04-01",
+		Method:      "GET",
+	}, nil); err != nil {
+		t.Fatalf("CallSdk returned error: %v", err)
+	}
+
+	line := out.String()
+	for _, want := range []string{
+		"service=ecs",
+		"action=DescribeInstances",
+		"region=ap-southeast-1",
+		"endpoint=" + server.URL,
+		"endpoint_source=endpoint",
+	} {
+		if !strings.Contains(line, want) {
+			t.Fatalf("verbose output missing %q:\n%s", want, line)
+		}
+	}
+}
+
+func TestNewSimpleClientLeavesVerboseOffByDefault(t *testing.T) {
+	defer setenvForTest(t, "BYTEPLUS_ACCESS_KEY", "ak-test")()
+	defer setenvForTest(t, "BYTEPLUS_SECRET_KEY", "sk-test")()
+	defer setenvForTest(t, "BYTEPLUS_REGION", "ap-southeast-1")()
+
+	sdk, err := NewSimpleClient(NewContext())
+	if err != nil {
+		t.Fatalf("NewSimpleClient returned error: %v", err)
+	}
+	if sdk.VerboseOut != nil {
+		t.Fatal("VerboseOut is set without ---verbose")
+	}
+}
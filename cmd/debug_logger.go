@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 	"unicode/utf8"
 )
@@ -25,6 +26,8 @@ type debugOptions struct {
 }
 
 type DebugLogger struct {
+	// mu 串行化并发写入，bp batch 并发执行时多个请求共用同一个 logger。
+	mu      sync.Mutex
 	enabled bool
 	out     io.Writer
 	flush   func() error
@@ -44,6 +47,8 @@ func (l *DebugLogger) Printf(format string, args ...interface{}) {
 	if !l.Enabled() || l.out == nil {
 		return
 	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
 	if _, err := fmt.Fprintf(l.out, "[debug] "+format+"\n", args...); err != nil && l.err == nil {
 		l.err = err
 	}
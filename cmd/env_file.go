@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// defaultEnvFileName 是当前目录中自动读取的环境变量默认值文件。
+const defaultEnvFileName = ".bp.env"
+
+// envFileVarPrefix 限定 env 文件可设置的变量，避免项目目录中的文件改动 PATH、代理等与 bp 无关的进程环境。
+const envFileVarPrefix = "BYTEPLUS_"
+
+// envFileWarningOut 为 env 文件中被忽略条目的提示输出目标。
+var envFileWarningOut io.Writer = os.Stderr
+
+// envFileEntry 是 env 文件中的一行 KEY=VALUE。
+type envFileEntry struct {
+	Key   string
+	Value string
+	Line  int
+}
+
+// extractEnvFileArgs 从命令行中取出 --env-file 与 --override-env（也接受 ---env-file、---override-env），
+// 返回剩余参数。这两个 flag 必须在命令分发前处理，服务命令不解析 cobra flag，不能留给后续解析。
+func extractEnvFileArgs(args []string) (rest []string, path string, explicit, override bool, err error) {
+	rest = make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		name, value, hasValue := splitKeyValue(strings.TrimLeft(arg, "-"))
+		if !strings.HasPrefix(arg, "--") || strings.HasPrefix(arg, "----") {
+			rest = append(rest, arg)
+			continue
+		}
+		switch name {
+		case "env-file":
+			if !hasValue {
+				if i+1 >= len(args) {
+					return nil, "", false, false, fmt.Errorf("%s requires a file path", arg)
+				}
+				i++
+				value = args[i]
+			}
+			path, explicit = value, true
+		case "override-env":
+			override = !hasValue || value == "true"
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	return rest, path, explicit, override, nil
+}
+
+// loadEnvFile 把 env 文件中的变量设置到进程环境。未指定 --env-file 时读取当前目录的 .bp.env，文件不存在时忽略；
+// 指定的文件不存在时报错。已设置的变量保持不变，override 为 true 时覆盖。
+func loadEnvFile(path string, explicit, override bool) error {
+	if !explicit {
+		path = defaultEnvFileName
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) && !explicit {
+			return nil
+		}
+		return fmt.Errorf("failed to open the env file: %w", err)
+	}
+	defer file.Close()
+	entries, err := parseEnvFile(file)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	for _, e := range entries {
+		if !strings.HasPrefix(e.Key, envFileVarPrefix) {
+			fmt.Fprintf(envFileWarningOut, "Warning: %s:%d: %s is ignored; only %s* variables can be set from an env file\n", path, e.Line, e.Key, envFileVarPrefix)
+			continue
+		}
+		if _, set := os.LookupEnv(e.Key); set && !override {
+			continue
+		}
+		if err := os.Setenv(e.Key, e.Value); err != nil {
+			return fmt.Errorf("%s:%d: %w", path, e.Line, err)
+		}
+	}
+	return nil
+}
+
+// parseEnvFile 解析 KEY=VALUE 行：忽略空行与 # 注释，允许 export 前缀，去掉值两侧成对的单引号或双引号。
+func parseEnvFile(r io.Reader) ([]envFileEntry, error) {
+	var entries []envFileEntry
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		text = strings.TrimSpace(strings.TrimPrefix(text, "export "))
+		key, value, ok := splitKeyValue(text)
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", line)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		entries = append(entries, envFileEntry{Key: key, Value: value, Line: line})
+	}
+	return entries, scanner.Err()
+}
+
+// splitKeyValue 在第一个 "=" 处拆分 s，没有 "=" 时 ok 为 false。
+func splitKeyValue(s string) (key, value string, ok bool) {
+	if i := strings.Index(s, "="); i >= 0 {
+		return s[:i], s[i+1:], true
+	}
+	return s, "", false
+}
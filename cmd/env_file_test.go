@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractEnvFileArgsRemovesEnvFileFlags(t *testing.T) {
+	rest, path, explicit, override, err := extractEnvFileArgs([]string{"ecs", "DescribeInstances", "--env-file", "dev.env", "--MaxResults", "10", "---override-env"})
+	if err != nil {
+		t.Fatalf("extractEnvFileArgs returned error: %v", err)
+	}
+	if want := []string{"ecs", "DescribeInstances", "--MaxResults", "10"}; !reflect.DeepEqual(rest, want) {
+		t.Fatalf("rest = %q, want %q", rest, want)
+	}
+	if path != "dev.env" || !explicit || !override {
+		t.Fatalf("path = %q explicit = %v override = %v", path, explicit, override)
+	}
+	if _, _, _, _, err := extractEnvFileArgs([]string{"--env-file"}); err == nil {
+		t.Fatal("expected an error for --env-file without a path")
+	}
+}
+
+func TestLoadEnvFileKeepsSetVariablesUnlessOverridden(t *testing.T) {
+	prevWarning := envFileWarningOut
+	envFileWarningOut = io.Discard
+	t.Cleanup(func() { envFileWarningOut = prevWarning })
+
+	path := filepath.Join(t.TempDir(), "project.env")
+	content := strings.Join([]string{
+		"# project defaults",
+		"export BYTEPLUS_REGION=\"ap-southeast-1\"",
+		"BYTEPLUS_PROFILE='project'",
+		"PATH=/tmp/evil",
+	}, "\n")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("write env file: %v", err)
+	}
+	t.Setenv("BYTEPLUS_PROFILE", "from-shell")
+	t.Setenv("BYTEPLUS_REGION", "")
+	os.Unsetenv("BYTEPLUS_REGION")
+	pathValue := os.Getenv("PATH")
+
+	if err := loadEnvFile(path, true, false); err != nil {
+		t.Fatalf("loadEnvFile returned error: %v", err)
+	}
+	if os.Getenv("BYTEPLUS_REGION") != "ap-southeast-1" || os.Getenv("BYTEPLUS_PROFILE") != "from-shell" || os.Getenv("PATH") != pathValue {
+		t.Fatalf("region = %q profile = %q PATH changed = %v", os.Getenv("BYTEPLUS_REGION"), os.Getenv("BYTEPLUS_PROFILE"), os.Getenv("PATH") != pathValue)
+	}
+
+	if err := loadEnvFile(path, true, true); err != nil {
+		t.Fatalf("loadEnvFile with override returned error: %v", err)
+	}
+	if os.Getenv("BYTEPLUS_PROFILE") != "project" {
+		t.Fatalf("profile = %q, want the env file to override it", os.Getenv("BYTEPLUS_PROFILE"))
+	}
+
+	if err := loadEnvFile(filepath.Join(t.TempDir(), "missing.env"), true, false); err == nil {
+		t.Fatal("expected an error for a missing --env-file")
+	}
+}
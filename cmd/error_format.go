@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/byteplus-sdk/byteplus-go-sdk-v2/byteplus/bytepluserr"
+	"github.com/spf13/cobra"
+)
+
+const (
+	errorFormatText = "text"
+	errorFormatJSON = "json"
+)
+
+// errorFormat 由全局 --error-format 设置，决定 Execute 如何把命令错误写到 stderr。
+var errorFormat = errorFormatText
+
+// errorFormatEnv 设置默认的错误格式，--error-format 与 ---error-format 优先。
+const errorFormatEnv = "BYTEPLUS_ERROR_FORMAT"
+
+// compactErrors 由全局 --compact-errors 设置，为 true 时文本格式的错误只保留最外层上下文与根因。
+var compactErrors bool
+
+// compactErrorsEnv 为 true 时默认使用精简的错误文本，--compact-errors 与 ---compact-errors 优先。
+const compactErrorsEnv = "BYTEPLUS_COMPACT_ERRORS"
+
+// commandError 是 --error-format json 输出的错误对象。Source 标明错误来自哪类接口：
+// "api"（业务 API）、"oauth"、"portal"，无结构化信息的错误只有 Message。
+type commandError struct {
+	Message    string `json:"Message"`
+	Source     string `json:"Source,omitempty"`
+	Code       string `json:"Code,omitempty"`
+	StatusCode int    `json:"StatusCode,omitempty"`
+	RequestID  string `json:"RequestId,omitempty"`
+}
+
+// resolveErrorFormat 返回本次调用的错误格式：---error-format > --error-format > BYTEPLUS_ERROR_FORMAT > text。
+// 服务命令不解析 cobra flag，因此另外读取 ---error-format。
+func resolveErrorFormat() string {
+	if ctx != nil && ctx.fixedFlags != nil {
+		if f := ctx.fixedFlags.GetByName("error-format"); f != nil && f.GetValue() != "" {
+			return normalizeErrorFormat(f.GetValue())
+		}
+	}
+	if f := rootCmd.PersistentFlags().Lookup("error-format"); f != nil && f.Changed {
+		return normalizeErrorFormat(errorFormat)
+	}
+	return normalizeErrorFormat(os.Getenv(errorFormatEnv))
+}
+
+// normalizeErrorFormat 只识别 json，其余取值按 text 处理，避免错误本身因格式参数有误而丢失。
+func normalizeErrorFormat(value string) string {
+	if strings.ToLower(strings.TrimSpace(value)) == errorFormatJSON {
+		return errorFormatJSON
+	}
+	return errorFormatText
+}
+
+// resolveCompactErrors 返回本次调用是否精简错误文本：---compact-errors > --compact-errors > BYTEPLUS_COMPACT_ERRORS。
+// 指定 ---verbose 或所执行命令的 --verbose 时始终输出完整的错误链。cmd 为 nil 表示命令分发前出错。
+func resolveCompactErrors(cmd *cobra.Command) bool {
+	if ctx != nil && ctx.fixedFlags != nil {
+		if f := ctx.fixedFlags.GetByName("verbose"); f != nil && f.GetValue() == "true" {
+			return false
+		}
+	}
+	if cmd != nil {
+		if verbose, err := cmd.Flags().GetBool("verbose"); err == nil && verbose {
+			return false
+		}
+	}
+	if ctx != nil && ctx.fixedFlags != nil {
+		if f := ctx.fixedFlags.GetByName("compact-errors"); f != nil && f.GetValue() == "true" {
+			return true
+		}
+	}
+	if f := rootCmd.PersistentFlags().Lookup("compact-errors"); f != nil && f.Changed {
+		return compactErrors
+	}
+	compact, _ := strconv.ParseBool(strings.TrimSpace(os.Getenv(compactErrorsEnv)))
+	return compact
+}
+
+// compactErrorMessage 把 "a: b: c: root" 形式层层包装的错误精简为 "a: root"。
+// 只跳过以 ": <内层错误>" 结尾的包装层；某层的文本不以内层错误结尾（例如在内层错误之后附加了修复建议）时，
+// 该层连同其内层原样保留，避免丢失建议。
+func compactErrorMessage(err error) string {
+	message := err.Error()
+	inner := errors.Unwrap(err)
+	if inner == nil || !strings.HasSuffix(message, ": "+inner.Error()) {
+		return message
+	}
+	outer := strings.TrimSuffix(message, ": "+inner.Error())
+	root := inner
+	for {
+		next := errors.Unwrap(root)
+		if next == nil || !strings.HasSuffix(root.Error(), ": "+next.Error()) {
+			break
+		}
+		root = next
+	}
+	return outer + ": " + root.Error()
+}
+
+// newCommandError 从错误链中提取 OAuth、Portal 或 SDK 错误的结构化字段。
+func newCommandError(err error) commandError {
+	e := commandError{Message: err.Error()}
+	var (
+		oauthErr   *OAuthAPIError
+		consoleErr *ConsoleOAuthAPIError
+		portalErr  *PortalAPIError
+		failure    bytepluserr.RequestFailure
+		apiErr     bytepluserr.Error
+	)
+	switch {
+	case errors.As(err, &oauthErr):
+		e.Source = "oauth"
+		e.Code = oauthErr.Response.Error
+		e.StatusCode = oauthErr.StatusCode
+	case errors.As(err, &consoleErr):
+		e.Source = "oauth"
+		e.Code = consoleErr.Response.Error
+		e.StatusCode = consoleErr.StatusCode
+		e.RequestID = consoleErr.RequestID
+	case errors.As(err, &portalErr):
+		e.Source = "portal"
+		e.Code = portalErr.Code
+		e.StatusCode = portalErr.StatusCode
+		e.RequestID = portalErr.RequestID
+	case errors.As(err, &failure):
+		e.Source = "api"
+		e.Code = failure.Code()
+		e.StatusCode = failure.StatusCode()
+		e.RequestID = failure.RequestID()
+	case errors.As(err, &apiErr):
+		e.Source = "api"
+		e.Code = apiErr.Code()
+	}
+	return e
+}
+
+// writeCommandError 按 format 把 err 写到 out：text 为原始错误文本，compact 为 true 时只保留最外层上下文与根因；
+// json 为单行 commandError，Message 始终是完整的错误文本。
+func writeCommandError(out io.Writer, err error, format string, compact bool) {
+	if format != errorFormatJSON {
+		if compact {
+			fmt.Fprintln(out, compactErrorMessage(err))
+			return
+		}
+		fmt.Fprintln(out, err)
+		return
+	}
+	data, marshalErr := json.Marshal(newCommandError(err))
+	if marshalErr != nil {
+		fmt.Fprintln(out, err)
+		return
+	}
+	fmt.Fprintln(out, string(data))
+}
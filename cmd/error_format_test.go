@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/byteplus-sdk/byteplus-go-sdk-v2/byteplus/bytepluserr"
+)
+
+func TestWriteCommandErrorJSONCarriesStructuredFields(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want commandError
+	}{
+		{
+			name: "sdk",
+			err:  fmt.Errorf("call failed: %w", bytepluserr.NewRequestFailure(bytepluserr.New("InvalidAccessKey", "bad key", nil), http.StatusUnauthorized, "req-1")),
+			want: commandError{Source: "api", Code: "InvalidAccessKey", StatusCode: http.StatusUnauthorized, RequestID: "req-1"},
+		},
+		{
+			name: "portal",
+			err:  &PortalAPIError{StatusCode: http.StatusForbidden, Code: "AccessDenied", RequestID: "req-2", Message: "denied"},
+			want: commandError{Source: "portal", Code: "AccessDenied", StatusCode: http.StatusForbidden, RequestID: "req-2"},
+		},
+		{
+			name: "oauth",
+			err:  &OAuthAPIError{StatusCode: http.StatusBadRequest, Response: oauthErrorResponse{Error: "expired_token"}},
+			want: commandError{Source: "oauth", Code: "expired_token", StatusCode: http.StatusBadRequest},
+		},
+		{
+			name: "plain",
+			err:  errors.New("profile is required"),
+			want: commandError{},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var out strings.Builder
+			writeCommandError(&out, tc.err, errorFormatJSON, false)
+			var got commandError
+			if err := json.Unmarshal([]byte(out.String()), &got); err != nil {
+				t.Fatalf("stderr %q is not JSON: %v", out.String(), err)
+			}
+			tc.want.Message = tc.err.Error()
+			if got != tc.want {
+				t.Fatalf("error = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+
+	var out strings.Builder
+	writeCommandError(&out, errors.New("profile is required"), errorFormatText, false)
+	if out.String() != "profile is required\n" {
+		t.Fatalf("text output = %q", out.String())
+	}
+}
+
+func TestResolveErrorFormatPrefersFixedFlagOverEnv(t *testing.T) {
+	withTestCtxConfig(t, &Configure{})
+	t.Setenv(errorFormatEnv, "json")
+	if got := resolveErrorFormat(); got != errorFormatJSON {
+		t.Fatalf("format from env = %q, want json", got)
+	}
+	f, err := ctx.fixedFlags.AddByName("error-format")
+	if err != nil {
+		t.Fatalf("AddByName: %v", err)
+	}
+	f.SetValue("text")
+	if got := resolveErrorFormat(); got != errorFormatText {
+		t.Fatalf("format with ---error-format text = %q, want text", got)
+	}
+}
+
+func TestCompactErrorMessageKeepsContextAndRootCause(t *testing.T) {
+	root := &PortalAPIError{StatusCode: http.StatusBadGateway, Message: "upstream unavailable"}
+	chained := fmt.Errorf("failed to refresh stsToken: %w", fmt.Errorf("failed to get role credentials: %w", fmt.Errorf("failed to get access token: %w", root)))
+	if got, want := compactErrorMessage(chained), "failed to refresh stsToken: "+root.Error(); got != want {
+		t.Fatalf("compactErrorMessage() = %q, want %q", got, want)
+	}
+
+	// 在内层错误之后附加了修复建议的层原样保留
+	advised := fmt.Errorf("role admin is not assigned: %w; run 'bp configure sso'", fmt.Errorf("failed to get role credentials: %w", root))
+	wrapped := fmt.Errorf("failed to refresh stsToken: %w", fmt.Errorf("call failed: %w", advised))
+	if got, want := compactErrorMessage(wrapped), "failed to refresh stsToken: "+advised.Error(); got != want {
+		t.Fatalf("compactErrorMessage() = %q, want %q", got, want)
+	}
+
+	plain := errors.New("profile is required")
+	if got := compactErrorMessage(plain); got != plain.Error() {
+		t.Fatalf("compactErrorMessage(plain) = %q", got)
+	}
+
+	var out strings.Builder
+	writeCommandError(&out, chained, errorFormatJSON, true)
+	var got commandError
+	if err := json.Unmarshal([]byte(out.String()), &got); err != nil || got.Message != chained.Error() {
+		t.Fatalf("json output = %q, want the full message", out.String())
+	}
+}
+
+func TestResolveCompactErrorsYieldsToVerbose(t *testing.T) {
+	withTestCtxConfig(t, &Configure{})
+	t.Setenv(compactErrorsEnv, "true")
+	if !resolveCompactErrors(nil) {
+		t.Fatal("resolveCompactErrors() = false, want true from env")
+	}
+	if _, err := NewParser([]string{"---verbose"}).ReadArgs(ctx); err != nil {
+		t.Fatalf("ReadArgs() error = %v", err)
+	}
+	if resolveCompactErrors(nil) {
+		t.Fatal("resolveCompactErrors() = true with ---verbose, want the full chain")
+	}
+}
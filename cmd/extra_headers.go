@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"strings"
+
+	"github.com/byteplus-sdk/byteplus-go-sdk-v2/byteplus/request"
+)
+
+// extraHeaderFlags 由全局 --header 设置，可重复，用于 sso、login 等不走服务命令的请求。
+var extraHeaderFlags []string
+
+// protectedHeaders 由 SDK 或签名生成，--header 覆盖后请求会签名失败或无法解析，因此拒绝。
+var protectedHeaders = map[string]struct{}{
+	"Authorization":    {},
+	"Host":             {},
+	"Content-Length":   {},
+	"Content-Type":     {},
+	"X-Date":           {},
+	"X-Content-Sha256": {},
+	"X-Security-Token": {},
+}
+
+// resolveExtraHeaders 合并 ---header 与 --header 的取值。同名 header 出现多次时保留全部取值，顺序与命令行一致。
+func resolveExtraHeaders(ctx *Context) (http.Header, error) {
+	var headers http.Header
+	add := func(from string, values []string) error {
+		for _, raw := range values {
+			name, value, err := parseExtraHeader(raw)
+			if err != nil {
+				return fmt.Errorf("%s %q is invalid: %v", from, raw, err)
+			}
+			if headers == nil {
+				headers = http.Header{}
+			}
+			headers.Add(name, value)
+		}
+		return nil
+	}
+	if ctx != nil && ctx.fixedFlags != nil {
+		if f := ctx.fixedFlags.GetByName("header"); f != nil {
+			if err := add("---header", f.GetValues()); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := add("--header", extraHeaderFlags); err != nil {
+		return nil, err
+	}
+	return headers, nil
+}
+
+// parseExtraHeader 解析 ---header 的 "Key: Value"，拒绝 protectedHeaders 中由 CLI 生成的 header。
+func parseExtraHeader(s string) (name, value string, err error) {
+	if name, value, err = parseHeaderLine(s); err != nil {
+		return "", "", err
+	}
+	if _, ok := protectedHeaders[name]; ok {
+		return "", "", fmt.Errorf("%s is set by the CLI and cannot be overridden", name)
+	}
+	return name, value, nil
+}
+
+// parseHeaderLine 解析 "Key: Value"。Key 必须是合法的 HTTP token，Value 不能包含换行，
+// 两侧空白被去掉；Value 可以为空。返回的 Key 为规范形式，如 X-Trace-Id。
+func parseHeaderLine(s string) (name, value string, err error) {
+	i := strings.Index(s, ":")
+	if i < 0 {
+		return "", "", fmt.Errorf("expected 'Key: Value'")
+	}
+	name, value = strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+1:])
+	if name == "" {
+		return "", "", fmt.Errorf("header name is empty")
+	}
+	for _, c := range name {
+		if !isHeaderTokenChar(c) {
+			return "", "", fmt.Errorf("header name contains invalid character %q", c)
+		}
+	}
+	if strings.ContainsAny(value, "\r\n\x00") {
+		return "", "", fmt.Errorf("header value must not contain line breaks")
+	}
+	return textproto.CanonicalMIMEHeaderKey(name), value, nil
+}
+
+// isHeaderTokenChar 判断 c 是否是 RFC 7230 token 允许的字符。
+func isHeaderTokenChar(c rune) bool {
+	if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' {
+		return true
+	}
+	return strings.ContainsRune("!#$%&'*+-.^_`|~", c)
+}
+
+// extraHeadersHandler 在请求构建完成后、签名前写入 headers，自定义 header 随请求一起签名。
+func extraHeadersHandler(headers http.Header) request.NamedHandler {
+	return request.NamedHandler{
+		Name: "ByteplusCliExtraHeadersHandler",
+		Fn: func(r *request.Request) {
+			for name, values := range headers {
+				r.HTTPRequest.Header.Del(name)
+				for _, v := range values {
+					r.HTTPRequest.Header.Add(name, v)
+				}
+			}
+		},
+	}
+}
+
+// headerTransport 为 OAuth 与 Portal 请求加上 --header 指定的 header，已由客户端设置的同名 header 被替换。
+type headerTransport struct {
+	base    http.RoundTripper
+	headers http.Header
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTripper 不能修改传入的请求，复制后再写入
+	req = req.Clone(req.Context())
+	for name, values := range t.headers {
+		req.Header.Del(name)
+		for _, v := range values {
+			req.Header.Add(name, v)
+		}
+	}
+	return t.base.RoundTrip(req)
+}
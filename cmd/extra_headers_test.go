@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParserCollectsRepeatedHeaderFlags(t *testing.T) {
+	withTestCtxConfig(t, &Configure{})
+	prev := extraHeaderFlags
+	t.Cleanup(func() { extraHeaderFlags = prev })
+	extraHeaderFlags = nil
+
+	if _, err := NewParser([]string{"---header", "X-Trace-Id: abc", "---header", "x-tag: a", "---header", "X-Tag:b"}).ReadArgs(ctx); err != nil {
+		t.Fatalf("ReadArgs() error = %v", err)
+	}
+	headers, err := resolveExtraHeaders(ctx)
+	if err != nil {
+		t.Fatalf("resolveExtraHeaders() error = %v", err)
+	}
+	if got := headers.Get("X-Trace-Id"); got != "abc" {
+		t.Fatalf("X-Trace-Id = %q, want abc", got)
+	}
+	if got := strings.Join(headers.Values("X-Tag"), ","); got != "a,b" {
+		t.Fatalf("X-Tag = %q, want a,b", got)
+	}
+}
+
+func TestParseExtraHeaderRejectsInvalidHeaders(t *testing.T) {
+	for input, want := range map[string]string{
+		"X-Trace-Id abc":        "expected 'Key: Value'",
+		": abc":                 "header name is empty",
+		"X Trace: abc":          "invalid character",
+		"X-Trace: a\r\nX-B: b":  "line breaks",
+		"authorization: secret": "cannot be overridden",
+	} {
+		if _, _, err := parseExtraHeader(input); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("parseExtraHeader(%q) error = %v, want %q", input, err, want)
+		}
+	}
+	name, value, err := parseExtraHeader("x-empty:")
+	if err != nil || name != "X-Empty" || value != "" {
+		t.Fatalf("parseExtraHeader(x-empty:) = %q, %q, %v", name, value, err)
+	}
+}
+
+func TestIdentityHTTPClientSendsExtraHeaders(t *testing.T) {
+	withTestCtxConfig(t, &Configure{})
+	prev := extraHeaderFlags
+	t.Cleanup(func() { extraHeaderFlags = prev })
+	extraHeaderFlags = []string{"X-Trace-Id: abc"}
+
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("X-Trace-Id")
+	}))
+	defer server.Close()
+
+	client, err := identityHTTPClient(defaultPortalTimeout)
+	if err != nil {
+		t.Fatalf("identityHTTPClient returned error: %v", err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("X-Trace-Id", "from-client")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if got != "abc" {
+		t.Fatalf("X-Trace-Id = %q, want abc", got)
+	}
+	if req.Header.Get("X-Trace-Id") != "from-client" {
+		t.Fatalf("the caller's request was modified")
+	}
+
+	extraHeaderFlags = []string{"bad header"}
+	if _, err := identityHTTPClient(defaultPortalTimeout); err == nil || !strings.Contains(err.Error(), "--header") {
+		t.Fatalf("error = %v, want an invalid --header", err)
+	}
+}
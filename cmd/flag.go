@@ -25,10 +25,18 @@ import (
 type Flag struct {
 	Name  string
 	value string
+	// values 按顺序记录每次设置的值，供 ---header 等可重复的 flag 读取全部取值。
+	values []string
 }
 
 func (f *Flag) SetValue(value string) {
 	f.value = value
+	f.values = append(f.values, value)
+}
+
+// GetValues 返回按顺序设置过的全部值；GetValue 只返回最后一个。
+func (f *Flag) GetValues() []string {
+	return f.values
 }
 
 func (f *Flag) GetValue() string {
@@ -12,7 +12,7 @@ func TestUsageTemplatesIncludeFixedFlags(t *testing.T) {
 	}{
 		{name: "root", text: rootUsageTemplate()},
 		{name: "service", text: serviceUsageTemplate()},
-		{name: "action", text: actionUsageTemplate("", []string{"InstanceId string"})},
+		{name: "action", text: actionUsageTemplate("", []string{"InstanceId string"}, nil)},
 	}
 
 	for _, tt := range tests {
@@ -29,3 +29,35 @@ func TestUsageTemplatesIncludeFixedFlags(t *testing.T) {
 func expectedFixedFlagsForTest() []string {
 	return []string{"---profile", "---region", "---endpoint"}
 }
+
+func TestActionUsageTemplateGroupsRequiredParameters(t *testing.T) {
+	text := actionUsageTemplate("", []string{"ZoneId string", "ImageId string"}, []string{"Name string", "Description string"})
+
+	required := strings.Index(text, "Required Parameters:")
+	optional := strings.Index(text, "Optional Parameters:")
+	if required < 0 || optional < required {
+		t.Fatalf("expected required section before optional section:\n%s", text)
+	}
+	if strings.Contains(text, "Available Parameters:") {
+		t.Fatalf("unexpected ungrouped section:\n%s", text)
+	}
+	order := []string{"--ImageId", "--ZoneId", "--Description", "--Name"}
+	last := -1
+	for _, name := range order {
+		idx := strings.Index(text, name)
+		if idx <= last {
+			t.Fatalf("expected %s after previous parameter:\n%s", name, text)
+		}
+		last = idx
+	}
+}
+
+func TestActionUsageTemplateWithoutRequiredParameters(t *testing.T) {
+	text := actionUsageTemplate("", nil, []string{"Name string"})
+	if !strings.Contains(text, "Available Parameters:\n  --Name string") {
+		t.Fatalf("expected single parameter section:\n%s", text)
+	}
+	if strings.Contains(text, "Required Parameters:") || strings.Contains(text, "Optional Parameters:") {
+		t.Fatalf("unexpected grouped sections:\n%s", text)
+	}
+}
@@ -0,0 +1,114 @@
+/*
+ * // Copyright (c) 2024 Bytedance Ltd. and/or its affiliates
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at
+ * //
+ * //	http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const helpTreeFlag = "help-tree"
+
+// paramTreeNode is one segment of a dotted parameter key. List index
+// segments ("N") are folded into their parent so that
+// NetworkInterfaces.N.SubnetId renders as NetworkInterfaces.N > SubnetId.
+type paramTreeNode struct {
+	name     string
+	path     string
+	typeName string
+	required bool
+	children map[string]*paramTreeNode
+}
+
+// actionParamsTree renders the request parameters of an action as an
+// indented tree. Types and required flags come from apiMeta when it is
+// available; otherwise only the structure is shown.
+func actionParamsTree(params []param, apiMeta *ApiMeta) string {
+	withMeta := apiMeta != nil && apiMeta.Request != nil
+	root := &paramTreeNode{children: map[string]*paramTreeNode{}}
+	for _, p := range params {
+		node := root
+		segments := strings.Split(p.key, ".")
+		for i := 0; i < len(segments); i++ {
+			name := segments[i]
+			for i+1 < len(segments) && segments[i+1] == "N" {
+				name += ".N"
+				i++
+			}
+			path := name
+			if node.path != "" {
+				path = node.path + "." + name
+			}
+			child, ok := node.children[name]
+			if !ok {
+				child = &paramTreeNode{name: name, path: path, children: map[string]*paramTreeNode{}}
+				if withMeta {
+					metaPath := strings.TrimSuffix(path, ".N")
+					child.typeName = strings.TrimSpace(apiMeta.GetReqTypeName(metaPath))
+					child.required = apiMeta.GetReqRequired(metaPath)
+				}
+				node.children[name] = child
+			}
+			node = child
+		}
+		node.typeName = strings.TrimSpace(p.typeName)
+		node.required = p.required
+	}
+
+	if len(root.children) == 0 {
+		return "This action has no request parameters.\n"
+	}
+
+	nameWidth, typeWidth := 0, 0
+	var measure func(node *paramTreeNode, depth int)
+	measure = func(node *paramTreeNode, depth int) {
+		for _, child := range node.children {
+			if w := depth*2 + len(child.name); w > nameWidth {
+				nameWidth = w
+			}
+			if len(child.typeName) > typeWidth {
+				typeWidth = len(child.typeName)
+			}
+			measure(child, depth+1)
+		}
+	}
+	measure(root, 0)
+
+	var b strings.Builder
+	b.WriteString("Request Parameters:\n")
+	var render func(node *paramTreeNode, depth int)
+	render = func(node *paramTreeNode, depth int) {
+		names := make([]string, 0, len(node.children))
+		for name := range node.children {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			child := node.children[name]
+			label := strings.Repeat("  ", depth) + child.name
+			line := "  " + label
+			if withMeta {
+				line = fmt.Sprintf("  %-*s  %-*s  %s", nameWidth, label, typeWidth, child.typeName, formatRequired(child.required))
+			}
+			b.WriteString(line + "\n")
+			render(child, depth+1)
+		}
+	}
+	render(root, 0)
+	return b.String()
+}
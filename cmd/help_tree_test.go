@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestActionParamsTreeNestsListFields(t *testing.T) {
+	apiMeta := &ApiMeta{
+		Request: &Meta{
+			MetaTypes: map[string]*MetaType{
+				"ImageId":           {TypeName: "string", Required: true},
+				"NetworkInterfaces": {TypeName: "array", TypeOf: "object"},
+			},
+			ChildMetas: map[string]*Meta{
+				"NetworkInterfaces": {
+					MetaTypes: map[string]*MetaType{
+						"SubnetId": {TypeName: "string", Required: true},
+					},
+				},
+			},
+		},
+	}
+	params := []param{
+		{key: "NetworkInterfaces.N.SubnetId", typeName: "string", required: true},
+		{key: "ImageId", typeName: "string", required: true},
+	}
+
+	got := actionParamsTree(params, apiMeta)
+	lines := strings.Split(strings.TrimRight(got, "\n"), "\n")
+	if len(lines) != 4 || lines[0] != "Request Parameters:" {
+		t.Fatalf("unexpected tree:\n%s", got)
+	}
+	if fields := strings.Fields(lines[1]); strings.Join(fields, " ") != "ImageId string Required" {
+		t.Fatalf("line 1 = %q", lines[1])
+	}
+	if fields := strings.Fields(lines[2]); strings.Join(fields, " ") != "NetworkInterfaces.N array Optional" {
+		t.Fatalf("line 2 = %q", lines[2])
+	}
+	if !strings.HasPrefix(lines[3], "    SubnetId") || !strings.HasSuffix(lines[3], "Required") {
+		t.Fatalf("line 3 = %q", lines[3])
+	}
+}
+
+func TestActionParamsTreeWithoutApiMeta(t *testing.T) {
+	got := actionParamsTree([]param{{key: "Filter.Name"}, {key: "Filter.Values"}}, nil)
+	want := "Request Parameters:\n  Filter\n    Name\n    Values\n"
+	if got != want {
+		t.Fatalf("tree = %q, want %q", got, want)
+	}
+	if got := actionParamsTree(nil, nil); !strings.Contains(got, "no request parameters") {
+		t.Fatalf("empty tree = %q", got)
+	}
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"math/rand"
 	"net"
@@ -46,7 +47,7 @@ func doWithRetry(ctx context.Context, opts retryOptions, fn func() error) error
 		}
 
 		if attempt == opts.maxAttempts || !shouldRetryError(lastErr) {
-			return lastErr
+			return withAttemptCount(lastErr, attempt)
 		}
 
 		delay := computeBackoff(opts, attempt)
@@ -54,7 +55,17 @@ func doWithRetry(ctx context.Context, opts retryOptions, fn func() error) error
 			return err
 		}
 	}
-	return lastErr
+	return withAttemptCount(lastErr, opts.maxAttempts)
+}
+
+// withAttemptCount annotates err with the number of attempts made when the
+// call was retried, so a persistent failure can be told apart from one that
+// failed immediately. The original error stays reachable via errors.As/Is.
+func withAttemptCount(err error, attempts int) error {
+	if err == nil || attempts <= 1 {
+		return err
+	}
+	return fmt.Errorf("%w (after %d attempts)", err, attempts)
 }
 
 func shouldRetryError(err error) bool {
@@ -80,6 +91,11 @@ func shouldRetryError(err error) bool {
 		return isRetryableHTTPStatus(portalErr.StatusCode)
 	}
 
+	var postErr *postResultError
+	if errors.As(err, &postErr) {
+		return isRetryableHTTPStatus(postErr.StatusCode)
+	}
+
 	var netErr net.Error
 	if errors.As(err, &netErr) {
 		return true
@@ -0,0 +1,103 @@
+/*
+ * // Copyright (c) 2024 Bytedance Ltd. and/or its affiliates
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at
+ * //
+ * //	http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// regionFromMetadataEnv opts in to discovering the region from the instance
+// metadata service when no other source provides one. It is off by default so
+// that commands run off-instance do not wait for an unreachable endpoint.
+const regionFromMetadataEnv = "BYTEPLUS_REGION_FROM_METADATA"
+
+// sourceInstanceMetadata is the region source reported by ---verbose.
+const sourceInstanceMetadata = "instance-metadata"
+
+// instanceMetadataEndpoint is the link-local metadata address, the same one
+// the SDK uses for ECS role credentials.
+var instanceMetadataEndpoint = "http://100.96.0.96"
+
+const (
+	instanceMetadataTokenPath   = "/latest/api/token"
+	instanceMetadataRegionPath  = "/latest/region_id"
+	instanceMetadataTTLHeader   = "X-volc-ecs-metadata-token-ttl-seconds"
+	instanceMetadataTokenHeader = "X-volc-ecs-metadata-token"
+	instanceMetadataTimeout     = time.Second
+)
+
+// regionFromMetadataEnabled reports whether BYTEPLUS_REGION_FROM_METADATA is
+// true. BYTEPLUS_ECS_METADATA_DISABLED=true, which also turns off ECS role
+// credentials in the SDK, wins over it.
+func regionFromMetadataEnabled() bool {
+	if disabled, _ := strconv.ParseBool(strings.TrimSpace(os.Getenv("BYTEPLUS_ECS_METADATA_DISABLED"))); disabled {
+		return false
+	}
+	enabled, _ := strconv.ParseBool(strings.TrimSpace(os.Getenv(regionFromMetadataEnv)))
+	return enabled
+}
+
+// instanceMetadataRegion reads the region of the current instance. A session
+// token is requested first; each request is bounded by a short timeout and
+// never goes through a proxy.
+func instanceMetadataRegion() (string, error) {
+	client := &http.Client{
+		Timeout:   instanceMetadataTimeout,
+		Transport: &http.Transport{Proxy: nil},
+	}
+	token, err := instanceMetadataRequest(client, http.MethodPut, instanceMetadataTokenPath, map[string]string{instanceMetadataTTLHeader: "60"})
+	if err != nil {
+		return "", err
+	}
+	region, err := instanceMetadataRequest(client, http.MethodGet, instanceMetadataRegionPath, map[string]string{instanceMetadataTokenHeader: token})
+	if err != nil {
+		return "", err
+	}
+	if region == "" {
+		return "", fmt.Errorf("instance metadata returned an empty region")
+	}
+	return region, nil
+}
+
+func instanceMetadataRequest(client *http.Client, method, path string, headers map[string]string) (string, error) {
+	req, err := http.NewRequest(method, instanceMetadataEndpoint+path, nil)
+	if err != nil {
+		return "", err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("instance metadata request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("instance metadata %s returned HTTP %d", path, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(body)), nil
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func withInstanceMetadataServer(t *testing.T, handler http.HandlerFunc) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	prev := instanceMetadataEndpoint
+	instanceMetadataEndpoint = server.URL
+	t.Cleanup(func() {
+		instanceMetadataEndpoint = prev
+		server.Close()
+	})
+}
+
+func metadataRegionHandler(t *testing.T, region string, calls *int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == instanceMetadataTokenPath:
+			_, _ = w.Write([]byte("imds-token"))
+		case r.Method == http.MethodGet && r.URL.Path == instanceMetadataRegionPath:
+			if got := r.Header.Get(instanceMetadataTokenHeader); got != "imds-token" {
+				t.Errorf("token header = %q, want imds-token", got)
+			}
+			_, _ = w.Write([]byte(region + "\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+}
+
+func TestNewSimpleClientReadsRegionFromInstanceMetadata(t *testing.T) {
+	t.Setenv("BYTEPLUS_DISABLE_DEFAULT_CREDENTIALS", "")
+	t.Setenv("BYTEPLUS_ECS_METADATA_DISABLED", "")
+	t.Setenv("BYTEPLUS_REGION", "")
+	t.Setenv(regionFromMetadataEnv, "true")
+	calls := 0
+	withInstanceMetadataServer(t, metadataRegionHandler(t, "ap-southeast-1", &calls))
+
+	testCtx := NewContext()
+	testCtx.SetConfig(&Configure{Profiles: map[string]*Profile{}})
+	client, err := NewSimpleClient(testCtx)
+	if err != nil {
+		t.Fatalf("NewSimpleClient returned error: %v", err)
+	}
+	if got := *client.Config.Region; got != "ap-southeast-1" {
+		t.Fatalf("region = %q, want ap-southeast-1", got)
+	}
+
+	// 已有 region 时不访问元数据服务
+	t.Setenv("BYTEPLUS_REGION", "cn-beijing")
+	calls = 0
+	client, err = NewSimpleClient(testCtx)
+	if err != nil {
+		t.Fatalf("NewSimpleClient returned error: %v", err)
+	}
+	if got := *client.Config.Region; got != "cn-beijing" || calls != 0 {
+		t.Fatalf("region = %q with %d metadata calls, want cn-beijing and none", got, calls)
+	}
+}
+
+func TestNewSimpleClientInstanceMetadataRegionIsOptIn(t *testing.T) {
+	t.Setenv("BYTEPLUS_DISABLE_DEFAULT_CREDENTIALS", "")
+	t.Setenv("BYTEPLUS_ACCESS_KEY", "env-ak")
+	t.Setenv("BYTEPLUS_SECRET_KEY", "env-sk")
+	t.Setenv("BYTEPLUS_REGION", "")
+	calls := 0
+	withInstanceMetadataServer(t, metadataRegionHandler(t, "ap-southeast-1", &calls))
+
+	testCtx := NewContext()
+	testCtx.SetConfig(&Configure{Profiles: map[string]*Profile{}})
+	for _, env := range []struct{ enabled, disabled string }{{"", ""}, {"true", "true"}} {
+		t.Setenv(regionFromMetadataEnv, env.enabled)
+		t.Setenv("BYTEPLUS_ECS_METADATA_DISABLED", env.disabled)
+		_, err := NewSimpleClient(testCtx)
+		if err == nil || !strings.Contains(err.Error(), "region not set, please set it") {
+			t.Fatalf("enabled=%q disabled=%q: error = %v, want missing region guidance", env.enabled, env.disabled, err)
+		}
+	}
+	if calls != 0 {
+		t.Fatalf("metadata service called %d times, want none", calls)
+	}
+}
+
+func TestNewSimpleClientReportsInstanceMetadataFailure(t *testing.T) {
+	t.Setenv("BYTEPLUS_DISABLE_DEFAULT_CREDENTIALS", "")
+	t.Setenv("BYTEPLUS_ECS_METADATA_DISABLED", "")
+	t.Setenv("BYTEPLUS_REGION", "")
+	t.Setenv(regionFromMetadataEnv, "true")
+	withInstanceMetadataServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+
+	testCtx := NewContext()
+	testCtx.SetConfig(&Configure{Profiles: map[string]*Profile{}})
+	_, err := NewSimpleClient(testCtx)
+	if err == nil || !strings.Contains(err.Error(), "could not be read from instance metadata") || !strings.Contains(err.Error(), "HTTP 403") {
+		t.Fatalf("error = %v, want instance metadata failure", err)
+	}
+}
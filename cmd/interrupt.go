@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/manifoldco/promptui"
+)
+
+// interruptExitCode 与 shell 对 SIGINT 终止进程的约定一致（128 + 2）。
+const interruptExitCode = 130
+
+// commandContext 是当前命令的 context，收到 SIGINT/SIGTERM 后被取消。
+// SSO 的设备码轮询和 Portal/OAuth 请求使用它，以便 Ctrl-C 能及时中断长时间等待。
+var commandContext = context.Background()
+
+var interruptOut io.Writer = os.Stderr
+
+// errAborted 表示用户主动中断了当前操作。
+var errAborted = errors.New("aborted")
+
+// installInterruptHandler 接管 SIGINT/SIGTERM：第一次信号取消 commandContext，让命令自行退出并返回错误；
+// 命令未响应时再次按 Ctrl-C 会恢复终端并立即退出。返回的函数用于停止接管。
+func installInterruptHandler() func() {
+	c, cancel := context.WithCancel(context.Background())
+	commandContext = c
+
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		if _, ok := <-signals; !ok {
+			return
+		}
+		cancel()
+		if _, ok := <-signals; !ok {
+			return
+		}
+		reportAborted()
+		// 强制退出不会执行 defer，先删除正在写入的临时文件
+		removeActiveTempFiles()
+		os.Exit(interruptExitCode)
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(signals)
+		cancel()
+	}
+}
+
+// isInterruptError 判断命令错误是否来自用户中断：promptui 在 raw 模式下把 Ctrl-C 作为 ErrInterrupt 返回，
+// 其他阶段的 Ctrl-C 通过取消 commandContext 体现为 context.Canceled。
+func isInterruptError(err error) bool {
+	return errors.Is(err, promptui.ErrInterrupt) ||
+		errors.Is(err, promptui.ErrEOF) ||
+		errors.Is(err, errAborted) ||
+		(errors.Is(err, context.Canceled) && commandContext.Err() != nil)
+}
+
+// reportAborted 恢复 promptui 隐藏的光标，并在新的一行输出 aborted。
+func reportAborted() {
+	fmt.Fprint(interruptOut, "\033[?25h\n")
+	fmt.Fprintln(interruptOut, errAborted.Error())
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/manifoldco/promptui"
+)
+
+func TestIsInterruptError(t *testing.T) {
+	oldCtx := commandContext
+	defer func() { commandContext = oldCtx }()
+
+	if !isInterruptError(fmt.Errorf("failed to select the account and role: %w", promptui.ErrInterrupt)) {
+		t.Fatal("wrapped promptui.ErrInterrupt should be treated as an interrupt")
+	}
+	if isInterruptError(context.Canceled) {
+		t.Fatal("context.Canceled without an interrupted command context should not be treated as an interrupt")
+	}
+
+	c, cancel := context.WithCancel(context.Background())
+	cancel()
+	commandContext = c
+	if !isInterruptError(fmt.Errorf("failed to obtain the access token: %w", context.Canceled)) {
+		t.Fatal("context.Canceled after interrupt should be treated as an interrupt")
+	}
+	if isInterruptError(errors.New("boom")) {
+		t.Fatal("ordinary error should not be treated as an interrupt")
+	}
+}
+
+func TestDeviceAuthorizationStopsWhenCommandContextIsCanceled(t *testing.T) {
+	sso := setupSsoTokenTest(t)
+	fakeOAuth := &fakeOAuthClient{}
+	newOAuthClientForSSO = func(region string) OAuthClientAPI {
+		return fakeOAuth
+	}
+	oldCtx := commandContext
+	defer func() { commandContext = oldCtx }()
+	c, cancel := context.WithCancel(context.Background())
+	commandContext = c
+	deviceAuthorizationSleep = func(time.Duration) { cancel() }
+
+	_, err := newDeviceCodeFetcher(sso).GetFreshTokenForLogin()
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("GetFreshTokenForLogin() error = %v, want context.Canceled", err)
+	}
+	for _, req := range fakeOAuth.createRequests {
+		if req.GrantType == deviceCodeGrantType {
+			t.Fatal("device code should not be polled after the command was interrupted")
+		}
+	}
+}
+
+func TestCallSdkReturnsCanceledAfterInterrupt(t *testing.T) {
+	defer disableProxyEnvForTest(t)()
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ResponseMetadata":{"RequestId":"req"},"Result":{}}`))
+	}))
+	defer server.Close()
+
+	defer setenvForTest(t, "BYTEPLUS_ACCESS_KEY", "ak-test")()
+	defer setenvForTest(t, "BYTEPLUS_SECRET_KEY", "sk-test")()
+	defer setenvForTest(t, "BYTEPLUS_REGION", "ap-southeast-1")()
+	testCtx := NewContext()
+	if _, err := NewParser([]string{"---endpoint", server.URL}).ReadArgs(testCtx); err != nil {
+		t.Fatalf("ReadArgs() error = %v", err)
+	}
+	sdk, err := NewSimpleClient(testCtx)
+	if err != nil {
+		t.Fatalf("NewSimpleClient() error = %v", err)
+	}
+
+	oldCtx := commandContext
+	defer func() { commandContext = oldCtx }()
+	c, cancel := context.WithCancel(context.Background())
+	cancel()
+	commandContext = c
+
+	_, err = sdk.CallSdk(SdkClientInfo{ServiceName: "ecs", Action: "DescribeInstances", Version: "2020-04-01", Method: "GET"}, nil)
+	if !isInterruptError(err) {
+		t.Fatalf("CallSdk() error = %v, want an interrupt", err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 0 {
+		t.Fatalf("requests = %d, want none after the command was interrupted", n)
+	}
+}
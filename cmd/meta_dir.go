@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// metaDirEnv 指定外部元数据目录，目录结构与内置资源一致：
+//
+//	<dir>/metadata/<service>/<version>/metadata.json
+//	<dir>/metatype/<service>/<version>/meta_type.json
+//	<dir>/structure/<service>/<version>/structure.json
+//
+// 目录中存在的文件覆盖同路径的内置资源，其余仍使用内置数据。
+const metaDirEnv = "BYTEPLUS_META_DIR"
+
+// embeddedMetaPrefix 是 go-bindata 生成的资源名前缀，外部文件映射为同名资源。
+const embeddedMetaPrefix = "byteplus-sdk-metadata/"
+
+// metaKindFiles 是每类元数据目录下允许的文件名。
+var metaKindFiles = map[string]string{
+	"metadata":  "metadata.json",
+	"metatype":  "meta_type.json",
+	"structure": "structure.json",
+}
+
+var metaDirWarningOut io.Writer = os.Stderr
+
+// metaBundle 合并一类内置资源与外部目录中的同类文件。
+type metaBundle struct {
+	embeddedNames []string
+	embedded      func(name string) ([]byte, error)
+	overlay       map[string][]byte
+}
+
+// Names 返回内置与外部资源名的并集，按名称排序。
+func (b *metaBundle) Names() []string {
+	seen := make(map[string]struct{}, len(b.embeddedNames)+len(b.overlay))
+	names := make([]string, 0, len(b.embeddedNames)+len(b.overlay))
+	for _, name := range b.embeddedNames {
+		seen[name] = struct{}{}
+		names = append(names, name)
+	}
+	for name := range b.overlay {
+		if _, ok := seen[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Asset 优先返回外部目录中的文件内容。
+func (b *metaBundle) Asset(name string) ([]byte, error) {
+	if data, ok := b.overlay[name]; ok {
+		return data, nil
+	}
+	return b.embedded(name)
+}
+
+// loadMetaOverlay 读取 BYTEPLUS_META_DIR，返回按元数据类别分组的外部文件。
+// 未设置环境变量时返回 nil；目录不可用时输出警告并完全使用内置数据；
+// 不符合目录结构或不是合法 JSON 的单个文件会被跳过并输出警告。
+func loadMetaOverlay() map[string]map[string][]byte {
+	dir := strings.TrimSpace(os.Getenv(metaDirEnv))
+	if dir == "" {
+		return nil
+	}
+	overlay, warnings, err := readMetaDir(dir)
+	for _, w := range warnings {
+		fmt.Fprintf(metaDirWarningOut, "Warning: %s: %s\n", metaDirEnv, w)
+	}
+	if err != nil {
+		fmt.Fprintf(metaDirWarningOut, "Warning: ignoring %s: %v\n", metaDirEnv, err)
+		return nil
+	}
+	return overlay
+}
+
+func readMetaDir(dir string) (map[string]map[string][]byte, []string, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !info.IsDir() {
+		return nil, nil, fmt.Errorf("%s is not a directory", dir)
+	}
+
+	overlay := make(map[string]map[string][]byte)
+	var warnings []string
+	found := false
+	kinds := make([]string, 0, len(metaKindFiles))
+	for kind := range metaKindFiles {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	for _, kind := range kinds {
+		kindDir := filepath.Join(dir, kind)
+		if info, err := os.Stat(kindDir); err != nil || !info.IsDir() {
+			continue
+		}
+		found = true
+		files := make(map[string][]byte)
+		err := filepath.Walk(kindDir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+			rel, err := filepath.Rel(kindDir, path)
+			if err != nil {
+				return err
+			}
+			rel = filepath.ToSlash(rel)
+			parts := strings.Split(rel, "/")
+			if len(parts) != 3 || parts[2] != metaKindFiles[kind] {
+				warnings = append(warnings, fmt.Sprintf("skip %s/%s, expected %s/<service>/<version>/%s", kind, rel, kind, metaKindFiles[kind]))
+				return nil
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			if !json.Valid(data) {
+				warnings = append(warnings, fmt.Sprintf("skip %s/%s, invalid JSON", kind, rel))
+				return nil
+			}
+			files[embeddedMetaPrefix+kind+"/"+rel] = data
+			return nil
+		})
+		if err != nil {
+			return nil, warnings, err
+		}
+		overlay[kind] = files
+	}
+	if !found {
+		return nil, warnings, fmt.Errorf("%s contains none of the metadata, metatype or structure directories", dir)
+	}
+	return overlay, warnings, nil
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeMetaFileForTest(t *testing.T, dir, rel, content string) {
+	t.Helper()
+	path := filepath.Join(dir, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write %s: %v", rel, err)
+	}
+}
+
+func TestReadMetaDirValidatesLayout(t *testing.T) {
+	dir := t.TempDir()
+	writeMetaFileForTest(t, dir, "metadata/demo/2024-01-01/metadata.json", `{}`)
+	writeMetaFileForTest(t, dir, "metadata/demo/metadata.json", `{}`)
+	writeMetaFileForTest(t, dir, "structure/demo/2024-01-01/structure.json", `{`)
+
+	overlay, warnings, err := readMetaDir(dir)
+	if err != nil {
+		t.Fatalf("readMetaDir() error = %v", err)
+	}
+	if _, ok := overlay["metadata"]["byteplus-sdk-metadata/metadata/demo/2024-01-01/metadata.json"]; !ok {
+		t.Fatalf("overlay = %v, want demo metadata mapped to embedded asset name", overlay)
+	}
+	if len(overlay["structure"]) != 0 {
+		t.Fatalf("structure overlay = %v, want invalid JSON skipped", overlay["structure"])
+	}
+	if len(warnings) != 2 {
+		t.Fatalf("warnings = %v, want layout and JSON warnings", warnings)
+	}
+}
+
+func TestReadMetaDirRejectsUnknownLayout(t *testing.T) {
+	dir := t.TempDir()
+	writeMetaFileForTest(t, dir, "other/file.json", `{}`)
+
+	if _, _, err := readMetaDir(dir); err == nil || !strings.Contains(err.Error(), "contains none of") {
+		t.Fatalf("readMetaDir() error = %v, want layout error", err)
+	}
+	if _, _, err := readMetaDir(filepath.Join(dir, "missing")); err == nil {
+		t.Fatal("readMetaDir() on missing directory error = nil, want error")
+	}
+}
+
+func TestNewRootSupportOverlaysMetaDir(t *testing.T) {
+	dir := t.TempDir()
+	writeMetaFileForTest(t, dir, "structure/demo/2024-01-01/structure.json",
+		`{"PkgName":"demo","ServiceName":"demo","Version":"2024-01-01"}`)
+	writeMetaFileForTest(t, dir, "metadata/demo/2024-01-01/metadata.json",
+		`{"ListWidgets":{"ApiInfo":{"Method":"POST","ContentType":"application/json"}}}`)
+	writeMetaFileForTest(t, dir, "metatype/demo/2024-01-01/meta_type.json", `{"ListWidgets":{}}`)
+	t.Setenv(metaDirEnv, dir)
+	defer delete(serviceMapping, "demo")
+
+	support := NewRootSupport()
+
+	if !support.IsValidAction("demo", "ListWidgets") {
+		t.Fatal("demo.ListWidgets from BYTEPLUS_META_DIR is not a valid action")
+	}
+	if got := support.GetVersion("demo"); got != "2024-01-01" {
+		t.Fatalf("demo version = %q, want 2024-01-01", got)
+	}
+	if info := support.GetApiInfo("demo", "ListWidgets"); info == nil || info.Method != "POST" {
+		t.Fatalf("demo.ListWidgets ApiInfo = %+v, want POST", info)
+	}
+	if !support.IsValidAction("ecs", "DescribeInstances") {
+		t.Fatal("embedded ecs.DescribeInstances missing after overlay")
+	}
+}
+
+func TestLoadMetaOverlayFallsBackOnInvalidDir(t *testing.T) {
+	var warnings bytes.Buffer
+	prev := metaDirWarningOut
+	metaDirWarningOut = &warnings
+	defer func() { metaDirWarningOut = prev }()
+
+	t.Setenv(metaDirEnv, filepath.Join(t.TempDir(), "missing"))
+	if overlay := loadMetaOverlay(); overlay != nil {
+		t.Fatalf("loadMetaOverlay() = %v, want nil", overlay)
+	}
+	if !strings.Contains(warnings.String(), "ignoring BYTEPLUS_META_DIR") {
+		t.Fatalf("warnings = %q, want fallback warning", warnings.String())
+	}
+}
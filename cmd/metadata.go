@@ -39,7 +39,9 @@ type ApiInfo struct {
 	Method      string
 	ContentType string
 	ServiceName string
-	ParamTypes  map[string]string
+	// Protocol 为 "json" 时整个入参以 JSON body 发送，未声明时使用 query 协议。
+	Protocol   string
+	ParamTypes map[string]string
 	// int float64
 	// [], {}
 }
@@ -56,7 +58,8 @@ type param struct {
 	required bool
 }
 
-func formatParamsHelpUsage(params []param) []string {
+// formatParamsHelpUsage 把参数格式化为帮助中的 "名称 类型" 行，按是否必填分为两组；两组使用相同列宽以便对齐。
+func formatParamsHelpUsage(params []param) (required []string, optional []string) {
 	maxKeyLen := -1
 	maxTypeNameLen := -1
 	for _, p := range params {
@@ -71,17 +74,18 @@ func formatParamsHelpUsage(params []param) []string {
 	maxKeyLen++
 	maxTypeNameLen++
 
-	// TODO: not print required field now
-	//formatString := "%-" + strconv.Itoa(maxKeyLen) + "v%-" + strconv.Itoa(maxTypeNameLen) + "v %v"
 	formatString := "%-" + strconv.Itoa(maxKeyLen) + "v%-" + strconv.Itoa(maxTypeNameLen) + "v"
 
-	var paramStrings []string
 	for _, p := range params {
-		//paramStrings = append(paramStrings, fmt.Sprintf(formatString, p.key, p.typeName, formatRequired(p.required)))
-		paramStrings = append(paramStrings, fmt.Sprintf(formatString, p.key, p.typeName))
+		line := fmt.Sprintf(formatString, p.key, p.typeName)
+		if p.required {
+			required = append(required, line)
+		} else {
+			optional = append(optional, line)
+		}
 	}
 
-	return paramStrings
+	return required, optional
 }
 
 func formatRequired(required bool) string {
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 )
@@ -17,8 +18,21 @@ type OAuthClientConfig struct {
 	Region string
 	// HTTPClient 允许注入自定义 HTTP 客户端（例如代理、超时）。
 	HTTPClient *http.Client
+	// BaseURL 覆盖按 Region 拼出的服务地址，需包含 scheme，例如本地 mock 服务 http://127.0.0.1:8080。
+	BaseURL string
+	// AllowInsecure 允许 BaseURL 使用不加密的 http，仅用于开发调试。
+	AllowInsecure bool
+	// Encoding 为请求体编码方式：OAuthEncodingJSON（默认）或 OAuthEncodingForm。
+	Encoding string
 }
 
+// OAuth 请求体编码方式。OAuthEncodingForm 按 RFC 6749 以 application/x-www-form-urlencoded 发送，
+// 字段名与 JSON 相同，数组字段编码为同名的多个值。
+const (
+	OAuthEncodingJSON = "json"
+	OAuthEncodingForm = "form"
+)
+
 const (
 	defaultOAuthRegion    = "ap-southeast-1"
 	defaultRegisterPath   = "/client/register"
@@ -38,6 +52,9 @@ type OAuthClient struct {
 	revokeURL   string
 	deviceURL   string
 	httpClient  *http.Client
+	encoding    string
+	// configErr 为 BaseURL、Encoding 或建立连接超时校验失败的原因，非空时所有请求直接返回该错误。
+	configErr error
 }
 
 // OAuthClientAPI 定义 OAuth 客户端对外暴露的方法集合，便于测试或替换实现。
@@ -82,6 +99,7 @@ type CreateTokenResponse struct {
 	TokenType    string `json:"token_type"`
 	RefreshToken string `json:"refresh_token,omitempty"`
 	ExpiresIn    int    `json:"expires_in"`
+	Scope        string `json:"scope,omitempty"`
 }
 
 // RevokeTokenRequest 为撤销 token 的请求参数。
@@ -148,9 +166,27 @@ func NewOAuthClient(cfg *OAuthClientConfig) *OAuthClient {
 	}
 
 	base := fmt.Sprintf(oAuthBaseURLTemplate, region)
-	client := &http.Client{Timeout: defaultRequestTimeout}
+	var configErr error
+	if cfg != nil && strings.TrimSpace(cfg.BaseURL) != "" {
+		base = strings.TrimSpace(cfg.BaseURL)
+		configErr = validateIdentityBaseURL(base, cfg.AllowInsecure)
+	}
+	var client *http.Client
 	if cfg != nil && cfg.HTTPClient != nil {
 		client = cfg.HTTPClient
+	} else {
+		var err error
+		client, err = identityHTTPClient(defaultRequestTimeout)
+		if configErr == nil {
+			configErr = err
+		}
+	}
+	encoding := OAuthEncodingJSON
+	if cfg != nil && strings.TrimSpace(cfg.Encoding) != "" {
+		encoding = strings.ToLower(strings.TrimSpace(cfg.Encoding))
+		if encoding != OAuthEncodingJSON && encoding != OAuthEncodingForm && configErr == nil {
+			configErr = fmt.Errorf("unsupported OAuth request encoding %q, expected %s or %s", cfg.Encoding, OAuthEncodingJSON, OAuthEncodingForm)
+		}
 	}
 
 	return &OAuthClient{
@@ -160,7 +196,36 @@ func NewOAuthClient(cfg *OAuthClientConfig) *OAuthClient {
 		revokeURL:   strings.TrimRight(base, "/") + defaultRevokePath,
 		deviceURL:   strings.TrimRight(base, "/") + defaultDeviceAuthPath,
 		httpClient:  client,
+		encoding:    encoding,
+		configErr:   configErr,
+	}
+}
+
+// validateIdentityBaseURL 校验自定义的身份服务地址：默认只接受 https，
+// allowInsecure 为 true 时也接受 http，便于对接本地未配置 TLS 的 mock 服务。
+func validateIdentityBaseURL(base string, allowInsecure bool) error {
+	u, err := url.Parse(base)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("invalid base URL %q: scheme and host are required", base)
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "https":
+		return nil
+	case "http":
+		if allowInsecure {
+			return nil
+		}
+		return fmt.Errorf("base URL %q uses plain http; set AllowInsecure to use a non-TLS endpoint", base)
 	}
+	return fmt.Errorf("base URL %q has unsupported scheme %q, expected https or http", base, u.Scheme)
+}
+
+// post 发送 OAuth 请求；BaseURL 配置无效时不发起请求。
+func (c *OAuthClient) post(ctx context.Context, endpoint string, req interface{}, out interface{}) error {
+	if c.configErr != nil {
+		return c.configErr
+	}
+	return doOAuthPost(ctx, c.httpClient, endpoint, c.encoding, req, out)
 }
 
 // RegisterClient 调用 RegisterClient API，返回注册后的 client_id/client_secret。
@@ -173,7 +238,7 @@ func (c *OAuthClient) RegisterClient(ctx context.Context, req *RegisterClientReq
 	}
 
 	var apiResp RegisterClientResponse
-	if err := doOAuthPost(ctx, c.httpClient, c.registerURL, req, &apiResp); err != nil {
+	if err := c.post(ctx, c.registerURL, req, &apiResp); err != nil {
 		return nil, err
 	}
 	if apiResp.ClientID == "" && apiResp.ClientSecret == "" && apiResp.ClientIDIssuedAt == 0 && apiResp.ClientSecretExpiresAt == 0 {
@@ -207,7 +272,7 @@ func (c *OAuthClient) CreateToken(ctx context.Context, req *CreateTokenRequest)
 	}
 
 	var apiResp CreateTokenResponse
-	if err := doOAuthPost(ctx, c.httpClient, c.tokenURL, req, &apiResp); err != nil {
+	if err := c.post(ctx, c.tokenURL, req, &apiResp); err != nil {
 		return nil, err
 	}
 	if apiResp.AccessToken == "" && apiResp.TokenType == "" && apiResp.RefreshToken == "" && apiResp.ExpiresIn == 0 {
@@ -229,7 +294,7 @@ func (c *OAuthClient) RevokeToken(ctx context.Context, req *RevokeTokenRequest)
 	}
 
 	var apiResp revokeTokenAPIResponse
-	if err := doOAuthPost(ctx, c.httpClient, c.revokeURL, req, &apiResp); err != nil {
+	if err := c.post(ctx, c.revokeURL, req, &apiResp); err != nil {
 		return err
 	}
 	return nil
@@ -245,7 +310,7 @@ func (c *OAuthClient) StartDeviceAuthorization(ctx context.Context, req *StartDe
 	}
 
 	var apiResp StartDeviceAuthorizationResponse
-	if err := doOAuthPost(ctx, c.httpClient, c.deviceURL, req, &apiResp); err != nil {
+	if err := c.post(ctx, c.deviceURL, req, &apiResp); err != nil {
 		return nil, err
 	}
 
@@ -256,12 +321,21 @@ func (c *OAuthClient) StartDeviceAuthorization(ctx context.Context, req *StartDe
 	return &apiResp, nil
 }
 
-// doOAuthPost 负责发起 OAuth POST 请求并统一处理错误与响应解析。
-func doOAuthPost(ctx context.Context, client *http.Client, url string, payload interface{}, out interface{}) error {
+// doOAuthPost 负责发起 OAuth POST 请求并统一处理错误与响应解析；encoding 决定请求体为 JSON 还是表单。
+func doOAuthPost(ctx context.Context, client *http.Client, url string, encoding string, payload interface{}, out interface{}) error {
 	body, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("failed to marshal request: %w", err)
 	}
+	contentType := "application/json"
+	if encoding == OAuthEncodingForm {
+		form, err := oauthFormValues(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		body = []byte(form.Encode())
+		contentType = "application/x-www-form-urlencoded"
+	}
 
 	attempts := 3
 	// Avoid retries for client registration because it's not guaranteed to be idempotent.
@@ -274,7 +348,7 @@ func doOAuthPost(ctx context.Context, client *http.Client, url string, payload i
 		if err != nil {
 			return fmt.Errorf("failed to build request: %w", err)
 		}
-		httpReq.Header.Set("Content-Type", "application/json")
+		httpReq.Header.Set("Content-Type", contentType)
 
 		resp, err := client.Do(httpReq)
 		if err != nil {
@@ -322,3 +396,27 @@ func doOAuthPost(ctx context.Context, client *http.Client, url string, payload i
 		return nil
 	})
 }
+
+// oauthFormValues 把请求的 JSON 编码转换为表单字段，沿用 json tag 的字段名与 omitempty；
+// 数组字段编码为同名的多个值，空值字段省略。
+func oauthFormValues(jsonBody []byte) (url.Values, error) {
+	var fields map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(jsonBody))
+	decoder.UseNumber()
+	if err := decoder.Decode(&fields); err != nil {
+		return nil, err
+	}
+	form := url.Values{}
+	for key, value := range fields {
+		switch v := value.(type) {
+		case nil:
+		case []interface{}:
+			for _, item := range v {
+				form.Add(key, fmt.Sprint(item))
+			}
+		default:
+			form.Set(key, fmt.Sprint(v))
+		}
+	}
+	return form, nil
+}
@@ -0,0 +1,654 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/byteplus-sdk/byteplus-cli/util"
+)
+
+const (
+	outputFormatJSON        = "json"
+	outputFormatJSONCompact = "json-compact"
+	outputFormatTable       = "table"
+	outputFormatText        = "text"
+)
+
+const supportedOutputFormatsMessage = "json, table, text, json-compact"
+
+// actionOutput 是一次 action 调用的输出设置，来自 ---output、---paginate、---fields、---count、---jq、---output-template、
+// ---created-after/---created-before/---time-field、---sort-by/---reverse 以及 ---output-file/---output-file-format。
+type actionOutput struct {
+	format     string
+	paginate   bool
+	fields     []string
+	count      bool
+	jq         *util.JQ
+	template   *util.OutputTemplate
+	timeWindow *util.TimeWindow
+	// sortBy 为 ---sort-by 指定的排序字段，reverse 为 true 时降序。
+	sortBy  string
+	reverse bool
+	color   bool
+	out     io.Writer
+	// file 为 ---output-file 指定的第二个输出目标，未指定时为 nil。
+	file *outputFile
+	// sink 为 ---post-result 指定的投递目标，未指定时为 nil。
+	sink *resultSink
+	// toFile 表示本输出写入 ---output-file；json 格式此时不带颜色，结尾也不追加空行。
+	toFile bool
+	// pager 为 ---pager 启用的分页输出，同时也是 out；未启用时为 nil。
+	pager *pagerWriter
+}
+
+// outputFile 把同一结果再以 ---output-file-format 渲染一份，全部页处理完后写入 path。
+type outputFile struct {
+	path   string
+	output *actionOutput
+	buf    bytes.Buffer
+	// discard 为 true 时 finish 不写文件，调用失败时的部分结果不应落盘。
+	discard bool
+}
+
+var actionOutputWriter io.Writer = os.Stdout
+
+func resolveActionOutput(ctx *Context) (*actionOutput, error) {
+	o := &actionOutput{
+		format: outputFormatJSON,
+		color:  config != nil && config.EnableColor,
+		out:    actionOutputWriter,
+	}
+	if f := ctx.fixedFlags.GetByName("output"); f != nil {
+		format := strings.ToLower(strings.TrimSpace(f.GetValue()))
+		switch format {
+		case outputFormatJSON, outputFormatJSONCompact, outputFormatTable, outputFormatText:
+			o.format = format
+		default:
+			return nil, fmt.Errorf("---output %q is not supported, supported values: %s", f.GetValue(), supportedOutputFormatsMessage)
+		}
+	}
+	if f := ctx.fixedFlags.GetByName("paginate"); f != nil {
+		o.paginate = f.GetValue() == "true"
+	}
+	if f := ctx.fixedFlags.GetByName("fields"); f != nil {
+		if o.format != outputFormatTable && o.format != outputFormatText {
+			return nil, fmt.Errorf("---fields can only be used with ---output table or text")
+		}
+		o.fields = util.ParseFields(f.GetValue())
+		if len(o.fields) == 0 {
+			return nil, fmt.Errorf("---fields requires at least one field name")
+		}
+	}
+	if f := ctx.fixedFlags.GetByName("count"); f != nil && f.GetValue() == "true" {
+		if o.format == outputFormatTable {
+			return nil, fmt.Errorf("---count cannot be used with ---output table")
+		}
+		o.count = true
+	}
+	if f := ctx.fixedFlags.GetByName("jq"); f != nil {
+		// ---jq 作用于完整的 JSON 响应，与按行输出的格式及 ---fields/---count 互斥
+		if !o.jsonFormat() || len(o.fields) > 0 || o.count {
+			return nil, fmt.Errorf("---jq cannot be used with ---output table or text, ---fields or ---count")
+		}
+		q, err := util.ParseJQ(f.GetValue())
+		if err != nil {
+			return nil, fmt.Errorf("invalid ---jq expression: %w", err)
+		}
+		o.jq = q
+	}
+	if f := ctx.fixedFlags.GetByName("output-template"); f != nil {
+		// 模板完全决定输出内容，不与其他格式化选项组合
+		if ctx.fixedFlags.GetByName("output") != nil || len(o.fields) > 0 || o.count || o.jq != nil {
+			return nil, fmt.Errorf("---output-template cannot be used with ---output, ---fields, ---count or ---jq")
+		}
+		tmpl, err := util.ParseOutputTemplate(f.GetValue())
+		if err != nil {
+			return nil, fmt.Errorf("invalid ---output-template: %w", err)
+		}
+		o.template = tmpl
+	}
+	timeWindow, err := resolveTimeWindow(ctx)
+	if err != nil {
+		return nil, err
+	}
+	o.timeWindow = timeWindow
+	if f := ctx.fixedFlags.GetByName("sort-by"); f != nil {
+		o.sortBy = strings.TrimSpace(f.GetValue())
+		if o.sortBy == "" {
+			return nil, fmt.Errorf("---sort-by requires a field name")
+		}
+	}
+	if f := ctx.fixedFlags.GetByName("reverse"); f != nil && f.GetValue() == "true" {
+		if o.sortBy == "" {
+			return nil, fmt.Errorf("---reverse requires ---sort-by")
+		}
+		o.reverse = true
+	}
+	file, err := resolveOutputFile(ctx)
+	if err != nil {
+		return nil, err
+	}
+	o.file = file
+	if o.sink, err = resolveResultSink(ctx, o.jq); err != nil {
+		return nil, err
+	}
+	if o.pager = resolvePager(ctx); o.pager != nil {
+		o.out = o.pager
+	}
+	return o, nil
+}
+
+// resolveOutputFile 解析 ---output-file 与 ---output-file-format，文件格式默认为 json。
+// ---fields、---count、---jq 与 ---output-template 只作用于终端输出，文件中始终是完整的结果。
+func resolveOutputFile(ctx *Context) (*outputFile, error) {
+	formatFlag := ctx.fixedFlags.GetByName("output-file-format")
+	f := ctx.fixedFlags.GetByName("output-file")
+	if f == nil {
+		if formatFlag != nil {
+			return nil, fmt.Errorf("---output-file-format requires ---output-file")
+		}
+		return nil, nil
+	}
+	path := strings.TrimSpace(f.GetValue())
+	if path == "" {
+		return nil, fmt.Errorf("---output-file requires a file path")
+	}
+	format := outputFormatJSON
+	if formatFlag != nil {
+		format = strings.ToLower(strings.TrimSpace(formatFlag.GetValue()))
+		switch format {
+		case outputFormatJSON, outputFormatJSONCompact, outputFormatTable, outputFormatText:
+		default:
+			return nil, fmt.Errorf("---output-file-format %q is not supported, supported values: %s", formatFlag.GetValue(), supportedOutputFormatsMessage)
+		}
+	}
+	file := &outputFile{path: path}
+	file.output = &actionOutput{format: format, out: &file.buf, toFile: true}
+	return file, nil
+}
+
+// resolveTimeWindow 解析 ---created-after、---created-before 与 ---time-field；两个边界都未指定时返回 nil。
+// 时长边界相对当前时间计算，例如 ---created-after 7d 表示最近 7 天。
+func resolveTimeWindow(ctx *Context) (*util.TimeWindow, error) {
+	w := &util.TimeWindow{}
+	now := nowFunc()
+	for _, bound := range []struct {
+		name   string
+		target *time.Time
+	}{{"created-after", &w.After}, {"created-before", &w.Before}} {
+		f := ctx.fixedFlags.GetByName(bound.name)
+		if f == nil {
+			continue
+		}
+		t, err := util.ParseTimeBound(f.GetValue(), now)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ---%s: %w", bound.name, err)
+		}
+		*bound.target = t
+	}
+	if f := ctx.fixedFlags.GetByName("time-field"); f != nil {
+		w.Field = strings.TrimSpace(f.GetValue())
+		if w.Field == "" {
+			return nil, fmt.Errorf("---time-field requires a field name")
+		}
+	}
+	if w.After.IsZero() && w.Before.IsZero() {
+		if w.Field != "" {
+			return nil, fmt.Errorf("---time-field requires ---created-after or ---created-before")
+		}
+		return nil, nil
+	}
+	if !w.After.IsZero() && !w.Before.IsZero() && !w.After.Before(w.Before) {
+		return nil, fmt.Errorf("---created-after must be earlier than ---created-before")
+	}
+	return w, nil
+}
+
+// jsonFormat 判断输出是否为 JSON 文档（缩进的 json 或单行的 json-compact）。
+func (o *actionOutput) jsonFormat() bool {
+	return o.format == outputFormatJSON || o.format == outputFormatJSONCompact
+}
+
+// pageHandler 处理一页响应；返回 error 时停止翻页。
+type pageHandler func(page map[string]interface{}) error
+
+// newPageHandler 返回处理每页响应的 handler，以及在全部页处理完后调用的 finish。
+// 由内到外依次组合：输出格式的 handler、---output-file 的 tee、---post-result 的 tee、
+// ---sort-by 的排序、---pager 的关闭，最外层是时间窗口过滤。
+func (o *actionOutput) newPageHandler() (pageHandler, func() error) {
+	handlePage, finish := o.newFormatHandler()
+	if o.file != nil {
+		handlePage, finish = o.file.tee(handlePage, finish)
+	}
+	if o.sink != nil {
+		handlePage = o.sink.tee(handlePage)
+	}
+	if o.sortBy != "" {
+		handlePage, finish = o.sortPages(handlePage, finish)
+	}
+	if o.pager != nil {
+		renderFinish := finish
+		finish = func() error {
+			defer o.closePager()
+			return renderFinish()
+		}
+	}
+	if o.timeWindow == nil {
+		return handlePage, finish
+	}
+	return func(page map[string]interface{}) error {
+		if err := o.filterPageByTime(page); err != nil {
+			return err
+		}
+		return handlePage(page)
+	}, finish
+}
+
+// finishPartial 在翻页中途调用失败时结束输出：已获取的行仍然输出到终端，---output-file 不写入。
+func (o *actionOutput) finishPartial(finish func() error) error {
+	if o.file != nil {
+		o.file.discard = true
+	}
+	return finish()
+}
+
+// deliverResult 在命令成功后把结果投递到 ---post-result；未指定时什么也不做。
+func (o *actionOutput) deliverResult() error {
+	if o.sink == nil {
+		return nil
+	}
+	return o.sink.deliver()
+}
+
+// closePager 关闭 ---pager 启动的分页器；未启用分页或已关闭时什么也不做。
+func (o *actionOutput) closePager() {
+	if o != nil && o.pager != nil {
+		_ = o.pager.Close()
+	}
+}
+
+// filterPageByTime 原地替换一页响应中的列表，只保留时间窗口内的元素。
+func (o *actionOutput) filterPageByTime(page map[string]interface{}) error {
+	result, ok := page["Result"].(map[string]interface{})
+	if !ok {
+		result = page
+	}
+	key, items, ok := resultListField(result)
+	if !ok {
+		return fmt.Errorf("---created-after and ---created-before require a list response, but the response Result contains no array")
+	}
+	kept, err := o.timeWindow.Filter(items)
+	if err != nil {
+		if o.timeWindow.Field == "" {
+			return fmt.Errorf("cannot filter %s by time: %w; set the timestamp field with ---time-field", key, err)
+		}
+		return fmt.Errorf("cannot filter %s by time: %w", key, err)
+	}
+	result[key] = kept
+	return nil
+}
+
+// sortPages 合并所有页的列表，全部页处理完后按 ---sort-by 排序，再把合并结果交给 handlePage。
+func (o *actionOutput) sortPages(handlePage pageHandler, finish func() error) (pageHandler, func() error) {
+	var merged map[string]interface{}
+	return func(page map[string]interface{}) error {
+			if merged == nil {
+				merged = page
+				return nil
+			}
+			mergePageList(merged, page)
+			return nil
+		}, func() error {
+			if merged != nil {
+				result, ok := merged["Result"].(map[string]interface{})
+				if !ok {
+					result = merged
+				}
+				key, items, ok := resultListField(result)
+				if !ok {
+					return fmt.Errorf("---sort-by requires a list response, but the response Result contains no array")
+				}
+				sorted, err := util.SortByField(items, o.sortBy, o.reverse)
+				if err != nil {
+					return fmt.Errorf("cannot sort %s: %w", key, err)
+				}
+				result[key] = sorted
+				if err := handlePage(merged); err != nil {
+					return err
+				}
+			}
+			return finish()
+		}
+}
+
+// tee 把每页同时交给终端与文件的 handler。json 格式会把后续页合并进第一页，
+// 因此文件 handler 拿到的是每页的副本，避免两个目标向同一个列表重复追加。
+func (f *outputFile) tee(handlePage pageHandler, finish func() error) (pageHandler, func() error) {
+	fileHandlePage, fileFinish := f.output.newFormatHandler()
+	return func(page map[string]interface{}) error {
+			copied, _ := copyJSONValue(page).(map[string]interface{})
+			if err := fileHandlePage(copied); err != nil {
+				return err
+			}
+			return handlePage(page)
+		}, func() error {
+			if err := finish(); err != nil {
+				return err
+			}
+			if f.discard {
+				return nil
+			}
+			if err := fileFinish(); err != nil {
+				return err
+			}
+			if err := f.write(); err != nil {
+				return fmt.Errorf("failed to write ---output-file: %w", err)
+			}
+			return nil
+		}
+}
+
+// write 先写入同目录下的临时文件再替换 path，写入中途失败时不会留下截断的文件。
+func (f *outputFile) write() error {
+	tempFile, err := os.CreateTemp(filepath.Dir(f.path), tempFilePrefix+"*")
+	if err != nil {
+		return err
+	}
+	tempName := tempFile.Name()
+	defer trackTempFile(tempName)()
+	defer func() {
+		_ = tempFile.Close()
+		_ = os.Remove(tempName)
+	}()
+	if _, err := tempFile.Write(f.buf.Bytes()); err != nil {
+		return err
+	}
+	if err := tempFile.Sync(); err != nil {
+		return err
+	}
+	if err := tempFile.Close(); err != nil {
+		return err
+	}
+	return replaceFile(tempName, f.path, 0644)
+}
+
+// copyJSONValue 深拷贝 JSON 解码得到的对象与数组，标量原样返回。
+func copyJSONValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(value))
+		for k, item := range value {
+			copied[k] = copyJSONValue(item)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(value))
+		for i, item := range value {
+			copied[i] = copyJSONValue(item)
+		}
+		return copied
+	default:
+		return v
+	}
+}
+
+// newFormatHandler 返回按输出格式处理每页的 handler：table 与 text 格式逐页输出，
+// json 格式合并所有页的列表，在 finish 中一次输出。
+func (o *actionOutput) newFormatHandler() (pageHandler, func() error) {
+	if o.count {
+		return o.newCountHandler()
+	}
+	if o.format == outputFormatTable {
+		tw := util.NewTableWriter(o.out, util.DefaultTableSampleSize)
+		if len(o.fields) > 0 {
+			tw.SetColumns(o.fields)
+		}
+		return func(page map[string]interface{}) error {
+			for _, row := range tableRows(page) {
+				if len(o.fields) > 0 {
+					row = util.ProjectFields(row, o.fields)
+				}
+				if err := tw.Write(row); err != nil {
+					return err
+				}
+			}
+			return nil
+		}, tw.Flush
+	}
+
+	if o.format == outputFormatText {
+		return o.newTextHandler()
+	}
+
+	var merged map[string]interface{}
+	return func(page map[string]interface{}) error {
+			if merged == nil {
+				merged = page
+				return nil
+			}
+			mergePageList(merged, page)
+			return nil
+		}, func() error {
+			if merged == nil {
+				return nil
+			}
+			if o.template != nil {
+				return o.template.Execute(o.out, responseResult(merged))
+			}
+			if o.jq != nil {
+				return o.writeJQResults(merged)
+			}
+			if o.format == outputFormatJSONCompact {
+				return util.WriteCompactJson(o.out, merged)
+			}
+			if o.toFile {
+				enc := json.NewEncoder(o.out)
+				enc.SetEscapeHTML(false)
+				enc.SetIndent("", "    ")
+				return enc.Encode(merged)
+			}
+			return util.WriteJson(o.out, merged, o.color)
+		}
+}
+
+// writeJQResults 对响应执行 ---jq 表达式，每个结果输出为一个缩进的 JSON 文档，json-compact 时每个结果占一行。
+func (o *actionOutput) writeJQResults(response map[string]interface{}) error {
+	results, err := o.jq.Run(response)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(o.out)
+	enc.SetEscapeHTML(false)
+	if o.format != outputFormatJSONCompact {
+		enc.SetIndent("", "    ")
+	}
+	for _, r := range results {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newCountHandler 累加每页列表的元素个数，全部页处理完后只输出总数；响应中没有列表时报错。
+func (o *actionOutput) newCountHandler() (pageHandler, func() error) {
+	var total int
+	return func(page map[string]interface{}) error {
+			_, items, ok := resultListField(responseResult(page))
+			if !ok {
+				return fmt.Errorf("---count requires a list response, but the response Result contains no array")
+			}
+			total += len(items)
+			return nil
+		}, func() error {
+			_, err := fmt.Fprintln(o.out, total)
+			return err
+		}
+}
+
+// newTextHandler 逐页输出制表符分隔的记录：列表响应每个元素一行，元素不是对象时原样输出；
+// 其他响应整个 Result 作为一行。
+func (o *actionOutput) newTextHandler() (pageHandler, func() error) {
+	tw := util.NewTextWriter(o.out)
+	if len(o.fields) > 0 {
+		tw.SetColumns(o.fields)
+	}
+	return func(page map[string]interface{}) error {
+			result := responseResult(page)
+			_, items, ok := resultListField(result)
+			if !ok {
+				items = []interface{}{result}
+			}
+			for _, item := range items {
+				row, isObject := item.(map[string]interface{})
+				if !isObject {
+					if err := tw.WriteValue(item); err != nil {
+						return err
+					}
+					continue
+				}
+				if len(o.fields) > 0 {
+					row = util.ProjectFields(row, o.fields)
+				}
+				if err := tw.Write(row); err != nil {
+					return err
+				}
+			}
+			return nil
+		}, func() error {
+			return nil
+		}
+}
+
+// responseResult 返回响应中的 Result；响应没有 Result 时返回去掉 ResponseMetadata 的响应本身。
+func responseResult(page map[string]interface{}) map[string]interface{} {
+	if result, ok := page["Result"].(map[string]interface{}); ok {
+		return result
+	}
+	result := make(map[string]interface{}, len(page))
+	for k, v := range page {
+		if k != "ResponseMetadata" {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// resultListField 返回 Result 中第一个数组字段（按字段名排序），作为列表响应的数据行。
+func resultListField(result map[string]interface{}) (string, []interface{}, bool) {
+	keys := make([]string, 0, len(result))
+	for k := range result {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if items, ok := result[k].([]interface{}); ok {
+			return k, items, true
+		}
+	}
+	return "", nil, false
+}
+
+// tableRows 把一页响应转换为表格行：列表响应每个元素一行，其他响应整个 Result 作为一行。
+func tableRows(page map[string]interface{}) []map[string]interface{} {
+	result := responseResult(page)
+	_, items, ok := resultListField(result)
+	if !ok {
+		return []map[string]interface{}{result}
+	}
+	rows := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		if row, ok := item.(map[string]interface{}); ok {
+			rows = append(rows, row)
+		} else {
+			rows = append(rows, map[string]interface{}{"Value": item})
+		}
+	}
+	return rows
+}
+
+func mergePageList(merged, page map[string]interface{}) {
+	key, items, ok := resultListField(responseResult(page))
+	if !ok {
+		return
+	}
+	result, ok := merged["Result"].(map[string]interface{})
+	if !ok {
+		result = merged
+	}
+	existing, _ := result[key].([]interface{})
+	result[key] = append(existing, items...)
+}
+
+// pageParams 返回可以写入翻页参数的请求参数 map；请求体不是 JSON 对象时无法翻页。
+func pageParams(input interface{}) map[string]interface{} {
+	switch v := input.(type) {
+	case *map[string]interface{}:
+		if v == nil {
+			return nil
+		}
+		if *v == nil {
+			*v = map[string]interface{}{}
+		}
+		return *v
+	case map[string]interface{}:
+		return v
+	}
+	return nil
+}
+
+// setNextPageParams 根据响应设置下一页的请求参数，没有下一页时返回 false。
+// 支持两种翻页方式：Result.NextToken，以及 Result.PageNumber/PageSize/TotalCount。
+func setNextPageParams(page, params map[string]interface{}, jsonBody bool) bool {
+	result, ok := page["Result"].(map[string]interface{})
+	if !ok || params == nil {
+		return false
+	}
+	if token, _ := result["NextToken"].(string); token != "" {
+		if prev, _ := params["NextToken"].(string); prev == token {
+			return false
+		}
+		params["NextToken"] = token
+		return true
+	}
+
+	pageNumber, ok1 := jsonInt(result["PageNumber"])
+	pageSize, ok2 := jsonInt(result["PageSize"])
+	total, ok3 := jsonInt(result["TotalCount"])
+	if !ok1 || !ok2 || !ok3 || pageNumber < 1 || pageSize < 1 || pageNumber*pageSize >= total {
+		return false
+	}
+	if jsonBody {
+		params["PageNumber"] = pageNumber + 1
+	} else {
+		// query/form API 的参数都以字符串形式传递
+		params["PageNumber"] = strconv.FormatInt(pageNumber+1, 10)
+	}
+	return true
+}
+
+func jsonInt(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return int64(n), true
+	case json.Number:
+		i, err := n.Int64()
+		return i, err == nil
+	case int:
+		return int64(n), true
+	case int64:
+		return n, true
+	case string:
+		i, err := strconv.ParseInt(n, 10, 64)
+		return i, err == nil
+	}
+	return 0, false
+}
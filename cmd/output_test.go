@@ -0,0 +1,578 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSetNextPageParamsUsesNextToken(t *testing.T) {
+	params := map[string]interface{}{"MaxResults": "2"}
+	page := map[string]interface{}{"Result": map[string]interface{}{"NextToken": "t-2"}}
+
+	if !setNextPageParams(page, params, false) {
+		t.Fatal("setNextPageParams() = false, want true")
+	}
+	if params["NextToken"] != "t-2" {
+		t.Fatalf("NextToken = %v, want t-2", params["NextToken"])
+	}
+	if setNextPageParams(page, params, false) {
+		t.Fatal("setNextPageParams() with repeated token = true, want false")
+	}
+}
+
+func TestSetNextPageParamsUsesPageNumber(t *testing.T) {
+	result := map[string]interface{}{"PageNumber": float64(1), "PageSize": float64(10), "TotalCount": float64(25)}
+	page := map[string]interface{}{"Result": result}
+
+	queryParams := map[string]interface{}{}
+	if !setNextPageParams(page, queryParams, false) || queryParams["PageNumber"] != "2" {
+		t.Fatalf("query PageNumber = %#v, want \"2\"", queryParams["PageNumber"])
+	}
+	jsonParams := map[string]interface{}{}
+	if !setNextPageParams(page, jsonParams, true) || jsonParams["PageNumber"] != int64(2) {
+		t.Fatalf("json PageNumber = %#v, want 2", jsonParams["PageNumber"])
+	}
+
+	result["PageNumber"] = float64(3)
+	if setNextPageParams(page, map[string]interface{}{}, false) {
+		t.Fatal("setNextPageParams() on last page = true, want false")
+	}
+}
+
+func TestTableRowsUsesResultList(t *testing.T) {
+	page := map[string]interface{}{
+		"ResponseMetadata": map[string]interface{}{"RequestId": "req"},
+		"Result": map[string]interface{}{
+			"TotalCount": float64(2),
+			"Instances": []interface{}{
+				map[string]interface{}{"InstanceId": "i-1"},
+				"i-2",
+			},
+		},
+	}
+	rows := tableRows(page)
+	if len(rows) != 2 || rows[0]["InstanceId"] != "i-1" || rows[1]["Value"] != "i-2" {
+		t.Fatalf("tableRows() = %#v", rows)
+	}
+
+	single := tableRows(map[string]interface{}{"Result": map[string]interface{}{"AccountId": "1"}})
+	if len(single) != 1 || single[0]["AccountId"] != "1" {
+		t.Fatalf("tableRows() for non-list result = %#v", single)
+	}
+}
+
+func TestResolveActionOutputRejectsUnknownFormat(t *testing.T) {
+	ctx := NewContext()
+	f, _ := ctx.fixedFlags.AddByName("output")
+	f.SetValue("xml")
+
+	if _, err := resolveActionOutput(ctx); err == nil || !strings.Contains(err.Error(), "supported values: json, table") {
+		t.Fatalf("resolveActionOutput() error = %v, want unsupported format error", err)
+	}
+}
+
+func TestResolveActionOutputFieldsRequireTable(t *testing.T) {
+	ctx := NewContext()
+	f, _ := ctx.fixedFlags.AddByName("fields")
+	f.SetValue("InstanceId,Status")
+
+	if _, err := resolveActionOutput(ctx); err == nil || !strings.Contains(err.Error(), "---output table") {
+		t.Fatalf("resolveActionOutput() error = %v, want ---output table error", err)
+	}
+
+	f, _ = ctx.fixedFlags.AddByName("output")
+	f.SetValue("table")
+	o, err := resolveActionOutput(ctx)
+	if err != nil {
+		t.Fatalf("resolveActionOutput() error = %v", err)
+	}
+	if len(o.fields) != 2 || o.fields[0] != "InstanceId" || o.fields[1] != "Status" {
+		t.Fatalf("fields = %#v", o.fields)
+	}
+}
+
+func TestCountHandlerSumsListsAcrossPages(t *testing.T) {
+	var out bytes.Buffer
+	o := &actionOutput{format: outputFormatJSON, count: true, out: &out}
+	handlePage, finish := o.newPageHandler()
+
+	pages := []map[string]interface{}{
+		{"Result": map[string]interface{}{"NextToken": "t-2", "Instances": []interface{}{"i-1", "i-2"}}},
+		{"Result": map[string]interface{}{"NextToken": "", "Instances": []interface{}{"i-3"}}},
+	}
+	for _, page := range pages {
+		if err := handlePage(page); err != nil {
+			t.Fatalf("handlePage() error = %v", err)
+		}
+	}
+	if err := finish(); err != nil {
+		t.Fatalf("finish() error = %v", err)
+	}
+	if out.String() != "3\n" {
+		t.Fatalf("count output = %q, want %q", out.String(), "3\n")
+	}
+
+	handlePage, _ = o.newPageHandler()
+	if err := handlePage(map[string]interface{}{"Result": map[string]interface{}{"AccountId": "1"}}); err == nil || !strings.Contains(err.Error(), "list response") {
+		t.Fatalf("handlePage() for non-list result error = %v", err)
+	}
+}
+
+func TestTextHandlerWritesTabSeparatedRows(t *testing.T) {
+	ctx := NewContext()
+	parser := NewParser([]string{"---output", "text", "---fields", "InstanceId,Placement.ZoneId"})
+	if _, err := parser.ReadArgs(ctx); err != nil {
+		t.Fatalf("ReadArgs() error = %v", err)
+	}
+	o, err := resolveActionOutput(ctx)
+	if err != nil {
+		t.Fatalf("resolveActionOutput() error = %v", err)
+	}
+	var out bytes.Buffer
+	o.out = &out
+	handlePage, finish := o.newPageHandler()
+
+	pages := []map[string]interface{}{
+		{"Result": map[string]interface{}{"Instances": []interface{}{
+			map[string]interface{}{"InstanceId": "i-1", "Placement": map[string]interface{}{"ZoneId": "z-a"}},
+		}}},
+		{"Result": map[string]interface{}{"Instances": []interface{}{
+			map[string]interface{}{"InstanceId": "i-2"},
+		}}},
+	}
+	for _, page := range pages {
+		if err := handlePage(page); err != nil {
+			t.Fatalf("handlePage() error = %v", err)
+		}
+	}
+	if err := finish(); err != nil {
+		t.Fatalf("finish() error = %v", err)
+	}
+	if want := "i-1\tz-a\ni-2\t\n"; out.String() != want {
+		t.Fatalf("text output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestTextHandlerPrintsScalarsRaw(t *testing.T) {
+	var out bytes.Buffer
+	o := &actionOutput{format: outputFormatText, out: &out}
+	handlePage, _ := o.newPageHandler()
+	if err := handlePage(map[string]interface{}{"Result": map[string]interface{}{"ZoneIds": []interface{}{"z-a", "z-b"}}}); err != nil {
+		t.Fatalf("handlePage() error = %v", err)
+	}
+	if err := handlePage(map[string]interface{}{"Result": "ok"}); err != nil {
+		t.Fatalf("handlePage() error = %v", err)
+	}
+	if want := "z-a\nz-b\nok\n"; out.String() != want {
+		t.Fatalf("text output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestResolveActionOutputCountRejectsTable(t *testing.T) {
+	ctx := NewContext()
+	parser := NewParser([]string{"---count", "---output", "table"})
+	if _, err := parser.ReadArgs(ctx); err != nil {
+		t.Fatalf("ReadArgs() error = %v", err)
+	}
+	if _, err := resolveActionOutput(ctx); err == nil || !strings.Contains(err.Error(), "---count cannot be used") {
+		t.Fatalf("resolveActionOutput() error = %v, want ---count conflict", err)
+	}
+}
+
+func TestResolveActionOutputJQ(t *testing.T) {
+	for _, args := range [][]string{
+		{"---jq", ".Result", "---output", "table"},
+		{"---jq", ".Result", "---count"},
+	} {
+		ctx := NewContext()
+		if _, err := NewParser(args).ReadArgs(ctx); err != nil {
+			t.Fatalf("ReadArgs(%v) error = %v", args, err)
+		}
+		if _, err := resolveActionOutput(ctx); err == nil || !strings.Contains(err.Error(), "---jq cannot be used") {
+			t.Fatalf("resolveActionOutput(%v) error = %v, want ---jq conflict", args, err)
+		}
+	}
+
+	ctx := NewContext()
+	if _, err := NewParser([]string{"---jq", ".Result.Instances[].InstanceId"}).ReadArgs(ctx); err != nil {
+		t.Fatalf("ReadArgs() error = %v", err)
+	}
+	o, err := resolveActionOutput(ctx)
+	if err != nil {
+		t.Fatalf("resolveActionOutput() error = %v", err)
+	}
+	var out bytes.Buffer
+	o.out = &out
+	handlePage, finish := o.newPageHandler()
+	for _, id := range []string{"i-1", "i-2"} {
+		page := map[string]interface{}{"Result": map[string]interface{}{"Instances": []interface{}{
+			map[string]interface{}{"InstanceId": id},
+		}}}
+		if err := handlePage(page); err != nil {
+			t.Fatalf("handlePage() error = %v", err)
+		}
+	}
+	if err := finish(); err != nil {
+		t.Fatalf("finish() error = %v", err)
+	}
+	if want := "\"i-1\"\n\"i-2\"\n"; out.String() != want {
+		t.Fatalf("jq output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestJSONCompactHandlerWritesOneLine(t *testing.T) {
+	ctx := NewContext()
+	if _, err := NewParser([]string{"---output", "json-compact"}).ReadArgs(ctx); err != nil {
+		t.Fatalf("ReadArgs() error = %v", err)
+	}
+	o, err := resolveActionOutput(ctx)
+	if err != nil {
+		t.Fatalf("resolveActionOutput() error = %v", err)
+	}
+	var out bytes.Buffer
+	o.out = &out
+	handlePage, finish := o.newPageHandler()
+	pages := []map[string]interface{}{
+		{"Result": map[string]interface{}{"NextToken": "t-2", "Instances": []interface{}{map[string]interface{}{"Name": "<a&b>"}}}},
+		{"Result": map[string]interface{}{"NextToken": "", "Instances": []interface{}{map[string]interface{}{"Name": "c"}}}},
+	}
+	for _, page := range pages {
+		if err := handlePage(page); err != nil {
+			t.Fatalf("handlePage() error = %v", err)
+		}
+	}
+	if err := finish(); err != nil {
+		t.Fatalf("finish() error = %v", err)
+	}
+	if want := `{"Result":{"Instances":[{"Name":"<a&b>"},{"Name":"c"}],"NextToken":"t-2"}}` + "\n"; out.String() != want {
+		t.Fatalf("json-compact output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestDoActionPaginatesTableOutput(t *testing.T) {
+	defer disableProxyEnvForTest(t)()
+
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("NextToken") == "" {
+			_, _ = w.Write([]byte(`{"ResponseMetadata":{"RequestId":"req-1"},"Result":{"NextToken":"t-2","Instances":[{"InstanceId":"i-1"}]}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ResponseMetadata":{"RequestId":"req-2"},"Result":{"NextToken":"","Instances":[{"InstanceId":"i-2"}]}}`))
+	}))
+	defer server.Close()
+
+	defer setenvForTest(t, "BYTEPLUS_ACCESS_KEY", "ak-test")()
+	defer setenvForTest(t, "BYTEPLUS_SECRET_KEY", "sk-test")()
+	defer setenvForTest(t, "BYTEPLUS_REGION", "ap-southeast-1")()
+
+	var out bytes.Buffer
+	prevWriter := actionOutputWriter
+	actionOutputWriter = &out
+	defer func() { actionOutputWriter = prevWriter }()
+
+	testCtx := NewContext()
+	parser := NewParser([]string{"---endpoint", server.URL, "---output", "table", "---paginate"})
+	if _, err := parser.ReadArgs(testCtx); err != nil {
+		t.Fatalf("ReadArgs() error = %v", err)
+	}
+	if err := doAction(testCtx, "ecs", "DescribeInstances"); err != nil {
+		t.Fatalf("doAction() error = %v", err)
+	}
+
+	if calls != 2 {
+		t.Fatalf("server calls = %d, want 2", calls)
+	}
+	want := "InstanceId\n----------\ni-1\ni-2\n"
+	if out.String() != want {
+		t.Fatalf("table output =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestDoActionMidPaginationFailureKeepsOutputFile(t *testing.T) {
+	defer disableProxyEnvForTest(t)()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("NextToken") == "" {
+			_, _ = w.Write([]byte(`{"ResponseMetadata":{"RequestId":"req-1"},"Result":{"NextToken":"t-2","Instances":[{"InstanceId":"i-1"}]}}`))
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"ResponseMetadata":{"RequestId":"req-2","Error":{"Code":"InvalidParameter","Message":"bad token"}}}`))
+	}))
+	defer server.Close()
+
+	defer setenvForTest(t, "BYTEPLUS_ACCESS_KEY", "ak-test")()
+	defer setenvForTest(t, "BYTEPLUS_SECRET_KEY", "sk-test")()
+	defer setenvForTest(t, "BYTEPLUS_REGION", "ap-southeast-1")()
+
+	var out bytes.Buffer
+	prevWriter := actionOutputWriter
+	actionOutputWriter = &out
+	defer func() { actionOutputWriter = prevWriter }()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "instances.json")
+	if err := os.WriteFile(path, []byte("previous"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	testCtx := NewContext()
+	parser := NewParser([]string{"---endpoint", server.URL, "---output", "table", "---paginate", "---output-file", path})
+	if _, err := parser.ReadArgs(testCtx); err != nil {
+		t.Fatalf("ReadArgs() error = %v", err)
+	}
+	if err := doAction(testCtx, "ecs", "DescribeInstances"); err == nil || !strings.Contains(err.Error(), "InvalidParameter") {
+		t.Fatalf("doAction() error = %v, want InvalidParameter", err)
+	}
+
+	if want := "InstanceId\n----------\ni-1\n"; out.String() != want {
+		t.Fatalf("table output =\n%s\nwant\n%s", out.String(), want)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "previous" {
+		t.Fatalf("output file = %q, %v, want the previous content untouched", data, err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Fatalf("dir entries = %d, want only the output file", len(entries))
+	}
+}
+
+func TestResolveActionOutputTemplate(t *testing.T) {
+	for _, args := range [][]string{
+		{"---output-template", "{{.}}", "---output", "json"},
+		{"---output-template", "{{.}}", "---count"},
+		{"---output-template", "{{.}}", "---jq", ".Result"},
+	} {
+		ctx := NewContext()
+		if _, err := NewParser(args).ReadArgs(ctx); err != nil {
+			t.Fatalf("ReadArgs(%v) error = %v", args, err)
+		}
+		if _, err := resolveActionOutput(ctx); err == nil || !strings.Contains(err.Error(), "---output-template cannot be used") {
+			t.Fatalf("resolveActionOutput(%v) error = %v, want ---output-template conflict", args, err)
+		}
+	}
+
+	ctx := NewContext()
+	if _, err := NewParser([]string{"---output-template", `{{range .Instances}}{{.InstanceId}} {{.Status | default "-"}}\n{{end}}`}).ReadArgs(ctx); err != nil {
+		t.Fatalf("ReadArgs() error = %v", err)
+	}
+	o, err := resolveActionOutput(ctx)
+	if err != nil {
+		t.Fatalf("resolveActionOutput() error = %v", err)
+	}
+	var out bytes.Buffer
+	o.out = &out
+	handlePage, finish := o.newPageHandler()
+	pages := []map[string]interface{}{
+		{"Result": map[string]interface{}{"Instances": []interface{}{map[string]interface{}{"InstanceId": "i-1", "Status": "RUNNING"}}}},
+		{"Result": map[string]interface{}{"Instances": []interface{}{map[string]interface{}{"InstanceId": "i-2"}}}},
+	}
+	for _, page := range pages {
+		if err := handlePage(page); err != nil {
+			t.Fatalf("handlePage() error = %v", err)
+		}
+	}
+	if err := finish(); err != nil {
+		t.Fatalf("finish() error = %v", err)
+	}
+	if want := "i-1 RUNNING\ni-2 -\n"; out.String() != want {
+		t.Fatalf("template output = %q, want %q", out.String(), want)
+	}
+
+	ctx = NewContext()
+	if _, err := NewParser([]string{"---output-template", "{{range .Instances}"}).ReadArgs(ctx); err != nil {
+		t.Fatalf("ReadArgs() error = %v", err)
+	}
+	if _, err := resolveActionOutput(ctx); err == nil || !strings.Contains(err.Error(), "invalid ---output-template") {
+		t.Fatalf("resolveActionOutput() error = %v, want template parse error", err)
+	}
+}
+
+func TestTimeWindowFiltersPagesBeforeOutput(t *testing.T) {
+	withFixedNow(t, time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC))
+	ctx := NewContext()
+	if _, err := NewParser([]string{"---created-after", "2d", "---count"}).ReadArgs(ctx); err != nil {
+		t.Fatalf("ReadArgs() error = %v", err)
+	}
+	o, err := resolveActionOutput(ctx)
+	if err != nil {
+		t.Fatalf("resolveActionOutput() error = %v", err)
+	}
+	var out bytes.Buffer
+	o.out = &out
+	handlePage, finish := o.newPageHandler()
+
+	pages := []map[string]interface{}{
+		{"Result": map[string]interface{}{"Instances": []interface{}{
+			map[string]interface{}{"InstanceId": "i-1", "CreatedAt": "2024-05-09T08:00:00Z"},
+			map[string]interface{}{"InstanceId": "i-2", "CreatedAt": "2024-04-01T08:00:00Z"},
+		}}},
+		{"Result": map[string]interface{}{"Instances": []interface{}{
+			map[string]interface{}{"InstanceId": "i-3", "CreatedAt": "2024-05-10T11:00:00Z"},
+		}}},
+	}
+	for _, page := range pages {
+		if err := handlePage(page); err != nil {
+			t.Fatalf("handlePage() error = %v", err)
+		}
+	}
+	if err := finish(); err != nil {
+		t.Fatalf("finish() error = %v", err)
+	}
+	if out.String() != "2\n" {
+		t.Fatalf("count output = %q, want %q", out.String(), "2\n")
+	}
+
+	handlePage, _ = o.newPageHandler()
+	err = handlePage(map[string]interface{}{"Result": map[string]interface{}{"Instances": []interface{}{
+		map[string]interface{}{"InstanceId": "i-4", "LaunchTime": "2024-05-09T08:00:00Z"},
+	}}})
+	if err == nil || !strings.Contains(err.Error(), "---time-field") {
+		t.Fatalf("handlePage() error = %v, want ---time-field hint", err)
+	}
+}
+
+func TestSortByOrdersListAcrossPages(t *testing.T) {
+	ctx := NewContext()
+	if _, err := NewParser([]string{"---output", "text", "---fields", "InstanceId", "---sort-by", "CreatedAt", "---reverse"}).ReadArgs(ctx); err != nil {
+		t.Fatalf("ReadArgs() error = %v", err)
+	}
+	o, err := resolveActionOutput(ctx)
+	if err != nil {
+		t.Fatalf("resolveActionOutput() error = %v", err)
+	}
+	var out bytes.Buffer
+	o.out = &out
+	handlePage, finish := o.newPageHandler()
+
+	pages := []map[string]interface{}{
+		{"Result": map[string]interface{}{"Instances": []interface{}{
+			map[string]interface{}{"InstanceId": "i-1", "CreatedAt": "2024-05-09T08:00:00Z"},
+			map[string]interface{}{"InstanceId": "i-2", "CreatedAt": "2024-04-01T08:00:00Z"},
+		}}},
+		{"Result": map[string]interface{}{"Instances": []interface{}{
+			map[string]interface{}{"InstanceId": "i-3", "CreatedAt": "2024-05-10T11:00:00Z"},
+		}}},
+	}
+	for _, page := range pages {
+		if err := handlePage(page); err != nil {
+			t.Fatalf("handlePage() error = %v", err)
+		}
+	}
+	if out.Len() != 0 {
+		t.Fatalf("output written before all pages were sorted: %q", out.String())
+	}
+	if err := finish(); err != nil {
+		t.Fatalf("finish() error = %v", err)
+	}
+	if want := "i-3\ni-1\ni-2\n"; out.String() != want {
+		t.Fatalf("sorted output = %q, want %q", out.String(), want)
+	}
+
+	ctx = NewContext()
+	if _, err := NewParser([]string{"---reverse"}).ReadArgs(ctx); err != nil {
+		t.Fatalf("ReadArgs() error = %v", err)
+	}
+	if _, err := resolveActionOutput(ctx); err == nil || !strings.Contains(err.Error(), "---reverse requires ---sort-by") {
+		t.Fatalf("resolveActionOutput() error = %v, want ---reverse requires ---sort-by", err)
+	}
+}
+
+func TestResolveTimeWindowRejectsInvalidFlags(t *testing.T) {
+	cases := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"---created-after", "last week"}, want: "invalid ---created-after"},
+		{args: []string{"---time-field", "LaunchTime"}, want: "requires ---created-after or ---created-before"},
+		{args: []string{"---created-after", "2024-05-02", "---created-before", "2024-05-01"}, want: "must be earlier"},
+	}
+	for _, tc := range cases {
+		ctx := NewContext()
+		if _, err := NewParser(tc.args).ReadArgs(ctx); err != nil {
+			t.Fatalf("ReadArgs(%v) error = %v", tc.args, err)
+		}
+		if _, err := resolveActionOutput(ctx); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("resolveActionOutput(%v) error = %v, want %q", tc.args, err, tc.want)
+		}
+	}
+}
+
+func TestOutputFileRendersSameResultInSecondFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "result.json")
+	ctx := NewContext()
+	if _, err := NewParser([]string{"---output", "table", "---fields", "InstanceId", "---output-file", path}).ReadArgs(ctx); err != nil {
+		t.Fatalf("ReadArgs() error = %v", err)
+	}
+	o, err := resolveActionOutput(ctx)
+	if err != nil {
+		t.Fatalf("resolveActionOutput() error = %v", err)
+	}
+	var out bytes.Buffer
+	o.out = &out
+	handlePage, finish := o.newPageHandler()
+
+	pages := []map[string]interface{}{
+		{"Result": map[string]interface{}{"Instances": []interface{}{
+			map[string]interface{}{"InstanceId": "i-1", "Status": "RUNNING"},
+		}}},
+		{"Result": map[string]interface{}{"Instances": []interface{}{
+			map[string]interface{}{"InstanceId": "i-2", "Status": "STOPPED"},
+		}}},
+	}
+	for _, page := range pages {
+		if err := handlePage(page); err != nil {
+			t.Fatalf("handlePage() error = %v", err)
+		}
+	}
+	if err := finish(); err != nil {
+		t.Fatalf("finish() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "i-2") || strings.Contains(out.String(), "STOPPED") {
+		t.Fatalf("table output = %q, want only the InstanceId column", out.String())
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	var saved map[string]interface{}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatalf("output file is not JSON: %v\n%s", err, data)
+	}
+	instances, _ := saved["Result"].(map[string]interface{})["Instances"].([]interface{})
+	if len(instances) != 2 || instances[1].(map[string]interface{})["Status"] != "STOPPED" {
+		t.Fatalf("output file Instances = %v, want both complete pages", instances)
+	}
+}
+
+func TestResolveOutputFileRejectsInvalidFlags(t *testing.T) {
+	cases := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"---output-file-format", "json"}, want: "requires ---output-file"},
+		{args: []string{"---output-file", "out.yaml", "---output-file-format", "yaml"}, want: "---output-file-format \"yaml\" is not supported"},
+	}
+	for _, tc := range cases {
+		ctx := NewContext()
+		if _, err := NewParser(tc.args).ReadArgs(ctx); err != nil {
+			t.Fatalf("ReadArgs(%v) error = %v", tc.args, err)
+		}
+		if _, err := resolveActionOutput(ctx); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("resolveActionOutput(%v) error = %v, want %q", tc.args, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/byteplus-sdk/byteplus-cli/util"
+)
+
+// defaultPager 是未设置 PAGER 时使用的分页器。
+const defaultPager = "less"
+
+// pagerWarningOut 为分页器无法启动时提示的输出目标，测试中替换。
+var pagerWarningOut io.Writer = os.Stderr
+
+// startPagerCommand 是启动分页器进程的注入点，测试中替换以避免依赖 less 与终端。
+var startPagerCommand = func(command string, stdout io.Writer) (io.WriteCloser, func() error, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	// 与 git 一样：less 在输出不足一屏时直接退出（F）、保留颜色（R）、退出后不清屏（X）
+	cmd.Env = os.Environ()
+	if _, ok := os.LookupEnv("LESS"); !ok {
+		cmd.Env = append(cmd.Env, "LESS=FRX")
+	}
+	if _, ok := os.LookupEnv("LV"); !ok {
+		cmd.Env = append(cmd.Env, "LV=-c")
+	}
+	cmd.Stdout = stdout
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, nil, err
+	}
+	return stdin, cmd.Wait, nil
+}
+
+// pagerWriter 在第一次写入时启动分页器，此后的输出都写到分页器的 stdin；
+// 分页器无法启动时直接写到 out。调用 Close 后等待分页器退出。
+type pagerWriter struct {
+	command string
+	out     io.Writer
+	started bool
+	stdin   io.WriteCloser
+	wait    func() error
+}
+
+// resolvePager 在指定 ---pager、stdout 是终端且未指定 --quiet 时返回分页输出的 writer，否则返回 nil。
+// 分页器取自 PAGER 环境变量，默认为 less；PAGER 为 cat 时等同于不分页。
+func resolvePager(ctx *Context) *pagerWriter {
+	if f := ctx.fixedFlags.GetByName("pager"); f == nil || f.GetValue() != "true" {
+		return nil
+	}
+	out, ok := actionOutputWriter.(*os.File)
+	if !ok || !util.IsTerminal(out) || quietOutput {
+		return nil
+	}
+	command := strings.TrimSpace(os.Getenv("PAGER"))
+	if command == "" {
+		command = defaultPager
+	}
+	if command == "cat" {
+		return nil
+	}
+	return &pagerWriter{command: command, out: out}
+}
+
+func (p *pagerWriter) Write(b []byte) (int, error) {
+	if !p.started {
+		p.started = true
+		stdin, wait, err := startPagerCommand(p.command, p.out)
+		if err != nil {
+			fmt.Fprintf(pagerWarningOut, "Warning: cannot start pager %q: %v; printing the output directly\n", p.command, err)
+		} else {
+			p.stdin, p.wait = stdin, wait
+		}
+	}
+	if p.stdin == nil {
+		return p.out.Write(b)
+	}
+	// 用户在输出结束前退出分页器时写入失败，其余输出直接丢弃
+	_, _ = p.stdin.Write(b)
+	return len(b), nil
+}
+
+// Close 关闭分页器的 stdin 并等待用户退出分页器；未启动分页器时什么也不做，可以重复调用。
+func (p *pagerWriter) Close() error {
+	if p == nil || p.stdin == nil {
+		return nil
+	}
+	stdin, wait := p.stdin, p.wait
+	p.stdin, p.wait = nil, nil
+	// 此后的写入直接输出，避免在分页器退出后丢失内容
+	p.started = true
+	_ = stdin.Close()
+	_ = wait()
+	return nil
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+type fakePagerInput struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (f *fakePagerInput) Close() error {
+	f.closed = true
+	return nil
+}
+
+func TestPagerWriterPagesFinishedOutput(t *testing.T) {
+	var stdin fakePagerInput
+	var started, waited int
+	prevStart := startPagerCommand
+	startPagerCommand = func(command string, stdout io.Writer) (io.WriteCloser, func() error, error) {
+		started++
+		if command != "less" {
+			t.Fatalf("pager command = %q, want less", command)
+		}
+		return &stdin, func() error { waited++; return nil }, nil
+	}
+	defer func() { startPagerCommand = prevStart }()
+
+	var terminal bytes.Buffer
+	pager := &pagerWriter{command: "less", out: &terminal}
+	o := &actionOutput{format: outputFormatJSON, out: pager, pager: pager}
+	handlePage, finish := o.newPageHandler()
+	if err := handlePage(map[string]interface{}{"Result": map[string]interface{}{"Items": []interface{}{"a"}}}); err != nil {
+		t.Fatalf("handlePage() error = %v", err)
+	}
+	if started != 0 {
+		t.Fatal("pager started before any output was written")
+	}
+	if err := finish(); err != nil {
+		t.Fatalf("finish() error = %v", err)
+	}
+	if started != 1 || waited != 1 || !stdin.closed {
+		t.Fatalf("started = %d, waited = %d, closed = %v; want the pager started once and waited for", started, waited, stdin.closed)
+	}
+	if !strings.Contains(stdin.String(), `"Items"`) || terminal.Len() != 0 {
+		t.Fatalf("pager input = %q, terminal = %q", stdin.String(), terminal.String())
+	}
+	o.closePager()
+	if waited != 1 {
+		t.Fatal("closePager waited for the pager twice")
+	}
+}
+
+func TestPagerWriterFallsBackWhenPagerCannotStart(t *testing.T) {
+	prevStart, prevWarn := startPagerCommand, pagerWarningOut
+	startPagerCommand = func(string, io.Writer) (io.WriteCloser, func() error, error) {
+		return nil, nil, errors.New("not found")
+	}
+	var warning bytes.Buffer
+	pagerWarningOut = &warning
+	defer func() { startPagerCommand, pagerWarningOut = prevStart, prevWarn }()
+
+	var terminal bytes.Buffer
+	pager := &pagerWriter{command: "missing-pager", out: &terminal}
+	_, _ = io.WriteString(pager, "line 1\n")
+	_, _ = io.WriteString(pager, "line 2\n")
+	if err := pager.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if terminal.String() != "line 1\nline 2\n" {
+		t.Fatalf("terminal = %q, want the output written directly", terminal.String())
+	}
+	if strings.Count(warning.String(), "cannot start pager") != 1 {
+		t.Fatalf("warning = %q, want one warning", warning.String())
+	}
+}
+
+func TestResolvePagerRequiresTerminal(t *testing.T) {
+	prevWriter := actionOutputWriter
+	actionOutputWriter = &bytes.Buffer{}
+	defer func() { actionOutputWriter = prevWriter }()
+
+	c := NewContext()
+	if _, err := NewParser([]string{"---pager"}).ReadArgs(c); err != nil {
+		t.Fatalf("ReadArgs() error = %v", err)
+	}
+	if p := resolvePager(c); p != nil {
+		t.Fatal("resolvePager() enabled paging for output that is not a terminal")
+	}
+}
@@ -24,12 +24,68 @@ import (
 )
 
 var allowedFixedFlags = map[string]struct{}{
-	"profile":  {},
-	"region":   {},
-	"endpoint": {},
+	"profile":                {},
+	"region":                 {},
+	"endpoint":               {},
+	"output":                 {},
+	"paginate":               {},
+	"protocol":               {},
+	"fields":                 {},
+	"count":                  {},
+	"jq":                     {},
+	"output-template":        {},
+	"output-file":            {},
+	"output-file-format":     {},
+	"created-after":          {},
+	"created-before":         {},
+	"time-field":             {},
+	"sort-by":                {},
+	"reverse":                {},
+	"all-regions":            {},
+	"fail-on-partial":        {},
+	"verbose":                {},
+	"no-config":              {},
+	"insecure-skip-verify":   {},
+	"config-readonly":        {},
+	"validate-response":      {},
+	"error-format":           {},
+	"credential-source":      {},
+	"output-raw":             {},
+	"connect-timeout":        {},
+	"tls-handshake-timeout":  {},
+	"header":                 {},
+	"post-result":            {},
+	"post-result-header":     {},
+	"proxy":                  {},
+	"dump-canonical-request": {},
+	"pager":                  {},
+	"compact-errors":         {},
 }
 
-const supportedFixedFlagsMessage = "---profile, ---region, ---endpoint"
+// booleanFixedFlags 不需要取值，出现即视为 true。
+var booleanFixedFlags = map[string]struct{}{
+	"paginate":               {},
+	"count":                  {},
+	"reverse":                {},
+	"all-regions":            {},
+	"fail-on-partial":        {},
+	"verbose":                {},
+	"no-config":              {},
+	"insecure-skip-verify":   {},
+	"config-readonly":        {},
+	"validate-response":      {},
+	"dump-canonical-request": {},
+	"pager":                  {},
+	"compact-errors":         {},
+}
+
+// repeatableFixedFlags 可以在一条命令中出现多次，取值通过 Flag.GetValues 读取。
+var repeatableFixedFlags = map[string]struct{}{
+	"header":             {},
+	"post-result-header": {},
+}
+
+const supportedFixedFlagsMessage = "---profile, ---region, ---endpoint, ---output, ---paginate, ---protocol, ---fields, ---count, ---jq, ---output-template, ---output-file, ---output-file-format, ---created-after, ---created-before, ---time-field, ---sort-by, ---reverse, ---all-regions, ---fail-on-partial, ---verbose, ---no-config, ---insecure-skip-verify, ---config-readonly, ---validate-response, ---error-format, ---credential-source, ---output-raw, ---connect-timeout, ---tls-handshake-timeout, ---header, ---post-result, ---post-result-header, ---proxy, ---dump-canonical-request, ---pager, ---compact-errors"
 
 type Parser struct {
 	currentIndex int
@@ -129,7 +185,16 @@ func (p *Parser) parseArg(arg string, ctx *Context) (flag *Flag, value string, e
 			err = fmt.Errorf("---%s is not supported, supported fixed flags: %s", name, supportedFixedFlagsMessage)
 			return
 		}
+		if _, ok := repeatableFixedFlags[name]; ok && ctx.fixedFlags.GetByName(name) != nil {
+			// 可重复的 flag 再次出现时追加取值，而不是报重复
+			flag = ctx.fixedFlags.GetByName(name)
+			return
+		}
 		flag, err = ctx.fixedFlags.AddByName(name)
+		if _, ok := booleanFixedFlags[name]; ok && err == nil {
+			flag.SetValue("true")
+			flag = nil
+		}
 	} else if strings.HasPrefix(arg, "--") {
 		if len(arg) == 2 {
 			err = fmt.Errorf("-- is not support command")
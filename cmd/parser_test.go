@@ -71,3 +71,21 @@ func TestParserRequiresFixedFlagValue(t *testing.T) {
 		t.Fatalf("ReadArgs() error = %q, want missing value message", err)
 	}
 }
+
+func TestParserReadsBooleanFixedFlagWithoutValue(t *testing.T) {
+	ctx := NewContext()
+	parser := NewParser([]string{"---paginate", "--MaxResults", "10", "---output", "table"})
+
+	if _, err := parser.ReadArgs(ctx); err != nil {
+		t.Fatalf("ReadArgs() error = %v", err)
+	}
+	if got := ctx.fixedFlags.GetByName("paginate").GetValue(); got != "true" {
+		t.Fatalf("paginate fixed flag = %q, want true", got)
+	}
+	if got := ctx.fixedFlags.GetByName("output").GetValue(); got != "table" {
+		t.Fatalf("output fixed flag = %q, want table", got)
+	}
+	if got := ctx.dynamicFlags.GetByName("MaxResults").GetValue(); got != "10" {
+		t.Fatalf("dynamic flag MaxResults = %q, want 10", got)
+	}
+}
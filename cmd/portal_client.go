@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -9,13 +11,18 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/byteplus-sdk/byteplus-cli/util"
 )
 
 const (
-	defaultPortalRegion       = "ap-southeast-1"
-	defaultPortalTimeout      = 30 * time.Second
-	defaultPortalPageSize     = 50
+	defaultPortalRegion   = "ap-southeast-1"
+	defaultPortalTimeout  = 30 * time.Second
+	defaultPortalPageSize = 50
+	// portalPageConcurrency 限制并发拉取分页的请求数，避免大租户一次性打满 Portal 限流。
+	portalPageConcurrency     = 4
 	portalBaseURLTemplate     = "https://cloudidentity-portal.%s.bytepluses.com"
 	portalListAccountsPath    = "/assignment/accounts"
 	portalListAccountRoles    = "/assignment/roles"
@@ -31,6 +38,8 @@ type PortalClientConfig struct {
 	BaseURL         string
 	HTTPClient      *http.Client
 	DefaultPageSize int
+	// AllowInsecure 允许 BaseURL 使用不加密的 http，仅用于对接本地 mock 服务。
+	AllowInsecure bool
 }
 
 // PortalClient 封装 CloudIdentity Portal API 调用，集中管理 URL、HTTP 客户端和默认分页参数。
@@ -41,6 +50,8 @@ type PortalClient struct {
 	roleCredentialsURL string
 	httpClient         *http.Client
 	defaultPageSize    int
+	// configErr 为 BaseURL 或建立连接超时校验失败的原因，非空时所有请求直接返回该错误。
+	configErr error
 }
 
 // PortalClientAPI 定义 Portal 客户端对外暴露的方法集合，便于测试或替换实现。
@@ -61,9 +72,52 @@ type ResponseMetadata struct {
 // PortalAPIError 用于承载 Portal API 非 2xx 响应时的结构化错误信息。
 type PortalAPIError struct {
 	StatusCode int
-	RequestID  string
-	Message    string
-	RawBody    string
+	// Code 为 ResponseMetadata.Error.Code，响应未携带时为空。
+	Code      string
+	RequestID string
+	Message   string
+	RawBody   string
+}
+
+// portalAccessTokenErrorCodes 是 Portal 判定 access token 无效或已过期时返回的错误码。
+var portalAccessTokenErrorCodes = map[string]struct{}{
+	"InvalidAccessToken":    {},
+	"AccessTokenExpired":    {},
+	"UnauthorizedException": {},
+	"Unauthorized":          {},
+}
+
+// IsAccessTokenRejected 判断 Portal 是否因 access token 无效或过期拒绝请求：HTTP 401，或错误码表明 token 失效。
+func (e *PortalAPIError) IsAccessTokenRejected() bool {
+	if e == nil {
+		return false
+	}
+	if e.StatusCode == http.StatusUnauthorized {
+		return true
+	}
+	_, ok := portalAccessTokenErrorCodes[e.Code]
+	return ok
+}
+
+// portalRoleNotAssignedErrorCodes 是 Portal 因角色未分配给当前用户（或已被收回）拒绝 GetRoleCredentials 时返回的错误码。
+var portalRoleNotAssignedErrorCodes = map[string]struct{}{
+	"AccessDenied":          {},
+	"AccessDeniedException": {},
+	"ForbiddenException":    {},
+	"RoleNotAssigned":       {},
+}
+
+// IsRoleNotAssigned 判断 Portal 是否因角色未分配给当前用户拒绝请求：HTTP 403，或错误码表明无权使用该角色。
+// access token 失效的错误不算在内，它应通过刷新 token 解决。
+func (e *PortalAPIError) IsRoleNotAssigned() bool {
+	if e == nil || e.IsAccessTokenRejected() {
+		return false
+	}
+	if e.StatusCode == http.StatusForbidden {
+		return true
+	}
+	_, ok := portalRoleNotAssignedErrorCodes[e.Code]
+	return ok
 }
 
 func (e *PortalAPIError) Error() string {
@@ -162,14 +216,22 @@ func NewPortalClient(cfg *PortalClientConfig) *PortalClient {
 	}
 
 	base := fmt.Sprintf(portalBaseURLTemplate, region)
+	var configErr error
 	if cfg != nil && strings.TrimSpace(cfg.BaseURL) != "" {
-		base = strings.TrimRight(cfg.BaseURL, "/")
+		base = strings.TrimRight(strings.TrimSpace(cfg.BaseURL), "/")
+		configErr = validateIdentityBaseURL(base, cfg.AllowInsecure)
 	}
 	base = strings.TrimRight(base, "/")
 
-	client := &http.Client{Timeout: defaultPortalTimeout}
+	var client *http.Client
 	if cfg != nil && cfg.HTTPClient != nil {
 		client = cfg.HTTPClient
+	} else {
+		var err error
+		client, err = identityHTTPClient(defaultPortalTimeout)
+		if configErr == nil {
+			configErr = err
+		}
 	}
 
 	pageSize := defaultPortalPageSize
@@ -184,6 +246,7 @@ func NewPortalClient(cfg *PortalClientConfig) *PortalClient {
 		roleCredentialsURL: base + portalGetRoleCredentials,
 		httpClient:         client,
 		defaultPageSize:    pageSize,
+		configErr:          configErr,
 	}
 }
 
@@ -222,13 +285,18 @@ func (c *PortalClient) ListAccounts(ctx context.Context, req *ListAccountsReques
 	}
 
 	var result listAccountsResult
-	if len(env.Result) == 0 {
+	if env.emptyResult() {
 		return nil, fmt.Errorf("ListAccounts succeeded but response was empty")
 	}
 	if err := json.Unmarshal(env.Result, &result); err != nil {
 		return nil, fmt.Errorf("failed to decode ListAccounts result: %w", err)
 	}
 
+	// Result 存在但没有账号（Total 为 0）是正常的空列表，与缺少 Result 的空响应区分开。
+	if result.AccountList == nil {
+		result.AccountList = []AccountInfo{}
+	}
+
 	nextToken := computeNextToken(result.Total, result.PageNumber, result.PageSize)
 	return &ListAccountsResponse{
 		Total:       result.Total,
@@ -279,7 +347,7 @@ func (c *PortalClient) ListAccountRoles(ctx context.Context, req *ListAccountRol
 	}
 
 	var result listAccountRolesResult
-	if len(env.Result) == 0 {
+	if env.emptyResult() {
 		return nil, fmt.Errorf("ListAccountRoles succeeded but response was empty")
 	}
 	if err := json.Unmarshal(env.Result, &result); err != nil {
@@ -330,7 +398,7 @@ func (c *PortalClient) GetRoleCredentials(ctx context.Context, req *GetRoleCrede
 	}
 
 	var result getRoleCredentialsAPIResult
-	if len(env.Result) == 0 {
+	if env.emptyResult() {
 		return nil, fmt.Errorf("GetRoleCredentials succeeded but response was empty")
 	}
 	if err := json.Unmarshal(env.Result, &result); err != nil {
@@ -345,6 +413,9 @@ func (c *PortalClient) GetRoleCredentials(ctx context.Context, req *GetRoleCrede
 
 // doPortalGet 封装 Portal GET 请求：构造请求头、发起请求并处理非 2xx 错误。
 func (c *PortalClient) doPortalGet(ctx context.Context, token string, fullURL string) ([]byte, error) {
+	if c.configErr != nil {
+		return nil, c.configErr
+	}
 	var result []byte
 	err := doWithRetry(ctx, retryOptions{maxAttempts: 3}, func() error {
 		body, err := c.doPortalGetOnce(ctx, token, fullURL)
@@ -417,11 +488,44 @@ func resolvePageNumber(pageNumber int, nextToken string) (int, error) {
 	if strings.TrimSpace(nextToken) == "" {
 		return 1, nil
 	}
-	page, err := strconv.Atoi(strings.TrimSpace(nextToken))
-	if err != nil || page < 1 {
-		return 0, fmt.Errorf("invalid NextToken %q: expect a positive integer page number", nextToken)
+	token, err := decodeNextToken(nextToken)
+	if err != nil {
+		return 0, err
+	}
+	return token.Page, nil
+}
+
+// portalPageToken 是 NextToken 编码前的内容。Page 为下一页页号；Token 预留给服务端返回的不透明续页 token，
+// 服务端改用续页 token 后可原样携带，调用方无需感知 NextToken 的格式变化。
+type portalPageToken struct {
+	Page  int    `json:"p,omitempty"`
+	Token string `json:"t,omitempty"`
+}
+
+// encodeNextToken 把下一页页号编码为不透明的 NextToken（JSON 后做 URL 安全的 base64）。
+func encodeNextToken(page int) string {
+	data, _ := json.Marshal(portalPageToken{Page: page})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeNextToken 解析 encodeNextToken 生成的 NextToken；旧版本返回的纯数字页号仍然接受。
+func decodeNextToken(nextToken string) (portalPageToken, error) {
+	raw := strings.TrimSpace(nextToken)
+	if page, err := strconv.Atoi(raw); err == nil {
+		if page < 1 {
+			return portalPageToken{}, fmt.Errorf("invalid NextToken %q: page number must be positive", nextToken)
+		}
+		return portalPageToken{Page: page}, nil
+	}
+	var token portalPageToken
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err == nil {
+		err = json.Unmarshal(data, &token)
 	}
-	return page, nil
+	if err != nil || token.Page < 1 {
+		return portalPageToken{}, fmt.Errorf("invalid NextToken %q: use the NextToken returned by the previous page", nextToken)
+	}
+	return token, nil
 }
 
 // computeNextToken 根据总数、页号、页大小计算下一页的 token（空字符串表示无下一页）。
@@ -430,11 +534,55 @@ func computeNextToken(total, pageNumber, pageSize int) string {
 		return ""
 	}
 	if total > pageNumber*pageSize {
-		return strconv.Itoa(pageNumber + 1)
+		return encodeNextToken(pageNumber + 1)
 	}
 	return ""
 }
 
+// remainingPortalPages 根据首页返回的 Total/PageSize 推算其后还需拉取的页号。
+// 响应缺少 Total 或分页信息时返回 ok=false，调用方应退回按 NextToken 顺序翻页。
+func remainingPortalPages(total, pageNumber, pageSize int) ([]int, bool) {
+	if total <= 0 || pageNumber <= 0 || pageSize <= 0 {
+		return nil, false
+	}
+	lastPage := (total + pageSize - 1) / pageSize
+	var pages []int
+	for page := pageNumber + 1; page <= lastPage; page++ {
+		pages = append(pages, page)
+	}
+	return pages, true
+}
+
+// fetchPortalPagesConcurrently 以最多 portalPageConcurrency 个 worker 执行 fetch(ctx, i)，i 为 [0, n) 的页下标。
+// 任一页失败后取消其余请求并返回首个错误；结果由 fetch 按下标写入，调用方据此保持页序。
+func fetchPortalPagesConcurrently(ctx context.Context, n int, fetch func(ctx context.Context, i int) error) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		errOnce  sync.Once
+		firstErr error
+	)
+	util.RunBounded(n, util.BoundedOptions{Concurrency: portalPageConcurrency}, func(i int) (interface{}, error) {
+		// 已有页失败时，尚未开始的页直接跳过
+		if ctx.Err() != nil {
+			return nil, nil
+		}
+		err := fetch(ctx, i)
+		if err != nil {
+			errOnce.Do(func() {
+				firstErr = err
+				cancel()
+			})
+		}
+		return nil, err
+	})
+	return firstErr
+}
+
 // listAccountsResult 为 ListAccounts 的内部解包结构。
 type listAccountsResult struct {
 	Total       int           `json:"Total"`
@@ -482,6 +630,12 @@ type portalEnvelope struct {
 	Result           json.RawMessage        `json:"Result"`
 }
 
+// emptyResult 判断响应是否缺少 Result：字段不存在或为 null 都视为空响应。
+func (e *portalEnvelope) emptyResult() bool {
+	result := bytes.TrimSpace(e.Result)
+	return len(result) == 0 || bytes.Equal(result, []byte("null"))
+}
+
 // decodePortalEnvelope 解包响应体并做基础错误检查。
 func decodePortalEnvelope(body []byte, action string) (*portalEnvelope, error) {
 	if len(body) == 0 {
@@ -511,6 +665,7 @@ func portalErrorFromMetadata(statusCode int, meta portalResponseMetadata, body [
 	}
 	return &PortalAPIError{
 		StatusCode: statusCode,
+		Code:       code,
 		RequestID:  meta.RequestID,
 		Message:    msg,
 		RawBody:    string(body),
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/byteplus-sdk/byteplus-cli/util"
+)
+
+// defaultPostResultTimeout 限制一次投递请求（含读取响应）的总时长，重试时每次单独计时。
+const defaultPostResultTimeout = 30 * time.Second
+
+// postResultAttempts 是投递失败（网络错误、408、429 或 5xx）时的最多尝试次数。
+const postResultAttempts = 3
+
+// postResultStatusOut 为投递结果提示的输出目标，测试中替换。
+var postResultStatusOut io.Writer = os.Stderr
+
+// resultSink 把命令结果以 JSON POST 到 ---post-result 指定的地址。与 ---output-file 一样收集每页响应，
+// 但只在命令成功后投递，失败或部分失败的调用不会把不完整的结果发送出去。
+type resultSink struct {
+	url       *url.URL
+	headers   http.Header
+	transport transportSettings
+	output    *actionOutput
+	buf       bytes.Buffer
+	// render 由 tee 设置，把收集到的各页写入 buf。
+	render func() error
+}
+
+// postResultError 是投递地址返回的非 2xx 状态。
+type postResultError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *postResultError) Error() string {
+	return "the server responded " + e.Status
+}
+
+// resolveResultSink 解析 ---post-result 与 ---post-result-header。投递内容是完整的 JSON 响应；
+// 指定 ---jq 时改为表达式的结果，每个结果一行。---fields、---count 与 ---output-template 只作用于终端输出。
+func resolveResultSink(ctx *Context, jq *util.JQ) (*resultSink, error) {
+	headerFlag := ctx.fixedFlags.GetByName("post-result-header")
+	f := ctx.fixedFlags.GetByName("post-result")
+	if f == nil {
+		if headerFlag != nil {
+			return nil, fmt.Errorf("---post-result-header requires ---post-result")
+		}
+		return nil, nil
+	}
+	u, err := url.Parse(strings.TrimSpace(f.GetValue()))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("---post-result %q is invalid: expected an http or https URL", f.GetValue())
+	}
+	sink := &resultSink{url: u, headers: http.Header{}}
+	if sink.transport.ConnectTimeout, sink.transport.TLSHandshakeTimeout, err = resolveConnectTimeouts(ctx); err != nil {
+		return nil, err
+	}
+	sink.headers.Set("Content-Type", "application/json")
+	if headerFlag != nil {
+		// 第一次出现的 header 替换默认值（如 Content-Type），同名 header 再次出现时追加取值
+		seen := map[string]bool{}
+		for _, raw := range headerFlag.GetValues() {
+			name, value, err := parseHeaderLine(raw)
+			if err == nil && (name == "Host" || name == "Content-Length") {
+				err = fmt.Errorf("%s is set by the CLI and cannot be overridden", name)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("---post-result-header %q is invalid: %v", raw, err)
+			}
+			if seen[name] {
+				sink.headers.Add(name, value)
+			} else {
+				sink.headers.Set(name, value)
+				seen[name] = true
+			}
+		}
+	}
+	sink.output = &actionOutput{format: outputFormatJSONCompact, jq: jq, out: &sink.buf, toFile: true}
+	return sink, nil
+}
+
+// tee 让每页响应的副本同时交给 sink 的 JSON handler；结果在 deliver 中渲染并投递。
+func (s *resultSink) tee(handlePage pageHandler) pageHandler {
+	sinkHandlePage, sinkFinish := s.output.newFormatHandler()
+	s.render = sinkFinish
+	return func(page map[string]interface{}) error {
+		copied, _ := copyJSONValue(page).(map[string]interface{})
+		if err := sinkHandlePage(copied); err != nil {
+			return err
+		}
+		return handlePage(page)
+	}
+}
+
+// deliver 渲染收集到的结果并 POST 到投递地址，网络错误、408、429 与 5xx 按退避重试，结果写到 stderr。
+func (s *resultSink) deliver() error {
+	if s.render == nil {
+		return nil
+	}
+	if err := s.render(); err != nil {
+		return err
+	}
+	// 不使用 identityHTTPClient：---header 是发给 API 的 header，不应发送到投递地址
+	client := &http.Client{Timeout: defaultPostResultTimeout, Transport: newHTTPTransport(s.transport)}
+	body := s.buf.Bytes()
+	target := s.url.Redacted()
+	var status string
+	err := doWithRetry(context.Background(), retryOptions{maxAttempts: postResultAttempts}, func() error {
+		req, err := http.NewRequest(http.MethodPost, s.url.String(), bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header = s.headers.Clone()
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 64*1024))
+		if resp.StatusCode/100 != 2 {
+			return &postResultError{StatusCode: resp.StatusCode, Status: resp.Status}
+		}
+		status = resp.Status
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to post the result to %s: %w", target, err)
+	}
+	fmt.Fprintf(statusWriter(postResultStatusOut), "Posted the result (%d bytes) to %s: %s\n", len(body), target, status)
+	return nil
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestDoActionPostsFilteredResult(t *testing.T) {
+	defer disableProxyEnvForTest(t)()
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ResponseMetadata":{"RequestId":"req"},"Result":{"Instances":[{"InstanceId":"i-1"},{"InstanceId":"i-2"}]}}`))
+	}))
+	defer api.Close()
+
+	var attempts int32
+	var body, auth, contentType string
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		body, auth, contentType = string(b), r.Header.Get("Authorization"), r.Header.Get("Content-Type")
+	}))
+	defer sink.Close()
+
+	defer setenvForTest(t, "BYTEPLUS_ACCESS_KEY", "ak-test")()
+	defer setenvForTest(t, "BYTEPLUS_SECRET_KEY", "sk-test")()
+	defer setenvForTest(t, "BYTEPLUS_REGION", "ap-southeast-1")()
+	defer setenvForTest(t, "BYTEPLUS_ENDPOINT", api.URL)()
+
+	var out, status bytes.Buffer
+	prevWriter, prevStatus := actionOutputWriter, postResultStatusOut
+	actionOutputWriter, postResultStatusOut = &out, &status
+	defer func() { actionOutputWriter, postResultStatusOut = prevWriter, prevStatus }()
+
+	testCtx := NewContext()
+	testCtx.SetConfig(&Configure{Profiles: map[string]*Profile{}})
+	parser := NewParser([]string{"---jq", ".Result.Instances[].InstanceId",
+		"---post-result", sink.URL, "---post-result-header", "Authorization: Bearer t"})
+	if _, err := parser.ReadArgs(testCtx); err != nil {
+		t.Fatalf("ReadArgs() error = %v", err)
+	}
+	if err := doAction(testCtx, "ecs", "DescribeInstances"); err != nil {
+		t.Fatalf("doAction() error = %v", err)
+	}
+
+	if attempts != 2 {
+		t.Fatalf("sink attempts = %d, want 2 (one retry after 503)", attempts)
+	}
+	if body != "\"i-1\"\n\"i-2\"\n" {
+		t.Fatalf("posted body = %q", body)
+	}
+	if auth != "Bearer t" || contentType != "application/json" {
+		t.Fatalf("posted headers Authorization=%q Content-Type=%q", auth, contentType)
+	}
+	if !strings.Contains(status.String(), "Posted the result") {
+		t.Fatalf("status output = %q", status.String())
+	}
+}
+
+func TestResolveResultSinkValidatesFlags(t *testing.T) {
+	withTestCtxConfig(t, &Configure{})
+	for _, tc := range []struct {
+		args    []string
+		wantErr string
+	}{
+		{args: []string{"---post-result-header", "X-A: 1"}, wantErr: "requires ---post-result"},
+		{args: []string{"---post-result", "ftp://example.com/x"}, wantErr: "expected an http or https URL"},
+		{args: []string{"---post-result", "https://example.com", "---post-result-header", "Host: other"}, wantErr: "cannot be overridden"},
+		{args: []string{"---post-result", "https://example.com", "---post-result-header", "bad"}, wantErr: "expected 'Key: Value'"},
+	} {
+		c := NewContext()
+		if _, err := NewParser(tc.args).ReadArgs(c); err != nil {
+			t.Fatalf("ReadArgs(%v) error = %v", tc.args, err)
+		}
+		if _, err := resolveResultSink(c, nil); err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Fatalf("resolveResultSink(%v) error = %v, want %q", tc.args, err, tc.wantErr)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+/*
+ * // Copyright (c) 2024 Bytedance Ltd. and/or its affiliates
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at
+ * //
+ * //	http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// profileExtraExcluded 中的固定 flag 决定使用哪个 profile 或是否读取配置，不能由 profile 自身提供默认值。
+var profileExtraExcluded = map[string]struct{}{
+	"profile":           {},
+	"no-config":         {},
+	"credential-source": {},
+}
+
+// normalizeProfileExtraKey 去掉键名前的短横线，"---output"、"output" 视为同一个固定 flag。
+func normalizeProfileExtraKey(key string) string {
+	return strings.TrimLeft(strings.TrimSpace(key), "-")
+}
+
+// validateProfileExtraEntry 校验一条 profile extra：键必须是支持的固定 flag，布尔 flag 的值必须是布尔值。
+func validateProfileExtraEntry(key, value string) error {
+	name := normalizeProfileExtraKey(key)
+	if _, ok := allowedFixedFlags[name]; !ok {
+		return fmt.Errorf("extra %q is not a supported fixed flag, supported fixed flags: %s", key, supportedFixedFlagsMessage)
+	}
+	if _, ok := profileExtraExcluded[name]; ok {
+		return fmt.Errorf("extra %q cannot be set on a profile", key)
+	}
+	if _, ok := booleanFixedFlags[name]; ok {
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("extra %q requires true or false, got %q", key, value)
+		}
+	}
+	return nil
+}
+
+// mergeProfileExtra 把 input 中的键合并到 base，值为空表示删除该键；返回新的 map，不修改 base。
+func mergeProfileExtra(base, input map[string]string) map[string]string {
+	merged := make(map[string]string, len(base)+len(input))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range input {
+		name := normalizeProfileExtraKey(k)
+		if strings.TrimSpace(v) == "" {
+			delete(merged, name)
+			continue
+		}
+		merged[name] = v
+	}
+	if len(merged) == 0 {
+		return nil
+	}
+	return merged
+}
+
+// applyProfileExtra 把当前 profile 的 extra 作为固定 flag 的默认值写入 ctx，
+// 命令行显式传入的固定 flag 优先；忽略配置文件或找不到 profile 时不做任何处理。
+func applyProfileExtra(ctx *Context) error {
+	if ctx == nil || ctx.fixedFlags == nil || ctx.config == nil || ignoreConfigRequested(ctx) {
+		return nil
+	}
+	profileName, _ := defaultProfileNameWithSource(ctx.config)
+	if f := ctx.fixedFlags.GetByName("profile"); f != nil && f.GetValue() != "" {
+		profileName = f.GetValue()
+	}
+	profile := ctx.config.Profiles[profileName]
+	if profile == nil || len(profile.Extra) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(profile.Extra))
+	for k := range profile.Extra {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := profile.Extra[key]
+		if err := validateProfileExtraEntry(key, value); err != nil {
+			return fmt.Errorf("profile %q: %w", profileName, err)
+		}
+		name := normalizeProfileExtraKey(key)
+		if ctx.fixedFlags.GetByName(name) != nil {
+			continue
+		}
+		if _, ok := booleanFixedFlags[name]; ok {
+			// 布尔 flag 出现即为 true，因此 false 等同于不设置
+			if enabled, _ := strconv.ParseBool(value); !enabled {
+				continue
+			}
+			value = "true"
+		}
+		flag, err := ctx.fixedFlags.AddByName(name)
+		if err != nil {
+			return err
+		}
+		flag.SetValue(value)
+	}
+	return nil
+}
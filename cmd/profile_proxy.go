@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// parseProxyURL 校验 profile proxy 或 ---proxy 的取值：必须是带 host 的 http、https 或 socks5 URL。
+func parseProxyURL(value string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(value))
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("proxy %q is invalid: expected a URL such as http://proxy.example.com:8080", value)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+		return u, nil
+	}
+	return nil, fmt.Errorf("proxy %q is invalid: scheme must be http, https or socks5", value)
+}
+
+// noProxyList 返回绕过代理的列表：profile 的 no-proxy，未设置时为 NO_PROXY 或 no_proxy 环境变量。
+func noProxyList(profileNoProxy string) string {
+	if strings.TrimSpace(profileNoProxy) != "" {
+		return profileNoProxy
+	}
+	if v := os.Getenv("NO_PROXY"); v != "" {
+		return v
+	}
+	return os.Getenv("no_proxy")
+}
+
+// profileProxyFunc 返回 http.Transport.Proxy：请求的 host 命中 noProxy 时直连，否则一律使用 proxy，
+// 不再读取 HTTP_PROXY、HTTPS_PROXY 环境变量。
+func profileProxyFunc(proxy, noProxy string) (func(*http.Request) (*url.URL, error), error) {
+	u, err := parseProxyURL(proxy)
+	if err != nil {
+		return nil, err
+	}
+	return func(req *http.Request) (*url.URL, error) {
+		if bypassProxy(req.URL, noProxy) {
+			return nil, nil
+		}
+		return u, nil
+	}, nil
+}
+
+// bypassProxy 按 NO_PROXY 的惯例判断 target 是否直连。noProxy 以逗号或空白分隔，每项可以是：
+// "*"（全部直连）、域名（同时匹配其子域名，前导 "." 可有可无）、IP 或 CIDR，可带 ":端口" 只匹配该端口。
+func bypassProxy(target *url.URL, noProxy string) bool {
+	host := strings.ToLower(target.Hostname())
+	port := target.Port()
+	if port == "" {
+		port = "80"
+		if target.Scheme == "https" {
+			port = "443"
+		}
+	}
+	ip := net.ParseIP(host)
+	for _, entry := range strings.FieldsFunc(strings.ToLower(noProxy), func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+		if entry == "*" {
+			return true
+		}
+		if _, cidr, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && cidr.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		entryHost, entryPort := entry, ""
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			entryHost, entryPort = h, p
+		}
+		if entryPort != "" && entryPort != port {
+			continue
+		}
+		if entryIP := net.ParseIP(entryHost); entryIP != nil {
+			if ip != nil && entryIP.Equal(ip) {
+				return true
+			}
+			continue
+		}
+		entryHost = strings.TrimPrefix(entryHost, "*")
+		entryHost = strings.TrimPrefix(entryHost, ".")
+		if entryHost != "" && (host == entryHost || strings.HasSuffix(host, "."+entryHost)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestBypassProxy(t *testing.T) {
+	noProxy := ".internal.example.com, example.org:8443,10.0.0.0/8 192.168.1.5"
+	for target, want := range map[string]bool{
+		"https://ecs.internal.example.com/":  true,
+		"https://internal.example.com/":      true,
+		"https://notinternal.example.com/":   false,
+		"https://example.org:8443/":          true,
+		"https://example.org/":               false,
+		"http://10.1.2.3/":                   true,
+		"http://192.168.1.5:8080/":           true,
+		"https://open.byteplusapi.com/":      false,
+		"https://sub.example.org:8443/x?y=1": true,
+	} {
+		u, _ := url.Parse(target)
+		if got := bypassProxy(u, noProxy); got != want {
+			t.Errorf("bypassProxy(%s) = %v, want %v", target, got, want)
+		}
+	}
+	u, _ := url.Parse("https://anything.example.net/")
+	if !bypassProxy(u, "*") {
+		t.Error("bypassProxy with * = false, want true")
+	}
+}
+
+func TestNewSimpleClientUsesProfileProxy(t *testing.T) {
+	withTestCtxConfig(t, &Configure{Current: "prod", Profiles: map[string]*Profile{
+		"prod": {Name: "prod", Mode: ModeAK, AccessKey: "ak", SecretKey: "sk", Region: "ap-southeast-1",
+			HTTPSProxy: "http://ignored.example.com:1", Proxy: "http://proxy.example.com:3128", NoProxy: ".internal.example.com"},
+	}})
+	t.Setenv("HTTPS_PROXY", "http://env.example.com:8080")
+
+	proxyFor := func(target string) string {
+		t.Helper()
+		sdk, err := NewSimpleClient(ctx)
+		if err != nil {
+			t.Fatalf("NewSimpleClient() error = %v", err)
+		}
+		req, _ := http.NewRequest(http.MethodGet, target, nil)
+		u, err := sdk.Config.HTTPClient.Transport.(*http.Transport).Proxy(req)
+		if err != nil {
+			t.Fatalf("Proxy() error = %v", err)
+		}
+		if u == nil {
+			return ""
+		}
+		return u.String()
+	}
+
+	if got := proxyFor("https://ecs.ap-southeast-1.byteplusapi.com/"); got != "http://proxy.example.com:3128" {
+		t.Fatalf("proxy = %q, want the profile proxy", got)
+	}
+	if got := proxyFor("https://api.internal.example.com/"); got != "" {
+		t.Fatalf("proxy for a no-proxy host = %q, want direct", got)
+	}
+
+	f, _ := ctx.fixedFlags.AddByName("proxy")
+	f.SetValue("socks5://127.0.0.1:1080")
+	if got := proxyFor("https://ecs.ap-southeast-1.byteplusapi.com/"); got != "socks5://127.0.0.1:1080" {
+		t.Fatalf("proxy = %q, want the ---proxy value", got)
+	}
+
+	f.SetValue("127.0.0.1:1080")
+	if _, err := NewSimpleClient(ctx); err == nil || !strings.Contains(err.Error(), "from flag") {
+		t.Fatalf("NewSimpleClient() error = %v, want an invalid proxy from flag", err)
+	}
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+)
+
+// plainPromptEnv 为 true 时总是使用编号列表，为 false 时总是使用 promptui，未设置时按 TERM 判断。
+const plainPromptEnv = "BYTEPLUS_PLAIN_PROMPT"
+
+var (
+	// plainPromptInput 与 plainPromptOutput 是编号列表的输入与输出，测试中替换。
+	plainPromptInput  io.Reader = os.Stdin
+	plainPromptOutput io.Writer = os.Stdout
+
+	// plainPromptReader 在多次提示间复用，避免 bufio 预读的输入在下一次提示时丢失。
+	plainPromptReader     *bufio.Reader
+	plainPromptReaderFrom io.Reader
+)
+
+// plainPromptRequested 判断是否改用编号列表：TERM=dumb，或非 Windows 下未设置 TERM（部分 CI shell 与 IDE 控制台），
+// promptui 在这些终端中无法移动光标，输出会错乱。
+func plainPromptRequested() bool {
+	if value, err := strconv.ParseBool(strings.TrimSpace(os.Getenv(plainPromptEnv))); err == nil {
+		return value
+	}
+	term := strings.TrimSpace(os.Getenv("TERM"))
+	return term == "dumb" || (term == "" && runtime.GOOS != "windows")
+}
+
+// runSelect 运行 promptui 选择列表；终端无法渲染时改为输出 labels 的编号列表并读取编号。
+func runSelect(sel promptui.Select, labels []string) (int, error) {
+	if !plainPromptRequested() {
+		idx, _, err := sel.Run()
+		return idx, err
+	}
+	label, _ := sel.Label.(string)
+	// 编号列表不支持输入过滤，去掉标签中的按键说明
+	if i := strings.Index(label, " (type to filter"); i >= 0 {
+		label = label[:i]
+	}
+	return plainSelect(plainPromptBufferedReader(), plainPromptOutput, label, labels)
+}
+
+// runPrompt 运行 promptui 输入框；终端无法渲染时改为按行读取，空输入使用默认值。
+func runPrompt(p promptui.Prompt) (string, error) {
+	if !plainPromptRequested() {
+		return p.Run()
+	}
+	label, _ := p.Label.(string)
+	in := plainPromptBufferedReader()
+	for {
+		if p.Default != "" {
+			fmt.Fprintf(plainPromptOutput, "%s [%s]: ", label, p.Default)
+		} else {
+			fmt.Fprintf(plainPromptOutput, "%s: ", label)
+		}
+		line, err := readPlainPromptLine(in)
+		if err != nil {
+			return "", err
+		}
+		if line == "" {
+			line = p.Default
+		}
+		if p.Validate != nil {
+			if err := p.Validate(line); err != nil {
+				fmt.Fprintf(plainPromptOutput, "%v\n", err)
+				continue
+			}
+		}
+		return line, nil
+	}
+}
+
+// plainSelect 输出编号列表并读取 1 到 len(labels) 的编号，输入无效时重新提示。
+func plainSelect(in *bufio.Reader, out io.Writer, label string, labels []string) (int, error) {
+	if len(labels) == 0 {
+		return 0, fmt.Errorf("nothing to select")
+	}
+	fmt.Fprintf(out, "%s:\n", label)
+	for i, l := range labels {
+		fmt.Fprintf(out, "  %d) %s\n", i+1, l)
+	}
+	for {
+		fmt.Fprint(out, "Enter number: ")
+		line, err := readPlainPromptLine(in)
+		if err != nil {
+			return 0, err
+		}
+		n, convErr := strconv.Atoi(line)
+		if convErr == nil && n >= 1 && n <= len(labels) {
+			return n - 1, nil
+		}
+		fmt.Fprintf(out, "Enter a number from 1 to %d.\n", len(labels))
+	}
+}
+
+// readPlainPromptLine 读取一行输入；输入结束时返回 promptui.ErrEOF，与 promptui 的中断处理一致。
+func readPlainPromptLine(in *bufio.Reader) (string, error) {
+	line, err := in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		if err == io.EOF {
+			return "", promptui.ErrEOF
+		}
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+func plainPromptBufferedReader() *bufio.Reader {
+	if plainPromptReader == nil || plainPromptReaderFrom != plainPromptInput {
+		plainPromptReader = bufio.NewReader(plainPromptInput)
+		plainPromptReaderFrom = plainPromptInput
+	}
+	return plainPromptReader
+}
+
+// sessionOptionLabels 是 SSO 会话选项在编号列表中的显示文本：名称、region 与 start URL。
+func sessionOptionLabels(options []sessionOption) []string {
+	labels := make([]string, len(options))
+	for i, o := range options {
+		labels[i] = o.Name
+		if o.Session != nil {
+			labels[i] = fmt.Sprintf("%s   %s   %s", o.Name, o.Session.Region, o.Session.StartURL)
+		}
+	}
+	return labels
+}
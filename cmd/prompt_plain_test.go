@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/manifoldco/promptui"
+)
+
+func withPlainPromptForTest(t *testing.T, input string) *strings.Builder {
+	t.Helper()
+	t.Setenv(plainPromptEnv, "true")
+	prevIn, prevOut := plainPromptInput, plainPromptOutput
+	var out strings.Builder
+	plainPromptInput, plainPromptOutput = strings.NewReader(input), &out
+	t.Cleanup(func() { plainPromptInput, plainPromptOutput = prevIn, prevOut })
+	return &out
+}
+
+func TestPromptSelectAccountFallsBackToNumberedList(t *testing.T) {
+	out := withPlainPromptForTest(t, "0\nabc\n2\n")
+
+	account, err := promptSelectAccount([]AccountInfo{{AccountID: "1", AccountName: "dev"}, {AccountID: "2", AccountName: "prod"}})
+	if err != nil {
+		t.Fatalf("promptSelectAccount returned error: %v", err)
+	}
+	if account.AccountID != "2" {
+		t.Fatalf("account = %+v, want prod", account)
+	}
+	if !strings.Contains(out.String(), "Select account:\n  1) dev (1)\n  2) prod (2)\n") || strings.Count(out.String(), "Enter a number from 1 to 2.") != 2 {
+		t.Fatalf("output = %q", out.String())
+	}
+}
+
+func TestPlainPromptUsesDefaultAndReportsEOF(t *testing.T) {
+	withPlainPromptForTest(t, "\n")
+
+	name, err := runPrompt(promptui.Prompt{Label: "Enter new SSO session name", Default: "my-sso"})
+	if err != nil || name != "my-sso" {
+		t.Fatalf("runPrompt = %q, %v; want the default", name, err)
+	}
+	if _, err := runSelect(promptui.Select{Label: "Select role"}, []string{"admin"}); !errors.Is(err, promptui.ErrEOF) {
+		t.Fatalf("runSelect at end of input error = %v, want promptui.ErrEOF", err)
+	}
+}
+
+func TestPlainPromptRequestedForDumbTerminal(t *testing.T) {
+	t.Setenv(plainPromptEnv, "")
+	t.Setenv("TERM", "dumb")
+	if !plainPromptRequested() {
+		t.Fatal("TERM=dumb should use the numbered list")
+	}
+	t.Setenv("TERM", "xterm-256color")
+	if plainPromptRequested() {
+		t.Fatal("xterm should use promptui")
+	}
+	t.Setenv(plainPromptEnv, "true")
+	if !plainPromptRequested() {
+		t.Fatalf("%s=true should force the numbered list", plainPromptEnv)
+	}
+}
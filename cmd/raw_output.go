@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/byteplus-sdk/byteplus-go-sdk-v2/byteplus/bytepluserr"
+	"github.com/byteplus-sdk/byteplus-go-sdk-v2/byteplus/byteplusquery"
+	"github.com/byteplus-sdk/byteplus-go-sdk-v2/byteplus/client"
+	"github.com/byteplus-sdk/byteplus-go-sdk-v2/byteplus/request"
+)
+
+// rawOutputConflicts 是与 ---output-raw 互斥的固定 flag：响应体不再按 JSON 解析，无法分页、过滤或格式化。
+var rawOutputConflicts = []string{"output", "paginate", "fields", "count", "jq", "output-template", "output-file", "output-file-format",
+	"created-after", "created-before", "sort-by", "all-regions", "validate-response", "post-result", "post-result-header"}
+
+// rawDownload 描述一次 ---output-raw 调用：dest 为用户给出的路径，保存后 Path、Bytes 与 ContentType 记录结果。
+type rawDownload struct {
+	dest string
+	// fallbackName 是 dest 为目录且响应未通过 Content-Disposition 给出文件名时使用的文件名。
+	fallbackName string
+
+	Path        string `json:"File"`
+	Bytes       int64  `json:"Bytes"`
+	ContentType string `json:"ContentType,omitempty"`
+}
+
+// resolveRawOutput 解析 ---output-raw；未指定时返回 nil。
+func resolveRawOutput(ctx *Context, action string) (*rawDownload, error) {
+	f := ctx.fixedFlags.GetByName("output-raw")
+	if f == nil {
+		return nil, nil
+	}
+	dest := strings.TrimSpace(f.GetValue())
+	if dest == "" {
+		return nil, fmt.Errorf("---output-raw requires a file or directory path")
+	}
+	for _, name := range rawOutputConflicts {
+		if ctx.fixedFlags.GetByName(name) != nil {
+			return nil, fmt.Errorf("---output-raw cannot be used with ---%s", name)
+		}
+	}
+	return &rawDownload{dest: dest, fallbackName: action}, nil
+}
+
+// targetPath 返回保存响应体的文件路径。dest 为已存在的目录或以路径分隔符结尾时，
+// 文件名取自 Content-Disposition，没有时使用 fallbackName；否则 dest 就是目标文件。
+func (d *rawDownload) targetPath(contentDisposition string) string {
+	isDir := strings.HasSuffix(d.dest, "/") || strings.HasSuffix(d.dest, string(filepath.Separator))
+	if info, err := os.Stat(d.dest); err == nil && info.IsDir() {
+		isDir = true
+	}
+	if !isDir {
+		return d.dest
+	}
+	name := contentDispositionFilename(contentDisposition)
+	if name == "" {
+		name = d.fallbackName
+	}
+	return filepath.Join(d.dest, name)
+}
+
+// contentDispositionFilename 读取 Content-Disposition 中的文件名（filename* 优先），只保留最后一段，
+// 避免服务端返回的 "../x" 或绝对路径把文件写到目标目录之外。
+func contentDispositionFilename(header string) string {
+	if header == "" {
+		return ""
+	}
+	_, params, err := mime.ParseMediaType(header)
+	if err != nil {
+		return ""
+	}
+	name := path.Base(strings.ReplaceAll(params["filename"], `\`, "/"))
+	switch name {
+	case "", ".", "..", "/":
+		return ""
+	}
+	return name
+}
+
+// save 把响应体流式写入同目录下的临时文件，完整写入后再替换目标文件，中断时不会留下半个文件。
+func (d *rawDownload) save(resp *http.Response) error {
+	target := d.targetPath(resp.Header.Get("Content-Disposition"))
+	dir := filepath.Dir(target)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tempFile, err := os.CreateTemp(dir, tempFilePrefix+"*")
+	if err != nil {
+		return err
+	}
+	tempName := tempFile.Name()
+	defer trackTempFile(tempName)()
+	defer func() {
+		_ = tempFile.Close()
+		_ = os.Remove(tempName)
+	}()
+	n, err := io.Copy(tempFile, resp.Body)
+	if err != nil {
+		return err
+	}
+	if err := tempFile.Sync(); err != nil {
+		return err
+	}
+	if err := tempFile.Close(); err != nil {
+		return err
+	}
+	if err := replaceFile(tempName, target, 0644); err != nil {
+		return err
+	}
+	d.Path, d.Bytes, d.ContentType = target, n, resp.Header.Get("Content-Type")
+	return nil
+}
+
+// rawUnmarshalHandler 替换 byteplusquery.UnmarshalHandler，成功响应的响应体不按 JSON 解析，直接写入文件。
+// 错误响应仍由 UnmarshalErrorHandler 解析，错误码与 request id 与普通调用一致。
+func rawUnmarshalHandler(d *rawDownload) request.NamedHandler {
+	return request.NamedHandler{
+		Name: "ByteplusCliRawUnmarshalHandler",
+		Fn: func(r *request.Request) {
+			defer r.HTTPResponse.Body.Close()
+			if err := d.save(r.HTTPResponse); err != nil {
+				r.Error = bytepluserr.New("SaveResponseError", "failed to write ---output-raw", err)
+			}
+		},
+	}
+}
+
+// CallSdkRaw 发起与 CallSdk 相同的调用，但把成功响应的原始响应体写入 d 指定的文件。
+func (s *SdkClient) CallSdkRaw(info SdkClientInfo, input interface{}, d *rawDownload) error {
+	return s.send(info, input, &map[string]interface{}{}, func(c *client.Client) {
+		c.Handlers.Unmarshal.RemoveByName(byteplusquery.UnmarshalHandler.Name)
+		c.Handlers.Unmarshal.PushBackNamed(rawUnmarshalHandler(d))
+	})
+}
+
+// writeRawDownloadSummary 在 stdout 输出保存结果，响应体本身只写入文件。
+func writeRawDownloadSummary(out io.Writer, d *rawDownload) error {
+	b, err := json.MarshalIndent(d, "", "    ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out, string(b))
+	return err
+}
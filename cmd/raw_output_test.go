@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestContentDispositionFilenameKeepsOnlyTheBaseName(t *testing.T) {
+	cases := map[string]string{
+		`attachment; filename="export.tar.gz"`:                "export.tar.gz",
+		`attachment; filename*=UTF-8''%E6%97%A5%E5%BF%97.log`: "日志.log",
+		`attachment; filename="../../etc/passwd"`:             "passwd",
+		`attachment; filename="..\\..\\evil.exe"`:             "evil.exe",
+		`attachment; filename=".."`:                           "",
+		`inline`:                                              "",
+		``:                                                    "",
+	}
+	for header, want := range cases {
+		if got := contentDispositionFilename(header); got != want {
+			t.Errorf("contentDispositionFilename(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestCallSdkRawStreamsBodyToDirectory(t *testing.T) {
+	defer disableProxyEnvForTest(t)()
+
+	body := []byte{0x1f, 0x8b, 0x00, 0xff, 'r', 'a', 'w'}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/gzip")
+		w.Header().Set("Content-Disposition", `attachment; filename="export.gz"`)
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	defer setenvForTest(t, "BYTEPLUS_ACCESS_KEY", "ak-test")()
+	defer setenvForTest(t, "BYTEPLUS_SECRET_KEY", "sk-test")()
+	defer setenvForTest(t, "BYTEPLUS_REGION", "ap-southeast-1")()
+
+	testCtx := NewContext()
+	endpointFlag, _ := testCtx.fixedFlags.AddByName("endpoint")
+	endpointFlag.SetValue(server.URL)
+	sdk, err := NewSimpleClient(testCtx)
+	if err != nil {
+		t.Fatalf("NewSimpleClient returned error: %v", err)
+	}
+
+	dir := t.TempDir()
+	download := &rawDownload{dest: dir + string(filepath.Separator), fallbackName: "ExportLogs"}
+	if err := sdk.CallSdkRaw(SdkClientInfo{ServiceName: "demo", Action: "ExportLogs", Version: "2024-01-01", Method: "GET"}, nil, download); err != nil {
+		t.Fatalf("CallSdkRaw returned error: %v", err)
+	}
+
+	want := filepath.Join(dir, "export.gz")
+	if download.Path != want || download.Bytes != int64(len(body)) || download.ContentType != "application/gzip" {
+		t.Fatalf("download = %+v, want %s with %d bytes", download, want, len(body))
+	}
+	got, err := os.ReadFile(want)
+	if err != nil {
+		t.Fatalf("read saved file: %v", err)
+	}
+	if string(got) != string(body) {
+		t.Fatalf("saved body = %q, want %q", got, body)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Fatalf("directory has %d entries, want only the saved file", len(entries))
+	}
+}
+
+func TestResolveRawOutputRejectsFormattingFlags(t *testing.T) {
+	testCtx := NewContext()
+	raw, _ := testCtx.fixedFlags.AddByName("output-raw")
+	raw.SetValue("out.bin")
+	paginate, _ := testCtx.fixedFlags.AddByName("paginate")
+	paginate.SetValue("true")
+	if _, err := resolveRawOutput(testCtx, "ExportLogs"); err == nil || err.Error() != "---output-raw cannot be used with ---paginate" {
+		t.Fatalf("resolveRawOutput error = %v, want a conflict with ---paginate", err)
+	}
+}
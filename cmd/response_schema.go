@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// responseSchemaWarningOut 为 ---validate-response 提示的输出目标。
+var responseSchemaWarningOut io.Writer = os.Stderr
+
+// validateResponseRequested 判断本次调用是否指定了 ---validate-response。
+func validateResponseRequested(ctx *Context) bool {
+	f := ctx.fixedFlags.GetByName("validate-response")
+	return f != nil && f.GetValue() == "true"
+}
+
+// validateResponseSchema 按元数据中的 ApiMeta.Response 检查响应的 Result，返回 "路径: 描述" 形式的差异：
+// 类型不符、元数据中没有的字段，以及缺少元数据标记为 Required 的字段。
+// 元数据未描述的类型不检查；没有响应元数据时返回 nil。
+func validateResponseSchema(page map[string]interface{}, apiMeta *ApiMeta) []string {
+	if apiMeta == nil || apiMeta.Response == nil || len(apiMeta.Response.MetaTypes) == 0 {
+		return nil
+	}
+	result, ok := page["Result"]
+	if !ok || result == nil {
+		return nil
+	}
+	object, ok := result.(map[string]interface{})
+	if !ok {
+		return []string{fmt.Sprintf("Result: expected object, got %s", responseValueKind(result))}
+	}
+	return appendSchemaMismatches(nil, "Result", object, apiMeta.Response)
+}
+
+func appendSchemaMismatches(found []string, prefix string, object map[string]interface{}, meta *Meta) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		path := prefix + "." + key
+		mt, ok := meta.MetaTypes[key]
+		if !ok {
+			found = append(found, path+": field is not in the response metadata")
+			continue
+		}
+		var child *Meta
+		if meta.ChildMetas != nil {
+			child = meta.ChildMetas[key]
+		}
+		found = appendValueMismatches(found, path, object[key], mt.TypeName, mt.TypeOf, child)
+	}
+
+	var required []string
+	for key, mt := range meta.MetaTypes {
+		if _, ok := object[key]; !ok && mt.Required {
+			required = append(required, key)
+		}
+	}
+	sort.Strings(required)
+	for _, key := range required {
+		found = append(found, prefix+"."+key+": required field is missing")
+	}
+	return found
+}
+
+// appendValueMismatches 检查单个值是否符合 typeName；数组与 map 的元素按 typeOf 检查，
+// 对象元素再按 child 递归。null 视为字段未返回，不报告。
+func appendValueMismatches(found []string, path string, value interface{}, typeName, typeOf string, child *Meta) []string {
+	if value == nil {
+		return found
+	}
+	switch typeName {
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return append(found, fmt.Sprintf("%s: expected array, got %s", path, responseValueKind(value)))
+		}
+		for i, item := range items {
+			found = appendValueMismatches(found, fmt.Sprintf("%s[%d]", path, i), item, typeOf, "", child)
+		}
+		return found
+	case "map":
+		entries, ok := value.(map[string]interface{})
+		if !ok {
+			return append(found, fmt.Sprintf("%s: expected map, got %s", path, responseValueKind(value)))
+		}
+		keys := make([]string, 0, len(entries))
+		for key := range entries {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			found = appendValueMismatches(found, path+"."+key, entries[key], typeOf, "", child)
+		}
+		return found
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return append(found, fmt.Sprintf("%s: expected object, got %s", path, responseValueKind(value)))
+		}
+		if child == nil {
+			return found
+		}
+		return appendSchemaMismatches(found, path, object, child)
+	}
+
+	want, known := responseSchemaKinds[typeName]
+	if !known {
+		return found
+	}
+	if got := responseValueKind(value); got != want {
+		return append(found, fmt.Sprintf("%s: expected %s, got %s", path, typeName, got))
+	}
+	return found
+}
+
+// responseSchemaKinds 把元数据中的标量类型映射到 JSON 值的种类。
+var responseSchemaKinds = map[string]string{
+	"string":  "string",
+	"boolean": "boolean",
+	"integer": "number",
+	"long":    "number",
+	"float":   "number",
+	"double":  "number",
+	"number":  "number",
+}
+
+func responseValueKind(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64, float32, int, int32, int64, json.Number:
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// reportResponseSchemaMismatches 把响应与元数据的差异打印到 stderr，只作提示，不影响退出状态。
+func reportResponseSchemaMismatches(mismatches []string) {
+	for _, m := range mismatches {
+		fmt.Fprintf(responseSchemaWarningOut, "Warning: response does not match the API metadata at %s\n", m)
+	}
+}
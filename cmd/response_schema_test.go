@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateResponseSchemaReportsDrift(t *testing.T) {
+	apiMeta := &ApiMeta{Response: &Meta{
+		MetaTypes: map[string]*MetaType{
+			"TotalCount": {TypeName: "integer"},
+			"NextToken":  {TypeName: "string", Required: true},
+			"Vpcs":       {TypeName: "array", TypeOf: "object"},
+			"Tags":       {TypeName: "map", TypeOf: "string"},
+		},
+		ChildMetas: map[string]*Meta{
+			"Vpcs": {MetaTypes: map[string]*MetaType{
+				"VpcId":     {TypeName: "string"},
+				"IsDefault": {TypeName: "boolean"},
+			}},
+		},
+	}}
+	page := map[string]interface{}{"Result": map[string]interface{}{
+		"TotalCount": float64(2),
+		"Vpcs": []interface{}{
+			map[string]interface{}{"VpcId": "vpc-1", "IsDefault": true},
+			map[string]interface{}{"VpcId": "vpc-2", "IsDefault": "false", "Extra": 1},
+		},
+		"Tags":  map[string]interface{}{"env": "prod", "team": nil},
+		"Added": "x",
+	}}
+
+	got := validateResponseSchema(page, apiMeta)
+	want := []string{
+		"Result.Added: field is not in the response metadata",
+		"Result.Vpcs[1].Extra: field is not in the response metadata",
+		"Result.Vpcs[1].IsDefault: expected boolean, got string",
+		"Result.NextToken: required field is missing",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("validateResponseSchema() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if got := validateResponseSchema(page, &ApiMeta{}); got != nil {
+		t.Fatalf("validateResponseSchema() without response metadata = %#v, want nil", got)
+	}
+}
@@ -22,91 +22,144 @@ import (
 	"encoding/json"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/byteplus-sdk/byteplus-cli/asset"
 	"github.com/byteplus-sdk/byteplus-cli/structset"
 	"github.com/byteplus-sdk/byteplus-cli/typeset"
 )
 
+// RootSupport indexes the embedded service metadata. Only the structure
+// assets are parsed at startup; the action and type metadata of a service is
+// parsed on first use, so commands that touch one service (or none, such as
+// bp --help) do not pay for all of them.
 type RootSupport struct {
 	SupportSvc    []string
 	SupportAction map[string]map[string]*ByteplusMeta
 	Versions      map[string]string
 	SupportTypes  map[string]map[string]*ApiMeta
+
+	mu           sync.Mutex
+	actionBundle *metaBundle
+	typeBundle   *metaBundle
+	actionAssets map[string]string
+	typeAssets   map[string]string
+	loaded       map[string]bool
 }
 
 func NewRootSupport() *RootSupport {
 	var svc []string
-	action := make(map[string]map[string]*ByteplusMeta)
 	version := make(map[string]string)
-	types := make(map[string]map[string]*ApiMeta)
 	svcs := make(map[string]string)
+	actionAssets := make(map[string]string)
+	typeAssets := make(map[string]string)
+
+	overlay := loadMetaOverlay()
+	structBundle := &metaBundle{embeddedNames: structset.AssetNames(), embedded: structset.Asset, overlay: overlay["structure"]}
+	actionBundle := &metaBundle{embeddedNames: asset.AssetNames(), embedded: asset.Asset, overlay: overlay["metadata"]}
+	typeBundle := &metaBundle{embeddedNames: typeset.AssetNames(), embedded: typeset.Asset, overlay: overlay["metatype"]}
 
 	//generate structure info form meta and set a map with service_version:pkgName
 	svcMappings := make(map[string]string)
-	structSet := structset.AssetNames()
-	sort.Strings(structSet)
-	for _, name := range structSet {
+	for _, name := range structBundle.Names() {
 		spaces := strings.Split(name, "/")
-		b, _ := structset.Asset(name)
-		st := StructInfo{}
-		err := json.Unmarshal(b, &st)
-		if err != nil {
-			panic(err)
-		}
 		svcName := spaces[2]
 		svcVersion := spaces[3]
-		pkgName := st.PkgName
+		pkgName := structPkgName(structBundle, name)
 		svcMappings[svcName+"_"+svcVersion] = pkgName
 		SetServiceMapping(pkgName, svcName)
 	}
 
-	temp := asset.AssetNames()
-	sort.Strings(temp)
-	for _, name := range temp {
+	for _, name := range actionBundle.Names() {
 		spaces := strings.Split(name, "/")
 		if len(spaces) == 5 {
-			var svcName string
 			//if structure info is nil skip it
 			if s, ok := svcMappings[spaces[2]+"_"+spaces[3]]; ok {
-				svcName = s
-				svcs[spaces[2]+"_"+spaces[3]] = svcName
-				b, _ := asset.Asset(name)
-				action[svcName] = make(map[string]*ByteplusMeta)
-				meta := make(map[string]*ByteplusMeta)
-				err := json.Unmarshal(b, &meta)
-				if err != nil {
-					panic(err)
-				}
-				action[svcName] = meta
-				version[svcName] = spaces[3]
+				svcs[spaces[2]+"_"+spaces[3]] = s
+				actionAssets[s] = name
+				version[s] = spaces[3]
 			}
 		}
 	}
-	for _, name := range typeset.AssetNames() {
+	for _, name := range typeBundle.Names() {
 		spaces := strings.Split(name, "/")
 		if len(spaces) == 5 {
 			//if structure info is nil skip it
 			if _, ok := svcMappings[spaces[2]+"_"+spaces[3]]; ok {
 				svcName := svcs[spaces[2]+"_"+spaces[3]]
 				svc = append(svc, svcName)
-				b, _ := typeset.Asset(name)
-				meta := make(map[string]*ApiMeta)
-				err := json.Unmarshal(b, &meta)
-				if err != nil {
-					panic(err)
-				}
-				types[svcName] = meta
+				typeAssets[svcName] = name
 			}
 		}
 	}
 
 	return &RootSupport{
 		SupportSvc:    svc,
-		SupportAction: action,
+		SupportAction: make(map[string]map[string]*ByteplusMeta),
 		Versions:      version,
-		SupportTypes:  types,
+		SupportTypes:  make(map[string]map[string]*ApiMeta),
+		actionBundle:  actionBundle,
+		typeBundle:    typeBundle,
+		actionAssets:  actionAssets,
+		typeAssets:    typeAssets,
+		loaded:        make(map[string]bool),
+	}
+}
+
+// structPkgName returns the PkgName of a structure asset. Embedded assets
+// are looked up in the index generated with structset.go, which saves
+// decompressing and parsing about 5 MB of JSON on every start; files from
+// BYTEPLUS_META_DIR and assets missing from the index are parsed.
+func structPkgName(bundle *metaBundle, name string) string {
+	if _, overridden := bundle.overlay[name]; !overridden {
+		if pkgName, ok := structset.PkgNames[name]; ok {
+			return pkgName
+		}
+	}
+	b, _ := bundle.Asset(name)
+	st := StructInfo{}
+	if err := json.Unmarshal(b, &st); err != nil {
+		panic(err)
+	}
+	return st.PkgName
+}
+
+// serviceMeta returns the action and type metadata of svc, parsing the
+// assets the first time the service is used.
+func (r *RootSupport) serviceMeta(svc string) (map[string]*ByteplusMeta, map[string]*ApiMeta) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.loaded[svc] {
+		r.loaded[svc] = true
+		if name, ok := r.actionAssets[svc]; ok {
+			b, _ := r.actionBundle.Asset(name)
+			meta := make(map[string]*ByteplusMeta)
+			if err := json.Unmarshal(b, &meta); err != nil {
+				panic(err)
+			}
+			r.SupportAction[svc] = meta
+		}
+		if name, ok := r.typeAssets[svc]; ok {
+			b, _ := r.typeBundle.Asset(name)
+			meta := make(map[string]*ApiMeta)
+			if err := json.Unmarshal(b, &meta); err != nil {
+				panic(err)
+			}
+			r.SupportTypes[svc] = meta
+		}
+	}
+	return r.SupportAction[svc], r.SupportTypes[svc]
+}
+
+// GetActionSvcs returns the services that have action metadata, which are
+// the services exposed as commands.
+func (r *RootSupport) GetActionSvcs() []string {
+	svcs := make([]string, 0, len(r.actionAssets))
+	for svc := range r.actionAssets {
+		svcs = append(svcs, svc)
 	}
+	sort.Strings(svcs)
+	return svcs
 }
 
 func (r *RootSupport) GetAllSvcCompatible() []string {
@@ -123,7 +176,8 @@ func (r *RootSupport) GetAllSvc() []string {
 
 func (r *RootSupport) GetAllAction(svc string) []string {
 	var as []string
-	for k, _ := range r.SupportAction[svc] {
+	actions, _ := r.serviceMeta(svc)
+	for k, _ := range actions {
 		as = append(as, k)
 	}
 	return as
@@ -134,19 +188,17 @@ func (r *RootSupport) GetVersion(svc string) string {
 }
 
 func (r *RootSupport) GetApiMeta(svc string, action string) *ApiMeta {
-	if metas, ok := r.SupportTypes[svc]; ok {
+	_, metas := r.serviceMeta(svc)
+	if metas != nil {
 		return metas[action]
 	}
 	return nil
 }
 
 func (r *RootSupport) GetApiInfo(svc string, action string) *ApiInfo {
-	for k, v := range r.SupportAction {
-		if k == svc {
-			if v1, ok := v[action]; ok {
-				return v1.ApiInfo
-			}
-		}
+	actions, _ := r.serviceMeta(svc)
+	if v1, ok := actions[action]; ok {
+		return v1.ApiInfo
 	}
 	return nil
 }
@@ -161,12 +213,7 @@ func (r *RootSupport) IsValidSvc(svc string) bool {
 }
 
 func (r *RootSupport) IsValidAction(svc, action string) bool {
-	for k, v := range r.SupportAction {
-		if k == svc {
-			if _, ok := v[action]; ok {
-				return true
-			}
-		}
-	}
-	return false
+	actions, _ := r.serviceMeta(svc)
+	_, ok := actions[action]
+	return ok
 }
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/byteplus-sdk/byteplus-cli/structset"
+)
+
+func TestNewRootSupportParsesServiceMetadataOnFirstUse(t *testing.T) {
+	support := NewRootSupport()
+
+	if len(support.SupportAction) != 0 || len(support.SupportTypes) != 0 {
+		t.Fatalf("NewRootSupport parsed %d action and %d type assets up front, want 0", len(support.SupportAction), len(support.SupportTypes))
+	}
+	if support.GetVersion("ecs") == "" {
+		t.Fatal("ecs version missing from the service index")
+	}
+
+	if len(support.GetAllAction("ecs")) == 0 {
+		t.Fatal("GetAllAction(ecs) returned no actions")
+	}
+	if !support.IsValidAction("ecs", "DescribeInstances") {
+		t.Fatal("ecs.DescribeInstances is not a valid action")
+	}
+	if _, ok := support.SupportAction["ecs"]; !ok {
+		t.Fatal("ecs metadata not cached after first use")
+	}
+	if _, ok := support.SupportAction["vpc"]; ok {
+		t.Fatal("vpc metadata parsed without being used")
+	}
+	if support.IsValidAction("ecs", "NoSuchAction") || support.IsValidAction("no-such-service", "DescribeInstances") {
+		t.Fatal("IsValidAction accepted an unknown action or service")
+	}
+}
+
+func TestStructureIndexMatchesEmbeddedAssets(t *testing.T) {
+	names := structset.AssetNames()
+	if len(structset.PkgNames) != len(names) {
+		t.Fatalf("structset.PkgNames has %d entries for %d assets; regenerate it with scripts/generate_structure_index", len(structset.PkgNames), len(names))
+	}
+	for _, name := range names {
+		b, _ := structset.Asset(name)
+		st := StructInfo{}
+		if err := json.Unmarshal(b, &st); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got := structset.PkgNames[name]; got != st.PkgName {
+			t.Fatalf("structset.PkgNames[%q] = %q, want %q", name, got, st.PkgName)
+		}
+	}
+}
+
+func TestStructPkgNamePrefersMetaDirOverlay(t *testing.T) {
+	name := structset.AssetNames()[0]
+	bundle := &metaBundle{
+		embeddedNames: structset.AssetNames(),
+		embedded:      structset.Asset,
+		overlay:       map[string][]byte{name: []byte(`{"PkgName":"overlaypkg"}`)},
+	}
+	if got := structPkgName(bundle, name); got != "overlaypkg" {
+		t.Fatalf("structPkgName() = %q, want overlaypkg", got)
+	}
+}
+
+func TestLoadActionCmdsForArgsAddsOnlyRequestedService(t *testing.T) {
+	loadActionCmdsForArgs([]string{"__complete", "ecs", "Desc"})
+
+	if !serviceCmds["ecs"].HasSubCommands() {
+		t.Fatal("ecs command has no action subcommands after loading")
+	}
+	if len(serviceCmds["ecs"].ValidArgs) == 0 {
+		t.Fatal("ecs ValidArgs not set after loading")
+	}
+	if serviceCmdsLoaded["vpc"] {
+		t.Fatal("vpc action commands loaded for an ecs invocation")
+	}
+}
+
+func TestResolveServiceAbbreviation(t *testing.T) {
+	cases := []struct {
+		args    []string
+		want    []string
+		wantErr string
+	}{
+		{args: []string{"autosc", "DescribeScalingGroups"}, want: []string{"autoscaling", "DescribeScalingGroups"}},
+		{args: []string{"cloudmon", "--help"}, want: []string{"cloudmonitor", "--help"}},
+		{args: []string{"ecs", "DescribeInstances"}, want: []string{"ecs", "DescribeInstances"}},
+		{args: []string{"configure", "list"}, want: []string{"configure", "list"}},
+		{args: []string{"nosuchservice"}, want: []string{"nosuchservice"}},
+		{args: []string{"__complete", "autosc", "Desc"}, want: []string{"__complete", "autoscaling", "Desc"}},
+		{args: []string{"__complete", "autosc"}, want: []string{"__complete", "autosc"}},
+		{args: []string{"rds", "DescribeDBInstances"}, wantErr: `service "rds" is ambiguous, it matches: rdsmssql, rdsmysqlv2, rdspostgresql`},
+	}
+	for _, tc := range cases {
+		got, err := resolveServiceAbbreviation(tc.args)
+		if tc.wantErr != "" {
+			if err == nil || err.Error() != tc.wantErr {
+				t.Fatalf("resolveServiceAbbreviation(%v) error = %v, want %q", tc.args, err, tc.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("resolveServiceAbbreviation(%v) error = %v", tc.args, err)
+		}
+		if strings.Join(got, " ") != strings.Join(tc.want, " ") {
+			t.Fatalf("resolveServiceAbbreviation(%v) = %v, want %v", tc.args, got, tc.want)
+		}
+	}
+}
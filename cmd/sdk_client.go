@@ -18,17 +18,18 @@ package cmd
 
 import (
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/byteplus-sdk/byteplus-go-sdk-v2/byteplus"
 	"github.com/byteplus-sdk/byteplus-go-sdk-v2/byteplus/byteplusquery"
 	"github.com/byteplus-sdk/byteplus-go-sdk-v2/byteplus/client"
 	"github.com/byteplus-sdk/byteplus-go-sdk-v2/byteplus/client/metadata"
 	"github.com/byteplus-sdk/byteplus-go-sdk-v2/byteplus/credentials"
-	"github.com/byteplus-sdk/byteplus-go-sdk-v2/byteplus/credentials/clicreds"
-	"github.com/byteplus-sdk/byteplus-go-sdk-v2/byteplus/defaults"
 	"github.com/byteplus-sdk/byteplus-go-sdk-v2/byteplus/endpoints"
 	"github.com/byteplus-sdk/byteplus-go-sdk-v2/byteplus/request"
 	"github.com/byteplus-sdk/byteplus-go-sdk-v2/byteplus/session"
@@ -39,14 +40,56 @@ type SdkClient struct {
 	Config      *byteplus.Config
 	Session     *session.Session
 	DebugLogger *DebugLogger
+	// ServiceEndpoints overrides the endpoint of individual services. Keys may
+	// be CLI service names or SDK service names.
+	ServiceEndpoints map[string]string
+	// VerboseOut receives the resolved endpoint of every call when ---verbose
+	// is set; nil disables it.
+	VerboseOut io.Writer
+	// ExtraHeaders are added to every request after it is built and before it
+	// is signed; set from ---header and --header.
+	ExtraHeaders http.Header
+	// CanonicalRequestOut receives the canonical request and string to sign
+	// of every signed request when ---dump-canonical-request is set; nil
+	// disables it.
+	CanonicalRequestOut io.Writer
+
+	// resolved records which profile, credential source and region the
+	// client was built from, e.g. for 'bp configure whoami'.
+	resolved *resolvedClient
+
+	// reauth refreshes the credentials and builds a replacement client after
+	// the server rejects them as expired. Only set for SSO profiles, and
+	// cleared after the first use so a call is retried at most once.
+	reauth func() (*SdkClient, error)
+	// reauthErr is the error of the re-auth, returned to calls that were
+	// waiting for it.
+	reauthErr error
+	// mu guards Config, Session, ServiceEndpoints and the re-auth fields once
+	// the client is in use: bp batch workers share one client, and a re-auth
+	// replaces them while other calls are building requests.
+	mu sync.Mutex
+}
+
+// sdkClientState is what a request is built from. A re-auth replaces it as a
+// whole, so each call reads it once through SdkClient.state.
+type sdkClientState struct {
+	config    *byteplus.Config
+	session   *session.Session
+	endpoints map[string]string
 }
 
+// verboseOutput is where ---verbose writes; tests replace it to capture output.
+var verboseOutput io.Writer = os.Stderr
+
 type SdkClientInfo struct {
 	ServiceName string
 	Action      string
 	Version     string
 	Method      string
 	ContentType string
+	// Protocol selects how the request is encoded: protocolQuery (default) or protocolJSON.
+	Protocol string
 }
 
 // NewSimpleClient creates an SDK client with credential resolution:
@@ -54,173 +97,178 @@ type SdkClientInfo struct {
 //     a. SSO mode: CLI refreshes STS credentials (EnsureValidStsToken), then delegates to SDK CliProvider.
 //     b. Console Login mode: CLI refreshes the login cache, then delegates to SDK CliProvider.
 //     c. Other modes: directly delegates to SDK CliProvider for credential resolution.
+//     If credential-process is set it takes precedence over the mode: the command's
+//     JSON output is used as static credentials, cached until it expires.
 //  2. If no profile is configured, use the SDK default credential chain (Env → OIDC → CliProvider → EcsRole).
+//  3. With ---no-config or BYTEPLUS_IGNORE_CONFIG=true the config file is skipped
+//     entirely and credentials must come from environment variables.
+//  4. ---credential-source (or BYTEPLUS_CREDENTIAL_SOURCE) overrides 1 and 2:
+//     "env" takes credentials only from environment variables even when a
+//     profile exists, "profile" fails instead of falling back to the default chain.
+//
+// The precedence rules for the profile, credentials, region and endpoint are
+// implemented by resolveCredentials. When none of them yields a region and
+// BYTEPLUS_REGION_FROM_METADATA=true, the region of the current instance is
+// read from the instance metadata service.
 func NewSimpleClient(ctx *Context) (*SdkClient, error) {
-	var (
-		creds            *credentials.Credentials
-		region, endpoint string
-		endpointResolver string
-		httpProxy        string
-		httpsProxy       string
-		disableSSl       bool
-		useDualStack     bool
-	)
 	if ctx == nil || ctx.fixedFlags == nil {
 		return nil, fmt.Errorf("invalid context for creating sdk client")
 	}
-	var currentProfile *Profile
-	profileName := ""
-	profileSource := "default-chain"
-	if ctx.config != nil {
-		// profile selection priority: ---profile > Current > env.
-		// Empty Current with no env does NOT fall back to a default profile;
-		// it goes to the default credential chain instead.
-		profileName, profileSource = defaultProfileNameWithSource(ctx.config)
-		overrideProfile := false
-		if f := ctx.fixedFlags.GetByName("profile"); f != nil && f.GetValue() != "" {
-			profileName = f.GetValue()
-			profileSource = "flag"
-			overrideProfile = true
-		}
-		currentProfile = ctx.config.Profiles[profileName]
-		if overrideProfile && currentProfile == nil {
-			return nil, fmt.Errorf("profile %q not found", profileName)
-		}
+	r, err := resolveCredentials(ctx, clientOverridesFromFlags(ctx))
+	if err != nil {
+		return nil, err
 	}
-
-	if currentProfile != nil {
-		// SSO 模式：CLI 负责刷新凭证并写回 config.json，再交给 SDK CliProvider 读取
-		if strings.ToLower(strings.TrimSpace(currentProfile.Mode)) == ModeSSO {
-			sso := &Sso{
-				Profile:        currentProfile,
-				SsoSessionName: currentProfile.SsoSessionName,
-				Region:         currentProfile.Region,
-			}
-			if err := sso.EnsureValidStsToken(ctx); err != nil {
-				return nil, err
-			}
-		}
-
-		if strings.ToLower(strings.TrimSpace(currentProfile.Mode)) == ModeConsoleLogin {
-			// Console Login 模式：CLI 负责刷新 login cache，再交给 SDK CliProvider 读取
-			_, err := EnsureValidLoginToken(ctx.config, profileName)
-			if err != nil {
-				return nil, err
-			}
-		}
-
-		// 所有模式统一委托 SDK CliProvider 解析凭证
-		creds = clicreds.NewCliCredentials("", profileName)
-
-		region = currentProfile.Region
-		if region == "" {
-			region = os.Getenv("BYTEPLUS_REGION")
-		}
-		endpoint = currentProfile.Endpoint
-		if endpoint == "" {
-			endpoint = os.Getenv("BYTEPLUS_ENDPOINT")
-		}
-		endpointResolver = currentProfile.EndpointResolver
-		if endpointResolver == "" {
-			endpointResolver = os.Getenv("BYTEPLUS_ENDPOINT_RESOLVER")
-		}
-		httpProxy = currentProfile.HTTPProxy
-		httpsProxy = currentProfile.HTTPSProxy
-		if currentProfile.DisableSSL != nil {
-			disableSSl = *currentProfile.DisableSSL
-		}
-		if currentProfile.UseDualStack != nil {
-			useDualStack = *currentProfile.UseDualStack
-		}
-	} else {
-		// 禁用默认凭证链
-		if os.Getenv("BYTEPLUS_DISABLE_DEFAULT_CREDENTIALS") == "true" {
-			return nil, fmt.Errorf("no profile configured and default credential chain is disabled (BYTEPLUS_DISABLE_DEFAULT_CREDENTIALS=true)")
-		}
-
-		// 无 profile，使用 SDK 默认凭证链（Env → OIDC → CliProvider → EcsRole）
-		creds = defaults.NewDefaultCredentialProvider()
-
-		region = os.Getenv("BYTEPLUS_REGION")
-		endpoint = os.Getenv("BYTEPLUS_ENDPOINT")
-		endpointResolver = os.Getenv("BYTEPLUS_ENDPOINT_RESOLVER")
-		ssl := os.Getenv("BYTEPLUS_DISABLE_SSL")
-		if ssl == "true" || ssl == "false" {
-			disableSSl, _ = strconv.ParseBool(ssl)
-		}
-		dualStack := os.Getenv("BYTEPLUS_USE_DUALSTACK")
-		if dualStack == "true" || dualStack == "false" {
-			useDualStack, _ = strconv.ParseBool(dualStack)
-		}
+	if r.Transport.ConnectTimeout, r.Transport.TLSHandshakeTimeout, err = resolveConnectTimeouts(ctx); err != nil {
+		return nil, err
 	}
-
-	// ---region 运行时覆盖 region
-	if f := ctx.fixedFlags.GetByName("region"); f != nil && f.GetValue() != "" {
-		region = f.GetValue()
+	extraHeaders, err := resolveExtraHeaders(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	// ---endpoint 运行时覆盖 endpoint
-	if f := ctx.fixedFlags.GetByName("endpoint"); f != nil && f.GetValue() != "" {
-		endpoint = f.GetValue()
-		endpointResolver = ""
+	if r.Region == "" && regionFromMetadataEnabled() {
+		// 其余来源都未提供 region 时，按需从实例元数据服务读取
+		region, err := instanceMetadataRegion()
+		if err != nil {
+			return nil, fmt.Errorf("region not set and could not be read from instance metadata (%s=true): %v", regionFromMetadataEnv, err)
+		}
+		r.Region, r.RegionSource = region, sourceInstanceMetadata
 	}
-
-	if region == "" {
-		if currentProfile == nil && !hasLocalCredentialSignal() {
+	if r.Region == "" {
+		if r.Profile == nil && !hasLocalCredentialSignal() {
 			return nil, fmt.Errorf("credentials not configured, please run 'bp login' or 'bp configure set', or set BYTEPLUS_ACCESS_KEY and BYTEPLUS_SECRET_KEY environment variables")
 		}
 		return nil, fmt.Errorf("region not set, please set it via profile, ---region flag, or BYTEPLUS_REGION environment variable")
 	}
 
 	config := byteplus.NewConfig().
-		WithRegion(region).
-		WithCredentials(creds).
-		WithDisableSSL(disableSSl)
+		WithRegion(r.Region).
+		WithCredentials(r.Credentials).
+		WithDisableSSL(r.DisableSSL)
 
-	resolverValue := strings.ToLower(strings.TrimSpace(endpointResolver))
-	switch resolverValue {
-	case "standard":
+	standardResolver, err := useStandardEndpointResolver(r.EndpointResolver)
+	if err != nil {
+		return nil, err
+	}
+	if !standardResolver && strings.ToLower(strings.TrimSpace(r.Endpoint)) == "auto-addressing" {
+		standardResolver = true
+	}
+	switch {
+	case standardResolver:
+		// 标准 resolver 按服务与 region 推导 endpoint，profile 中按服务配置的 endpoints 同样被忽略
 		config.WithEndpointResolver(endpoints.NewStandardEndpointResolver())
-	default:
-		if endpoint != "" {
-			if strings.ToLower(strings.TrimSpace(endpoint)) == "auto-addressing" {
-				config.WithEndpointResolver(endpoints.NewStandardEndpointResolver())
-			} else {
-				config.WithEndpoint(endpoint)
-			}
-		}
+		r.ServiceEndpoints = nil
+	case r.Endpoint != "":
+		config.WithEndpoint(r.Endpoint)
 	}
 
-	if useDualStack {
+	if r.UseDualStack {
 		config.WithUseDualStack(true)
 	}
-	if httpProxy != "" {
-		config.WithHTTPProxy(httpProxy)
-	}
-	if httpsProxy != "" {
-		config.WithHTTPSProxy(httpsProxy)
+	if r.Proxy != "" {
+		// proxy 优先：不把 http-proxy/https-proxy 交给 SDK，避免 SDK 覆盖 Transport.Proxy
+		if r.Transport.Proxy, err = profileProxyFunc(r.Proxy, noProxyList(r.NoProxy)); err != nil {
+			return nil, fmt.Errorf("%s (from %s)", err, r.ProxySource)
+		}
+	} else {
+		if r.HTTPProxy != "" {
+			config.WithHTTPProxy(r.HTTPProxy)
+		}
+		if r.HTTPSProxy != "" {
+			config.WithHTTPSProxy(r.HTTPSProxy)
+		}
 	}
+	// SDK 会在该 Transport 上设置代理，因此连接池设置与跳过证书校验不影响 http-proxy/https-proxy
+	config.WithHTTPClient(sdkHTTPClient(r.Transport, r.InsecureSkipVerify))
 
+	credentialMode := debugCredentialMode(r.Profile)
+	if r.CredentialSource == "env-only" {
+		credentialMode = "env-only"
+	}
 	debugLogClientConfig(ctx, debugClientConfig{
-		ProfileName:          profileName,
-		ProfileSource:        profileSource,
-		CredentialMode:       debugCredentialMode(currentProfile),
-		Region:               region,
-		Endpoint:             endpoint,
-		EndpointResolver:     endpointResolver,
-		DisableSSL:           disableSSl,
-		UseDualStack:         useDualStack,
-		HTTPProxyConfigured:  httpProxy != "",
-		HTTPSProxyConfigured: httpsProxy != "",
+		ProfileName:          r.ProfileName,
+		ProfileSource:        r.ProfileSource,
+		CredentialMode:       credentialMode,
+		Region:               r.Region,
+		Endpoint:             r.Endpoint,
+		EndpointResolver:     r.EndpointResolver,
+		DisableSSL:           r.DisableSSL,
+		UseDualStack:         r.UseDualStack,
+		InsecureSkipVerify:   r.InsecureSkipVerify,
+		HTTPProxyConfigured:  r.HTTPProxy != "",
+		HTTPSProxyConfigured: r.HTTPSProxy != "",
+		ProxySource:          r.ProxySource,
 	})
+	r.printResolvedSources(ctx)
 
 	sess, _ := session.NewSession(config)
 
-	return &SdkClient{
-		Config:      config,
-		Session:     sess,
-		DebugLogger: debugLoggerFromContext(ctx),
-	}, nil
+	sdk := &SdkClient{
+		Config:           config,
+		Session:          sess,
+		DebugLogger:      debugLoggerFromContext(ctx),
+		ServiceEndpoints: r.ServiceEndpoints,
+		ExtraHeaders:     extraHeaders,
+		resolved:         r,
+	}
+	if f := ctx.fixedFlags.GetByName("verbose"); f != nil && f.GetValue() == "true" {
+		sdk.VerboseOut = verboseOutput
+	}
+	if f := ctx.fixedFlags.GetByName("dump-canonical-request"); f != nil && f.GetValue() == "true" {
+		sdk.CanonicalRequestOut = canonicalRequestOutput
+	}
+	if r.CredentialSource == "profile:"+ModeSSO {
+		sdk.reauth = ssoReauth(ctx, r.Profile)
+	}
+	return sdk, nil
+}
+
+// ignoreConfigEnv makes every invocation behave as if ---no-config was given.
+const ignoreConfigEnv = "BYTEPLUS_IGNORE_CONFIG"
+
+// ignoreConfigRequested reports whether the config file must be ignored for
+// this invocation, via ---no-config or BYTEPLUS_IGNORE_CONFIG=true.
+func ignoreConfigRequested(ctx *Context) bool {
+	if f := ctx.fixedFlags.GetByName("no-config"); f != nil && f.GetValue() == "true" {
+		return true
+	}
+	value, _ := strconv.ParseBool(strings.TrimSpace(os.Getenv(ignoreConfigEnv)))
+	return value
+}
+
+// envOnlyCredentials builds static credentials from BYTEPLUS_ACCESS_KEY,
+// BYTEPLUS_SECRET_KEY and the optional BYTEPLUS_SESSION_TOKEN. Every missing
+// variable, including BYTEPLUS_REGION when ---region is absent, is reported
+// in one error so a CI job can be fixed in a single pass; reason explains why
+// only the environment is used.
+func envOnlyCredentials(ctx *Context, reason string) (*credentials.Credentials, error) {
+	accessKey := firstEnv("BYTEPLUS_ACCESS_KEY", "BYTEPLUS_ACCESS_KEY_ID")
+	secretKey := firstEnv("BYTEPLUS_SECRET_KEY", "BYTEPLUS_SECRET_ACCESS_KEY")
+	var missing []string
+	if accessKey == "" {
+		missing = append(missing, "BYTEPLUS_ACCESS_KEY")
+	}
+	if secretKey == "" {
+		missing = append(missing, "BYTEPLUS_SECRET_KEY")
+	}
+	if os.Getenv("BYTEPLUS_REGION") == "" {
+		if f := ctx.fixedFlags.GetByName("region"); f == nil || f.GetValue() == "" {
+			missing = append(missing, "BYTEPLUS_REGION")
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%s, but environment variables are missing: %s", reason, strings.Join(missing, ", "))
+	}
+	return credentials.NewStaticCredentials(accessKey, secretKey, os.Getenv("BYTEPLUS_SESSION_TOKEN")), nil
+}
+
+func firstEnv(names ...string) string {
+	for _, name := range names {
+		if v := strings.TrimSpace(os.Getenv(name)); v != "" {
+			return v
+		}
+	}
+	return ""
 }
 
 // hasLocalCredentialSignal reports whether any local credential signal exists
@@ -244,22 +292,48 @@ func hasLocalCredentialSignal() bool {
 	return false
 }
 
+// ssoSessionRegion returns the region of the SSO session an SSO profile
+// belongs to, or "" for other modes and unknown sessions.
+func ssoSessionRegion(cfg *Configure, profile *Profile) string {
+	if cfg == nil || profile == nil || strings.ToLower(strings.TrimSpace(profile.Mode)) != ModeSSO {
+		return ""
+	}
+	if session := cfg.SsoSession[profile.SsoSessionName]; session != nil {
+		return strings.TrimSpace(session.Region)
+	}
+	return ""
+}
+
 func defaultProfileName(cfg *Configure) string {
 	name, _ := defaultProfileNameWithSource(cfg)
 	return name
 }
 
+// defaultProfileNameWithSource resolves the profile used when ---profile is
+// absent. BYTEPLUS_PROFILE (or the legacy BYTEPLUS_CLI_PROFILE) selects a
+// profile for the current process only and takes precedence over Current,
+// so different shells can work against different accounts without
+// rewriting the shared config file.
 func defaultProfileNameWithSource(cfg *Configure) (string, string) {
+	if name, source := envProfileName(); name != "" {
+		return name, source
+	}
 	if cfg != nil && cfg.Current != "" {
 		return cfg.Current, "current"
 	}
-	if profile := os.Getenv("BYTEPLUS_PROFILE"); profile != "" {
+	return "", "default-chain"
+}
+
+// envProfileName returns the profile selected through environment variables
+// together with its debug source label.
+func envProfileName() (string, string) {
+	if profile := strings.TrimSpace(os.Getenv("BYTEPLUS_PROFILE")); profile != "" {
 		return profile, "env:BYTEPLUS_PROFILE"
 	}
-	if profile := os.Getenv("BYTEPLUS_CLI_PROFILE"); profile != "" {
+	if profile := strings.TrimSpace(os.Getenv("BYTEPLUS_CLI_PROFILE")); profile != "" {
 		return profile, "env:BYTEPLUS_CLI_PROFILE"
 	}
-	return "", "default-chain"
+	return "", ""
 }
 
 type debugClientConfig struct {
@@ -271,8 +345,10 @@ type debugClientConfig struct {
 	EndpointResolver     string
 	DisableSSL           bool
 	UseDualStack         bool
+	InsecureSkipVerify   bool
 	HTTPProxyConfigured  bool
 	HTTPSProxyConfigured bool
+	ProxySource          string
 }
 
 func debugCredentialMode(profile *Profile) string {
@@ -291,7 +367,7 @@ func debugLogClientConfig(ctx *Context, info debugClientConfig) {
 	if logger == nil || !logger.Enabled() {
 		return
 	}
-	logger.Printf("client_config profile_source=%s profile=%s credential_mode=%s region=%s endpoint=%s endpoint_resolver=%s disable_ssl=%t use_dual_stack=%t http_proxy_configured=%t https_proxy_configured=%t",
+	logger.Printf("client_config profile_source=%s profile=%s credential_mode=%s region=%s endpoint=%s endpoint_resolver=%s disable_ssl=%t use_dual_stack=%t insecure_skip_verify=%t http_proxy_configured=%t https_proxy_configured=%t proxy_source=%s",
 		info.ProfileSource,
 		info.ProfileName,
 		info.CredentialMode,
@@ -300,13 +376,49 @@ func debugLogClientConfig(ctx *Context, info debugClientConfig) {
 		info.EndpointResolver,
 		info.DisableSSL,
 		info.UseDualStack,
+		info.InsecureSkipVerify,
 		info.HTTPProxyConfigured,
 		info.HTTPSProxyConfigured,
+		info.ProxySource,
 	)
 }
 
-func (s *SdkClient) initClient(svc string, version string) *client.Client {
-	config := s.Session.ClientConfig(svc)
+// state returns the config, session and per-service endpoints the next
+// request is built from.
+func (s *SdkClient) state() sdkClientState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return sdkClientState{config: s.Config, session: s.Session, endpoints: s.ServiceEndpoints}
+}
+
+// serviceEndpoint returns the per-service endpoint configured for svc, which
+// is the SDK service name.
+func (s *SdkClient) serviceEndpoint(svc string) string {
+	return s.state().serviceEndpoint(svc)
+}
+
+// serviceEndpoint returns the per-service endpoint configured for svc, which
+// is the SDK service name. Keys written with the CLI service name are matched
+// through the service mapping.
+func (st sdkClientState) serviceEndpoint(svc string) string {
+	if endpoint := strings.TrimSpace(st.endpoints[svc]); endpoint != "" {
+		return endpoint
+	}
+	for name, endpoint := range st.endpoints {
+		if mapped, ok := GetServiceMapping(name); ok && mapped == svc && strings.TrimSpace(endpoint) != "" {
+			return strings.TrimSpace(endpoint)
+		}
+	}
+	return ""
+}
+
+func (s *SdkClient) initClient(st sdkClientState, svc string, version string, protocol string) *client.Client {
+	var config client.Config
+	if endpoint := st.serviceEndpoint(svc); endpoint != "" {
+		config = st.session.ClientConfig(svc, byteplus.NewConfig().WithEndpoint(endpoint))
+	} else {
+		config = st.session.ClientConfig(svc)
+	}
 	c := client.New(
 		*config.Config,
 		metadata.ClientInfo{
@@ -322,7 +434,17 @@ func (s *SdkClient) initClient(svc string, version string) *client.Client {
 
 	c.Handlers.Build.PushBackNamed(clientVersionAndUserAgentHandler)
 	c.Handlers.Sign.PushBackNamed(byteplussign.SignRequestHandler)
-	c.Handlers.Build.PushBackNamed(byteplusquery.BuildHandler)
+	if s.CanonicalRequestOut != nil {
+		c.Handlers.Sign.PushBackNamed(dumpCanonicalRequestHandler(s.CanonicalRequestOut))
+	}
+	if protocol == protocolJSON {
+		c.Handlers.Build.PushBackNamed(jsonProtocolBuildHandler)
+	} else {
+		c.Handlers.Build.PushBackNamed(byteplusquery.BuildHandler)
+	}
+	if len(s.ExtraHeaders) > 0 {
+		c.Handlers.Build.PushBackNamed(extraHeadersHandler(s.ExtraHeaders))
+	}
 	c.Handlers.Unmarshal.PushBackNamed(byteplusquery.UnmarshalHandler)
 	c.Handlers.UnmarshalMeta.PushBackNamed(byteplusquery.UnmarshalMetaHandler)
 	c.Handlers.UnmarshalError.PushBackNamed(byteplusquery.UnmarshalErrorHandler)
@@ -332,22 +454,123 @@ func (s *SdkClient) initClient(svc string, version string) *client.Client {
 }
 
 func (s *SdkClient) CallSdk(info SdkClientInfo, input interface{}) (output *map[string]interface{}, err error) {
-	c := s.initClient(info.ServiceName, info.Version)
+	output = &map[string]interface{}{}
+	err = s.send(info, input, output, nil)
+	return output, err
+}
+
+// send builds and sends one request, decoding the response into output.
+// configure, when set, adjusts the client handlers before the request is
+// built. Expired SSO credentials are refreshed and the call retried once.
+func (s *SdkClient) send(info SdkClientInfo, input interface{}, output interface{}, configure func(c *client.Client)) error {
+	if input == nil {
+		input = &map[string]interface{}{}
+	}
+	st := s.state()
+	req := s.newRequest(st, info, input, output, configure)
+	err := req.Send()
+	if err != nil && commandContext.Err() == nil && isExpiredCredentialError(err) {
+		fresh, retry, reauthErr := s.reauthAfter(st.session)
+		if reauthErr != nil {
+			return reauthErr
+		}
+		if retry {
+			req = s.newRequest(fresh, info, input, output, configure)
+			err = req.Send()
+		}
+	}
+	if err != nil && commandContext.Err() != nil {
+		// Ctrl-C cancelled the request; return the context error so the
+		// command reports an interrupt instead of a request failure.
+		return commandContext.Err()
+	}
+	return withAttemptCount(err, req.RetryCount+1)
+}
+
+// newRequest builds the request of one call from st.
+func (s *SdkClient) newRequest(st sdkClientState, info SdkClientInfo, input interface{}, output interface{}, configure func(c *client.Client)) *request.Request {
+	c := s.initClient(st, info.ServiceName, info.Version, info.Protocol)
+	if configure != nil {
+		configure(c)
+	}
 	op := &request.Operation{
 		Name:       info.Action,
 		HTTPMethod: strings.ToUpper(info.Method),
 		HTTPPath:   "/",
 	}
-	if input == nil {
-		input = &map[string]interface{}{}
-	}
-	output = &map[string]interface{}{}
 	req := c.NewRequest(op, input, output)
+	req.SetContext(commandContext)
 	if strings.ToLower(info.ContentType) == "application/json" {
 		req.HTTPRequest.Header.Set("Content-Type", "application/json; charset=utf-8")
 	} else if info.ContentType != "" {
 		req.HTTPRequest.Header.Set("Content-Type", info.ContentType)
 	}
-	err = req.Send()
-	return output, err
+	s.printResolvedEndpoint(c, info, st)
+	return req
+}
+
+// reauthAfter handles a call made with the session used whose credentials
+// were rejected as expired. It returns the state to retry with, or retry
+// false when the call must not be retried. Calls that share the client wait
+// for the first of them to re-auth and then retry with its session.
+func (s *SdkClient) reauthAfter(used *session.Session) (st sdkClientState, retry bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Session != used {
+		return sdkClientState{config: s.Config, session: s.Session, endpoints: s.ServiceEndpoints}, true, nil
+	}
+	if s.reauth == nil {
+		return sdkClientState{}, false, s.reauthErr
+	}
+	reauth := s.reauth
+	s.reauth = nil
+	fresh, err := reauth()
+	if err != nil {
+		s.reauthErr = err
+		return sdkClientState{}, false, err
+	}
+	s.Config, s.Session, s.ServiceEndpoints = fresh.Config, fresh.Session, fresh.ServiceEndpoints
+	return sdkClientState{config: s.Config, session: s.Session, endpoints: s.ServiceEndpoints}, true, nil
+}
+
+// printResolvedEndpoint writes the service, region, signing region and
+// endpoint the SDK client resolved for this call, together with where the
+// endpoint came from, so wrong-region or wrong-endpoint problems are visible.
+func (s *SdkClient) printResolvedEndpoint(c *client.Client, info SdkClientInfo, st sdkClientState) {
+	if s.VerboseOut == nil {
+		return
+	}
+	source := "resolver"
+	if st.serviceEndpoint(info.ServiceName) != "" {
+		source = "service-endpoint"
+	} else if byteplus.StringValue(st.config.Endpoint) != "" {
+		source = "endpoint"
+	}
+	fmt.Fprintf(s.VerboseOut, "[verbose] service=%s action=%s version=%s region=%s signing_region=%s endpoint=%s endpoint_source=%s\n",
+		c.ClientInfo.ServiceName,
+		info.Action,
+		c.ClientInfo.APIVersion,
+		byteplus.StringValue(c.Config.Region),
+		c.ClientInfo.SigningRegion,
+		c.ClientInfo.Endpoint,
+		source,
+	)
+}
+
+// supportedEndpointResolversMessage lists the accepted endpoint-resolver values.
+const supportedEndpointResolversMessage = "standard, auto, auto-addressing"
+
+// useStandardEndpointResolver reports whether an endpoint-resolver value
+// selects the SDK standard resolver, which derives the endpoint from the
+// service and region of each call. An empty value keeps the static endpoint;
+// unknown values are rejected instead of being ignored.
+func useStandardEndpointResolver(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "":
+		return false, nil
+	case "standard", "auto", "auto-addressing":
+		return true, nil
+	default:
+		return false, fmt.Errorf("endpoint-resolver %q is not supported, supported values: %s", value, supportedEndpointResolversMessage)
+	}
 }
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/byteplus-sdk/byteplus-go-sdk-v2/byteplus/bytepluserr"
+)
+
+// expiredCredentialErrorCodes are API error codes returned when temporary
+// credentials are no longer accepted by the server.
+var expiredCredentialErrorCodes = map[string]struct{}{
+	"InvalidSecurityToken": {},
+	"ExpiredSecurityToken": {},
+	"SecurityTokenExpired": {},
+	"InvalidSessionToken":  {},
+	"ExpiredToken":         {},
+}
+
+// isExpiredCredentialError reports whether err is an API error saying the
+// request credentials have expired.
+func isExpiredCredentialError(err error) bool {
+	var apiErr bytepluserr.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	if _, ok := expiredCredentialErrorCodes[apiErr.Code()]; ok {
+		return true
+	}
+	code := strings.ToLower(apiErr.Code())
+	return strings.Contains(code, "expired") && (strings.Contains(code, "token") || strings.Contains(code, "credential"))
+}
+
+// ssoReauth returns the function CallSdk uses to recover from an expired
+// credentials error with an SSO profile: it forces an STS refresh and builds
+// a new client from the refreshed profile.
+func ssoReauth(ctx *Context, profile *Profile) func() (*SdkClient, error) {
+	return func() (*SdkClient, error) {
+		sso := &Sso{
+			Profile:        profile,
+			SsoSessionName: profile.SsoSessionName,
+		}
+		if err := sso.RefreshExpiredStsToken(ctx); err != nil {
+			return nil, fmt.Errorf("the SSO credentials of profile %s expired and could not be refreshed, please run 'bp sso login --sso-session %s': %w",
+				profile.Name, profile.SsoSessionName, err)
+		}
+		return NewSimpleClient(ctx)
+	}
+}
@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/byteplus-sdk/byteplus-go-sdk-v2/byteplus/bytepluserr"
+)
+
+func TestIsExpiredCredentialError(t *testing.T) {
+	for code, want := range map[string]bool{
+		"InvalidSecurityToken":   true,
+		"AccessKey.TokenExpired": true,
+		"InvalidParameter":       false,
+		"OrderExpired":           false,
+	} {
+		err := bytepluserr.NewRequestFailure(bytepluserr.New(code, "message", nil), http.StatusUnauthorized, "req")
+		if got := isExpiredCredentialError(err); got != want {
+			t.Errorf("isExpiredCredentialError(%s) = %v, want %v", code, got, want)
+		}
+	}
+	if isExpiredCredentialError(errors.New("InvalidSecurityToken")) {
+		t.Error("isExpiredCredentialError() = true for a non-API error")
+	}
+}
+
+func ssoRetryTestContext(t *testing.T, sso *Sso, endpoint string) *Context {
+	t.Helper()
+	dir := withTestConfigDir(t)
+	// SDK 的 CliProvider 从 BYTEPLUS_CLI_CONFIG_FILE 读取凭证，指向测试目录以免读到用户的配置
+	t.Cleanup(setenvForTest(t, "BYTEPLUS_CLI_CONFIG_FILE", filepath.Join(dir, ConfigFile)))
+	cacheTokenForTest(t, sso, &SsoTokenCache{
+		AccessToken:           "cached-access",
+		ExpiresAt:             time.Now().Add(time.Hour).Format(time.RFC3339),
+		ClientId:              "cached-client",
+		ClientSecret:          "cached-secret",
+		ClientSecretExpiresAt: validClientSecretExpiry(),
+	})
+	cfg := &Configure{
+		Current: "sso-dev",
+		Profiles: map[string]*Profile{
+			"sso-dev": {
+				Name:           "sso-dev",
+				Mode:           ModeSSO,
+				SsoSessionName: "test-session",
+				AccountId:      "account-id",
+				RoleName:       "role-name",
+				Region:         "ap-southeast-1",
+				Endpoint:       endpoint,
+				AccessKey:      "old-ak",
+				SecretKey:      "old-sk",
+				SessionToken:   "old-token",
+				StsExpiration:  time.Now().Add(time.Hour).Unix(),
+			},
+		},
+		SsoSession: map[string]*SsoSession{
+			"test-session": {Name: "test-session", StartURL: sso.StartURL, Region: sso.Region},
+		},
+	}
+	if err := WriteConfigToFile(cfg); err != nil {
+		t.Fatalf("WriteConfigToFile returned error: %v", err)
+	}
+	testCtx := NewContext()
+	testCtx.SetConfig(cfg)
+	return testCtx
+}
+
+func TestCallSdkRefreshesExpiredSsoCredentialsOnce(t *testing.T) {
+	defer disableProxyEnvForTest(t)()
+	sso := setupSsoTokenTest(t)
+	fakePortal := &fakePortalClient{}
+	newPortalClientForSSO = func(region string) PortalClientAPI { return fakePortal }
+
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		if calls == 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"ResponseMetadata":{"RequestId":"req-1","Error":{"Code":"InvalidSecurityToken","Message":"token expired"}}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ResponseMetadata":{"RequestId":"req-2"},"Result":{"Ok":true}}`))
+	}))
+	defer server.Close()
+
+	testCtx := ssoRetryTestContext(t, sso, server.URL)
+	sdk, err := NewSimpleClient(testCtx)
+	if err != nil {
+		t.Fatalf("NewSimpleClient returned error: %v", err)
+	}
+	out, err := sdk.CallSdk(SdkClientInfo{ServiceName: "ecs", Action: "DescribeInstances", Version: "2020-04-01", Method: "GET"}, nil)
+	if err != nil {
+		t.Fatalf("CallSdk returned error: %v", err)
+	}
+	if calls != 2 || fakePortal.credentialCalls != 1 {
+		t.Fatalf("server calls = %d, portal calls = %d, want 2 and 1", calls, fakePortal.credentialCalls)
+	}
+	if result, _ := (*out)["Result"].(map[string]interface{}); result["Ok"] != true {
+		t.Fatalf("CallSdk output = %#v", *out)
+	}
+	if token := testCtx.config.Profiles["sso-dev"].SessionToken; token != "session-token" {
+		t.Fatalf("profile session token = %q, want the refreshed token", token)
+	}
+}
+
+func TestCallSdkReportsSsoLoginWhenRefreshFails(t *testing.T) {
+	defer disableProxyEnvForTest(t)()
+	sso := setupSsoTokenTest(t)
+	newPortalClientForSSO = func(region string) PortalClientAPI {
+		return &fakePortalClient{err: errors.New("access token revoked")}
+	}
+
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"ResponseMetadata":{"RequestId":"req","Error":{"Code":"InvalidSecurityToken","Message":"token expired"}}}`))
+	}))
+	defer server.Close()
+
+	sdk, err := NewSimpleClient(ssoRetryTestContext(t, sso, server.URL))
+	if err != nil {
+		t.Fatalf("NewSimpleClient returned error: %v", err)
+	}
+	_, err = sdk.CallSdk(SdkClientInfo{ServiceName: "ecs", Action: "DescribeInstances", Version: "2020-04-01", Method: "GET"}, nil)
+	if err == nil || !strings.Contains(err.Error(), "bp sso login --sso-session test-session") {
+		t.Fatalf("CallSdk error = %v, want sso login guidance", err)
+	}
+	if calls != 1 {
+		t.Fatalf("server calls = %d, want 1", calls)
+	}
+}
+
+// 与 bp batch --concurrency 一样由两个 worker 共用一个 client，二者同时遇到凭证过期时只重新授权一次。
+func TestCallSdkSharedClientReauthsOnceForConcurrentCalls(t *testing.T) {
+	defer disableProxyEnvForTest(t)()
+	sso := setupSsoTokenTest(t)
+	fakePortal := &fakePortalClient{}
+	newPortalClientForSSO = func(region string) PortalClientAPI { return fakePortal }
+
+	const workers = 2
+	var expired int32
+	bothExpired := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Header.Get("X-Security-Token") == "old-token" {
+			// 等两个 worker 都用旧凭证发出请求后再返回过期，确保二者同时进入重新授权
+			if atomic.AddInt32(&expired, 1) == workers {
+				close(bothExpired)
+			}
+			select {
+			case <-bothExpired:
+			case <-time.After(5 * time.Second):
+			}
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"ResponseMetadata":{"RequestId":"req-1","Error":{"Code":"InvalidSecurityToken","Message":"token expired"}}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ResponseMetadata":{"RequestId":"req-2"},"Result":{"Ok":true}}`))
+	}))
+	defer server.Close()
+
+	sdk, err := NewSimpleClient(ssoRetryTestContext(t, sso, server.URL))
+	if err != nil {
+		t.Fatalf("NewSimpleClient returned error: %v", err)
+	}
+	var wg sync.WaitGroup
+	errs := make([]error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = sdk.CallSdk(SdkClientInfo{ServiceName: "ecs", Action: "DescribeInstances", Version: "2020-04-01", Method: "GET"}, nil)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("worker %d: CallSdk returned error: %v", i, err)
+		}
+	}
+	if got := atomic.LoadInt32(&expired); got != workers || fakePortal.credentialCalls != 1 {
+		t.Fatalf("expired calls = %d, portal calls = %d, want %d and 1", got, fakePortal.credentialCalls, workers)
+	}
+}
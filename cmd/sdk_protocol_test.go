@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestApplyProtocolOverrideForcesJSON(t *testing.T) {
+	testCtx := NewContext()
+	f, _ := testCtx.fixedFlags.AddByName("protocol")
+	f.SetValue("JSON")
+
+	info := SdkClientInfo{Method: "GET", Protocol: protocolQuery}
+	if err := applyProtocolOverride(testCtx, &info); err != nil {
+		t.Fatalf("applyProtocolOverride() error = %v", err)
+	}
+	if info.Protocol != protocolJSON || info.ContentType != "application/json" || info.Method != "POST" {
+		t.Fatalf("info = %+v, want json protocol sent as POST application/json", info)
+	}
+}
+
+func TestApplyProtocolOverrideRejectsUnknownProtocol(t *testing.T) {
+	testCtx := NewContext()
+	f, _ := testCtx.fixedFlags.AddByName("protocol")
+	f.SetValue("xml")
+
+	err := applyProtocolOverride(testCtx, &SdkClientInfo{})
+	if err == nil || !strings.Contains(err.Error(), "supported values: query, json") {
+		t.Fatalf("applyProtocolOverride() error = %v, want unsupported protocol error", err)
+	}
+}
+
+func TestDetectProtocolUsesMetadata(t *testing.T) {
+	if got := detectProtocol(&ApiInfo{Protocol: "json"}); got != protocolJSON {
+		t.Fatalf("detectProtocol(Protocol=json) = %q, want json", got)
+	}
+	if got := detectProtocol(&ApiInfo{ContentType: "application/json"}); got != protocolQuery {
+		t.Fatalf("detectProtocol(ContentType=application/json) = %q, want query", got)
+	}
+	if got := detectProtocol(nil); got != protocolQuery {
+		t.Fatalf("detectProtocol(nil) = %q, want query", got)
+	}
+}
+
+func TestCallSdkJSONProtocolSendsBody(t *testing.T) {
+	defer disableProxyEnvForTest(t)()
+
+	var (
+		gotQuery       string
+		gotContentType string
+		gotBody        map[string]interface{}
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.RawQuery
+		gotContentType = r.Header.Get("Content-Type")
+		b, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(b, &gotBody)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ResponseMetadata":{"RequestId":"req-json"},"Result":{}}`))
+	}))
+	defer server.Close()
+
+	defer setenvForTest(t, "BYTEPLUS_ACCESS_KEY", "ak-test")()
+	defer setenvForTest(t, "BYTEPLUS_SECRET_KEY", "sk-test")()
+	defer setenvForTest(t, "BYTEPLUS_REGION", "ap-southeast-1")()
+
+	testCtx := NewContext()
+	endpointFlag, _ := testCtx.fixedFlags.AddByName("endpoint")
+	endpointFlag.SetValue(server.URL)
+	sdk, err := NewSimpleClient(testCtx)
+	if err != nil {
+		t.Fatalf("NewSimpleClient returned error: %v", err)
+	}
+
+	input := map[string]interface{}{"Filter": map[string]interface{}{"Name": "web"}}
+	if _, err := sdk.CallSdk(SdkClientInfo{
+		ServiceName: "demo",
+		Action:      "ListWidgets",
+		Version:     "2024-01-01",
+		Method:      "POST",
+		Protocol:    protocolJSON,
+	}, &input); err != nil {
+		t.Fatalf("CallSdk returned error: %v", err)
+	}
+
+	if !strings.Contains(gotQuery, "Action=ListWidgets") || !strings.Contains(gotQuery, "Version=2024-01-01") {
+		t.Fatalf("query = %q, want Action and Version", gotQuery)
+	}
+	if strings.Contains(gotQuery, "Filter") {
+		t.Fatalf("query = %q, want input only in the body", gotQuery)
+	}
+	if !strings.HasPrefix(gotContentType, "application/json") {
+		t.Fatalf("Content-Type = %q, want application/json", gotContentType)
+	}
+	filter, _ := gotBody["Filter"].(map[string]interface{})
+	if filter["Name"] != "web" {
+		t.Fatalf("body = %v, want nested Filter.Name", gotBody)
+	}
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/byteplus-sdk/byteplus-go-sdk-v2/byteplus/request"
+)
+
+// canonicalRequestOutput 是 ---dump-canonical-request 的输出目标，测试中替换。
+var canonicalRequestOutput io.Writer = os.Stderr
+
+// signedAuthorizationPattern 解析 byteplussign 写入的 Authorization header，取出 Credential、SignedHeaders 与 Signature。
+var signedAuthorizationPattern = regexp.MustCompile(`^HMAC-SHA256 Credential=([^,]+), SignedHeaders=([^,]+), Signature=([0-9a-fA-F]*)`)
+
+// dumpCanonicalRequestHandler 在 byteplussign.SignRequestHandler 之后执行，按签名后的请求重建
+// canonical request 与 string to sign 并写到 out。byteplussign 不对外提供它生成的 canonical request，
+// 因此用当前凭证的 secret key 重算签名，与 Authorization 中的 Signature 不一致时输出警告。
+// 每次重试都会重新签名，因此每次尝试各输出一份。
+func dumpCanonicalRequestHandler(out io.Writer) request.NamedHandler {
+	return request.NamedHandler{
+		Name: "byteplus-cli.debug.canonical-request",
+		Fn: func(r *request.Request) {
+			if r.Error != nil || r.HTTPRequest == nil {
+				return
+			}
+			var secretKey string
+			if r.Config.Credentials != nil {
+				if v, err := r.Config.Credentials.Get(); err == nil {
+					secretKey = v.SecretAccessKey
+				}
+			}
+			writeCanonicalRequest(out, debugRequestService(r), debugRequestAction(r), r.HTTPRequest, secretKey)
+		},
+	}
+}
+
+// writeCanonicalRequest 输出 req 的 canonical request 与 string to sign。secretKey 非空时用它派生签名密钥，
+// 校验重建结果能否得到 Authorization 中的 Signature；secret key 与 Signature 都不输出，
+// access key 只显示末尾 4 位，x-security-token 的取值被隐去。
+func writeCanonicalRequest(out io.Writer, service, action string, req *http.Request, secretKey string) {
+	m := signedAuthorizationPattern.FindStringSubmatch(req.Header.Get("Authorization"))
+	if m == nil {
+		fmt.Fprintf(out, "[canonical-request] service=%s action=%s: the request has no HMAC-SHA256 Authorization header\n", service, action)
+		return
+	}
+	accessKey, scope := m[1], ""
+	if i := strings.Index(m[1], "/"); i >= 0 {
+		accessKey, scope = m[1][:i], m[1][i+1:]
+	}
+	signedHeaders := strings.Split(m[2], ";")
+	canonical := canonicalRequest(req, signedHeaders, false)
+	hash := sha256.Sum256([]byte(canonical))
+	stringToSign := strings.Join([]string{"HMAC-SHA256", req.Header.Get("X-Date"), scope, hex.EncodeToString(hash[:])}, "\n")
+
+	fmt.Fprintf(out, "[canonical-request] service=%s action=%s credential=%s/%s\n",
+		service, action, displayProfileField("access-key", accessKey, false), scope)
+	fmt.Fprintf(out, "---- canonical request ----\n%s\n---- string to sign ----\n%s\n---- end ----\n",
+		canonicalRequest(req, signedHeaders, true), stringToSign)
+	if secretKey != "" && !strings.EqualFold(requestSignature(secretKey, scope, stringToSign), m[3]) {
+		fmt.Fprintf(out, "Warning: the canonical request above does not reproduce the request signature; the signer may have used a different canonical request\n")
+	}
+}
+
+// requestSignature 按 scope（date/region/service/request）逐段 HMAC 派生签名密钥，返回 stringToSign 的十六进制签名。
+func requestSignature(secretKey, scope, stringToSign string) string {
+	key := []byte(secretKey)
+	for _, part := range strings.Split(scope, "/") {
+		key = hmacSHA256(key, part)
+	}
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// canonicalRequest 按 HMAC-SHA256 签名规则拼出 canonical request：方法、逐段转义的路径、
+// 按 key 排序且空格编码为 %20 的 query、SignedHeaders 中各 header 的取值、SignedHeaders 与 body 的 SHA256。
+// redact 为 true 时 x-security-token 以及 isSensitiveDebugKey 判定为敏感的 query 参数取值替换为 <redacted>，仅用于输出。
+func canonicalRequest(req *http.Request, signedHeaders []string, redact bool) string {
+	path := req.URL.Path
+	if path == "" {
+		path = "/"
+	}
+	var headers strings.Builder
+	for _, name := range signedHeaders {
+		value := canonicalHeaderValue(req, name)
+		if redact && name == "x-security-token" && value != "" {
+			value = "<redacted>"
+		}
+		headers.WriteString(name + ":" + value + "\n")
+	}
+	query := req.URL.Query()
+	if redact {
+		for key, values := range query {
+			if isSensitiveDebugKey(key) {
+				for i := range values {
+					values[i] = "<redacted>"
+				}
+			}
+		}
+	}
+	return strings.Join([]string{
+		req.Method,
+		canonicalURI(path),
+		strings.Replace(query.Encode(), "+", "%20", -1),
+		headers.String(),
+		strings.Join(signedHeaders, ";"),
+		req.Header.Get("X-Content-Sha256"),
+	}, "\n")
+}
+
+// canonicalHeaderValue 返回参与签名的 header 取值；host 取请求的 Host，默认端口 80、443 不计入。
+func canonicalHeaderValue(req *http.Request, name string) string {
+	if name != "host" {
+		return strings.TrimSpace(req.Header.Get(name))
+	}
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	if h, port, err := net.SplitHostPort(host); err == nil && (port == "80" || port == "443") {
+		host = h
+	}
+	return host
+}
+
+// canonicalURI 逐段转义路径，只保留字母、数字与 "-_.~"。
+func canonicalURI(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		var b strings.Builder
+		for j := 0; j < len(segment); j++ {
+			c := segment[j]
+			if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte("-_.~", c) >= 0 {
+				b.WriteByte(c)
+			} else {
+				fmt.Fprintf(&b, "%%%02X", c)
+			}
+		}
+		segments[i] = b.String()
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestDumpCanonicalRequestMatchesSignedRequest(t *testing.T) {
+	defer disableProxyEnvForTest(t)()
+
+	var auth, xDate, method string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, xDate, method = r.Header.Get("Authorization"), r.Header.Get("X-Date"), r.Method
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ResponseMetadata":{"RequestId":"req"},"Result":{}}`))
+	}))
+	defer api.Close()
+
+	defer setenvForTest(t, "BYTEPLUS_ACCESS_KEY", "AKLTtestaccesskey1234")()
+	defer setenvForTest(t, "BYTEPLUS_SECRET_KEY", "sk-test")()
+	defer setenvForTest(t, "BYTEPLUS_REGION", "ap-southeast-1")()
+	defer setenvForTest(t, "BYTEPLUS_ENDPOINT", api.URL)()
+
+	var out, dump bytes.Buffer
+	prevWriter, prevDump := actionOutputWriter, canonicalRequestOutput
+	actionOutputWriter, canonicalRequestOutput = &out, &dump
+	defer func() { actionOutputWriter, canonicalRequestOutput = prevWriter, prevDump }()
+
+	testCtx := NewContext()
+	testCtx.SetConfig(&Configure{Profiles: map[string]*Profile{}})
+	if _, err := NewParser([]string{"---dump-canonical-request"}).ReadArgs(testCtx); err != nil {
+		t.Fatalf("ReadArgs() error = %v", err)
+	}
+	if err := doAction(testCtx, "ecs", "DescribeInstances"); err != nil {
+		t.Fatalf("doAction() error = %v", err)
+	}
+
+	m := signedAuthorizationPattern.FindStringSubmatch(auth)
+	if m == nil {
+		t.Fatalf("Authorization = %q, want an HMAC-SHA256 signature", auth)
+	}
+	scope := m[1][strings.Index(m[1], "/")+1:]
+	got := dump.String()
+	for _, want := range []string{
+		"action=DescribeInstances credential=****1234/" + scope,
+		"---- canonical request ----\n" + method + "\n/\n",
+		"x-date:" + xDate + "\n",
+		"\n" + m[2] + "\n",
+		"HMAC-SHA256\n" + xDate + "\n" + scope + "\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("dump does not contain %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "AKLTtestaccesskey1234") || strings.Contains(got, "sk-test") || strings.Contains(got, m[3]) {
+		t.Fatalf("dump leaks credentials:\n%s", got)
+	}
+	if strings.Contains(got, "Warning:") {
+		t.Fatalf("dump does not reproduce the signature of the signed request:\n%s", got)
+	}
+}
+
+func TestDumpCanonicalRequestWarnsOnSignatureMismatch(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "https://open.byteplusapi.com/?Action=DescribeInstances", nil)
+	req.Header.Set("X-Date", "20240101T000000Z")
+	req.Header.Set("X-Content-Sha256", "hash")
+	scope := "20240101/ap-southeast-1/ecs/request"
+	signed := []string{"host", "x-content-sha256", "x-date"}
+	hash := sha256.Sum256([]byte(canonicalRequest(req, signed, false)))
+	stringToSign := "HMAC-SHA256\n20240101T000000Z\n" + scope + "\n" + hex.EncodeToString(hash[:])
+	signature := requestSignature("sk-test", scope, stringToSign)
+
+	for _, c := range []struct {
+		signature string
+		warn      bool
+	}{
+		{signature, false},
+		{strings.Repeat("0", len(signature)), true},
+	} {
+		req.Header.Set("Authorization", "HMAC-SHA256 Credential=AKLTtest/"+scope+", SignedHeaders="+strings.Join(signed, ";")+", Signature="+c.signature)
+		var dump bytes.Buffer
+		writeCanonicalRequest(&dump, "ecs", "DescribeInstances", req, "sk-test")
+		if got := strings.Contains(dump.String(), "does not reproduce the request signature"); got != c.warn {
+			t.Fatalf("signature %s: warning = %v, want %v:\n%s", c.signature, got, c.warn, dump.String())
+		}
+	}
+}
+
+func TestCanonicalRequestEncodesPathQueryAndRedactsToken(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodPost, "https://open.byteplusapi.com:443/a b/c", nil)
+	req.URL.RawQuery = url.Values{"Name": {"x y"}, "Action": {"Run"}}.Encode()
+	req.Header.Set("X-Security-Token", "secret-token")
+	req.Header.Set("X-Content-Sha256", "hash")
+	signed := []string{"host", "x-security-token"}
+
+	want := "POST\n/a%20b/c\nAction=Run&Name=x%20y\nhost:open.byteplusapi.com\nx-security-token:secret-token\n\nhost;x-security-token\nhash"
+	if got := canonicalRequest(req, signed, false); got != want {
+		t.Fatalf("canonicalRequest() = %q, want %q", got, want)
+	}
+	if got := canonicalRequest(req, signed, true); strings.Contains(got, "secret-token") || !strings.Contains(got, "x-security-token:<redacted>") {
+		t.Fatalf("redacted canonicalRequest() = %q", got)
+	}
+}
+
+func TestCanonicalRequestRedactsSensitiveQueryValues(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "https://open.byteplusapi.com/", nil)
+	req.URL.RawQuery = url.Values{"Action": {"CreateUser"}, "Password": {"p@ss w0rd"}, "SecretKey": {"sk-real"}, "UserName": {"alice"}}.Encode()
+	req.Header.Set("X-Content-Sha256", "hash")
+	signed := []string{"host"}
+
+	if got := canonicalRequest(req, signed, false); !strings.Contains(got, "Password=p%40ss%20w0rd&SecretKey=sk-real") {
+		t.Fatalf("canonicalRequest() = %q, want the real query values", got)
+	}
+	got := canonicalRequest(req, signed, true)
+	want := "Action=CreateUser&Password=%3Credacted%3E&SecretKey=%3Credacted%3E&UserName=alice"
+	if !strings.Contains(got, "\n"+want+"\n") {
+		t.Fatalf("redacted canonicalRequest() = %q, want query %q", got, want)
+	}
+}
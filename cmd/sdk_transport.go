@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultMaxIdleConnsPerHost 高于标准库的 2：bp batch 与 ---all-regions 会并发调用同一 endpoint，
+// 空闲连接过少时多出的连接用完即关，后续调用需要重新建立 TLS 连接。
+const defaultMaxIdleConnsPerHost = 32
+
+// transportSettings 是 API 调用使用的连接池设置，0 表示使用默认值。
+type transportSettings struct {
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost 限制同一 host 的连接总数，0 表示不限制。
+	MaxConnsPerHost int
+	// ConnectTimeout 与 TLSHandshakeTimeout 只限制建立连接的阶段，读取响应体不受影响，0 表示使用默认值。
+	ConnectTimeout      time.Duration
+	TLSHandshakeTimeout time.Duration
+	// Proxy 替换 Transport 默认的 http.ProxyFromEnvironment，nil 表示不替换。
+	Proxy func(*http.Request) (*url.URL, error)
+}
+
+// 建立连接的默认超时，与 http.DefaultTransport 一致。
+const (
+	defaultConnectTimeout      = 30 * time.Second
+	defaultTLSHandshakeTimeout = 10 * time.Second
+)
+
+// connectTimeoutFlag 与 tlsHandshakeTimeoutFlag 由全局 --connect-timeout、--tls-handshake-timeout 设置。
+var (
+	connectTimeoutFlag      string
+	tlsHandshakeTimeoutFlag string
+)
+
+// 建立连接超时的环境变量，flag 优先。
+const (
+	connectTimeoutEnv      = "BYTEPLUS_CONNECT_TIMEOUT"
+	tlsHandshakeTimeoutEnv = "BYTEPLUS_TLS_HANDSHAKE_TIMEOUT"
+)
+
+// resolveConnectTimeouts 读取本次调用的建立连接超时：---flag > --flag > 环境变量；未设置的保持为 0。
+func resolveConnectTimeouts(ctx *Context) (connect, tlsHandshake time.Duration, err error) {
+	if connect, err = resolveTimeoutSetting(ctx, "connect-timeout", connectTimeoutFlag, connectTimeoutEnv); err != nil {
+		return 0, 0, err
+	}
+	if tlsHandshake, err = resolveTimeoutSetting(ctx, "tls-handshake-timeout", tlsHandshakeTimeoutFlag, tlsHandshakeTimeoutEnv); err != nil {
+		return 0, 0, err
+	}
+	return connect, tlsHandshake, nil
+}
+
+func resolveTimeoutSetting(ctx *Context, name, flagValue, env string) (time.Duration, error) {
+	value, from := flagValue, "--"+name
+	if ctx != nil && ctx.fixedFlags != nil {
+		if f := ctx.fixedFlags.GetByName(name); f != nil {
+			value, from = f.GetValue(), "---"+name
+		}
+	}
+	if strings.TrimSpace(value) == "" {
+		value, from = os.Getenv(env), env
+	}
+	if strings.TrimSpace(value) == "" {
+		return 0, nil
+	}
+	d, err := parseTimeout(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s %q is invalid: %v", from, value, err)
+	}
+	return d, nil
+}
+
+// parseTimeout 接受 Go duration（如 500ms、5s）或不带单位的秒数，必须大于 0。
+func parseTimeout(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		seconds, numErr := strconv.ParseFloat(value, 64)
+		if numErr != nil {
+			return 0, fmt.Errorf("expected a duration such as 5s or a number of seconds")
+		}
+		d = time.Duration(seconds * float64(time.Second))
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("must be greater than 0")
+	}
+	return d, nil
+}
+
+// newHTTPTransport 基于 http.DefaultTransport 复制一个 Transport，按 settings 设置建立连接与 TLS 握手的超时。
+func newHTTPTransport(settings transportSettings) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	connect := settings.ConnectTimeout
+	if connect <= 0 {
+		connect = defaultConnectTimeout
+	}
+	dialer := &net.Dialer{Timeout: connect, KeepAlive: 30 * time.Second}
+	transport.DialContext = dialer.DialContext
+	transport.TLSHandshakeTimeout = defaultTLSHandshakeTimeout
+	if settings.TLSHandshakeTimeout > 0 {
+		transport.TLSHandshakeTimeout = settings.TLSHandshakeTimeout
+	}
+	if settings.Proxy != nil {
+		transport.Proxy = settings.Proxy
+	}
+	return transport
+}
+
+// identityHTTPClient 返回 OAuth 与 Portal 客户端默认使用的 HTTP client：timeout 限制整个请求，
+// 建立连接的超时取自 --connect-timeout、--tls-handshake-timeout 或对应环境变量，
+// --header 与 ---header 指定的 header 加到每个请求上。
+func identityHTTPClient(timeout time.Duration) (*http.Client, error) {
+	connect, tlsHandshake, err := resolveConnectTimeouts(ctx)
+	transport := newHTTPTransport(transportSettings{ConnectTimeout: connect, TLSHandshakeTimeout: tlsHandshake})
+	client := &http.Client{Timeout: timeout, Transport: transport}
+	if err != nil {
+		return client, err
+	}
+	headers, err := resolveExtraHeaders(ctx)
+	if len(headers) > 0 {
+		client.Transport = &headerTransport{base: transport, headers: headers}
+	}
+	return client, err
+}
+
+// transportSettingsFromConfig 读取配置文件顶层的 max-idle-conns-per-host 与 max-conns-per-host。
+func transportSettingsFromConfig(cfg *Configure) transportSettings {
+	if cfg == nil {
+		return transportSettings{}
+	}
+	return transportSettings{
+		MaxIdleConnsPerHost: cfg.MaxIdleConnsPerHost,
+		MaxConnsPerHost:     cfg.MaxConnsPerHost,
+	}
+}
+
+// sdkHTTPClient 返回 SDK 使用的 HTTP client。Transport 由 newHTTPTransport 创建，
+// 每个 SdkClient 独占一个，SDK 设置代理时不会影响其他 client。
+// insecure 为 true 时不校验服务端证书，仅用于 insecure-skip-verify。
+func sdkHTTPClient(settings transportSettings, insecure bool) *http.Client {
+	transport := newHTTPTransport(settings)
+	perHost := settings.MaxIdleConnsPerHost
+	if perHost <= 0 {
+		perHost = defaultMaxIdleConnsPerHost
+	}
+	if settings.MaxConnsPerHost > 0 && perHost > settings.MaxConnsPerHost {
+		perHost = settings.MaxConnsPerHost
+	}
+	transport.MaxIdleConnsPerHost = perHost
+	if transport.MaxIdleConns < perHost {
+		transport.MaxIdleConns = perHost
+	}
+	if settings.MaxConnsPerHost > 0 {
+		transport.MaxConnsPerHost = settings.MaxConnsPerHost
+	}
+	if insecure {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	return &http.Client{Transport: transport}
+}
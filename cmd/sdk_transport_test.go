@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResolveConnectTimeoutsPrefersFlagsOverEnv(t *testing.T) {
+	withTestCtxConfig(t, &Configure{})
+	prevConnect, prevTLS := connectTimeoutFlag, tlsHandshakeTimeoutFlag
+	t.Cleanup(func() { connectTimeoutFlag, tlsHandshakeTimeoutFlag = prevConnect, prevTLS })
+	connectTimeoutFlag, tlsHandshakeTimeoutFlag = "", ""
+	t.Setenv(connectTimeoutEnv, "7")
+	t.Setenv(tlsHandshakeTimeoutEnv, "")
+
+	connect, tlsHandshake, err := resolveConnectTimeouts(ctx)
+	if err != nil || connect != 7*time.Second || tlsHandshake != 0 {
+		t.Fatalf("from env = %v / %v / %v, want 7s / 0 / nil", connect, tlsHandshake, err)
+	}
+
+	connectTimeoutFlag = "2s"
+	f, _ := ctx.fixedFlags.AddByName("tls-handshake-timeout")
+	f.SetValue("500ms")
+	connect, tlsHandshake, err = resolveConnectTimeouts(ctx)
+	if err != nil || connect != 2*time.Second || tlsHandshake != 500*time.Millisecond {
+		t.Fatalf("from flags = %v / %v / %v, want 2s / 500ms / nil", connect, tlsHandshake, err)
+	}
+
+	f.SetValue("0")
+	if _, _, err := resolveConnectTimeouts(ctx); err == nil || !strings.Contains(err.Error(), "---tls-handshake-timeout") {
+		t.Fatalf("error = %v, want an invalid ---tls-handshake-timeout", err)
+	}
+}
+
+func TestIdentityHTTPClientKeepsRequestTimeoutAndSetsHandshakeTimeout(t *testing.T) {
+	withTestCtxConfig(t, &Configure{})
+	t.Setenv(connectTimeoutEnv, "")
+	t.Setenv(tlsHandshakeTimeoutEnv, "3s")
+
+	client, err := identityHTTPClient(defaultPortalTimeout)
+	if err != nil {
+		t.Fatalf("identityHTTPClient returned error: %v", err)
+	}
+	if client.Timeout != defaultPortalTimeout {
+		t.Fatalf("client timeout = %v, want %v", client.Timeout, defaultPortalTimeout)
+	}
+	transport := client.Transport.(*http.Transport)
+	if transport.TLSHandshakeTimeout != 3*time.Second || transport.DialContext == nil {
+		t.Fatalf("transport handshake timeout = %v, dial set = %v", transport.TLSHandshakeTimeout, transport.DialContext != nil)
+	}
+}
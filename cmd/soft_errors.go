@@ -0,0 +1,153 @@
+/*
+ * // Copyright (c) 2024 Bytedance Ltd. and/or its affiliates
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at
+ * //
+ * //	http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// defaultSoftErrorPaths 为常见的部分失败字段：批量接口在 HTTP 200 时把失败条目放在这些位置。
+// 路径以 "." 分隔，"[]" 表示遍历数组中的每个元素。
+var defaultSoftErrorPaths = []string{
+	"Result.Errors",
+	"Result.FailedItems",
+	"Result.FailedList",
+	"Result.Failures",
+	"Result.OperationDetails[].Error",
+}
+
+// softErrorWarningOut 为部分失败提示的输出目标。
+var softErrorWarningOut io.Writer = os.Stderr
+
+// softErrorPaths 返回当前配置使用的检测路径，配置了 soft-error-paths 时替换默认值。
+func softErrorPaths(ctx *Context) []string {
+	if ctx.config != nil && len(ctx.config.SoftErrorPaths) > 0 {
+		return ctx.config.SoftErrorPaths
+	}
+	return defaultSoftErrorPaths
+}
+
+// failOnPartialRequested 判断本次调用是否指定了 ---fail-on-partial。
+func failOnPartialRequested(ctx *Context) bool {
+	f := ctx.fixedFlags.GetByName("fail-on-partial")
+	return f != nil && f.GetValue() == "true"
+}
+
+// detectSoftErrors 按 paths 查找响应中非空的错误字段，返回 "路径: 值" 形式的描述。
+func detectSoftErrors(page map[string]interface{}, paths []string) []string {
+	var found []string
+	for _, path := range paths {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		found = appendSoftErrors(found, page, "", strings.Split(path, "."))
+	}
+	return found
+}
+
+func appendSoftErrors(found []string, value interface{}, prefix string, segments []string) []string {
+	if len(segments) == 0 {
+		if isEmptySoftErrorValue(value) {
+			return found
+		}
+		return append(found, prefix+": "+softErrorValueString(value))
+	}
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return found
+	}
+	segment := segments[0]
+	key := strings.TrimSuffix(segment, "[]")
+	next, ok := m[key]
+	if !ok {
+		return found
+	}
+	name := key
+	if prefix != "" {
+		name = prefix + "." + key
+	}
+	if key == segment {
+		return appendSoftErrors(found, next, name, segments[1:])
+	}
+	items, ok := next.([]interface{})
+	if !ok {
+		return found
+	}
+	for i, item := range items {
+		found = appendSoftErrors(found, item, fmt.Sprintf("%s[%d]", name, i), segments[1:])
+	}
+	return found
+}
+
+func isEmptySoftErrorValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(v) == ""
+	case bool:
+		return !v
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// softErrorValueString 把错误值压缩为单行：带 Code/Message 的错误只输出这两项，其余按 JSON 输出。
+func softErrorValueString(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	if m, ok := value.(map[string]interface{}); ok {
+		if code, message := m["Code"], m["Message"]; code != nil || message != nil {
+			return strings.TrimSpace(fmt.Sprintf("%v %v", valueOrEmpty(code), valueOrEmpty(message)))
+		}
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
+
+func valueOrEmpty(v interface{}) interface{} {
+	if v == nil {
+		return ""
+	}
+	return v
+}
+
+// reportSoftErrors 把检测到的部分失败打印到 stderr；指定 ---fail-on-partial 时返回错误使命令以非零状态退出。
+func reportSoftErrors(ctx *Context, softErrors []string) error {
+	if len(softErrors) == 0 {
+		return nil
+	}
+	for _, e := range softErrors {
+		fmt.Fprintf(softErrorWarningOut, "Warning: response reports a partial failure at %s\n", e)
+	}
+	if failOnPartialRequested(ctx) {
+		return fmt.Errorf("response reports %d partial failure(s)", len(softErrors))
+	}
+	return nil
+}
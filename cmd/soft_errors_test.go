@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDetectSoftErrorsDefaultPaths(t *testing.T) {
+	page := map[string]interface{}{"Result": map[string]interface{}{
+		"Errors": []interface{}{},
+		"OperationDetails": []interface{}{
+			map[string]interface{}{"InstanceId": "i-1"},
+			map[string]interface{}{"InstanceId": "i-2", "Error": map[string]interface{}{"Code": "InvalidInstance.NotFound", "Message": "not found"}},
+		},
+	}}
+	got := detectSoftErrors(page, defaultSoftErrorPaths)
+	want := []string{"Result.OperationDetails[1].Error: InvalidInstance.NotFound not found"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("detectSoftErrors() = %#v, want %#v", got, want)
+	}
+
+	custom := detectSoftErrors(map[string]interface{}{"Result": map[string]interface{}{"Failed": []interface{}{"u-1"}}}, []string{"Result.Failed"})
+	if len(custom) != 1 || custom[0] != `Result.Failed: ["u-1"]` {
+		t.Fatalf("detectSoftErrors() with custom path = %#v", custom)
+	}
+}
+
+func TestDoActionFailOnPartial(t *testing.T) {
+	defer disableProxyEnvForTest(t)()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ResponseMetadata":{"RequestId":"req"},"Result":{"FailedItems":[{"Id":"i-2"}]}}`))
+	}))
+	defer server.Close()
+
+	defer setenvForTest(t, "BYTEPLUS_ACCESS_KEY", "ak-test")()
+	defer setenvForTest(t, "BYTEPLUS_SECRET_KEY", "sk-test")()
+	defer setenvForTest(t, "BYTEPLUS_REGION", "ap-southeast-1")()
+
+	prevWriter, prevWarning := actionOutputWriter, softErrorWarningOut
+	defer func() { actionOutputWriter, softErrorWarningOut = prevWriter, prevWarning }()
+
+	for _, failOnPartial := range []bool{false, true} {
+		var out, warnings bytes.Buffer
+		actionOutputWriter, softErrorWarningOut = &out, &warnings
+		args := []string{"---endpoint", server.URL, "---output", "text"}
+
+		if failOnPartial {
+			args = append(args, "---fail-on-partial")
+		}
+		testCtx := NewContext()
+		if _, err := NewParser(args).ReadArgs(testCtx); err != nil {
+			t.Fatalf("ReadArgs() error = %v", err)
+		}
+		err := doAction(testCtx, "ecs", "DescribeInstances")
+		if failOnPartial && (err == nil || !strings.Contains(err.Error(), "1 partial failure")) {
+			t.Fatalf("doAction() with ---fail-on-partial error = %v, want partial failure error", err)
+		}
+		if !failOnPartial && err != nil {
+			t.Fatalf("doAction() error = %v", err)
+		}
+		if want := `Warning: response reports a partial failure at Result.FailedItems: [{"Id":"i-2"}]`; !strings.Contains(warnings.String(), want) {
+			t.Fatalf("warnings = %q, want %q", warnings.String(), want)
+		}
+	}
+}
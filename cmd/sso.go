@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/byteplus-sdk/byteplus-cli/util"
@@ -19,6 +20,9 @@ import (
 
 const ssoAccessTokenRefreshWindow = 5 * time.Minute
 
+// ssoStsClockSkewBuffer 为 STS 凭证过期判断预留的本地时钟误差，临近过期的凭证提前刷新。
+const ssoStsClockSkewBuffer = 30 * time.Second
+
 var (
 	// getSsoConfigFileDir 是 SSO 缓存目录的注入点，生产环境固定使用 util.GetConfigFileDir。
 	// 单测会替换为临时目录，避免读写真实用户目录下的 ~/.byteplus。
@@ -31,14 +35,40 @@ var (
 	newPortalClientForSSO = func(region string) PortalClientAPI {
 		return NewPortalClient(&PortalClientConfig{Region: region})
 	}
+	// newSTSClientForSSO 集中创建 STS 客户端，用于 credential-source 为 web-identity 的会话。
+	newSTSClientForSSO = func() STSClientAPI {
+		return NewSTSClient(nil)
+	}
 	// selectSsoAccount/selectSsoRole 是账号与角色交互选择的注入点，生产环境使用 promptui，
 	// 单测替换为确定性选择，避免测试阻塞在真实终端交互上。
 	selectSsoAccount = promptSelectAccount
 	selectSsoRole    = promptSelectRole
+	// nowFunc 是 SSO token 生命周期判断使用的时钟注入点，生产环境固定为 time.Now。
+	// 单测可替换为固定时间，确定性地覆盖过期、刷新窗口与时钟偏差等边界。
+	nowFunc = time.Now
 	// deviceAuthorizationSleep 是设备码轮询等待的注入点，测试中会置空以避免真实等待。
-	deviceAuthorizationSleep = time.Sleep
+	deviceAuthorizationSleep = func(d time.Duration) {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-commandContext.Done():
+		}
+	}
+	// deviceAuthorizationProgressOut 是 --verbose 轮询进度的输出目标。
+	// 进度固定写 stderr，stdout 只保留授权 URL 等脚本可能解析的内容。
+	deviceAuthorizationProgressOut io.Writer = os.Stderr
 )
 
+// deviceAuthorizationProgressInterval 控制 --verbose 下两次进度提示的最小间隔，
+// 避免轮询间隔较短时刷屏。
+const deviceAuthorizationProgressInterval = 15 * time.Second
+
+// deviceAuthorizationFirstPollDelay 是设备码授权开始后首次轮询前的等待时间。
+const deviceAuthorizationFirstPollDelay = time.Second
+
+// Sso 的 Region 是 SSO 登录区域，用于 OAuth/Portal 调用，取自 SsoSession.Region；
+// Profile.Region 只用于业务 API 调用，两者可以不同。
 type Sso struct {
 	Profile        *Profile
 	SsoSessionName string
@@ -46,7 +76,59 @@ type Sso struct {
 	Region         string
 	UseDeviceCode  bool
 	NoBrowser      bool
-	Scopes         []string
+	Verbose        bool
+	// Reauth 为 true 时 Login 不复用缓存 token、不用 refresh token 续期，直接进行设备码授权。
+	Reauth bool
+	// Transient 为 true 时 StartURL 与 Region 直接来自 sso login 的参数，Login 不读取配置文件中的 sso-session。
+	Transient bool
+	Scopes    []string
+	// CredentialSource 取自 SsoSession.CredentialSource，决定角色凭证从 Portal 获取还是通过 STS 扮演。
+	CredentialSource string
+	// MinValidity 是调用方要求 STS 凭证至少剩余的有效时长，与 sts-min-validity 取较大者。
+	MinValidity time.Duration
+
+	// MessageOut 为登录过程中提示信息（授权链接等）的输出目标，为空时输出到 stdout。
+	MessageOut io.Writer
+	// OAuthFactory、PortalFactory 与 STSFactory 用于替换该实例使用的客户端，
+	// 为空时使用包级的 newOAuthClientForSSO 等默认构造函数。
+	OAuthFactory  func(region string) OAuthClientAPI
+	PortalFactory func(region string) PortalClientAPI
+	STSFactory    func() STSClientAPI
+
+	// reusedLoginToken 记录最近一次 Login 未经设备码授权而复用的 token。
+	reusedLoginToken *SsoTokenCache
+	// loginToken 记录最近一次 Login 成功得到的 token。
+	loginToken *SsoTokenCache
+	// skipCredentialCache 为 true 时 GetRoleCredentials 不复用缓存的角色凭证。
+	skipCredentialCache bool
+}
+
+func (s *Sso) oauthClient() OAuthClientAPI {
+	if s.OAuthFactory != nil {
+		return s.OAuthFactory(s.Region)
+	}
+	return newOAuthClientForSSO(s.Region)
+}
+
+func (s *Sso) portalClient() PortalClientAPI {
+	if s.PortalFactory != nil {
+		return s.PortalFactory(s.Region)
+	}
+	return newPortalClientForSSO(s.Region)
+}
+
+func (s *Sso) stsClient() STSClientAPI {
+	if s.STSFactory != nil {
+		return s.STSFactory()
+	}
+	return newSTSClientForSSO()
+}
+
+func (s *Sso) messageOut() io.Writer {
+	if s.MessageOut != nil {
+		return s.MessageOut
+	}
+	return os.Stdout
 }
 
 type SSOService interface {
@@ -86,9 +168,39 @@ func (s *Sso) applySessionDefaults(session *SsoSession) {
 	if len(s.Scopes) == 0 {
 		s.Scopes = session.RegistrationScopes
 	}
+	if strings.TrimSpace(s.CredentialSource) == "" {
+		s.CredentialSource = session.CredentialSource
+	}
 }
 
 func (s *Sso) EnsureValidStsToken(ctx *Context) error {
+	if err := s.prepareStsRefresh(ctx); err != nil {
+		return err
+	}
+
+	stsToken := strings.TrimSpace(s.Profile.SessionToken)
+	expiration := s.Profile.StsExpiration
+	minValidity := stsMinValidity(ctx.config, s.Profile)
+	if s.MinValidity > minValidity {
+		minValidity = s.MinValidity
+	}
+	refreshAt := nowFunc().Add(ssoStsClockSkewBuffer + minValidity)
+	if stsToken != "" && expiration > 0 && refreshAt.Before(util.UnixTimestampToTime(expiration)) {
+		return nil
+	}
+	return s.refreshStsToken(ctx)
+}
+
+// RefreshExpiredStsToken 在服务端判定 STS 凭证已过期时强制刷新：不看本地记录的过期时间，也不复用角色凭证缓存。
+func (s *Sso) RefreshExpiredStsToken(ctx *Context) error {
+	if err := s.prepareStsRefresh(ctx); err != nil {
+		return err
+	}
+	s.skipCredentialCache = true
+	return s.refreshStsToken(ctx)
+}
+
+func (s *Sso) prepareStsRefresh(ctx *Context) error {
 	if ctx == nil || ctx.config == nil {
 		return fmt.Errorf("failed to refresh stsToken: failed to obtain the config in ctx")
 	}
@@ -99,26 +211,34 @@ func (s *Sso) EnsureValidStsToken(ctx *Context) error {
 	if s.SsoSessionName == "" {
 		s.SsoSessionName = s.Profile.SsoSessionName
 	}
-	if s.Region == "" {
-		s.Region = s.Profile.Region
-	}
+	return nil
+}
 
-	stsToken := strings.TrimSpace(s.Profile.SessionToken)
-	expiration := s.Profile.StsExpiration
-	if stsToken != "" && expiration > 0 && time.Now().Before(util.UnixTimestampToTime(expiration)) {
-		return nil
+// refreshStsToken 获取新的角色凭证并写回 profile 与配置文件。
+func (s *Sso) refreshStsToken(ctx *Context) error {
+	if err := missingSsoSessionError(ctx.config, s.Profile); err != nil {
+		return err
 	}
-
 	ssoSession, err := s.loadSsoSession(ctx.config)
 	if err != nil {
 		return err
 	}
 	s.applySessionDefaults(ssoSession)
+	// 旧版本写入的 sso-session 可能没有 region，此时沿用 profile 的 region 作为登录区域。
+	if strings.TrimSpace(s.Region) == "" {
+		s.Region = s.Profile.Region
+	}
 	if strings.TrimSpace(s.StartURL) == "" {
 		return fmt.Errorf("the start URL of SSO session %s is not configured", s.SsoSessionName)
 	}
 
 	roleCredentials, err := s.GetRoleCredentials()
+	if isRoleNotAssignedError(err) {
+		// 角色分配被收回时依次尝试 profile 的 fallback-roles，成功后 role-name 随凭证一起写回配置文件。
+		if roleCredentials, err = s.getFallbackRoleCredentials(err); err != nil {
+			return err
+		}
+	}
 	if err != nil {
 		return fmt.Errorf("failed to get role credentials: %w", err)
 	}
@@ -131,6 +251,21 @@ func (s *Sso) EnsureValidStsToken(ctx *Context) error {
 	return WriteConfigToFile(ctx.config)
 }
 
+// stsMinValidity 返回 STS 凭证至少需要剩余的有效时长：profile 的 sts-min-validity 优先，否则使用全局配置，单位为分钟。
+func stsMinValidity(cfg *Configure, profile *Profile) time.Duration {
+	minutes := 0
+	if cfg != nil {
+		minutes = cfg.StsMinValidity
+	}
+	if profile != nil && profile.StsMinValidity > 0 {
+		minutes = profile.StsMinValidity
+	}
+	if minutes <= 0 {
+		return 0
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
 type SsoTokenCache struct {
 	StartURL              string `json:"start_url"`
 	SessionName           string `json:"session_name"`
@@ -142,12 +277,15 @@ type SsoTokenCache struct {
 	ClientSecretExpiresAt int64  `json:"client_secret_expires_at,omitempty"`
 	RefreshToken          string `json:"refresh_token,omitempty"`
 	Region                string `json:"region"`
+	// Scopes 是签发 access token 时授予的 scopes；旧版本写入的缓存没有该字段。
+	Scopes []string `json:"scopes,omitempty"`
 }
 
 type DeviceCodeFetcher struct {
 	sso       *Sso
 	oauth     OAuthClientAPI
 	noBrowser bool
+	verbose   bool
 }
 
 type clientRegistrationCache struct {
@@ -165,6 +303,7 @@ func writeJSONFileAtomic(path string, perm os.FileMode, payload interface{}) (re
 		return fmt.Errorf("failed to create temp file: %w", err)
 	}
 	tempName := tempFile.Name()
+	defer trackTempFile(tempName)()
 	defer func() {
 		if retErr != nil {
 			_ = tempFile.Close()
@@ -183,18 +322,17 @@ func writeJSONFileAtomic(path string, perm os.FileMode, payload interface{}) (re
 		return retErr
 	}
 
+	if err := tempFile.Sync(); err != nil {
+		retErr = fmt.Errorf("failed to sync cache file: %w", err)
+		return retErr
+	}
+
 	if err := tempFile.Close(); err != nil {
 		retErr = fmt.Errorf("failed to close cache file: %w", err)
 		return retErr
 	}
 
-	if err := os.Rename(tempName, path); err != nil {
-		removeErr := os.Remove(path)
-		if removeErr == nil || os.IsNotExist(removeErr) {
-			if err2 := os.Rename(tempName, path); err2 == nil {
-				return nil
-			}
-		}
+	if err := replaceFile(tempName, path, perm); err != nil {
 		retErr = fmt.Errorf("failed to replace cache file: %w", err)
 		return retErr
 	}
@@ -207,16 +345,53 @@ func (s *Sso) tokenCacheFilePath() (string, error) {
 	if err != nil {
 		return "", err
 	}
-	fileName := s.generateCacheFileName(s.StartURL, s.SsoSessionName)
+	fileName := s.generateCacheFileName(s.StartURL, s.SsoSessionName, s.Region)
 	return filepath.Join(cacheDir, fileName), nil
 }
 
+// legacyTokenCacheFilePath 返回旧版本不区分 region 时的缓存文件路径；region 为空时与新路径相同，返回空串。
+func (s *Sso) legacyTokenCacheFilePath() (string, error) {
+	if strings.TrimSpace(s.Region) == "" {
+		return "", nil
+	}
+	cacheDir, err := s.getSsoCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, s.generateCacheFileName(s.StartURL, s.SsoSessionName, "")), nil
+}
+
+// readTokenCache 读取当前 session 的 token 缓存。新文件名不存在时回退读取旧文件名，
+// 但只接受 region 与当前 session 一致（或旧缓存未记录 region）的 token，避免跨区域误用。
 func (s *Sso) readTokenCache() (*SsoTokenCache, error) {
 	filePath, err := s.tokenCacheFilePath()
 	if err != nil {
 		return nil, err
 	}
+	token, err := readTokenCacheFile(filePath)
+	if token != nil || err != nil {
+		return token, err
+	}
+	return s.readLegacyTokenCache()
+}
+
+func (s *Sso) readLegacyTokenCache() (*SsoTokenCache, error) {
+	legacyPath, err := s.legacyTokenCacheFilePath()
+	if err != nil || legacyPath == "" {
+		return nil, err
+	}
+	token, err := readTokenCacheFile(legacyPath)
+	if token == nil || err != nil {
+		return nil, err
+	}
+	if region := strings.TrimSpace(token.Region); region != "" && !strings.EqualFold(region, s.Region) {
+		return nil, nil
+	}
+	return token, nil
+}
 
+// readTokenCacheFile 读取一个 token 缓存文件；文件不存在或为空时返回 nil，内容损坏时删除该文件。
+func readTokenCacheFile(filePath string) (*SsoTokenCache, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -247,7 +422,7 @@ func tokenExpired(expiresAt string) bool {
 	if err != nil {
 		return true
 	}
-	return time.Now().After(expTime)
+	return nowFunc().After(expTime)
 }
 
 // tokenNeedsRefresh 判断 access token 是否需要刷新。
@@ -260,14 +435,14 @@ func tokenNeedsRefresh(expiresAt string) bool {
 	if err != nil {
 		return true
 	}
-	return !time.Now().Add(ssoAccessTokenRefreshWindow).Before(expTime)
+	return !nowFunc().Add(ssoAccessTokenRefreshWindow).Before(expTime)
 }
 
 func clientSecretExpired(expiresAt int64) bool {
 	if expiresAt == 0 {
 		return false
 	}
-	return time.Now().UnixMilli() >= expiresAt
+	return nowFunc().UnixMilli() >= expiresAt
 }
 
 func (f *DeviceCodeFetcher) registrationClientCacheKey() (string, error) {
@@ -347,10 +522,10 @@ func (f *DeviceCodeFetcher) cacheClientRegistration(client *RegisterClientRespon
 	if err != nil {
 		return err
 	}
-	if err := os.MkdirAll(cacheDir, 0700); err != nil {
+	if err := os.MkdirAll(cacheDir, configDirPerm()); err != nil {
 		return fmt.Errorf("failed to create the cache directory: %v", err)
 	}
-	_ = os.Chmod(cacheDir, 0700)
+	_ = os.Chmod(cacheDir, configDirPerm())
 	filePath, err := f.registrationClientCachePath()
 	if err != nil {
 		return err
@@ -364,14 +539,33 @@ func (f *DeviceCodeFetcher) cacheClientRegistration(client *RegisterClientRespon
 		ClientSecretExpiresAt: client.ClientSecretExpiresAt,
 	}
 
-	return writeJSONFileAtomic(filePath, 0600, cache)
+	return writeJSONFileAtomic(filePath, configFilePerm(), cache)
+}
+
+// ssoIdentityRegions 是提供 CloudIdentity OAuth/Portal 服务的区域。
+// OAuth/Portal 的域名由 region 拼接而成，region 不在其中时域名无法解析，登录只会失败在难以理解的 DNS 错误上。
+var ssoIdentityRegions = []string{"ap-southeast-1"}
+
+// validateSsoRegion 在构造 OAuth/Portal 客户端前校验 SSO 区域；region 为空时客户端使用默认区域，无需校验。
+func validateSsoRegion(region string) error {
+	region = strings.TrimSpace(region)
+	if region == "" {
+		return nil
+	}
+	for _, r := range ssoIdentityRegions {
+		if region == r {
+			return nil
+		}
+	}
+	return fmt.Errorf("region %s is not a valid identity region, supported regions: %s", region, strings.Join(ssoIdentityRegions, ", "))
 }
 
 func newDeviceCodeFetcher(s *Sso) *DeviceCodeFetcher {
 	return &DeviceCodeFetcher{
 		sso:       s,
-		oauth:     newOAuthClientForSSO(s.Region),
+		oauth:     s.oauthClient(),
 		noBrowser: s.NoBrowser,
+		verbose:   s.Verbose,
 	}
 }
 
@@ -482,11 +676,15 @@ func (f *DeviceCodeFetcher) loadClientForRefresh(cached *SsoTokenCache) (*Regist
 	return client, nil
 }
 
-func (f *DeviceCodeFetcher) storeToken(resp *CreateTokenResponse, client *RegisterClientResponse) (*SsoTokenCache, error) {
+// storeToken 缓存新签发的 token。服务端返回 scope 时以其为准，否则记录为 scopes。
+func (f *DeviceCodeFetcher) storeToken(resp *CreateTokenResponse, client *RegisterClientResponse, scopes []string) (*SsoTokenCache, error) {
 	if client == nil {
 		return nil, fmt.Errorf("client registration is required to store token")
 	}
-	expiresAt := time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second).Format(time.RFC3339)
+	if granted := strings.Fields(resp.Scope); len(granted) > 0 {
+		scopes = granted
+	}
+	expiresAt := nowFunc().Add(time.Duration(resp.ExpiresIn) * time.Second).Format(time.RFC3339)
 	token := &SsoTokenCache{
 		StartURL:              f.sso.StartURL,
 		SessionName:           f.sso.SsoSessionName,
@@ -498,6 +696,7 @@ func (f *DeviceCodeFetcher) storeToken(resp *CreateTokenResponse, client *Regist
 		ClientIdIssuedAt:      client.ClientIDIssuedAt,
 		ClientSecretExpiresAt: client.ClientSecretExpiresAt,
 		Region:                f.sso.Region,
+		Scopes:                append([]string(nil), scopes...),
 	}
 	if err := f.sso.setAccessTokenToCache(f.sso.StartURL, f.sso.SsoSessionName, token); err != nil {
 		return nil, err
@@ -523,20 +722,45 @@ func (f *DeviceCodeFetcher) createToken(ctx context.Context, grantType string, r
 	return f.oauth.CreateToken(ctx, req)
 }
 
-func (f *DeviceCodeFetcher) refreshToken(ctx context.Context, refreshToken string, client *RegisterClientResponse) (*SsoTokenCache, error) {
+func (f *DeviceCodeFetcher) refreshToken(ctx context.Context, cached *SsoTokenCache, client *RegisterClientResponse) (*SsoTokenCache, error) {
 	if client == nil {
 		return nil, fmt.Errorf("client registration is required to refresh token")
 	}
-	resp, err := f.createToken(ctx, "refresh_token", refreshToken, "", client)
+	resp, err := f.createToken(ctx, "refresh_token", cached.RefreshToken, "", client)
 	if err != nil {
 		return nil, err
 	}
 	// 有些 OAuth 服务会在刷新 access token 时轮换 refresh token。
 	// 只有当服务端未返回新的 refresh_token 时，才沿用旧值，避免把新 token 覆盖掉导致下一次静默刷新失败。
 	if resp.RefreshToken == "" {
-		resp.RefreshToken = refreshToken
+		resp.RefreshToken = cached.RefreshToken
 	}
-	return f.storeToken(resp, client)
+	// refresh 不会扩大授权范围，沿用原 token 的 scopes
+	return f.storeToken(resp, client, cached.Scopes)
+}
+
+// tokenCoversScopes 判断缓存 token 授予的 scopes 是否包含当前请求的全部 scopes。
+// 旧缓存未记录 scopes 时无法判断，视为满足，避免升级后所有用户被迫重新授权。
+func tokenCoversScopes(token *SsoTokenCache, requested []string) bool {
+	if token == nil || token.Scopes == nil {
+		return true
+	}
+	granted := make(map[string]struct{}, len(token.Scopes))
+	for _, scope := range token.Scopes {
+		granted[scope] = struct{}{}
+	}
+	for _, scope := range requested {
+		if _, ok := granted[strings.TrimSpace(scope)]; !ok && strings.TrimSpace(scope) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// warnScopeMismatch 提示缓存 token 的 scopes 不满足当前 sso-session 配置，即将重新授权。
+func (f *DeviceCodeFetcher) warnScopeMismatch(token *SsoTokenCache) {
+	fmt.Fprintf(f.sso.messageOut(), "The cached SSO token was granted scopes [%s], which do not cover the requested scopes [%s]; authorizing again.\n",
+		strings.Join(token.Scopes, ","), strings.Join(f.sso.Scopes, ","))
 }
 
 func oauthErrorCode(err error) (string, bool) {
@@ -609,13 +833,14 @@ func (f *DeviceCodeFetcher) performDeviceAuthorization(ctx context.Context, clie
 		return nil, fmt.Errorf("failed to start device authorization: verificationURI is empty")
 	}
 
+	out := f.sso.messageOut()
 	if f.noBrowser {
-		fmt.Printf("To authorize, open the following URL in your browser:\n\n%s\n", verificationURIComplete)
+		fmt.Fprintf(out, "To authorize, open the following URL in your browser:\n\n%s\n", verificationURIComplete)
 	} else {
-		fmt.Printf("Attempting to open your default browser.\n")
-		fmt.Printf("If the browser does not open or you want to authorize from another device, open the following URL:\n\n%s\n", verificationURIComplete)
+		fmt.Fprintf(out, "Attempting to open your default browser.\n")
+		fmt.Fprintf(out, "If the browser does not open or you want to authorize from another device, open the following URL:\n\n%s\n", verificationURIComplete)
 		if err := util.OpenBrowser(verificationURIComplete); err != nil {
-			fmt.Printf("Failed to open the browser automatically: %v\n", err)
+			fmt.Fprintf(out, "Failed to open the browser automatically: %v\n", err)
 		}
 	}
 
@@ -624,12 +849,22 @@ func (f *DeviceCodeFetcher) performDeviceAuthorization(ctx context.Context, clie
 		interval = 5 * time.Second
 	}
 	expiresIn := time.Duration(authResp.ExpiresIn) * time.Second
-	deadline := time.Now().Add(expiresIn)
+	deadline := nowFunc().Add(expiresIn)
+
+	fmt.Fprintf(out, "Please complete authorization promptly to avoid timeout. This device code expires in %d seconds.\n", authResp.ExpiresIn)
 
-	fmt.Printf("Please complete authorization promptly to avoid timeout. This device code expires in %d seconds.\n", authResp.ExpiresIn)
+	var progress *deviceAuthorizationProgress
+	if f.verbose {
+		progress = &deviceAuthorizationProgress{out: deviceAuthorizationProgressOut, interval: deviceAuthorizationProgressInterval}
+		defer progress.done()
+	}
 
-	for time.Now().Before(deadline) {
-		deviceAuthorizationSleep(interval)
+	for attempt := 0; nowFunc().Before(deadline); attempt++ {
+		deviceAuthorizationSleep(deviceAuthorizationPollDelay(interval, attempt))
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		progress.poll(nowFunc(), deadline)
 
 		tokenResp, err := f.createToken(ctx, deviceCodeGrantType, "", authResp.DeviceCode, client)
 		if err != nil {
@@ -644,22 +879,77 @@ func (f *DeviceCodeFetcher) performDeviceAuthorization(ctx context.Context, clie
 			return nil, fmt.Errorf("failed to poll access token: %w", err)
 		}
 
-		return f.storeToken(tokenResp, client)
+		return f.storeToken(tokenResp, client, f.sso.Scopes)
 	}
 
 	return nil, fmt.Errorf("authorization has timed out. Please try again")
 }
 
+// deviceAuthorizationPollDelay 返回第 attempt 次（从 0 开始）轮询前的等待时间。
+// 首次轮询只等待 deviceAuthorizationFirstPollDelay（不超过 interval），用户立即完成授权时无需多等一个完整间隔；
+// 之后以服务端返回的 interval 为下限。每次再叠加最多 interval/10 的随机抖动，避免同时发起的登录同步轮询。
+func deviceAuthorizationPollDelay(interval time.Duration, attempt int) time.Duration {
+	delay := interval
+	if attempt == 0 && deviceAuthorizationFirstPollDelay < interval {
+		delay = deviceAuthorizationFirstPollDelay
+	}
+	if maxJitter := int64(interval / 10); maxJitter > 0 {
+		retryRandMu.Lock()
+		delay += time.Duration(retryRand.Int63n(maxJitter))
+		retryRandMu.Unlock()
+	}
+	return delay
+}
+
+// deviceAuthorizationProgress 在 --verbose 下输出设备码轮询进度。
+// 每次轮询输出一个点，距离上次完整提示超过 interval 时再输出剩余有效秒数。
+type deviceAuthorizationProgress struct {
+	out        io.Writer
+	interval   time.Duration
+	lastReport time.Time
+}
+
+func (p *deviceAuthorizationProgress) poll(now, deadline time.Time) {
+	if p == nil || p.out == nil {
+		return
+	}
+	if !p.lastReport.IsZero() && now.Sub(p.lastReport) < p.interval {
+		fmt.Fprint(p.out, ".")
+		return
+	}
+	remaining := int(deadline.Sub(now).Seconds())
+	if remaining < 0 {
+		remaining = 0
+	}
+	if !p.lastReport.IsZero() {
+		fmt.Fprintln(p.out)
+	}
+	fmt.Fprintf(p.out, "Still waiting for authorization... %d seconds remaining", remaining)
+	p.lastReport = now
+}
+
+// done 结束当前进度行，避免后续输出接在点号后面。
+func (p *deviceAuthorizationProgress) done() {
+	if p == nil || p.out == nil || p.lastReport.IsZero() {
+		return
+	}
+	fmt.Fprintln(p.out)
+}
+
 // GetToken 协调设备码流程、refresh token 刷新及缓存复用。
 // 该方法保留给 configure sso 等交互式流程使用：它可以复用缓存、尝试 refresh，并在必要时回退到设备码授权。
 func (f *DeviceCodeFetcher) GetToken() (*SsoTokenCache, error) {
-	ctx := context.Background()
+	ctx := commandContext
 
 	cached, err := f.loadCachedToken()
 	if err != nil {
 		return nil, err
 	}
-	if cached != nil && cached.AccessToken != "" && !tokenExpired(cached.ExpiresAt) {
+	covered := tokenCoversScopes(cached, f.sso.Scopes)
+	if cached != nil && cached.AccessToken != "" && !covered {
+		f.warnScopeMismatch(cached)
+	}
+	if cached != nil && cached.AccessToken != "" && !tokenExpired(cached.ExpiresAt) && covered {
 		return cached, nil
 	}
 
@@ -668,8 +958,8 @@ func (f *DeviceCodeFetcher) GetToken() (*SsoTokenCache, error) {
 		return nil, err
 	}
 
-	if cached != nil && cached.RefreshToken != "" {
-		token, err := f.refreshToken(ctx, cached.RefreshToken, client)
+	if cached != nil && cached.RefreshToken != "" && covered {
+		token, err := f.refreshToken(ctx, cached, client)
 		if err == nil {
 			return token, nil
 		}
@@ -697,7 +987,7 @@ func (f *DeviceCodeFetcher) GetToken() (*SsoTokenCache, error) {
 // GetFreshTokenForLogin 执行显式登录授权。
 // 无论缓存 access token 是否有效，也不会用 refresh_token 静默完成登录。
 func (f *DeviceCodeFetcher) GetFreshTokenForLogin() (*SsoTokenCache, error) {
-	ctx := context.Background()
+	ctx := commandContext
 	cached, err := f.loadCachedToken()
 	if err != nil {
 		return nil, err
@@ -709,10 +999,35 @@ func (f *DeviceCodeFetcher) GetFreshTokenForLogin() (*SsoTokenCache, error) {
 	return f.performDeviceAuthorization(ctx, client)
 }
 
+// GetTokenForLogin 供 sso login 使用：缓存 token 不在刷新窗口内时直接复用，临近过期时先尝试静默刷新，
+// 两者都不可行才进入设备码授权。第二个返回值表示 token 是否无需浏览器授权即获得。
+func (f *DeviceCodeFetcher) GetTokenForLogin() (*SsoTokenCache, bool, error) {
+	cached, err := f.loadCachedToken()
+	if err != nil {
+		return nil, false, err
+	}
+	if cached != nil && strings.TrimSpace(cached.AccessToken) != "" && !tokenCoversScopes(cached, f.sso.Scopes) {
+		f.warnScopeMismatch(cached)
+	} else if cached != nil && strings.TrimSpace(cached.AccessToken) != "" {
+		if !tokenNeedsRefresh(cached.ExpiresAt) {
+			return cached, true, nil
+		}
+		if strings.TrimSpace(cached.RefreshToken) != "" {
+			if client, err := f.loadClientForRefresh(cached); err == nil {
+				if token, err := f.refreshToken(commandContext, cached, client); err == nil {
+					return token, true, nil
+				}
+			}
+		}
+	}
+	token, err := f.GetFreshTokenForLogin()
+	return token, false, err
+}
+
 // GetValidTokenForBusiness 返回业务命令可用的 access token 缓存。
 // 业务命令只允许静默 refresh，不允许回退到设备码授权，避免普通 API 调用突然打开浏览器或阻塞等待用户授权。
 func (f *DeviceCodeFetcher) GetValidTokenForBusiness() (*SsoTokenCache, error) {
-	ctx := context.Background()
+	ctx := commandContext
 	cached, err := f.loadCachedToken()
 	if err != nil {
 		return nil, err
@@ -730,34 +1045,65 @@ func (f *DeviceCodeFetcher) GetValidTokenForBusiness() (*SsoTokenCache, error) {
 	if err != nil {
 		return nil, err
 	}
-	token, err := f.refreshToken(ctx, cached.RefreshToken, client)
+	token, err := f.refreshToken(ctx, cached, client)
 	if err != nil {
 		return nil, fmt.Errorf("failed to refresh SSO access token; please log in using the `sso login` command: %w", err)
 	}
 	return token, nil
 }
 
+// RefreshRejectedTokenForBusiness 在 Portal 拒绝 rejected 这个 access token 后，不看本地记录的过期时间，
+// 直接用 refresh token 换取新 token；与 GetValidTokenForBusiness 一样不会回退到设备码授权。
+// 缓存中的 token 已被其他进程换新时直接复用。
+func (f *DeviceCodeFetcher) RefreshRejectedTokenForBusiness(rejected string) (*SsoTokenCache, error) {
+	cached, err := f.loadCachedToken()
+	if err != nil {
+		return nil, err
+	}
+	if cached != nil && cached.AccessToken != "" && cached.AccessToken != rejected && !tokenNeedsRefresh(cached.ExpiresAt) {
+		return cached, nil
+	}
+	if cached == nil || strings.TrimSpace(cached.RefreshToken) == "" {
+		return nil, fmt.Errorf("SSO access token was rejected and cannot be refreshed because refresh token is missing; please log in using the `sso login` command")
+	}
+	client, err := f.loadClientForRefresh(cached)
+	if err != nil {
+		return nil, err
+	}
+	token, err := f.refreshToken(commandContext, cached, client)
+	if err != nil {
+		return nil, fmt.Errorf("SSO access token was rejected and could not be refreshed; please log in using the `sso login` command: %w", err)
+	}
+	return token, nil
+}
+
 func (s *Sso) SetProfile() error {
 	if !s.UseDeviceCode {
 		return fmt.Errorf("currently, only device code authentication is supported")
 	}
 
+	if err := validateSsoRegion(s.Region); err != nil {
+		return err
+	}
 	fetcher := newDeviceCodeFetcher(s)
 	token, err := fetcher.GetToken()
 	if err != nil {
-		return fmt.Errorf("failed to obtain the access token: %v", err)
+		return fmt.Errorf("failed to obtain the access token: %w", err)
 	}
 
 	accountId, roleName, err := s.chooseAccountAndRole(token)
 	if err != nil {
-		return fmt.Errorf("failed to select the account and role: %v", err)
+		return fmt.Errorf("failed to select the account and role: %w", err)
 	}
 
 	s.Profile.Mode = ModeSSO
 	s.Profile.SsoSessionName = s.SsoSessionName
 	s.Profile.AccountId = accountId
 	s.Profile.RoleName = roleName
-	s.Profile.Region = s.Region
+	// profile 已有 API region 时保留，否则默认使用 SSO 登录区域。
+	if strings.TrimSpace(s.Profile.Region) == "" {
+		s.Profile.Region = s.Region
+	}
 	// 重新选择 SSO 账号或角色后，旧 STS 临时凭证已经不再可信。
 	// 如果不清空，后续业务命令会在过期前继续复用旧身份，导致配置已变更但调用仍落到旧账号/角色。
 	clearSsoProfileTemporaryCredentials(s.Profile)
@@ -776,11 +1122,7 @@ func (s *Sso) SetProfile() error {
 
 	cfg.Profiles[s.Profile.Name] = s.Profile
 
-	if err := WriteConfigToFile(cfg); err != nil {
-		return err
-	}
-	fmt.Printf("SSO profile [%s] has been configured successfully\n", s.Profile.Name)
-	return nil
+	return WriteConfigToFile(cfg)
 }
 
 func (s *Sso) setAccessTokenToCache(startURL, sessionName string, token *SsoTokenCache) error {
@@ -789,15 +1131,15 @@ func (s *Sso) setAccessTokenToCache(startURL, sessionName string, token *SsoToke
 		return err
 	}
 
-	if err := os.MkdirAll(cacheDir, 0700); err != nil {
+	if err := os.MkdirAll(cacheDir, configDirPerm()); err != nil {
 		return fmt.Errorf("failed to create the cache directory: %v", err)
 	}
-	_ = os.Chmod(cacheDir, 0700)
+	_ = os.Chmod(cacheDir, configDirPerm())
 
-	fileName := s.generateCacheFileName(startURL, sessionName)
+	fileName := s.generateCacheFileName(startURL, sessionName, s.Region)
 	filePath := filepath.Join(cacheDir, fileName)
 
-	return writeJSONFileAtomic(filePath, 0600, token)
+	return writeJSONFileAtomic(filePath, configFilePerm(), token)
 }
 
 func (s *Sso) chooseAccountAndRole(token *SsoTokenCache) (string, string, error) {
@@ -805,8 +1147,11 @@ func (s *Sso) chooseAccountAndRole(token *SsoTokenCache) (string, string, error)
 		return "", "", fmt.Errorf("access token is empty, please login again")
 	}
 
-	var client PortalClientAPI = newPortalClientForSSO(s.Region)
-	ctx := context.Background()
+	if err := validateSsoRegion(s.Region); err != nil {
+		return "", "", err
+	}
+	client := s.portalClient()
+	ctx := commandContext
 
 	accounts, err := s.fetchAllAccounts(ctx, client, token.AccessToken)
 	if err != nil {
@@ -838,31 +1183,135 @@ func (s *Sso) chooseAccountAndRole(token *SsoTokenCache) (string, string, error)
 }
 
 func (s *Sso) GetRoleCredentials() (*RoleCredentials, error) {
+	// 同一 session/账号/角色的有效凭证可能已由其他进程或 profile 获取过，优先复用。
+	if !s.skipCredentialCache {
+		if cached := s.readCachedRoleCredentials(); cached != nil {
+			return cached, nil
+		}
+	}
+
+	if s.CredentialSource == SsoCredentialSourceWebIdentity {
+		creds, err := s.AssumeRoleWithWebIdentity(s.roleTrn())
+		if err != nil {
+			return nil, err
+		}
+		s.storeRoleCredentials(creds)
+		return creds, nil
+	}
+
 	accessToken, err := s.GetValidAccessToken()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get access token: %w", err)
 	}
 
-	var client PortalClientAPI = newPortalClientForSSO(s.Region)
-	ctx := context.Background()
-	resp, err := client.GetRoleCredentials(ctx, &GetRoleCredentialsRequest{
+	client := s.portalClient()
+	ctx := commandContext
+	req := &GetRoleCredentialsRequest{
 		AccessToken: accessToken,
 		AccountID:   s.Profile.AccountId,
 		RoleName:    s.Profile.RoleName,
-	})
+	}
+	resp, err := client.GetRoleCredentials(ctx, req)
+	// 本地记录未过期的 access token 仍可能被 Portal 拒绝（例如服务端提前吊销），此时用 refresh token 换新后重试一次。
+	var portalErr *PortalAPIError
+	if errors.As(err, &portalErr) && portalErr.IsAccessTokenRejected() {
+		token, refreshErr := newDeviceCodeFetcher(s).RefreshRejectedTokenForBusiness(accessToken)
+		if refreshErr != nil {
+			return nil, refreshErr
+		}
+		req.AccessToken = token.AccessToken
+		resp, err = client.GetRoleCredentials(ctx, req)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get role credentials: %w", err)
 	}
 
+	s.storeRoleCredentials(&resp.RoleCredentials)
 	return &resp.RoleCredentials, nil
 }
 
+// AssumeRoleWithWebIdentity 以缓存的 SSO access token 作为 OIDC token 调用 STS 扮演 roleTrn，
+// 目标角色的信任策略需要信任签发该 token 的身份源。返回的凭证不写入缓存，由调用方决定。
+func (s *Sso) AssumeRoleWithWebIdentity(roleTrn string) (*RoleCredentials, error) {
+	if strings.TrimSpace(roleTrn) == "" {
+		return nil, fmt.Errorf("the role TRN to assume must be specified")
+	}
+	accessToken, err := s.GetValidAccessToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get access token: %w", err)
+	}
+
+	creds, err := s.stsClient().AssumeRoleWithOIDC(commandContext, &AssumeRoleWithOIDCRequest{
+		OIDCToken:       accessToken,
+		RoleTrn:         roleTrn,
+		RoleSessionName: s.roleSessionName(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to assume role %s with web identity: %w", roleTrn, err)
+	}
+	return creds, nil
+}
+
+// roleTrn 优先使用 profile 显式配置的 role-trn，否则由账号和角色名拼出。
+func (s *Sso) roleTrn() string {
+	if s.Profile == nil {
+		return ""
+	}
+	if trn := strings.TrimSpace(s.Profile.RoleTrn); trn != "" {
+		return trn
+	}
+	if s.Profile.AccountId == "" || s.Profile.RoleName == "" {
+		return ""
+	}
+	return fmt.Sprintf("trn:iam::%s:role/%s", s.Profile.AccountId, s.Profile.RoleName)
+}
+
+// roleSessionName 使用 profile 名便于在审计日志中区分来源，缺省时退回 SSO 会话名。
+func (s *Sso) roleSessionName() string {
+	if s.Profile != nil && s.Profile.Name != "" {
+		return "bp-" + s.Profile.Name
+	}
+	return "bp-" + s.SsoSessionName
+}
+
 func (s *Sso) fetchAllAccounts(ctx context.Context, client PortalClientAPI, accessToken string) ([]AccountInfo, error) {
-	var (
-		accounts  []AccountInfo
-		nextToken string
-	)
+	first, err := client.ListAccounts(ctx, &ListAccountsRequest{AccessToken: accessToken})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list accounts: %w", err)
+	}
+	accounts := first.AccountList
+	if accounts == nil {
+		accounts = []AccountInfo{}
+	}
+	if strings.TrimSpace(first.NextToken) == "" {
+		return accounts, nil
+	}
+
+	// 首页给出 Total 时剩余页号可直接推算，并发拉取后按页号顺序拼接。
+	if pages, ok := remainingPortalPages(first.Total, first.PageNumber, first.PageSize); ok {
+		results := make([][]AccountInfo, len(pages))
+		err := fetchPortalPagesConcurrently(ctx, len(pages), func(ctx context.Context, i int) error {
+			resp, err := client.ListAccounts(ctx, &ListAccountsRequest{
+				AccessToken: accessToken,
+				PageSize:    first.PageSize,
+				PageNumber:  pages[i],
+			})
+			if err != nil {
+				return err
+			}
+			results[i] = resp.AccountList
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list accounts: %w", err)
+		}
+		for _, page := range results {
+			accounts = append(accounts, page...)
+		}
+		return accounts, nil
+	}
 
+	nextToken := first.NextToken
 	for {
 		resp, err := client.ListAccounts(ctx, &ListAccountsRequest{
 			AccessToken: accessToken,
@@ -881,11 +1330,43 @@ func (s *Sso) fetchAllAccounts(ctx context.Context, client PortalClientAPI, acce
 }
 
 func (s *Sso) fetchAllRoles(ctx context.Context, client PortalClientAPI, accessToken, accountID string) ([]RoleInfo, error) {
-	var (
-		roles     []RoleInfo
-		nextToken string
-	)
+	first, err := client.ListAccountRoles(ctx, &ListAccountRolesRequest{
+		AccessToken: accessToken,
+		AccountID:   accountID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles for account %s: %w", accountID, err)
+	}
+	roles := first.RoleList
+	if strings.TrimSpace(first.NextToken) == "" {
+		return roles, nil
+	}
+
+	if pages, ok := remainingPortalPages(first.Total, first.PageNumber, first.PageSize); ok {
+		results := make([][]RoleInfo, len(pages))
+		err := fetchPortalPagesConcurrently(ctx, len(pages), func(ctx context.Context, i int) error {
+			resp, err := client.ListAccountRoles(ctx, &ListAccountRolesRequest{
+				AccessToken: accessToken,
+				AccountID:   accountID,
+				PageSize:    first.PageSize,
+				PageNumber:  pages[i],
+			})
+			if err != nil {
+				return err
+			}
+			results[i] = resp.RoleList
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list roles for account %s: %w", accountID, err)
+		}
+		for _, page := range results {
+			roles = append(roles, page...)
+		}
+		return roles, nil
+	}
 
+	nextToken := first.NextToken
 	for {
 		resp, err := client.ListAccountRoles(ctx, &ListAccountRolesRequest{
 			AccessToken: accessToken,
@@ -929,16 +1410,21 @@ Name:   {{ .AccountName }}
 ID:     {{ .AccountID }}`,
 	}
 
+	size, searchMode := promptSelectSettings()
 	sel := promptui.Select{
 		Label:             "Select account (type to filter, Enter to choose)",
 		Items:             accounts,
 		Templates:         templates,
 		Searcher:          searcher,
-		StartInSearchMode: true,
-		Size:              10,
+		StartInSearchMode: searchMode,
+		Size:              size,
 	}
 
-	idx, _, err := sel.Run()
+	labels := make([]string, len(accounts))
+	for i, a := range accounts {
+		labels[i] = fmt.Sprintf("%s (%s)", a.AccountName, a.AccountID)
+	}
+	idx, err := runSelect(sel, labels)
 	if err != nil {
 		return AccountInfo{}, err
 	}
@@ -970,16 +1456,21 @@ Name:    {{ .RoleName }}
 Account: {{ .AccountID }}`,
 	}
 
+	size, searchMode := promptSelectSettings()
 	sel := promptui.Select{
 		Label:             "Select role (type to filter, Enter to choose)",
 		Items:             roles,
 		Templates:         templates,
 		Searcher:          searcher,
-		StartInSearchMode: true,
-		Size:              10,
+		StartInSearchMode: searchMode,
+		Size:              size,
 	}
 
-	idx, _, err := sel.Run()
+	labels := make([]string, len(roles))
+	for i, r := range roles {
+		labels[i] = fmt.Sprintf("%s (%s)", r.RoleName, r.AccountID)
+	}
+	idx, err := runSelect(sel, labels)
 	if err != nil {
 		return RoleInfo{}, err
 	}
@@ -995,18 +1486,23 @@ func (s *Sso) getSsoCacheDir() (string, error) {
 	return filepath.Join(configDir, "sso", "cache"), nil
 }
 
-func (s *Sso) generateCacheFileName(startURL, sessionName string) string {
+// generateCacheFileName 由 start URL、session 名与 region 生成 token 缓存文件名，
+// 同名 session 指向不同 region 时使用不同的文件。scopes 不参与命名：扩大 scopes 后由 tokenCoversScopes 触发重新授权。
+// region 为空时的文件名与旧版本一致。
+func (s *Sso) generateCacheFileName(startURL, sessionName, region string) string {
 	payload := struct {
 		StartURL    string `json:"start_url"`
 		SessionName string `json:"session_name"`
+		Region      string `json:"region,omitempty"`
 	}{
 		StartURL:    startURL,
 		SessionName: sessionName,
+		Region:      strings.TrimSpace(region),
 	}
 
 	data, err := json.Marshal(payload)
 	if err != nil {
-		data = []byte(startURL + "\n" + sessionName)
+		data = []byte(startURL + "\n" + sessionName + "\n" + region)
 	}
 	hash := sha1.Sum(data)
 	return fmt.Sprintf("%x.json", hash)
@@ -1025,7 +1521,7 @@ func (s *Sso) GetAccessToken() (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("failed to parse access token expiry: %w", err)
 	}
-	if time.Now().After(expTime) {
+	if nowFunc().After(expTime) {
 		return "", fmt.Errorf("your access token has expired. Please log in again using the `sso login` command")
 	}
 
@@ -1035,6 +1531,9 @@ func (s *Sso) GetAccessToken() (string, error) {
 // GetValidAccessToken 获取业务命令可用的 access token。
 // access token 未进入刷新窗口时直接复用；过期或即将过期时仅尝试 refresh_token 静默续期。
 func (s *Sso) GetValidAccessToken() (string, error) {
+	if err := validateSsoRegion(s.Region); err != nil {
+		return "", err
+	}
 	fetcher := newDeviceCodeFetcher(s)
 	tokenCache, err := fetcher.GetValidTokenForBusiness()
 	if err != nil {
@@ -1051,14 +1550,14 @@ func (s *Sso) Login() error {
 		return fmt.Errorf("the SSO information is incomplete. Please configure the profile first")
 	}
 
-	config := ctx.config
-	ssoSession, err := s.loadSsoSession(config)
-	if err != nil {
-		return err
+	if !s.Transient {
+		ssoSession, err := s.loadSsoSession(ctx.config)
+		if err != nil {
+			return err
+		}
+		s.applySessionDefaults(ssoSession)
 	}
 
-	s.applySessionDefaults(ssoSession)
-
 	if strings.TrimSpace(s.StartURL) == "" {
 		return fmt.Errorf("the start URL of SSO session %s is not configured", s.SsoSessionName)
 	}
@@ -1066,13 +1565,49 @@ func (s *Sso) Login() error {
 		return fmt.Errorf("the SSO information is incomplete. Please configure the profile first")
 	}
 
+	if err := validateSsoRegion(s.Region); err != nil {
+		return err
+	}
+
+	s.reusedLoginToken = nil
+	s.loginToken = nil
 	fetcher := newDeviceCodeFetcher(s)
-	if _, err := fetcher.GetFreshTokenForLogin(); err != nil {
-		return fmt.Errorf("failed to obtain the access token: %v", err)
+	var (
+		token  *SsoTokenCache
+		reused bool
+		err    error
+	)
+	if s.Reauth {
+		token, err = fetcher.GetFreshTokenForLogin()
+	} else {
+		token, reused, err = fetcher.GetTokenForLogin()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to obtain the access token: %w", err)
+	}
+	if s.Reauth {
+		// 重新授权通常意味着权限或账号分配已变化，旧 token 换出的角色凭证不再复用
+		if err := s.clearRoleCredentialsCache(); err != nil {
+			fmt.Fprintf(s.messageOut(), "Warning: %v\n", err)
+		}
+	}
+	s.loginToken = token
+	if reused {
+		s.reusedLoginToken = token
 	}
 	return nil
 }
 
+// ReusedLoginToken 返回最近一次 Login 从缓存复用或静默刷新得到的 token；Login 走了设备码授权时返回 nil。
+func (s *Sso) ReusedLoginToken() *SsoTokenCache {
+	return s.reusedLoginToken
+}
+
+// LoginToken 返回最近一次 Login 成功得到的 token，无论是否复用；Login 失败时返回 nil。
+func (s *Sso) LoginToken() *SsoTokenCache {
+	return s.loginToken
+}
+
 func (s *Sso) Logout() error {
 	cfg := ctx.config
 	ssoSession, err := s.loadSsoSession(cfg)
@@ -1084,6 +1619,10 @@ func (s *Sso) Logout() error {
 		return fmt.Errorf("the sign-in URL of SSO session %s is not configured", s.SsoSessionName)
 	}
 
+	if err := s.clearRoleCredentialsCache(); err != nil {
+		return err
+	}
+
 	tokenCache, err := s.readTokenCache()
 	if err != nil {
 		return err
@@ -1142,13 +1681,27 @@ func (s *Sso) clearCachedToken(tokenCache *SsoTokenCache) error {
 	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to remove token cache file: %v", err)
 	}
+	// 旧文件名下属于该 session 的 token 一并清理，避免登出后仍被回退读取
+	if legacy, _ := s.readLegacyTokenCache(); legacy != nil {
+		if legacyPath, err := s.legacyTokenCacheFilePath(); err == nil && legacyPath != "" {
+			if err := os.Remove(legacyPath); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove token cache file: %v", err)
+			}
+		}
+	}
 	return nil
 }
 
+// ssoProfileConfigMu 保护 clearProfileStsCredentials 对配置的修改。
+var ssoProfileConfigMu sync.Mutex
+
 func (s *Sso) clearProfileStsCredentials(cfg *Configure) error {
 	if cfg == nil {
 		return fmt.Errorf("the configuration file cannot be loaded")
 	}
+	// 登出全部 session 时多个 Logout 并发执行，修改与写回配置需串行
+	ssoProfileConfigMu.Lock()
+	defer ssoProfileConfigMu.Unlock()
 	updated := false
 	for name, profile := range cfg.Profiles {
 		if profile == nil || strings.ToLower(strings.TrimSpace(profile.Mode)) != ModeSSO || profile.SsoSessionName != s.SsoSessionName {
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/byteplus-sdk/byteplus-cli/util"
+	"github.com/spf13/cobra"
+)
+
+// SSO 缓存文件的类型。
+const (
+	ssoCacheTypeToken        = "token"
+	ssoCacheTypeRegistration = "registration"
+	ssoCacheTypeTemp         = "temp"
+	ssoCacheTypeUnknown      = "unknown"
+)
+
+// ssoCacheEntry 汇总 SSO 缓存目录中的一个文件。access token、refresh token 与 client secret 不输出，
+// 只通过 HasRefreshToken 等字段说明是否存在。
+type ssoCacheEntry struct {
+	File                  string `json:"File"`
+	Type                  string `json:"Type"`
+	Session               string `json:"Session,omitempty"`
+	StartURL              string `json:"StartURL,omitempty"`
+	Region                string `json:"Region,omitempty"`
+	ClientName            string `json:"ClientName,omitempty"`
+	TokenExpiresAt        string `json:"TokenExpiresAt,omitempty"`
+	ClientSecretExpiresAt string `json:"ClientSecretExpiresAt,omitempty"`
+	HasRefreshToken       bool   `json:"HasRefreshToken,omitempty"`
+	// Status 为 valid、token-expired、client-secret-expired（可同时出现，以逗号分隔）或 unreadable。
+	Status string `json:"Status"`
+}
+
+func newSsoCacheCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Inspect the local SSO token and client registration cache",
+	}
+	cmd.AddCommand(newSsoCacheListCmd())
+	cmd.SetUsageTemplate(ssoUsageTemplate())
+	return cmd
+}
+
+func newSsoCacheListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the cached SSO tokens and client registrations",
+		Long: `List every file in the SSO cache directory (~/.byteplus/sso/cache) with a summary
+of its contents and whether the access token or the client secret has expired.
+Access tokens, refresh tokens and client secrets are never printed.`,
+		Example: `  bp sso cache list
+  bp sso cache list --output table`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format := strings.ToLower(strings.TrimSpace(cmd.Flag("output").Value.String()))
+			if format != outputFormatJSON && format != outputFormatTable {
+				return fmt.Errorf("unsupported --output %q, supported: json, table", format)
+			}
+			cacheDir, err := (&Sso{}).getSsoCacheDir()
+			if err != nil {
+				return err
+			}
+			entries, err := listSsoCache(cacheDir)
+			if err != nil {
+				return err
+			}
+			if format == outputFormatTable {
+				return writeSsoCacheTable(cmd.OutOrStdout(), entries)
+			}
+			return writeSsoList(cmd.OutOrStdout(), map[string]interface{}{"CacheDir": cacheDir, "Files": entries})
+		},
+	}
+	cmd.Flags().String("output", outputFormatJSON, "Output format: json or table")
+	cmd.SetUsageTemplate(ssoUsageTemplate())
+	return cmd
+}
+
+// listSsoCache 按文件名顺序汇总 dir 中的文件；目录不存在时返回空列表。
+func listSsoCache(dir string) ([]ssoCacheEntry, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []ssoCacheEntry{}, nil
+		}
+		return nil, fmt.Errorf("failed to read the SSO cache directory: %w", err)
+	}
+	entries := make([]ssoCacheEntry, 0, len(files))
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		entries = append(entries, describeSsoCacheFile(filepath.Join(dir, f.Name())))
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].File < entries[j].File })
+	return entries, nil
+}
+
+// describeSsoCacheFile 识别缓存文件类型：含 access_token 或 expires_at 的是 token 缓存，
+// 只有 client_id 的是客户端注册缓存，写入中断留下的临时文件标记为 temp。
+func describeSsoCacheFile(path string) ssoCacheEntry {
+	entry := ssoCacheEntry{File: filepath.Base(path), Type: ssoCacheTypeUnknown, Status: "unreadable"}
+	if strings.HasPrefix(entry.File, tempFilePrefix) {
+		entry.Type = ssoCacheTypeTemp
+		return entry
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return entry
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return entry
+	}
+
+	var clientSecretExpiresAt int64
+	var expired []string
+	switch {
+	case fields["access_token"] != nil || fields["expires_at"] != nil:
+		var token SsoTokenCache
+		if err := json.Unmarshal(data, &token); err != nil {
+			return entry
+		}
+		entry.Type = ssoCacheTypeToken
+		entry.Session, entry.StartURL, entry.Region = token.SessionName, token.StartURL, token.Region
+		entry.TokenExpiresAt = token.ExpiresAt
+		entry.HasRefreshToken = token.RefreshToken != ""
+		clientSecretExpiresAt = token.ClientSecretExpiresAt
+		if tokenExpired(token.ExpiresAt) {
+			expired = append(expired, "token-expired")
+		}
+	case fields["client_id"] != nil:
+		var registration clientRegistrationCache
+		if err := json.Unmarshal(data, &registration); err != nil {
+			return entry
+		}
+		entry.Type = ssoCacheTypeRegistration
+		entry.ClientName = registration.ClientName
+		clientSecretExpiresAt = registration.ClientSecretExpiresAt
+	default:
+		return entry
+	}
+	if clientSecretExpiresAt != 0 {
+		entry.ClientSecretExpiresAt = time.UnixMilli(clientSecretExpiresAt).UTC().Format(time.RFC3339)
+	}
+	if clientSecretExpired(clientSecretExpiresAt) {
+		expired = append(expired, "client-secret-expired")
+	}
+	entry.Status = "valid"
+	if len(expired) > 0 {
+		entry.Status = strings.Join(expired, ",")
+	}
+	return entry
+}
+
+func writeSsoCacheTable(out io.Writer, entries []ssoCacheEntry) error {
+	table := util.NewTableWriter(out, 0)
+	table.SetColumns([]string{"File", "Type", "Session", "StartURL", "Region", "TokenExpiresAt", "ClientSecretExpiresAt", "Status"})
+	for _, e := range entries {
+		if err := table.Write(map[string]interface{}{
+			"File":                  e.File,
+			"Type":                  e.Type,
+			"Session":               e.Session,
+			"StartURL":              e.StartURL,
+			"Region":                e.Region,
+			"TokenExpiresAt":        e.TokenExpiresAt,
+			"ClientSecretExpiresAt": e.ClientSecretExpiresAt,
+			"Status":                e.Status,
+		}); err != nil {
+			return err
+		}
+	}
+	return table.Flush()
+}
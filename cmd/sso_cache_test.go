@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSsoCacheListSummarizesFilesWithoutSecrets(t *testing.T) {
+	sso := setupSsoTokenTest(t)
+	now := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	withFixedNow(t, now)
+	cacheTokenForTest(t, sso, &SsoTokenCache{
+		AccessToken:           "secret-access-token",
+		RefreshToken:          "secret-refresh-token",
+		ClientSecret:          "secret-client-secret",
+		ExpiresAt:             now.Add(-time.Minute).Format(time.RFC3339),
+		ClientSecretExpiresAt: now.Add(time.Hour).UnixMilli(),
+	})
+	cacheDir, err := sso.getSsoCacheDir()
+	if err != nil {
+		t.Fatalf("getSsoCacheDir: %v", err)
+	}
+	if err := writeJSONFileAtomic(filepath.Join(cacheDir, "registration.json"), 0600, clientRegistrationCache{
+		ClientName:            "byteplus-cli",
+		ClientID:              "client",
+		ClientSecret:          "secret-registration",
+		ClientSecretExpiresAt: now.Add(-time.Hour).UnixMilli(),
+	}); err != nil {
+		t.Fatalf("write registration: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(cacheDir, tempFilePrefix+"leftover"), []byte("{"), 0600); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+
+	cmd := newSsoCacheListCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("sso cache list error = %v", err)
+	}
+	for _, secret := range []string{"secret-access-token", "secret-refresh-token", "secret-client-secret", "secret-registration"} {
+		if strings.Contains(out.String(), secret) {
+			t.Fatalf("output leaks %s: %s", secret, out.String())
+		}
+	}
+	var got struct {
+		Files []ssoCacheEntry
+	}
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out.String())
+	}
+	byType := map[string]ssoCacheEntry{}
+	for _, e := range got.Files {
+		byType[e.Type] = e
+	}
+	if len(got.Files) != 3 {
+		t.Fatalf("files = %+v, want a token, a registration and a temp file", got.Files)
+	}
+	if token := byType[ssoCacheTypeToken]; token.Session != sso.SsoSessionName || token.Status != "token-expired" || !token.HasRefreshToken {
+		t.Fatalf("token entry = %+v", token)
+	}
+	if reg := byType[ssoCacheTypeRegistration]; reg.ClientName != "byteplus-cli" || reg.Status != "client-secret-expired" {
+		t.Fatalf("registration entry = %+v", reg)
+	}
+	if tmp := byType[ssoCacheTypeTemp]; tmp.File != tempFilePrefix+"leftover" {
+		t.Fatalf("temp entry = %+v", tmp)
+	}
+}
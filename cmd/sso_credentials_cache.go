@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/byteplus-sdk/byteplus-cli/util"
+)
+
+// roleCredentialsMinRemaining 是复用缓存 STS 凭证时要求的最短剩余有效期，
+// 避免返回马上过期的凭证导致紧接着的 API 调用失败。
+const roleCredentialsMinRemaining = 5 * time.Minute
+
+// roleCredentialsCache 是 GetRoleCredentials 结果的磁盘缓存，按 (sessionName, accountId, roleName) 区分，
+// 与 access token 缓存分开存放在 ~/.byteplus/sso/credentials 下。
+type roleCredentialsCache struct {
+	SessionName string          `json:"session_name"`
+	AccountId   string          `json:"account_id"`
+	RoleName    string          `json:"role_name"`
+	Credentials RoleCredentials `json:"credentials"`
+}
+
+func getRoleCredentialsCacheDir() (string, error) {
+	configDir, err := getSsoConfigFileDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "sso", "credentials"), nil
+}
+
+func roleCredentialsCacheFileName(sessionName, accountId, roleName string) string {
+	data, err := json.Marshal([]string{sessionName, accountId, roleName})
+	if err != nil {
+		data = []byte(sessionName + "\n" + accountId + "\n" + roleName)
+	}
+	return fmt.Sprintf("%x.json", sha1.Sum(data))
+}
+
+// readCachedRoleCredentials 返回当前账号/角色仍在有效期内的缓存凭证；缓存不存在、损坏或即将过期时返回 nil。
+func (s *Sso) readCachedRoleCredentials() *RoleCredentials {
+	cacheDir, err := getRoleCredentialsCacheDir()
+	if err != nil {
+		return nil
+	}
+	filePath := filepath.Join(cacheDir, roleCredentialsCacheFileName(s.SsoSessionName, s.Profile.AccountId, s.Profile.RoleName))
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil
+	}
+	var cached roleCredentialsCache
+	if err := json.Unmarshal(data, &cached); err != nil {
+		_ = os.Remove(filePath)
+		return nil
+	}
+	if cached.SessionName != s.SsoSessionName || cached.AccountId != s.Profile.AccountId || cached.RoleName != s.Profile.RoleName {
+		return nil
+	}
+	creds := cached.Credentials
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" || creds.Expiration <= 0 {
+		return nil
+	}
+	if !nowFunc().Add(roleCredentialsMinRemaining).Before(util.UnixTimestampToTime(creds.Expiration)) {
+		return nil
+	}
+	return &creds
+}
+
+// storeRoleCredentials 写入凭证缓存。缓存只用于减少 Portal 调用，写入失败不影响本次获取的凭证。
+func (s *Sso) storeRoleCredentials(creds *RoleCredentials) {
+	if creds == nil || creds.Expiration <= 0 {
+		return
+	}
+	cacheDir, err := getRoleCredentialsCacheDir()
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(cacheDir, configDirPerm()); err != nil {
+		return
+	}
+	_ = os.Chmod(cacheDir, configDirPerm())
+	filePath := filepath.Join(cacheDir, roleCredentialsCacheFileName(s.SsoSessionName, s.Profile.AccountId, s.Profile.RoleName))
+	_ = writeJSONFileAtomic(filePath, configFilePerm(), &roleCredentialsCache{
+		SessionName: s.SsoSessionName,
+		AccountId:   s.Profile.AccountId,
+		RoleName:    s.Profile.RoleName,
+		Credentials: *creds,
+	})
+}
+
+// clearRoleCredentialsCache 删除当前 SSO session 的全部缓存凭证，在 logout 时调用。
+func (s *Sso) clearRoleCredentialsCache() error {
+	cacheDir, err := getRoleCredentialsCacheDir()
+	if err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(cacheDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read the credentials cache directory: %v", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		filePath := filepath.Join(cacheDir, entry.Name())
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			continue
+		}
+		var cached roleCredentialsCache
+		// 无法解析的文件同样删除，避免残留凭证
+		if err := json.Unmarshal(data, &cached); err == nil && cached.SessionName != s.SsoSessionName {
+			continue
+		}
+		if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove credentials cache file: %v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,341 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// ssoDoctorMaxClockSkew is the largest local clock offset from the identity
+// service that is reported as healthy. Token expiry checks are made against
+// the local clock, so a larger skew makes valid tokens look expired or the
+// other way round.
+const ssoDoctorMaxClockSkew = 5 * time.Minute
+
+var (
+	// ssoDoctorHTTPClient sends the reachability probes. It uses the default
+	// transport so HTTPS_PROXY/NO_PROXY apply the same way as for login.
+	ssoDoctorHTTPClient = &http.Client{Timeout: 5 * time.Second}
+	// ssoDoctorEndpoints returns the OAuth and Portal base URLs probed for a region.
+	ssoDoctorEndpoints = func(region string) []string {
+		return []string{
+			fmt.Sprintf(oAuthBaseURLTemplate, region),
+			fmt.Sprintf(portalBaseURLTemplate, region),
+		}
+	}
+)
+
+const (
+	doctorPass = "PASS"
+	doctorWarn = "WARN"
+	doctorFail = "FAIL"
+)
+
+type doctorResult struct {
+	status string
+	check  string
+	detail string
+	hint   string
+}
+
+// ssoDoctor collects the results of the checks run by `bp sso doctor`.
+type ssoDoctor struct {
+	cfg     *Configure
+	results []doctorResult
+}
+
+func newSsoDoctorCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose common SSO login problems",
+		Long: `Run a series of checks for common SSO problems and print a pass/fail report:
+the config file, sso-session settings, the SSO cache directory, reachability
+of the OAuth and Portal endpoints, local clock skew, and cached token validity.`,
+		Example: `  # Check all sso-sessions
+  bp sso doctor
+  # Check a single sso-session
+  bp sso doctor --sso-session my-sso-session`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sessionName := strings.TrimSpace(cmd.Flag("sso-session").Value.String())
+			return runSsoDoctor(ctx.config, sessionName, cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().String("sso-session", "", "Only check the specified SSO session")
+
+	cmd.SetUsageTemplate(ssoUsageTemplate())
+	registerConfigNameCompletions(cmd)
+
+	return cmd
+}
+
+// runSsoDoctor runs every check, writes the report to out and returns an
+// error when at least one check failed.
+func runSsoDoctor(cfg *Configure, sessionName string, out io.Writer) error {
+	d := &ssoDoctor{cfg: cfg}
+	d.run(sessionName)
+
+	failed := 0
+	for _, r := range d.results {
+		fmt.Fprintf(out, "[%s] %s: %s\n", r.status, r.check, r.detail)
+		if r.hint != "" && r.status != doctorPass {
+			fmt.Fprintf(out, "       hint: %s\n", r.hint)
+		}
+		if r.status == doctorFail {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("sso doctor found %d problem(s)", failed)
+	}
+	fmt.Fprintln(out, "all checks passed")
+	return nil
+}
+
+func (d *ssoDoctor) add(status, check, detail, hint string) {
+	d.results = append(d.results, doctorResult{status: status, check: check, detail: detail, hint: hint})
+}
+
+func (d *ssoDoctor) run(sessionName string) {
+	if !d.checkConfig() {
+		return
+	}
+	sessions := d.checkSessions(sessionName)
+	d.checkCacheDir()
+	if len(sessions) == 0 {
+		return
+	}
+	d.checkEndpoints(sessions)
+	for _, name := range sessions {
+		d.checkCachedToken(name, d.cfg.SsoSession[name])
+	}
+}
+
+func (d *ssoDoctor) checkConfig() bool {
+	configPath := ConfigFile
+	if dir, err := configFileDirFunc(); err == nil {
+		configPath = filepath.Join(dir, ConfigFile)
+	}
+	if d.cfg == nil {
+		d.add(doctorFail, "config", fmt.Sprintf("%s cannot be loaded", configPath),
+			"make sure the file is valid JSON and readable by the current user")
+		return false
+	}
+	d.add(doctorPass, "config", fmt.Sprintf("loaded %s", configPath), "")
+	return true
+}
+
+// checkSessions validates the selected sso-sessions and returns the names of
+// those that are well-formed enough for the network and token checks.
+func (d *ssoDoctor) checkSessions(sessionName string) []string {
+	var names []string
+	if sessionName != "" {
+		if _, ok := d.cfg.SsoSession[sessionName]; !ok {
+			d.add(doctorFail, "sso-session "+sessionName, "not found in the config file",
+				fmt.Sprintf("run 'bp sso login --sso-session %s --start-url URL' or 'bp configure sso-session' to create it", sessionName))
+			return nil
+		}
+		names = []string{sessionName}
+	} else {
+		for name := range d.cfg.SsoSession {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		d.checkProfileSessions()
+	}
+	if len(names) == 0 {
+		d.add(doctorFail, "sso-session", "no sso-session configured",
+			"run 'bp configure sso-session' or 'bp sso login --sso-session NAME --start-url URL'")
+		return nil
+	}
+
+	var valid []string
+	for _, name := range names {
+		if d.checkSession(name, d.cfg.SsoSession[name]) {
+			valid = append(valid, name)
+		}
+	}
+	return valid
+}
+
+func (d *ssoDoctor) checkSession(name string, session *SsoSession) bool {
+	check := "sso-session " + name
+	hint := fmt.Sprintf("run 'bp configure sso-session --name %s' to fix it", name)
+	if session == nil {
+		d.add(doctorFail, check, "the entry is empty", hint)
+		return false
+	}
+	u, err := url.Parse(strings.TrimSpace(session.StartURL))
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		d.add(doctorFail, check, fmt.Sprintf("start-url %q is not a valid https URL", session.StartURL), hint)
+		return false
+	}
+	if err := validateSsoRegion(session.Region); err != nil {
+		d.add(doctorFail, check, err.Error(), hint)
+		return false
+	}
+	if _, err := normalizeRegistrationScopes(session.RegistrationScopes); err != nil {
+		d.add(doctorFail, check, err.Error(), hint)
+		return false
+	}
+	d.add(doctorPass, check, fmt.Sprintf("start-url %s, region %s", session.StartURL, ssoDoctorRegion(session)), "")
+	return true
+}
+
+// checkProfileSessions reports sso profiles whose sso-session no longer exists.
+func (d *ssoDoctor) checkProfileSessions() {
+	var names []string
+	for name := range d.cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		profile := d.cfg.Profiles[name]
+		if profile == nil || strings.ToLower(strings.TrimSpace(profile.Mode)) != ModeSSO {
+			continue
+		}
+		if _, ok := d.cfg.SsoSession[profile.SsoSessionName]; !ok {
+			d.add(doctorFail, "profile "+name, fmt.Sprintf("references missing sso-session %q", profile.SsoSessionName),
+				fmt.Sprintf("run 'bp configure sso --profile %s' to bind it to an existing sso-session", name))
+		}
+	}
+}
+
+func (d *ssoDoctor) checkCacheDir() {
+	cacheDir, err := (&Sso{}).getSsoCacheDir()
+	if err != nil {
+		d.add(doctorFail, "cache directory", err.Error(), "set HOME so the config directory can be resolved")
+		return
+	}
+	hint := fmt.Sprintf("make sure %s is owned by the current user, e.g. chmod %04o %s", cacheDir, configDirPerm(), cacheDir)
+	if err := os.MkdirAll(cacheDir, configDirPerm()); err != nil {
+		d.add(doctorFail, "cache directory", fmt.Sprintf("cannot create %s: %v", cacheDir, err), hint)
+		return
+	}
+	probe, err := os.CreateTemp(cacheDir, ".doctor-*")
+	if err != nil {
+		d.add(doctorFail, "cache directory", fmt.Sprintf("%s is not writable: %v", cacheDir, err), hint)
+		return
+	}
+	_ = probe.Close()
+	_ = os.Remove(probe.Name())
+
+	info, err := os.Stat(cacheDir)
+	if err != nil {
+		d.add(doctorFail, "cache directory", err.Error(), hint)
+		return
+	}
+	if perm := info.Mode().Perm(); perm&^configDirPerm() != 0 {
+		d.add(doctorWarn, "cache directory", fmt.Sprintf("%s has mode %04o, expected at most %04o", cacheDir, perm, configDirPerm()), hint)
+		return
+	}
+
+	entries, _ := os.ReadDir(cacheDir)
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || entry.IsDir() {
+			continue
+		}
+		if perm := info.Mode().Perm(); perm&^configFilePerm() != 0 {
+			path := filepath.Join(cacheDir, entry.Name())
+			d.add(doctorWarn, "cache directory", fmt.Sprintf("%s has mode %04o, expected at most %04o", path, perm, configFilePerm()),
+				fmt.Sprintf("chmod %04o %s", configFilePerm(), path))
+			return
+		}
+	}
+	d.add(doctorPass, "cache directory", fmt.Sprintf("%s is writable", cacheDir), "")
+}
+
+// checkEndpoints probes the OAuth and Portal endpoints of every region in use
+// and estimates the clock skew from the Date header of the responses.
+func (d *ssoDoctor) checkEndpoints(sessions []string) {
+	seen := map[string]bool{}
+	var serverTime time.Time
+	var localTime time.Time
+	for _, name := range sessions {
+		region := ssoDoctorRegion(d.cfg.SsoSession[name])
+		if seen[region] {
+			continue
+		}
+		seen[region] = true
+		for _, endpoint := range ssoDoctorEndpoints(region) {
+			req, err := http.NewRequestWithContext(commandContext, http.MethodHead, endpoint, nil)
+			if err != nil {
+				d.add(doctorFail, "endpoint", err.Error(), "")
+				continue
+			}
+			resp, err := ssoDoctorHTTPClient.Do(req)
+			if err != nil {
+				d.add(doctorFail, "endpoint", fmt.Sprintf("cannot reach %s: %v", endpoint, err),
+					"check the network connection and the HTTPS_PROXY/HTTP_PROXY/NO_PROXY environment variables")
+				continue
+			}
+			_ = resp.Body.Close()
+			d.add(doctorPass, "endpoint", fmt.Sprintf("%s is reachable (HTTP %d)", endpoint, resp.StatusCode), "")
+			if serverTime.IsZero() {
+				if t, err := http.ParseTime(resp.Header.Get("Date")); err == nil {
+					serverTime, localTime = t, nowFunc()
+				}
+			}
+		}
+	}
+
+	if serverTime.IsZero() {
+		d.add(doctorWarn, "clock", "could not determine the server time", "")
+		return
+	}
+	skew := localTime.Sub(serverTime)
+	if skew < 0 {
+		skew = -skew
+	}
+	skew = skew.Round(time.Second)
+	if skew > ssoDoctorMaxClockSkew {
+		d.add(doctorFail, "clock", fmt.Sprintf("local clock differs from the server by %s", skew),
+			"synchronize the system clock, e.g. enable NTP")
+		return
+	}
+	d.add(doctorPass, "clock", fmt.Sprintf("local clock is within %s of the server", skew), "")
+}
+
+func (d *ssoDoctor) checkCachedToken(name string, session *SsoSession) {
+	check := "token " + name
+	loginHint := fmt.Sprintf("run 'bp sso login --sso-session %s'", name)
+	s := &Sso{SsoSessionName: name, StartURL: session.StartURL, Region: session.Region}
+	token, err := s.readTokenCache()
+	if err != nil {
+		d.add(doctorFail, check, err.Error(), loginHint)
+		return
+	}
+	if token == nil || strings.TrimSpace(token.AccessToken) == "" {
+		d.add(doctorWarn, check, "not logged in", loginHint)
+		return
+	}
+	if !tokenExpired(token.ExpiresAt) {
+		if tokenNeedsRefresh(token.ExpiresAt) {
+			d.add(doctorPass, check, fmt.Sprintf("expires at %s and will be refreshed on next use", token.ExpiresAt), "")
+			return
+		}
+		d.add(doctorPass, check, fmt.Sprintf("valid until %s", token.ExpiresAt), "")
+		return
+	}
+	if strings.TrimSpace(token.RefreshToken) != "" && !clientSecretExpired(token.ClientSecretExpiresAt) {
+		d.add(doctorWarn, check, fmt.Sprintf("access token expired at %s; it will be refreshed on next use", token.ExpiresAt), loginHint+" if the refresh fails")
+		return
+	}
+	d.add(doctorFail, check, fmt.Sprintf("access token expired at %s and cannot be refreshed", token.ExpiresAt), loginHint)
+}
+
+func ssoDoctorRegion(session *SsoSession) string {
+	if session == nil || strings.TrimSpace(session.Region) == "" {
+		return defaultOAuthRegion
+	}
+	return strings.TrimSpace(session.Region)
+}
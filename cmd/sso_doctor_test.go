@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func withSsoDoctorServer(t *testing.T, serverTime time.Time) *int {
+	t.Helper()
+	probes := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		probes++
+		w.Header().Set("Date", serverTime.UTC().Format(http.TimeFormat))
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(server.Close)
+
+	oldEndpoints := ssoDoctorEndpoints
+	oldClient := ssoDoctorHTTPClient
+	ssoDoctorEndpoints = func(region string) []string {
+		return []string{server.URL + "/oauth/" + region, server.URL + "/portal/" + region}
+	}
+	ssoDoctorHTTPClient = server.Client()
+	t.Cleanup(func() {
+		ssoDoctorEndpoints = oldEndpoints
+		ssoDoctorHTTPClient = oldClient
+	})
+	return &probes
+}
+
+func TestSsoDoctorReportsHealthySetup(t *testing.T) {
+	sso := setupSsoTokenTest(t)
+	withTestConfigDir(t)
+	probes := withSsoDoctorServer(t, time.Now())
+	cacheTokenForTest(t, sso, &SsoTokenCache{
+		AccessToken:           "access",
+		ExpiresAt:             time.Now().Add(time.Hour).Format(time.RFC3339),
+		ClientId:              "client-id",
+		ClientSecret:          "client-secret",
+		ClientSecretExpiresAt: validClientSecretExpiry(),
+	})
+	cfg := &Configure{
+		Profiles: map[string]*Profile{},
+		SsoSession: map[string]*SsoSession{
+			sso.SsoSessionName: {Name: sso.SsoSessionName, StartURL: sso.StartURL, Region: sso.Region},
+		},
+	}
+
+	var out bytes.Buffer
+	if err := runSsoDoctor(cfg, "", &out); err != nil {
+		t.Fatalf("runSsoDoctor() error = %v\n%s", err, out.String())
+	}
+	report := out.String()
+	for _, want := range []string{
+		"[PASS] config:",
+		"[PASS] sso-session test-session: start-url https://example.com/userportal, region cn-beijing",
+		"[PASS] cache directory:",
+		"[PASS] clock:",
+		"[PASS] token test-session: valid until",
+		"all checks passed",
+	} {
+		if !strings.Contains(report, want) {
+			t.Fatalf("report missing %q:\n%s", want, report)
+		}
+	}
+	if *probes != 2 {
+		t.Fatalf("endpoint probes = %d, want 2", *probes)
+	}
+}
+
+func TestSsoDoctorReportsProblemsWithHints(t *testing.T) {
+	sso := setupSsoTokenTest(t)
+	withTestConfigDir(t)
+	withSsoDoctorServer(t, time.Now().Add(-time.Hour))
+	cfg := &Configure{
+		Profiles: map[string]*Profile{
+			"orphan": {Name: "orphan", Mode: ModeSSO, SsoSessionName: "gone"},
+		},
+		SsoSession: map[string]*SsoSession{
+			"broken":           {Name: "broken", StartURL: "http://example.com/userportal", Region: sso.Region},
+			sso.SsoSessionName: {Name: sso.SsoSessionName, StartURL: sso.StartURL, Region: sso.Region},
+		},
+	}
+
+	var out bytes.Buffer
+	err := runSsoDoctor(cfg, "", &out)
+	if err == nil || !strings.Contains(err.Error(), "found 3 problem(s)") {
+		t.Fatalf("runSsoDoctor() error = %v, want 3 problems\n%s", err, out.String())
+	}
+	report := out.String()
+	for _, want := range []string{
+		`[FAIL] profile orphan: references missing sso-session "gone"`,
+		`[FAIL] sso-session broken: start-url "http://example.com/userportal" is not a valid https URL`,
+		"[FAIL] clock: local clock differs from the server by 1h",
+		"hint: synchronize the system clock",
+		"[WARN] token test-session: not logged in",
+		"hint: run 'bp sso login --sso-session test-session'",
+	} {
+		if !strings.Contains(report, want) {
+			t.Fatalf("report missing %q:\n%s", want, report)
+		}
+	}
+}
+
+func TestSsoDoctorFailsWithoutConfig(t *testing.T) {
+	withTestConfigDir(t)
+	var out bytes.Buffer
+	if err := runSsoDoctor(nil, "", &out); err == nil {
+		t.Fatal("runSsoDoctor(nil) error = nil, want failure")
+	}
+	if !strings.Contains(out.String(), "[FAIL] config:") {
+		t.Fatalf("report = %q, want config failure", out.String())
+	}
+}
@@ -0,0 +1,343 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/byteplus-sdk/byteplus-cli/util"
+	"github.com/spf13/cobra"
+)
+
+// ssoListPage is the --page-number/--page-size selection of sso list-accounts
+// and list-roles. When neither flag is set every page is fetched.
+type ssoListPage struct {
+	number int
+	size   int
+}
+
+func (p ssoListPage) explicit() bool {
+	return p.number > 0 || p.size > 0
+}
+
+// ssoListPageFromFlags reads the page flags. --page-size alone selects the
+// first page of that size.
+func ssoListPageFromFlags(cmd *cobra.Command) (ssoListPage, error) {
+	var p ssoListPage
+	var err error
+	if p.number, err = cmd.Flags().GetInt("page-number"); err != nil {
+		return p, err
+	}
+	if p.size, err = cmd.Flags().GetInt("page-size"); err != nil {
+		return p, err
+	}
+	if p.number < 0 || p.size < 0 || (cmd.Flags().Changed("page-number") && p.number == 0) || (cmd.Flags().Changed("page-size") && p.size == 0) {
+		return p, fmt.Errorf("--page-number and --page-size must be positive")
+	}
+	if p.size > 0 && p.number == 0 {
+		p.number = 1
+	}
+	return p, nil
+}
+
+// ssoForListing returns the logged-in session selected by --profile,
+// --sso-session or BYTEPLUS_PROFILE, or the only configured session.
+func ssoForListing(cmd *cobra.Command) (*Sso, error) {
+	cfg := ctx.config
+	if cfg == nil {
+		return nil, fmt.Errorf("the configuration file cannot be loaded")
+	}
+	profileName := strings.TrimSpace(cmd.Flag("profile").Value.String())
+	sessionName := strings.TrimSpace(cmd.Flag("sso-session").Value.String())
+	if profileName != "" && sessionName != "" {
+		return nil, fmt.Errorf("--profile and --sso-session cannot be used together")
+	}
+	if profileName == "" && sessionName == "" {
+		profileName = ssoProfileNameFromEnv(cfg)
+	}
+	if profileName != "" {
+		profile, ok := cfg.Profiles[profileName]
+		if !ok {
+			return nil, fmt.Errorf("the specified profile was not found: %s", profileName)
+		}
+		if strings.ToLower(strings.TrimSpace(profile.Mode)) != ModeSSO || strings.TrimSpace(profile.SsoSessionName) == "" {
+			return nil, fmt.Errorf("the specified profile is not an sso profile bound to an sso-session: %s", profileName)
+		}
+		sessionName = profile.SsoSessionName
+	}
+	if sessionName == "" {
+		switch len(cfg.SsoSession) {
+		case 0:
+			return nil, fmt.Errorf("no sso-session configured")
+		case 1:
+			for name := range cfg.SsoSession {
+				sessionName = name
+			}
+		default:
+			names := make([]string, 0, len(cfg.SsoSession))
+			for name := range cfg.SsoSession {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("multiple sso-sessions are configured, specify one with --sso-session: %s", strings.Join(names, ", "))
+		}
+	}
+	session, ok := cfg.SsoSession[sessionName]
+	if !ok || session == nil {
+		return nil, fmt.Errorf("the specified sso-session was not found: %s", sessionName)
+	}
+	return &Sso{
+		SsoSessionName: sessionName,
+		StartURL:       session.StartURL,
+		Region:         session.Region,
+	}, nil
+}
+
+func newSsoListAccountsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list-accounts",
+		Short: "List the accounts available to an SSO session",
+		Long: `List the accounts the cached access token of an SSO session can access.
+All pages are fetched unless --page-number or --page-size selects a single page.
+Run 'bp sso login' first.`,
+		Example: `  # List every account
+  bp sso list-accounts --sso-session my-sso-session
+  # Fetch only the third page of 50 accounts
+  bp sso list-accounts --sso-session my-sso-session --page-number 3 --page-size 50`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			page, err := ssoListPageFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+			sso, err := ssoForListing(cmd)
+			if err != nil {
+				return err
+			}
+			accessToken, err := sso.GetValidAccessToken()
+			if err != nil {
+				return err
+			}
+			client := sso.portalClient()
+
+			if !page.explicit() {
+				accounts, err := sso.fetchAllAccounts(commandContext, client, accessToken)
+				if err != nil {
+					return err
+				}
+				return writeSsoList(cmd.OutOrStdout(), map[string]interface{}{"Accounts": accounts})
+			}
+			resp, err := client.ListAccounts(commandContext, &ListAccountsRequest{
+				AccessToken: accessToken,
+				PageNumber:  page.number,
+				PageSize:    page.size,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to list accounts: %w", err)
+			}
+			return writeSsoList(cmd.OutOrStdout(), ssoListPageResult("Accounts", resp.AccountList, resp.Total, resp.PageNumber, resp.PageSize, resp.NextToken))
+		},
+	}
+	addSsoListFlags(cmd)
+	return cmd
+}
+
+func newSsoListRolesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list-roles",
+		Short: "List the roles available in an account to an SSO session",
+		Long: `List the roles of --account-id that the cached access token of an SSO session can assume.
+All pages are fetched unless --page-number or --page-size selects a single page.
+Run 'bp sso login' first.`,
+		Example: `  # List every role in an account
+  bp sso list-roles --sso-session my-sso-session --account-id 2100000000
+  # Fetch only the first page of 20 roles
+  bp sso list-roles --sso-session my-sso-session --account-id 2100000000 --page-size 20`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			accountID := strings.TrimSpace(cmd.Flag("account-id").Value.String())
+			if accountID == "" {
+				return fmt.Errorf("--account-id is required")
+			}
+			page, err := ssoListPageFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+			sso, err := ssoForListing(cmd)
+			if err != nil {
+				return err
+			}
+			accessToken, err := sso.GetValidAccessToken()
+			if err != nil {
+				return err
+			}
+			client := sso.portalClient()
+
+			if !page.explicit() {
+				roles, err := sso.fetchAllRoles(commandContext, client, accessToken, accountID)
+				if err != nil {
+					return err
+				}
+				return writeSsoList(cmd.OutOrStdout(), map[string]interface{}{"Roles": roles})
+			}
+			resp, err := client.ListAccountRoles(commandContext, &ListAccountRolesRequest{
+				AccessToken: accessToken,
+				AccountID:   accountID,
+				PageNumber:  page.number,
+				PageSize:    page.size,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to list roles for account %s: %w", accountID, err)
+			}
+			return writeSsoList(cmd.OutOrStdout(), ssoListPageResult("Roles", resp.RoleList, resp.Total, resp.PageNumber, resp.PageSize, resp.NextToken))
+		},
+	}
+	cmd.Flags().String("account-id", "", "Account whose roles are listed")
+	addSsoListFlags(cmd)
+	return cmd
+}
+
+// ssoAssignmentConcurrency limits how many accounts list-assignments fetches
+// roles for at the same time. Each lookup may itself fetch pages concurrently.
+const ssoAssignmentConcurrency = 4
+
+// ssoAssignment is one account of sso list-assignments with the names of the
+// roles the session can assume in it.
+type ssoAssignment struct {
+	AccountID   string   `json:"AccountId"`
+	AccountName string   `json:"AccountName"`
+	Roles       []string `json:"Roles"`
+}
+
+func newSsoListAssignmentsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list-assignments",
+		Short: "List every account and its roles available to an SSO session",
+		Long: `List the accounts the cached access token of an SSO session can access,
+together with the roles it can assume in each account.
+Roles of several accounts are fetched concurrently. Run 'bp sso login' first.`,
+		Example: `  # Print accounts and roles as JSON
+  bp sso list-assignments --sso-session my-sso-session
+  # Print one row per role, grouped by account
+  bp sso list-assignments --sso-session my-sso-session --output table`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format := strings.ToLower(strings.TrimSpace(cmd.Flag("output").Value.String()))
+			if format != outputFormatJSON && format != outputFormatTable {
+				return fmt.Errorf("unsupported --output %q, supported: json, table", format)
+			}
+			sso, err := ssoForListing(cmd)
+			if err != nil {
+				return err
+			}
+			accessToken, err := sso.GetValidAccessToken()
+			if err != nil {
+				return err
+			}
+			assignments, err := sso.fetchAllAssignments(sso.portalClient(), accessToken)
+			if err != nil {
+				return err
+			}
+			if format == outputFormatTable {
+				return writeSsoAssignmentsTable(cmd.OutOrStdout(), assignments)
+			}
+			return writeSsoList(cmd.OutOrStdout(), map[string]interface{}{"Assignments": assignments})
+		},
+	}
+	cmd.Flags().String("profile", "", "SSO profile whose sso-session is used")
+	cmd.Flags().String("sso-session", "", "SSO session to use when no profile is provided")
+	cmd.Flags().String("output", outputFormatJSON, "Output format: json or table")
+	cmd.SetUsageTemplate(ssoUsageTemplate())
+	registerConfigNameCompletions(cmd)
+	return cmd
+}
+
+// fetchAllAssignments lists every account and then the roles of each account
+// with at most ssoAssignmentConcurrency lookups in flight. Accounts keep the
+// portal order. Any failed lookup fails the whole listing.
+func (s *Sso) fetchAllAssignments(client PortalClientAPI, accessToken string) ([]ssoAssignment, error) {
+	accounts, err := s.fetchAllAccounts(commandContext, client, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	results := util.RunBounded(len(accounts), util.BoundedOptions{Concurrency: ssoAssignmentConcurrency}, func(i int) (interface{}, error) {
+		return s.fetchAllRoles(commandContext, client, accessToken, accounts[i].AccountID)
+	})
+
+	assignments := make([]ssoAssignment, len(accounts))
+	var failures []string
+	for _, r := range results {
+		if r.Err != nil {
+			failures = append(failures, r.Err.Error())
+			continue
+		}
+		account := accounts[r.Index]
+		roles, _ := r.Value.([]RoleInfo)
+		names := make([]string, 0, len(roles))
+		for _, role := range roles {
+			names = append(names, role.RoleName)
+		}
+		assignments[r.Index] = ssoAssignment{AccountID: account.AccountID, AccountName: account.AccountName, Roles: names}
+	}
+	if len(failures) > 0 {
+		return nil, fmt.Errorf("failed to list assignments: %s", strings.Join(failures, "; "))
+	}
+	return assignments, nil
+}
+
+// writeSsoAssignmentsTable prints one row per role. The account columns are
+// filled only on the first row of each account; an account without roles
+// still gets a row with an empty RoleName.
+func writeSsoAssignmentsTable(out io.Writer, assignments []ssoAssignment) error {
+	table := util.NewTableWriter(out, 0)
+	table.SetColumns([]string{"AccountId", "AccountName", "RoleName"})
+	for _, a := range assignments {
+		roles := a.Roles
+		if len(roles) == 0 {
+			roles = []string{""}
+		}
+		for i, role := range roles {
+			row := map[string]interface{}{"RoleName": role}
+			if i == 0 {
+				row["AccountId"] = a.AccountID
+				row["AccountName"] = a.AccountName
+			}
+			if err := table.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	return table.Flush()
+}
+
+func addSsoListFlags(cmd *cobra.Command) {
+	cmd.Flags().String("profile", "", "SSO profile whose sso-session is used")
+	cmd.Flags().String("sso-session", "", "SSO session to use when no profile is provided")
+	cmd.Flags().Int("page-number", 0, "Fetch only this page instead of all pages")
+	cmd.Flags().Int("page-size", 0, "Page size of the single page to fetch (default from the portal)")
+	cmd.SetUsageTemplate(ssoUsageTemplate())
+	registerConfigNameCompletions(cmd)
+}
+
+// ssoListPageResult describes a single requested page. NextToken is an
+// opaque token for the following page, empty on the last page.
+func ssoListPageResult(key string, items interface{}, total, pageNumber, pageSize int, nextToken string) map[string]interface{} {
+	return map[string]interface{}{
+		key:          items,
+		"Total":      total,
+		"PageNumber": pageNumber,
+		"PageSize":   pageSize,
+		"NextToken":  nextToken,
+	}
+}
+
+// writeSsoList prints the listing as indented JSON, the layout of the default
+// action output without color.
+func writeSsoList(out io.Writer, result map[string]interface{}) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "    ")
+	return encoder.Encode(result)
+}
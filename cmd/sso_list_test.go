@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func setupSsoListTest(t *testing.T) *fakePortalClient {
+	t.Helper()
+	sso := setupSsoTokenTest(t)
+	withTestConfigDir(t)
+	withTestCtxConfig(t, &Configure{
+		Profiles:   map[string]*Profile{},
+		SsoSession: map[string]*SsoSession{"test-session": {Name: "test-session", StartURL: sso.StartURL, Region: sso.Region}},
+	})
+	cacheTokenForTest(t, sso, &SsoTokenCache{AccessToken: "access", ExpiresAt: time.Now().Add(time.Hour).Format(time.RFC3339)})
+	fakePortal := &fakePortalClient{}
+	newPortalClientForSSO = func(string) PortalClientAPI { return fakePortal }
+	return fakePortal
+}
+
+func TestSsoListAccountsFetchesOnlyRequestedPage(t *testing.T) {
+	fakePortal := setupSsoListTest(t)
+	fakePortal.accountsResp = &ListAccountsResponse{
+		Total:       120,
+		PageNumber:  3,
+		PageSize:    50,
+		AccountList: []AccountInfo{{AccountID: "2100000101", AccountName: "acc"}},
+		NextToken:   "",
+	}
+
+	cmd := newSsoListAccountsCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--page-number", "3", "--page-size", "50"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("sso list-accounts error = %v", err)
+	}
+
+	if len(fakePortal.accountRequests) != 1 {
+		t.Fatalf("ListAccounts calls = %d, want 1", len(fakePortal.accountRequests))
+	}
+	if req := fakePortal.accountRequests[0]; req.PageNumber != 3 || req.PageSize != 50 || req.AccessToken != "access" {
+		t.Fatalf("ListAccounts request = %#v", req)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out.String())
+	}
+	if got["PageNumber"] != float64(3) || got["Total"] != float64(120) {
+		t.Fatalf("output = %s", out.String())
+	}
+	if accounts, _ := got["Accounts"].([]interface{}); len(accounts) != 1 {
+		t.Fatalf("accounts = %#v", got["Accounts"])
+	}
+}
+
+func TestSsoListRolesPageSizeSelectsFirstPage(t *testing.T) {
+	fakePortal := setupSsoListTest(t)
+	fakePortal.rolesResp = &ListAccountRolesResponse{Total: 30, PageNumber: 1, PageSize: 20, NextToken: "2", RoleList: []RoleInfo{{AccountID: "1", RoleName: "admin"}}}
+
+	cmd := newSsoListRolesCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--account-id", "1", "--page-size", "20"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("sso list-roles error = %v", err)
+	}
+	if len(fakePortal.roleRequests) != 1 {
+		t.Fatalf("ListAccountRoles calls = %d, want 1 even though NextToken is set", len(fakePortal.roleRequests))
+	}
+	if req := fakePortal.roleRequests[0]; req.PageNumber != 1 || req.PageSize != 20 || req.AccountID != "1" {
+		t.Fatalf("ListAccountRoles request = %#v", req)
+	}
+	if !strings.Contains(out.String(), `"NextToken": "2"`) {
+		t.Fatalf("output = %s", out.String())
+	}
+
+	for _, args := range [][]string{{"--page-number", "1"}, {"--account-id", "1", "--page-number", "0"}} {
+		cmd = newSsoListRolesCmd()
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err == nil {
+			t.Fatalf("sso list-roles %v succeeded, want an error", args)
+		}
+	}
+}
+
+func TestSsoListAccountsWithoutPageFlagsFetchesAll(t *testing.T) {
+	fakePortal := setupSsoListTest(t)
+	fakePortal.accountsResp = &ListAccountsResponse{Total: 1, PageNumber: 1, PageSize: 10, AccountList: []AccountInfo{{AccountID: "1"}}}
+
+	cmd := newSsoListAccountsCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("sso list-accounts error = %v", err)
+	}
+	if req := fakePortal.accountRequests[0]; req.PageNumber != 0 || req.PageSize != 0 {
+		t.Fatalf("first ListAccounts request = %#v, want the default full walk", req)
+	}
+	if strings.Contains(out.String(), "PageNumber") {
+		t.Fatalf("full listing includes page fields: %s", out.String())
+	}
+}
+
+func TestSsoListAssignmentsGroupsRolesByAccount(t *testing.T) {
+	fakePortal := setupSsoListTest(t)
+	fakePortal.accountsResp = &ListAccountsResponse{AccountList: []AccountInfo{{AccountID: "1", AccountName: "dev"}, {AccountID: "2", AccountName: "prod"}}}
+	fakePortal.rolesByAccount = map[string][]RoleInfo{
+		"1": {{AccountID: "1", RoleName: "admin"}, {AccountID: "1", RoleName: "reader"}},
+	}
+
+	cmd := newSsoListAssignmentsCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("sso list-assignments error = %v", err)
+	}
+	var got struct{ Assignments []ssoAssignment }
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out.String())
+	}
+	if len(got.Assignments) != 2 || got.Assignments[0].AccountID != "1" || len(got.Assignments[0].Roles) != 2 || len(got.Assignments[1].Roles) != 0 {
+		t.Fatalf("assignments = %#v", got.Assignments)
+	}
+
+	cmd = newSsoListAssignmentsCmd()
+	out.Reset()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--output", "table"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("sso list-assignments --output table error = %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(out.String()), "\n"); len(lines) < 4 || !strings.Contains(out.String(), "reader") || !strings.Contains(out.String(), "prod") {
+		t.Fatalf("table output = %s", out.String())
+	}
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// roleFallbackWarningOut 为改用备用角色时提示的输出目标，测试中替换。
+var roleFallbackWarningOut io.Writer = os.Stderr
+
+// isRoleNotAssignedError 判断 err 是否为 Portal 因角色未分配（或已被收回）拒绝 GetRoleCredentials 的错误。
+func isRoleNotAssignedError(err error) bool {
+	var portalErr *PortalAPIError
+	return errors.As(err, &portalErr) && portalErr.IsRoleNotAssigned()
+}
+
+// getFallbackRoleCredentials 在 profile 的 role-name 不再分配给当前用户时，按顺序尝试 fallback-roles 中的角色，
+// 返回第一个可用角色的凭证并把 s.Profile.RoleName 改为该角色；cause 为 role-name 被拒绝的错误。
+// 备用角色均不可用时 RoleName 保持不变，返回提示重新选择账号与角色的错误。
+func (s *Sso) getFallbackRoleCredentials(cause error) (*RoleCredentials, error) {
+	revoked := s.Profile.RoleName
+	var tried []string
+	seen := map[string]bool{revoked: true}
+	for _, role := range s.Profile.FallbackRoles {
+		role = strings.TrimSpace(role)
+		if role == "" || seen[role] {
+			continue
+		}
+		seen[role] = true
+		tried = append(tried, role)
+
+		s.Profile.RoleName = role
+		creds, err := s.GetRoleCredentials()
+		if err == nil {
+			fmt.Fprintf(roleFallbackWarningOut, "Warning: role %q is no longer assigned to you in account %s; profile %q now uses fallback role %q\n",
+				revoked, s.Profile.AccountId, s.Profile.Name, role)
+			return creds, nil
+		}
+		if !isRoleNotAssignedError(err) {
+			s.Profile.RoleName = revoked
+			return nil, fmt.Errorf("failed to get role credentials of fallback role %s: %w", role, err)
+		}
+	}
+	s.Profile.RoleName = revoked
+
+	if len(tried) == 0 {
+		return nil, fmt.Errorf("role %s is not assigned to you in account %s: %w; run 'bp configure sso --profile %s' to select another account and role, or set fallback roles with 'bp configure set --profile %s --fallback-roles ROLE1,ROLE2'",
+			revoked, s.Profile.AccountId, cause, s.Profile.Name, s.Profile.Name)
+	}
+	return nil, fmt.Errorf("role %s is not assigned to you in account %s, and neither are the fallback roles %s: %w; run 'bp configure sso --profile %s' to select another account and role",
+		revoked, s.Profile.AccountId, strings.Join(tried, ", "), cause, s.Profile.Name)
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// roleAssignmentPortal 只为 assigned 中的角色返回凭证，其余角色返回 403。
+type roleAssignmentPortal struct {
+	*fakePortalClient
+	assigned  map[string]bool
+	requested []string
+}
+
+func (p *roleAssignmentPortal) GetRoleCredentials(ctx context.Context, req *GetRoleCredentialsRequest) (*GetRoleCredentialsResponse, error) {
+	p.requested = append(p.requested, req.RoleName)
+	if !p.assigned[req.RoleName] {
+		return nil, &PortalAPIError{StatusCode: http.StatusForbidden, Code: "AccessDenied", Message: "the role is not assigned to the user"}
+	}
+	return p.fakePortalClient.GetRoleCredentials(ctx, req)
+}
+
+func setupRoleFallbackTest(t *testing.T, fallbackRoles []string, assigned ...string) (*Sso, *Configure, *roleAssignmentPortal) {
+	t.Helper()
+	withTestConfigDir(t)
+	sso := setupSsoTokenTest(t)
+	cacheTokenForTest(t, sso, &SsoTokenCache{
+		AccessToken:           "cached-access",
+		RefreshToken:          "cached-refresh",
+		ExpiresAt:             time.Now().Add(time.Hour).Format(time.RFC3339),
+		ClientId:              "cached-client",
+		ClientSecret:          "cached-secret",
+		ClientSecretExpiresAt: validClientSecretExpiry(),
+	})
+
+	cfg := &Configure{
+		Current: "sso-prod",
+		Profiles: map[string]*Profile{
+			"sso-prod": {
+				Name:           "sso-prod",
+				Mode:           ModeSSO,
+				Region:         "cn-beijing",
+				SsoSessionName: "test-session",
+				AccountId:      "account-id",
+				RoleName:       "admin",
+				FallbackRoles:  fallbackRoles,
+			},
+		},
+		SsoSession: map[string]*SsoSession{
+			"test-session": {Name: "test-session", StartURL: sso.StartURL, Region: sso.Region},
+		},
+	}
+	withTestCtxConfig(t, cfg)
+
+	portal := &roleAssignmentPortal{fakePortalClient: &fakePortalClient{}, assigned: map[string]bool{}}
+	for _, role := range assigned {
+		portal.assigned[role] = true
+	}
+	newPortalClientForSSO = func(region string) PortalClientAPI {
+		return portal
+	}
+	sso.Profile = cfg.Profiles["sso-prod"]
+	return sso, cfg, portal
+}
+
+func TestEnsureValidStsTokenSwitchesToFallbackRole(t *testing.T) {
+	sso, _, portal := setupRoleFallbackTest(t, []string{"admin", " ", "auditor", "reader", "auditor"}, "reader")
+	var warning bytes.Buffer
+	prevOut := roleFallbackWarningOut
+	roleFallbackWarningOut = &warning
+	defer func() { roleFallbackWarningOut = prevOut }()
+
+	if err := sso.EnsureValidStsToken(ctx); err != nil {
+		t.Fatalf("EnsureValidStsToken() error = %v", err)
+	}
+	if got := strings.Join(portal.requested, ","); got != "admin,auditor,reader" {
+		t.Fatalf("requested roles = %s, want admin,auditor,reader", got)
+	}
+	saved := LoadConfig().Profiles["sso-prod"]
+	if saved.RoleName != "reader" || saved.AccessKey != "ak" {
+		t.Fatalf("saved role-name = %q, access-key = %q; want reader with its credentials", saved.RoleName, saved.AccessKey)
+	}
+	if !strings.Contains(warning.String(), `role "admin" is no longer assigned`) || !strings.Contains(warning.String(), `fallback role "reader"`) {
+		t.Fatalf("warning = %q", warning.String())
+	}
+}
+
+func TestEnsureValidStsTokenReportsRevokedRoleWithoutWorkingFallback(t *testing.T) {
+	sso, cfg, _ := setupRoleFallbackTest(t, []string{"reader"})
+
+	err := sso.EnsureValidStsToken(ctx)
+	if err == nil {
+		t.Fatal("EnsureValidStsToken() error = nil, want the revoked role reported")
+	}
+	var portalErr *PortalAPIError
+	if !errors.As(err, &portalErr) || portalErr.StatusCode != http.StatusForbidden {
+		t.Fatalf("error = %v, want it to wrap the portal error", err)
+	}
+	for _, want := range []string{"role admin is not assigned", "fallback roles reader", "bp configure sso --profile sso-prod"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("error = %q, want it to contain %q", err.Error(), want)
+		}
+	}
+	if cfg.Profiles["sso-prod"].RoleName != "admin" {
+		t.Fatalf("role-name = %q, want it unchanged", cfg.Profiles["sso-prod"].RoleName)
+	}
+}
+
+func TestPortalAPIErrorIsRoleNotAssigned(t *testing.T) {
+	cases := []struct {
+		err  *PortalAPIError
+		want bool
+	}{
+		{&PortalAPIError{StatusCode: http.StatusForbidden}, true},
+		{&PortalAPIError{StatusCode: http.StatusBadRequest, Code: "RoleNotAssigned"}, true},
+		{&PortalAPIError{StatusCode: http.StatusForbidden, Code: "InvalidAccessToken"}, false},
+		{&PortalAPIError{StatusCode: http.StatusUnauthorized}, false},
+		{&PortalAPIError{StatusCode: http.StatusInternalServerError}, false},
+	}
+	for _, c := range cases {
+		if got := c.err.IsRoleNotAssigned(); got != c.want {
+			t.Fatalf("IsRoleNotAssigned(%+v) = %v, want %v", c.err, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/byteplus-sdk/byteplus-cli/util"
+	"github.com/spf13/cobra"
+)
+
+// ssoServeRefreshLead 是 sso serve 提前刷新 STS 凭证的时长：剩余有效期不足时即获取新凭证，
+// 使客户端拿到的凭证总有足够的使用时间。
+const ssoServeRefreshLead = 10 * time.Minute
+
+// ssoServeCheckInterval 是后台检查凭证是否需要刷新的间隔，远小于 ssoServeRefreshLead。
+const ssoServeCheckInterval = time.Minute
+
+// ssoServeLogOut 为凭证刷新与失败提示的输出目标，测试中替换。
+var ssoServeLogOut io.Writer = os.Stderr
+
+// credentialServer 通过本地 unix socket 或回环地址上的 HTTP 提供 SSO profile 的 STS 凭证，
+// 响应与 credential-process 的输出格式相同。
+type credentialServer struct {
+	ctx *Context
+	sso *Sso
+	// token 非空时，请求的 Authorization header 必须与之相同；只用于 TCP 监听。
+	token string
+	mu    sync.Mutex
+}
+
+func newSsoServeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the STS credentials of an SSO profile on a local endpoint",
+		Long: `Serve the STS credentials of an SSO profile over a local unix socket or a loopback
+HTTP endpoint, for tools that read credentials from an endpoint instead of running a command.
+A GET request to / returns the same JSON as a credential-process command:
+AccessKeyId, SecretAccessKey, SessionToken and Expiration.
+
+Credentials are refreshed in the background 10 minutes before they expire, using the cached
+SSO access token; run 'bp sso login' first. By default the socket is created in
+~/.byteplus/credential-server with permissions 0600. With --listen the endpoint requires the
+printed token in the Authorization header. The server runs until interrupted.`,
+		Example: `  # Serve on ~/.byteplus/credential-server/my-sso-profile.sock
+  bp sso serve --profile my-sso-profile
+  curl --unix-socket ~/.byteplus/credential-server/my-sso-profile.sock http://localhost/
+  # Serve on a loopback port
+  bp sso serve --profile my-sso-profile --listen 127.0.0.1:9911`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			socketPath := strings.TrimSpace(cmd.Flag("socket").Value.String())
+			listenAddr := strings.TrimSpace(cmd.Flag("listen").Value.String())
+			if socketPath != "" && listenAddr != "" {
+				return fmt.Errorf("--socket and --listen cannot be used together")
+			}
+			profile, err := ssoServeProfile(ctx.config, strings.TrimSpace(cmd.Flag("profile").Value.String()))
+			if err != nil {
+				return err
+			}
+			s := &credentialServer{ctx: ctx, sso: &Sso{
+				Profile:        profile,
+				SsoSessionName: profile.SsoSessionName,
+				MinValidity:    ssoServeRefreshLead,
+				// 提前刷新时缓存中的角色凭证同样临近过期，直接获取新凭证
+				skipCredentialCache: true,
+			}}
+			// 启动前确认凭证可用，未登录时立即报错而不是在第一次请求时才失败
+			if _, err := s.credentials(); err != nil {
+				return err
+			}
+
+			var l net.Listener
+			out := cmd.OutOrStdout()
+			if listenAddr != "" {
+				if s.token, err = newCredentialServerToken(); err != nil {
+					return err
+				}
+				if l, err = listenCredentialLoopback(listenAddr); err != nil {
+					return err
+				}
+				fmt.Fprintf(out, "Serving the credentials of profile %q on http://%s/\n", profile.Name, l.Addr())
+				fmt.Fprintf(out, "Send this token in the Authorization header: %s\n", s.token)
+			} else {
+				if socketPath == "" {
+					if socketPath, err = defaultCredentialSocketPath(profile.Name); err != nil {
+						return err
+					}
+				}
+				if l, err = listenCredentialSocket(socketPath); err != nil {
+					return err
+				}
+				fmt.Fprintf(out, "Serving the credentials of profile %q on unix socket %s\n", profile.Name, socketPath)
+			}
+			return s.serve(l)
+		},
+	}
+
+	cmd.Flags().String("profile", "", "SSO profile whose credentials are served; defaults to BYTEPLUS_PROFILE or the current profile")
+	cmd.Flags().String("socket", "", "Unix socket path to serve on (default ~/.byteplus/credential-server/<profile>.sock)")
+	cmd.Flags().String("listen", "", "Loopback address to serve HTTP on instead of a socket, such as 127.0.0.1:9911")
+
+	cmd.SetUsageTemplate(ssoUsageTemplate())
+	registerConfigNameCompletions(cmd)
+
+	return cmd
+}
+
+// ssoServeProfile 返回 --profile、BYTEPLUS_PROFILE 或当前 profile 指定的 SSO profile。
+func ssoServeProfile(cfg *Configure, name string) (*Profile, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("the configuration file cannot be loaded")
+	}
+	if name == "" {
+		name = ssoProfileNameFromEnv(cfg)
+	}
+	if name == "" {
+		name = cfg.Current
+	}
+	if name == "" {
+		return nil, fmt.Errorf("no profile specified, use --profile")
+	}
+	profile, ok := cfg.Profiles[name]
+	if !ok || profile == nil {
+		return nil, fmt.Errorf("the specified profile was not found: %s", name)
+	}
+	if strings.ToLower(strings.TrimSpace(profile.Mode)) != ModeSSO || strings.TrimSpace(profile.SsoSessionName) == "" {
+		return nil, fmt.Errorf("the specified profile is not an sso profile bound to an sso-session: %s", name)
+	}
+	if profile.Name == "" {
+		profile.Name = name
+	}
+	return profile, nil
+}
+
+// credentials 在凭证剩余有效期不足 ssoServeRefreshLead 时刷新，并返回当前凭证。
+func (s *credentialServer) credentials() (*credentialProcessOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	previous := s.sso.Profile.StsExpiration
+	if err := s.sso.EnsureValidStsToken(s.ctx); err != nil {
+		return nil, err
+	}
+	p := s.sso.Profile
+	expiration := util.UnixTimestampToTime(p.StsExpiration).UTC().Format(time.RFC3339)
+	if previous != 0 && p.StsExpiration != previous {
+		fmt.Fprintf(statusWriter(ssoServeLogOut), "Refreshed the credentials of profile %q, valid until %s\n", p.Name, expiration)
+	}
+	return &credentialProcessOutput{
+		AccessKeyId:     p.AccessKey,
+		SecretAccessKey: p.SecretKey,
+		SessionToken:    p.SessionToken,
+		Expiration:      expiration,
+	}, nil
+}
+
+func (s *credentialServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.token != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(s.token)) != 1 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	creds, err := s.credentials()
+	if err != nil {
+		fmt.Fprintf(ssoServeLogOut, "Warning: failed to refresh the credentials of profile %q: %v\n", s.sso.Profile.Name, err)
+		http.Error(w, "failed to refresh the credentials: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = json.NewEncoder(w).Encode(creds)
+}
+
+// serve 处理请求直到 commandContext 被取消，期间每 ssoServeCheckInterval 检查一次凭证，
+// 使刷新发生在过期之前而不依赖客户端请求。
+func (s *credentialServer) serve(l net.Listener) error {
+	srv := &http.Server{Handler: s, ReadHeaderTimeout: 10 * time.Second}
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- srv.Serve(l) }()
+
+	ticker := time.NewTicker(ssoServeCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-serveErr:
+			return err
+		case <-ticker.C:
+			if _, err := s.credentials(); err != nil {
+				fmt.Fprintf(ssoServeLogOut, "Warning: failed to refresh the credentials of profile %q: %v\n", s.sso.Profile.Name, err)
+			}
+		case <-commandContext.Done():
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			return srv.Shutdown(shutdownCtx)
+		}
+	}
+}
+
+func newCredentialServerToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate the authorization token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// listenCredentialLoopback 只允许监听回环地址，凭证不应暴露给其他主机。
+func listenCredentialLoopback(addr string) (net.Listener, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("--listen %q is invalid: %v", addr, err)
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return nil, fmt.Errorf("--listen %q is invalid: the address must be a loopback address such as 127.0.0.1", addr)
+	}
+	return net.Listen("tcp", addr)
+}
+
+func defaultCredentialSocketPath(profileName string) (string, error) {
+	configDir, err := configFileDirFunc()
+	if err != nil {
+		return "", err
+	}
+	// 目录权限为 0700，避免 socket 在设置权限之前被其他用户连接
+	dir := filepath.Join(configDir, "credential-server")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	if err := os.Chmod(dir, 0700); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, profileName+".sock"), nil
+}
+
+// listenCredentialSocket 在 path 创建只有当前用户可访问的 unix socket。残留的 socket 文件会被替换，
+// 仍有服务在监听时报错。
+func listenCredentialSocket(path string) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s is already served by another process", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCredentialServerRefreshesAheadOfExpiry(t *testing.T) {
+	withTestConfigDir(t)
+	sso := setupSsoTokenTest(t)
+	cacheTokenForTest(t, sso, &SsoTokenCache{
+		AccessToken:           "cached-access",
+		ExpiresAt:             time.Now().Add(time.Hour).Format(time.RFC3339),
+		ClientId:              "cached-client",
+		ClientSecret:          "cached-secret",
+		ClientSecretExpiresAt: validClientSecretExpiry(),
+	})
+	fakePortal := &fakePortalClient{}
+	newPortalClientForSSO = func(region string) PortalClientAPI {
+		return fakePortal
+	}
+	var logs bytes.Buffer
+	prevLogOut := ssoServeLogOut
+	ssoServeLogOut = &logs
+	defer func() { ssoServeLogOut = prevLogOut }()
+
+	cfg := &Configure{
+		Current: "sso-prod",
+		Profiles: map[string]*Profile{
+			"sso-prod": {
+				Name: "sso-prod", Mode: ModeSSO, Region: "cn-beijing", SsoSessionName: "test-session",
+				AccountId: "account-id", RoleName: "role-name",
+				AccessKey: "old-ak", SecretKey: "old-sk", SessionToken: "old-token",
+				// 剩余 5 分钟，不足 ssoServeRefreshLead
+				StsExpiration: time.Now().Add(5 * time.Minute).Unix(),
+			},
+		},
+		SsoSession: map[string]*SsoSession{
+			"test-session": {Name: "test-session", StartURL: sso.StartURL, Region: sso.Region},
+		},
+	}
+	withTestCtxConfig(t, cfg)
+
+	profile, err := ssoServeProfile(cfg, "")
+	if err != nil {
+		t.Fatalf("ssoServeProfile() error = %v", err)
+	}
+	s := &credentialServer{ctx: ctx, token: "secret", sso: &Sso{
+		Profile:             profile,
+		SsoSessionName:      profile.SsoSessionName,
+		MinValidity:         ssoServeRefreshLead,
+		skipCredentialCache: true,
+	}}
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("status without token = %d, want 401", resp.StatusCode)
+	}
+
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+		req.Header.Set("Authorization", "secret")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("GET error = %v", err)
+		}
+		var creds credentialProcessOutput
+		err = json.NewDecoder(resp.Body).Decode(&creds)
+		resp.Body.Close()
+		if err != nil || resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET status = %d, decode error = %v", resp.StatusCode, err)
+		}
+		if creds.AccessKeyId != "ak" || creds.SecretAccessKey != "sk" || creds.SessionToken != "session-token" {
+			t.Fatalf("credentials = %+v, want the refreshed role credentials", creds)
+		}
+		if _, err := parseCredentialProcessOutput([]byte(`{"AccessKeyId":"a","SecretAccessKey":"s","Expiration":"` + creds.Expiration + `"}`)); err != nil {
+			t.Fatalf("Expiration %q is not usable: %v", creds.Expiration, err)
+		}
+	}
+	if fakePortal.credentialCalls != 1 {
+		t.Fatalf("GetRoleCredentials calls = %d, want 1", fakePortal.credentialCalls)
+	}
+	if !strings.Contains(logs.String(), "Refreshed the credentials") {
+		t.Fatalf("log output = %q", logs.String())
+	}
+}
+
+func TestListenCredentialLoopbackRejectsOtherAddresses(t *testing.T) {
+	for _, addr := range []string{"0.0.0.0:0", "192.168.1.5:9911", "example.com:9911", "9911"} {
+		if l, err := listenCredentialLoopback(addr); err == nil {
+			l.Close()
+			t.Fatalf("listenCredentialLoopback(%q) succeeded, want an error", addr)
+		}
+	}
+	l, err := listenCredentialLoopback("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listenCredentialLoopback() error = %v", err)
+	}
+	l.Close()
+}
@@ -1,9 +1,18 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -91,9 +100,22 @@ type fakePortalClient struct {
 	listRolesErr    error
 	resp            *GetRoleCredentialsResponse
 	err             error
+	credentialCalls int
+	accountRequests []*ListAccountsRequest
+	roleRequests    []*ListAccountRolesRequest
+
+	// rejectAccessToken 非空时，GetRoleCredentials 对该 access token 返回 401。
+	rejectAccessToken string
+
+	// rolesByAccount 非空时按 AccountID 返回单页角色，优先于 rolesResp。
+	rolesByAccount map[string][]RoleInfo
+	mu             sync.Mutex
 }
 
 func (f *fakePortalClient) ListAccounts(ctx context.Context, req *ListAccountsRequest) (*ListAccountsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.accountRequests = append(f.accountRequests, req)
 	if f.listAccountsErr != nil {
 		return nil, f.listAccountsErr
 	}
@@ -104,9 +126,15 @@ func (f *fakePortalClient) ListAccounts(ctx context.Context, req *ListAccountsRe
 }
 
 func (f *fakePortalClient) ListAccountRoles(ctx context.Context, req *ListAccountRolesRequest) (*ListAccountRolesResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.roleRequests = append(f.roleRequests, req)
 	if f.listRolesErr != nil {
 		return nil, f.listRolesErr
 	}
+	if f.rolesByAccount != nil {
+		return &ListAccountRolesResponse{RoleList: f.rolesByAccount[req.AccountID]}, nil
+	}
 	if f.rolesResp != nil {
 		return f.rolesResp, nil
 	}
@@ -115,6 +143,10 @@ func (f *fakePortalClient) ListAccountRoles(ctx context.Context, req *ListAccoun
 
 func (f *fakePortalClient) GetRoleCredentials(ctx context.Context, req *GetRoleCredentialsRequest) (*GetRoleCredentialsResponse, error) {
 	f.lastAccessToken = req.AccessToken
+	f.credentialCalls++
+	if f.rejectAccessToken != "" && req.AccessToken == f.rejectAccessToken {
+		return nil, &PortalAPIError{StatusCode: http.StatusUnauthorized, Code: "InvalidAccessToken", Message: "access token is invalid"}
+	}
 	if f.err != nil {
 		return nil, f.err
 	}
@@ -137,18 +169,24 @@ func setupSsoTokenTest(t *testing.T) *Sso {
 	oldConfigDir := getSsoConfigFileDir
 	oldOAuthFactory := newOAuthClientForSSO
 	oldPortalFactory := newPortalClientForSSO
+	oldSTSFactory := newSTSClientForSSO
 	oldSleep := deviceAuthorizationSleep
+	oldRegions := ssoIdentityRegions
 
 	cacheRoot := t.TempDir()
 	getSsoConfigFileDir = func() (string, error) {
 		return cacheRoot, nil
 	}
 	deviceAuthorizationSleep = func(time.Duration) {}
+	// SSO 用例的 fixture 使用任意区域名，OAuth/Portal 客户端均为 fake，不需要真实的身份服务区域
+	ssoIdentityRegions = append([]string{"cn-beijing", "cn-shanghai"}, oldRegions...)
 	t.Cleanup(func() {
 		getSsoConfigFileDir = oldConfigDir
 		newOAuthClientForSSO = oldOAuthFactory
 		newPortalClientForSSO = oldPortalFactory
+		newSTSClientForSSO = oldSTSFactory
 		deviceAuthorizationSleep = oldSleep
+		ssoIdentityRegions = oldRegions
 	})
 
 	return &Sso{
@@ -336,6 +374,9 @@ func TestSetProfileClearsTemporaryCredentialsWhenReconfiguringExistingProfile(t
 	if profile.RoleName != "new-role" {
 		t.Fatalf("RoleName = %q, want new-role", profile.RoleName)
 	}
+	if profile.Region != "cn-shanghai" {
+		t.Fatalf("Region = %q, want existing API region cn-shanghai kept", profile.Region)
+	}
 	if cfg.Current != "default" {
 		t.Fatalf("Current = %q, want unchanged default", cfg.Current)
 	}
@@ -375,6 +416,87 @@ func TestGetFreshTokenForLoginIgnoresCachedRefreshToken(t *testing.T) {
 	}
 }
 
+func TestGetTokenForLoginReusesValidCachedToken(t *testing.T) {
+	sso := setupSsoTokenTest(t)
+	cacheTokenForTest(t, sso, &SsoTokenCache{
+		AccessToken:           "cached-access",
+		RefreshToken:          "cached-refresh",
+		ExpiresAt:             time.Now().Add(time.Hour).Format(time.RFC3339),
+		ClientId:              "cached-client",
+		ClientSecret:          "cached-secret",
+		ClientSecretExpiresAt: validClientSecretExpiry(),
+	})
+	fakeOAuth := &fakeOAuthClient{}
+	newOAuthClientForSSO = func(region string) OAuthClientAPI {
+		return fakeOAuth
+	}
+
+	token, reused, err := newDeviceCodeFetcher(sso).GetTokenForLogin()
+	if err != nil {
+		t.Fatalf("GetTokenForLogin() error = %v", err)
+	}
+	if !reused || token.AccessToken != "cached-access" {
+		t.Fatalf("GetTokenForLogin() = (%q, %v), want cached-access reused", token.AccessToken, reused)
+	}
+	if len(fakeOAuth.createRequests) != 0 || len(fakeOAuth.startRequests) != 0 {
+		t.Fatalf("login should reuse cached token without OAuth calls, create=%d start=%d", len(fakeOAuth.createRequests), len(fakeOAuth.startRequests))
+	}
+}
+
+func TestGetTokenForLoginRefreshesNearExpirySilently(t *testing.T) {
+	sso := setupSsoTokenTest(t)
+	cacheTokenForTest(t, sso, &SsoTokenCache{
+		AccessToken:           "expiring-access",
+		RefreshToken:          "cached-refresh",
+		ExpiresAt:             time.Now().Add(time.Minute).Format(time.RFC3339),
+		ClientId:              "cached-client",
+		ClientSecret:          "cached-secret",
+		ClientSecretExpiresAt: validClientSecretExpiry(),
+	})
+	fakeOAuth := &fakeOAuthClient{}
+	newOAuthClientForSSO = func(region string) OAuthClientAPI {
+		return fakeOAuth
+	}
+
+	token, reused, err := newDeviceCodeFetcher(sso).GetTokenForLogin()
+	if err != nil {
+		t.Fatalf("GetTokenForLogin() error = %v", err)
+	}
+	if !reused || token.AccessToken != "refreshed-access" {
+		t.Fatalf("GetTokenForLogin() = (%q, %v), want refreshed-access reused", token.AccessToken, reused)
+	}
+	if len(fakeOAuth.startRequests) != 0 {
+		t.Fatalf("StartDeviceAuthorization calls = %d, want 0", len(fakeOAuth.startRequests))
+	}
+}
+
+func TestGetTokenForLoginFallsBackToDeviceFlowWhenRefreshFails(t *testing.T) {
+	sso := setupSsoTokenTest(t)
+	cacheTokenForTest(t, sso, &SsoTokenCache{
+		AccessToken:           "expired-access",
+		RefreshToken:          "revoked-refresh",
+		ExpiresAt:             time.Now().Add(-time.Minute).Format(time.RFC3339),
+		ClientId:              "cached-client",
+		ClientSecret:          "cached-secret",
+		ClientSecretExpiresAt: validClientSecretExpiry(),
+	})
+	fakeOAuth := &fakeOAuthClient{refreshErr: errors.New("invalid_grant")}
+	newOAuthClientForSSO = func(region string) OAuthClientAPI {
+		return fakeOAuth
+	}
+
+	token, reused, err := newDeviceCodeFetcher(sso).GetTokenForLogin()
+	if err != nil {
+		t.Fatalf("GetTokenForLogin() error = %v", err)
+	}
+	if reused || token.AccessToken != "device-access" {
+		t.Fatalf("GetTokenForLogin() = (%q, %v), want device-access from device flow", token.AccessToken, reused)
+	}
+	if len(fakeOAuth.startRequests) != 1 {
+		t.Fatalf("StartDeviceAuthorization calls = %d, want 1", len(fakeOAuth.startRequests))
+	}
+}
+
 func TestGetValidTokenForBusinessUsesCachedAccessTokenOutsideRefreshWindow(t *testing.T) {
 	sso := setupSsoTokenTest(t)
 	cacheTokenForTest(t, sso, &SsoTokenCache{
@@ -575,6 +697,96 @@ func TestGetRoleCredentialsRefreshesAccessTokenBeforeFetchingCredentials(t *test
 	}
 }
 
+func TestGetRoleCredentialsRefreshesRejectedAccessToken(t *testing.T) {
+	sso := setupSsoTokenTest(t)
+	cacheTokenForTest(t, sso, &SsoTokenCache{
+		AccessToken:           "revoked-access",
+		RefreshToken:          "refresh-token",
+		ExpiresAt:             time.Now().Add(time.Hour).Format(time.RFC3339),
+		ClientId:              "cached-client",
+		ClientSecret:          "cached-secret",
+		ClientSecretExpiresAt: validClientSecretExpiry(),
+	})
+	fakeOAuth := &fakeOAuthClient{
+		refreshResp: &CreateTokenResponse{AccessToken: "refreshed-access", RefreshToken: "refresh-token", ExpiresIn: 3600},
+	}
+	fakePortal := &fakePortalClient{rejectAccessToken: "revoked-access"}
+	newOAuthClientForSSO = func(region string) OAuthClientAPI {
+		return fakeOAuth
+	}
+	newPortalClientForSSO = func(region string) PortalClientAPI {
+		return fakePortal
+	}
+
+	credentials, err := sso.GetRoleCredentials()
+	if err != nil {
+		t.Fatalf("GetRoleCredentials() error = %v", err)
+	}
+	if credentials.AccessKeyID != "ak" || fakePortal.credentialCalls != 2 || fakePortal.lastAccessToken != "refreshed-access" {
+		t.Fatalf("credentials = %#v after %d calls, last access token %q", credentials, fakePortal.credentialCalls, fakePortal.lastAccessToken)
+	}
+
+	// refresh 也失败时提示重新登录，不回退到设备码授权
+	fakePortal.rejectAccessToken = "refreshed-access"
+	fakeOAuth.refreshErr = errors.New("invalid_grant")
+	sso.skipCredentialCache = true
+	_, err = sso.GetRoleCredentials()
+	if err == nil || !strings.Contains(err.Error(), "sso login") {
+		t.Fatalf("GetRoleCredentials() error = %v, want sso login hint", err)
+	}
+	if len(fakeOAuth.startRequests) != 0 {
+		t.Fatalf("device authorization started %d times", len(fakeOAuth.startRequests))
+	}
+}
+
+func TestSsoInstanceFactoriesOverridePackageDefaults(t *testing.T) {
+	sso := setupSsoTokenTest(t)
+	withTestConfigDir(t)
+	withTestCtxConfig(t, &Configure{
+		Profiles: map[string]*Profile{},
+		SsoSession: map[string]*SsoSession{"test-session": {
+			Name:               "test-session",
+			StartURL:           sso.StartURL,
+			Region:             sso.Region,
+			RegistrationScopes: sso.Scopes,
+		}},
+	})
+	newOAuthClientForSSO = func(string) OAuthClientAPI {
+		t.Fatal("package OAuth factory used despite Sso.OAuthFactory")
+		return nil
+	}
+	newPortalClientForSSO = func(string) PortalClientAPI {
+		t.Fatal("package Portal factory used despite Sso.PortalFactory")
+		return nil
+	}
+
+	fakeOAuth := &fakeOAuthClient{}
+	fakePortal := &fakePortalClient{}
+	var regions []string
+	sso.OAuthFactory = func(region string) OAuthClientAPI {
+		regions = append(regions, region)
+		return fakeOAuth
+	}
+	sso.PortalFactory = func(region string) PortalClientAPI {
+		regions = append(regions, region)
+		return fakePortal
+	}
+
+	if err := sso.Login(); err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	credentials, err := sso.GetRoleCredentials()
+	if err != nil {
+		t.Fatalf("GetRoleCredentials() error = %v", err)
+	}
+	if credentials.AccessKeyID != "ak" || fakePortal.lastAccessToken != "device-access" {
+		t.Fatalf("credentials = %#v via access token %q", credentials, fakePortal.lastAccessToken)
+	}
+	if len(fakeOAuth.startRequests) != 1 || len(regions) < 2 || regions[0] != "cn-beijing" {
+		t.Fatalf("device authorizations = %d, factory regions = %v", len(fakeOAuth.startRequests), regions)
+	}
+}
+
 func TestEnsureValidStsTokenWritesToProfileNameWhenCurrentDiffers(t *testing.T) {
 	withTestConfigDir(t)
 	sso := setupSsoTokenTest(t)
@@ -651,3 +863,1199 @@ func TestEnsureValidStsTokenWritesToProfileNameWhenCurrentDiffers(t *testing.T)
 		t.Fatalf("sso-prod SessionToken = %q, want new-token", cfg.Profiles["sso-prod"].SessionToken)
 	}
 }
+
+func TestEnsureValidStsTokenUsesSessionRegionForPortal(t *testing.T) {
+	withTestConfigDir(t)
+	sso := setupSsoTokenTest(t)
+	cacheTokenForTest(t, sso, &SsoTokenCache{
+		AccessToken:           "cached-access",
+		RefreshToken:          "cached-refresh",
+		ExpiresAt:             time.Now().Add(time.Hour).Format(time.RFC3339),
+		ClientId:              "cached-client",
+		ClientSecret:          "cached-secret",
+		ClientSecretExpiresAt: validClientSecretExpiry(),
+	})
+
+	falseVal := false
+	cfg := &Configure{
+		Current: "sso-prod",
+		Profiles: map[string]*Profile{
+			"sso-prod": {
+				Name:           "sso-prod",
+				Mode:           ModeSSO,
+				Region:         "ap-southeast-3",
+				SsoSessionName: "test-session",
+				AccountId:      "account-id",
+				RoleName:       "role-name",
+				DisableSSL:     &falseVal,
+			},
+		},
+		SsoSession: map[string]*SsoSession{
+			"test-session": {
+				Name:               "test-session",
+				StartURL:           sso.StartURL,
+				Region:             sso.Region,
+				RegistrationScopes: []string{"cloudidentity:account:access", "offline_access"},
+			},
+		},
+	}
+	withTestCtxConfig(t, cfg)
+
+	var portalRegion string
+	newPortalClientForSSO = func(region string) PortalClientAPI {
+		portalRegion = region
+		return &fakePortalClient{
+			resp: &GetRoleCredentialsResponse{
+				RoleCredentials: RoleCredentials{
+					AccessKeyID:     "new-ak",
+					SecretAccessKey: "new-sk",
+					SessionToken:    "new-token",
+					Expiration:      time.Now().Add(time.Hour).Unix(),
+				},
+			},
+		}
+	}
+
+	refresher := &Sso{Profile: cfg.Profiles["sso-prod"]}
+	if err := refresher.EnsureValidStsToken(ctx); err != nil {
+		t.Fatalf("EnsureValidStsToken returned error: %v", err)
+	}
+	if portalRegion != sso.Region {
+		t.Fatalf("portal region = %q, want SSO session region %q", portalRegion, sso.Region)
+	}
+	if got := cfg.Profiles["sso-prod"].Region; got != "ap-southeast-3" {
+		t.Fatalf("profile Region = %q, want API region ap-southeast-3 unchanged", got)
+	}
+}
+
+func TestSsoProfileNameFromEnv(t *testing.T) {
+	cfg := &Configure{
+		Profiles: map[string]*Profile{
+			"sso-dev": {Name: "sso-dev", Mode: ModeSSO, SsoSessionName: "dev"},
+			"ak":      {Name: "ak", Mode: ModeAK},
+		},
+	}
+
+	t.Setenv("BYTEPLUS_CLI_PROFILE", "")
+	t.Setenv("BYTEPLUS_PROFILE", "sso-dev")
+	if got := ssoProfileNameFromEnv(cfg); got != "sso-dev" {
+		t.Fatalf("ssoProfileNameFromEnv = %q, want sso-dev", got)
+	}
+
+	t.Setenv("BYTEPLUS_PROFILE", "ak")
+	if got := ssoProfileNameFromEnv(cfg); got != "" {
+		t.Fatalf("ssoProfileNameFromEnv = %q, want empty for non-sso profile", got)
+	}
+
+	t.Setenv("BYTEPLUS_PROFILE", "missing")
+	if got := ssoProfileNameFromEnv(cfg); got != "" {
+		t.Fatalf("ssoProfileNameFromEnv = %q, want empty for unknown profile", got)
+	}
+}
+
+func TestDeviceAuthorizationProgressThrottlesCountdown(t *testing.T) {
+	var out strings.Builder
+	progress := &deviceAuthorizationProgress{out: &out, interval: 15 * time.Second}
+	start := time.Unix(1700000000, 0)
+	deadline := start.Add(60 * time.Second)
+
+	progress.poll(start, deadline)
+	progress.poll(start.Add(5*time.Second), deadline)
+	progress.poll(start.Add(10*time.Second), deadline)
+	progress.poll(start.Add(15*time.Second), deadline)
+	progress.done()
+
+	want := "Still waiting for authorization... 60 seconds remaining..\n" +
+		"Still waiting for authorization... 45 seconds remaining\n"
+	if out.String() != want {
+		t.Fatalf("progress output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestDeviceAuthorizationPollDelayShortensFirstPoll(t *testing.T) {
+	interval := 5 * time.Second
+	for i := 0; i < 20; i++ {
+		if got := deviceAuthorizationPollDelay(interval, 0); got < deviceAuthorizationFirstPollDelay || got >= deviceAuthorizationFirstPollDelay+interval/10 {
+			t.Fatalf("first poll delay = %v, want [%v, %v)", got, deviceAuthorizationFirstPollDelay, deviceAuthorizationFirstPollDelay+interval/10)
+		}
+		if got := deviceAuthorizationPollDelay(interval, 1); got < interval || got >= interval+interval/10 {
+			t.Fatalf("poll delay = %v, want [%v, %v)", got, interval, interval+interval/10)
+		}
+	}
+	if got := deviceAuthorizationPollDelay(500*time.Millisecond, 0); got < 500*time.Millisecond {
+		t.Fatalf("first poll delay = %v, want at least an interval shorter than the default first delay", got)
+	}
+}
+
+func TestPerformDeviceAuthorizationVerboseWritesProgressToStderrWriter(t *testing.T) {
+	sso := setupSsoTokenTest(t)
+	sso.Verbose = true
+
+	var progressOut strings.Builder
+	oldOut := deviceAuthorizationProgressOut
+	deviceAuthorizationProgressOut = &progressOut
+	t.Cleanup(func() { deviceAuthorizationProgressOut = oldOut })
+
+	oauth := &fakeOAuthClient{}
+	newOAuthClientForSSO = func(string) OAuthClientAPI { return oauth }
+
+	fetcher := newDeviceCodeFetcher(sso)
+	client := &RegisterClientResponse{ClientID: "client", ClientSecret: "secret", ClientSecretExpiresAt: validClientSecretExpiry()}
+	if _, err := fetcher.performDeviceAuthorization(context.Background(), client); err != nil {
+		t.Fatalf("performDeviceAuthorization returned error: %v", err)
+	}
+	if !strings.Contains(progressOut.String(), "Still waiting for authorization...") {
+		t.Fatalf("progress output = %q, want waiting message", progressOut.String())
+	}
+}
+
+func withFixedNow(t *testing.T, now time.Time) {
+	t.Helper()
+	oldNow := nowFunc
+	nowFunc = func() time.Time { return now }
+	t.Cleanup(func() { nowFunc = oldNow })
+}
+
+func TestTokenExpiryHelpersUseNowFunc(t *testing.T) {
+	now := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	withFixedNow(t, now)
+
+	if tokenExpired(now.Add(time.Second).Format(time.RFC3339)) {
+		t.Fatal("token expiring after the mocked now should not be expired")
+	}
+	if !tokenExpired(now.Add(-time.Second).Format(time.RFC3339)) {
+		t.Fatal("token expiring before the mocked now should be expired")
+	}
+	if tokenNeedsRefresh(now.Add(ssoAccessTokenRefreshWindow + time.Minute).Format(time.RFC3339)) {
+		t.Fatal("token outside the refresh window should not need refresh")
+	}
+	if !tokenNeedsRefresh(now.Add(ssoAccessTokenRefreshWindow - time.Minute).Format(time.RFC3339)) {
+		t.Fatal("token inside the refresh window should need refresh")
+	}
+	if clientSecretExpired(now.Add(time.Minute).UnixMilli()) {
+		t.Fatal("client secret expiring after the mocked now should not be expired")
+	}
+	if !clientSecretExpired(now.UnixMilli()) {
+		t.Fatal("client secret expiring at the mocked now should be expired")
+	}
+}
+
+func TestGetAccessTokenUsesNowFunc(t *testing.T) {
+	sso := setupSsoTokenTest(t)
+	expiresAt := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	cacheTokenForTest(t, sso, &SsoTokenCache{
+		AccessToken: "cached-access",
+		ExpiresAt:   expiresAt.Format(time.RFC3339),
+	})
+
+	withFixedNow(t, expiresAt.Add(-time.Minute))
+	if token, err := sso.GetAccessToken(); err != nil || token != "cached-access" {
+		t.Fatalf("GetAccessToken = (%q, %v), want cached-access", token, err)
+	}
+
+	withFixedNow(t, expiresAt.Add(time.Minute))
+	if _, err := sso.GetAccessToken(); err == nil || !strings.Contains(err.Error(), "expired") {
+		t.Fatalf("GetAccessToken error = %v, want expired", err)
+	}
+}
+
+func TestEnsureValidStsTokenSkipsRefreshBeforeMockedExpiry(t *testing.T) {
+	now := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	withFixedNow(t, now)
+	sso := setupSsoTokenTest(t)
+	newPortalClientForSSO = func(region string) PortalClientAPI {
+		return &fakePortalClient{err: errors.New("GetRoleCredentials should not be called")}
+	}
+
+	sso.Profile = &Profile{
+		Name:           "sso-prod",
+		Mode:           ModeSSO,
+		SsoSessionName: "test-session",
+		SessionToken:   "still-valid",
+		StsExpiration:  now.Add(time.Minute).Unix(),
+	}
+	testCtx := NewContext()
+	testCtx.SetConfig(&Configure{Profiles: map[string]*Profile{"sso-prod": sso.Profile}})
+	if err := sso.EnsureValidStsToken(testCtx); err != nil {
+		t.Fatalf("EnsureValidStsToken returned error: %v", err)
+	}
+}
+
+func TestEnsureValidStsTokenHonorsMinValidity(t *testing.T) {
+	now := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	withFixedNow(t, now)
+	sso := setupSsoTokenTest(t)
+	cacheTokenForTest(t, sso, &SsoTokenCache{
+		AccessToken:           "cached-access",
+		ExpiresAt:             now.Add(time.Hour).Format(time.RFC3339),
+		ClientId:              "cached-client",
+		ClientSecret:          "cached-secret",
+		ClientSecretExpiresAt: validClientSecretExpiry(),
+	})
+	newPortalClientForSSO = func(region string) PortalClientAPI {
+		return &fakePortalClient{err: errors.New("refresh attempted")}
+	}
+
+	cases := []struct {
+		name            string
+		global, profile int
+		wantRefresh     bool
+	}{
+		{name: "default", wantRefresh: false},
+		{name: "global window longer than remaining", global: 15, wantRefresh: true},
+		{name: "profile overrides global", global: 15, profile: 5, wantRefresh: false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			sso.Profile = &Profile{
+				Name:           "sso-prod",
+				Mode:           ModeSSO,
+				SsoSessionName: "test-session",
+				SessionToken:   "still-valid",
+				StsExpiration:  now.Add(10 * time.Minute).Unix(),
+				StsMinValidity: tc.profile,
+			}
+			testCtx := NewContext()
+			testCtx.SetConfig(&Configure{
+				Profiles:       map[string]*Profile{"sso-prod": sso.Profile},
+				SsoSession:     map[string]*SsoSession{"test-session": {Name: "test-session", StartURL: sso.StartURL, Region: sso.Region}},
+				StsMinValidity: tc.global,
+			})
+			err := sso.EnsureValidStsToken(testCtx)
+			refreshed := err != nil && strings.Contains(err.Error(), "refresh attempted")
+			if refreshed != tc.wantRefresh {
+				t.Fatalf("EnsureValidStsToken() error = %v, want refresh = %v", err, tc.wantRefresh)
+			}
+			if !tc.wantRefresh && err != nil {
+				t.Fatalf("EnsureValidStsToken() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestGetRoleCredentialsReusesCachedCredentials(t *testing.T) {
+	sso := setupSsoTokenTest(t)
+	cacheTokenForTest(t, sso, &SsoTokenCache{
+		AccessToken:           "cached-access",
+		RefreshToken:          "cached-refresh",
+		ExpiresAt:             time.Now().Add(time.Hour).Format(time.RFC3339),
+		ClientId:              "cached-client",
+		ClientSecret:          "cached-secret",
+		ClientSecretExpiresAt: validClientSecretExpiry(),
+	})
+	fakePortal := &fakePortalClient{}
+	newPortalClientForSSO = func(region string) PortalClientAPI {
+		return fakePortal
+	}
+
+	for i := 0; i < 2; i++ {
+		creds, err := sso.GetRoleCredentials()
+		if err != nil {
+			t.Fatalf("GetRoleCredentials() error = %v", err)
+		}
+		if creds.AccessKeyID != "ak" {
+			t.Fatalf("AccessKeyID = %q, want ak", creds.AccessKeyID)
+		}
+	}
+	if fakePortal.credentialCalls != 1 {
+		t.Fatalf("portal GetRoleCredentials calls = %d, want 1", fakePortal.credentialCalls)
+	}
+
+	// 其他角色使用独立的缓存项
+	sso.Profile.RoleName = "other-role"
+	if _, err := sso.GetRoleCredentials(); err != nil {
+		t.Fatalf("GetRoleCredentials() error = %v", err)
+	}
+	if fakePortal.credentialCalls != 2 {
+		t.Fatalf("portal GetRoleCredentials calls = %d, want 2 after role change", fakePortal.credentialCalls)
+	}
+}
+
+// pagedPortalClient 按页号返回账号/角色，记录并发峰值；withoutTotal 模拟不返回 Total 的响应。
+type pagedPortalClient struct {
+	fakePortalClient
+	total        int
+	pageSize     int
+	withoutTotal bool
+	failPage     int
+
+	mu          sync.Mutex
+	inFlight    int
+	maxInFlight int
+	calls       int
+}
+
+func (f *pagedPortalClient) page(pageNumber int, nextToken string) (int, []string, int, string, error) {
+	if pageNumber == 0 {
+		pageNumber, _ = resolvePageNumber(0, nextToken)
+	}
+	f.mu.Lock()
+	f.calls++
+	f.inFlight++
+	if f.inFlight > f.maxInFlight {
+		f.maxInFlight = f.inFlight
+	}
+	f.mu.Unlock()
+	time.Sleep(5 * time.Millisecond)
+	f.mu.Lock()
+	f.inFlight--
+	f.mu.Unlock()
+
+	if pageNumber == f.failPage {
+		return 0, nil, 0, "", errors.New("page failed")
+	}
+	var ids []string
+	for i := (pageNumber-1)*f.pageSize + 1; i <= pageNumber*f.pageSize && i <= f.total; i++ {
+		ids = append(ids, fmt.Sprintf("id-%03d", i))
+	}
+	next := computeNextToken(f.total, pageNumber, f.pageSize)
+	total := f.total
+	if f.withoutTotal {
+		total = 0
+	}
+	return pageNumber, ids, total, next, nil
+}
+
+func (f *pagedPortalClient) ListAccounts(ctx context.Context, req *ListAccountsRequest) (*ListAccountsResponse, error) {
+	pageNumber, ids, total, next, err := f.page(req.PageNumber, req.NextToken)
+	if err != nil {
+		return nil, err
+	}
+	resp := &ListAccountsResponse{Total: total, PageNumber: pageNumber, PageSize: f.pageSize, NextToken: next}
+	for _, id := range ids {
+		resp.AccountList = append(resp.AccountList, AccountInfo{AccountID: id})
+	}
+	return resp, nil
+}
+
+func (f *pagedPortalClient) ListAccountRoles(ctx context.Context, req *ListAccountRolesRequest) (*ListAccountRolesResponse, error) {
+	pageNumber, ids, total, next, err := f.page(req.PageNumber, req.NextToken)
+	if err != nil {
+		return nil, err
+	}
+	resp := &ListAccountRolesResponse{Total: total, PageNumber: pageNumber, PageSize: f.pageSize, NextToken: next}
+	for _, id := range ids {
+		resp.RoleList = append(resp.RoleList, RoleInfo{AccountID: req.AccountID, RoleName: id})
+	}
+	return resp, nil
+}
+
+func TestNextTokenRoundTrip(t *testing.T) {
+	token := computeNextToken(45, 2, 20)
+	if token == "" || token == "3" {
+		t.Fatalf("computeNextToken = %q, want an opaque token", token)
+	}
+	if page, err := resolvePageNumber(0, token); err != nil || page != 3 {
+		t.Fatalf("resolvePageNumber(%q) = %d, %v, want 3", token, page, err)
+	}
+	if next := computeNextToken(45, 3, 20); next != "" {
+		t.Fatalf("computeNextToken on the last page = %q, want empty", next)
+	}
+	// 旧版本返回的纯数字 NextToken 仍然可用
+	if page, err := resolvePageNumber(0, "4"); err != nil || page != 4 {
+		t.Fatalf("resolvePageNumber(\"4\") = %d, %v, want 4", page, err)
+	}
+	for _, bad := range []string{"0", "not-a-token", encodeNextToken(0)} {
+		if _, err := resolvePageNumber(0, bad); err == nil {
+			t.Fatalf("resolvePageNumber(%q) error = nil", bad)
+		}
+	}
+}
+
+func TestFetchAllAccountsFetchesRemainingPagesConcurrentlyInOrder(t *testing.T) {
+	client := &pagedPortalClient{total: 23, pageSize: 2}
+	accounts, err := (&Sso{}).fetchAllAccounts(context.Background(), client, "token")
+	if err != nil {
+		t.Fatalf("fetchAllAccounts() error = %v", err)
+	}
+	if len(accounts) != 23 {
+		t.Fatalf("len(accounts) = %d, want 23", len(accounts))
+	}
+	for i, account := range accounts {
+		if want := fmt.Sprintf("id-%03d", i+1); account.AccountID != want {
+			t.Fatalf("accounts[%d] = %q, want %q", i, account.AccountID, want)
+		}
+	}
+	if client.calls != 12 {
+		t.Fatalf("ListAccounts calls = %d, want 12", client.calls)
+	}
+	if client.maxInFlight < 2 || client.maxInFlight > portalPageConcurrency {
+		t.Fatalf("max concurrent requests = %d, want between 2 and %d", client.maxInFlight, portalPageConcurrency)
+	}
+}
+
+func TestFetchAllRolesFallsBackToSequentialWithoutTotal(t *testing.T) {
+	client := &pagedPortalClient{total: 5, pageSize: 2, withoutTotal: true}
+	roles, err := (&Sso{}).fetchAllRoles(context.Background(), client, "token", "acc")
+	if err != nil {
+		t.Fatalf("fetchAllRoles() error = %v", err)
+	}
+	if len(roles) != 5 || roles[4].RoleName != "id-005" {
+		t.Fatalf("fetchAllRoles() = %+v, want 5 roles in order", roles)
+	}
+	if client.maxInFlight != 1 {
+		t.Fatalf("max concurrent requests = %d, want 1 for sequential fallback", client.maxInFlight)
+	}
+}
+
+func TestFetchAllRolesReturnsPageError(t *testing.T) {
+	client := &pagedPortalClient{total: 20, pageSize: 2, failPage: 4}
+	_, err := (&Sso{}).fetchAllRoles(context.Background(), client, "token", "acc")
+	if err == nil || !strings.Contains(err.Error(), "page failed") {
+		t.Fatalf("fetchAllRoles() error = %v, want page failure", err)
+	}
+}
+
+type fakeSTSClient struct {
+	lastReq *AssumeRoleWithOIDCRequest
+	calls   int
+}
+
+func (f *fakeSTSClient) AssumeRoleWithOIDC(ctx context.Context, req *AssumeRoleWithOIDCRequest) (*RoleCredentials, error) {
+	f.calls++
+	f.lastReq = req
+	return &RoleCredentials{
+		AccessKeyID:     "sts-ak",
+		SecretAccessKey: "sts-sk",
+		SessionToken:    "sts-token",
+		Expiration:      time.Now().Add(time.Hour).Unix(),
+	}, nil
+}
+
+func TestGetRoleCredentialsUsesWebIdentityWhenConfigured(t *testing.T) {
+	sso := setupSsoTokenTest(t)
+	sso.Profile.Name = "dev"
+	cacheTokenForTest(t, sso, &SsoTokenCache{
+		AccessToken:           "cached-access",
+		RefreshToken:          "cached-refresh",
+		ExpiresAt:             time.Now().Add(time.Hour).Format(time.RFC3339),
+		ClientId:              "cached-client",
+		ClientSecret:          "cached-secret",
+		ClientSecretExpiresAt: validClientSecretExpiry(),
+	})
+	sso.applySessionDefaults(&SsoSession{CredentialSource: SsoCredentialSourceWebIdentity})
+	fakePortal := &fakePortalClient{}
+	newPortalClientForSSO = func(region string) PortalClientAPI {
+		return fakePortal
+	}
+	fakeSTS := &fakeSTSClient{}
+	newSTSClientForSSO = func() STSClientAPI {
+		return fakeSTS
+	}
+
+	for i := 0; i < 2; i++ {
+		creds, err := sso.GetRoleCredentials()
+		if err != nil {
+			t.Fatalf("GetRoleCredentials() error = %v", err)
+		}
+		if creds.AccessKeyID != "sts-ak" || creds.SessionToken != "sts-token" {
+			t.Fatalf("GetRoleCredentials() = %+v, want STS credentials", creds)
+		}
+	}
+	if fakePortal.credentialCalls != 0 {
+		t.Fatalf("portal GetRoleCredentials calls = %d, want 0", fakePortal.credentialCalls)
+	}
+	if fakeSTS.calls != 1 {
+		t.Fatalf("STS calls = %d, want 1 with cached credentials reused", fakeSTS.calls)
+	}
+	if fakeSTS.lastReq.OIDCToken != "cached-access" {
+		t.Fatalf("OIDCToken = %q, want cached access token", fakeSTS.lastReq.OIDCToken)
+	}
+	if fakeSTS.lastReq.RoleTrn != "trn:iam::account-id:role/role-name" {
+		t.Fatalf("RoleTrn = %q", fakeSTS.lastReq.RoleTrn)
+	}
+	if fakeSTS.lastReq.RoleSessionName != "bp-dev" {
+		t.Fatalf("RoleSessionName = %q, want bp-dev", fakeSTS.lastReq.RoleSessionName)
+	}
+}
+
+func TestAssumeRoleWithWebIdentityPrefersProfileRoleTrn(t *testing.T) {
+	sso := setupSsoTokenTest(t)
+	sso.Profile.RoleTrn = "trn:iam::2100000000:role/Custom"
+	cacheTokenForTest(t, sso, &SsoTokenCache{
+		AccessToken:           "cached-access",
+		RefreshToken:          "cached-refresh",
+		ExpiresAt:             time.Now().Add(time.Hour).Format(time.RFC3339),
+		ClientId:              "cached-client",
+		ClientSecret:          "cached-secret",
+		ClientSecretExpiresAt: validClientSecretExpiry(),
+	})
+	fakeSTS := &fakeSTSClient{}
+	newSTSClientForSSO = func() STSClientAPI {
+		return fakeSTS
+	}
+
+	if _, err := sso.AssumeRoleWithWebIdentity(sso.roleTrn()); err != nil {
+		t.Fatalf("AssumeRoleWithWebIdentity() error = %v", err)
+	}
+	if fakeSTS.lastReq.RoleTrn != "trn:iam::2100000000:role/Custom" {
+		t.Fatalf("RoleTrn = %q, want profile role-trn", fakeSTS.lastReq.RoleTrn)
+	}
+	if _, err := sso.AssumeRoleWithWebIdentity(""); err == nil {
+		t.Fatal("AssumeRoleWithWebIdentity(\"\") error = nil, want error")
+	}
+}
+
+func TestSTSClientAssumeRoleWithOIDC(t *testing.T) {
+	expiredAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("Action") != "AssumeRoleWithOIDC" || q.Get("OIDCToken") != "token" || q.Get("RoleTrn") != "trn:iam::1:role/r" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		if q.Get("DurationSeconds") != "3600" {
+			t.Errorf("DurationSeconds = %q, want 3600", q.Get("DurationSeconds"))
+		}
+		fmt.Fprintf(w, `{"ResponseMetadata":{"RequestId":"req"},"Result":{"Credentials":{"AccessKeyId":"ak","SecretAccessKey":"sk","SessionToken":"st","ExpiredTime":%q}}}`, expiredAt.Format(time.RFC3339))
+	}))
+	defer server.Close()
+
+	client := NewSTSClient(&STSClientConfig{BaseURL: server.URL})
+	creds, err := client.AssumeRoleWithOIDC(context.Background(), &AssumeRoleWithOIDCRequest{
+		OIDCToken:       "token",
+		RoleTrn:         "trn:iam::1:role/r",
+		RoleSessionName: "bp-test",
+	})
+	if err != nil {
+		t.Fatalf("AssumeRoleWithOIDC() error = %v", err)
+	}
+	if creds.AccessKeyID != "ak" || creds.SecretAccessKey != "sk" || creds.SessionToken != "st" || creds.Expiration != expiredAt.Unix() {
+		t.Fatalf("AssumeRoleWithOIDC() = %+v", creds)
+	}
+}
+
+func TestSTSClientAssumeRoleWithOIDCReturnsAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"ResponseMetadata":{"RequestId":"req","Error":{"Code":"InvalidIdentityToken","Message":"token rejected"}}}`)
+	}))
+	defer server.Close()
+
+	client := NewSTSClient(&STSClientConfig{BaseURL: server.URL})
+	_, err := client.AssumeRoleWithOIDC(context.Background(), &AssumeRoleWithOIDCRequest{OIDCToken: "token", RoleTrn: "trn:iam::1:role/r"})
+	if err == nil || !strings.Contains(err.Error(), "InvalidIdentityToken") {
+		t.Fatalf("AssumeRoleWithOIDC() error = %v, want InvalidIdentityToken", err)
+	}
+}
+
+func TestGetRoleCredentialsSkipsNearlyExpiredCache(t *testing.T) {
+	sso := setupSsoTokenTest(t)
+	cacheTokenForTest(t, sso, &SsoTokenCache{
+		AccessToken:           "cached-access",
+		RefreshToken:          "cached-refresh",
+		ExpiresAt:             time.Now().Add(time.Hour).Format(time.RFC3339),
+		ClientId:              "cached-client",
+		ClientSecret:          "cached-secret",
+		ClientSecretExpiresAt: validClientSecretExpiry(),
+	})
+	sso.storeRoleCredentials(&RoleCredentials{
+		AccessKeyID:     "old-ak",
+		SecretAccessKey: "old-sk",
+		SessionToken:    "old-token",
+		Expiration:      time.Now().Add(time.Minute).Unix(),
+	})
+	fakePortal := &fakePortalClient{}
+	newPortalClientForSSO = func(region string) PortalClientAPI {
+		return fakePortal
+	}
+
+	creds, err := sso.GetRoleCredentials()
+	if err != nil {
+		t.Fatalf("GetRoleCredentials() error = %v", err)
+	}
+	if creds.AccessKeyID != "ak" || fakePortal.credentialCalls != 1 {
+		t.Fatalf("AccessKeyID = %q, portal calls = %d, want fresh credentials from portal", creds.AccessKeyID, fakePortal.credentialCalls)
+	}
+}
+
+func TestClearRoleCredentialsCacheOnlyRemovesSession(t *testing.T) {
+	sso := setupSsoTokenTest(t)
+	creds := &RoleCredentials{AccessKeyID: "ak", SecretAccessKey: "sk", Expiration: time.Now().Add(time.Hour).Unix()}
+	sso.storeRoleCredentials(creds)
+	other := &Sso{SsoSessionName: "other-session", Profile: &Profile{AccountId: "account-id", RoleName: "role-name"}}
+	other.storeRoleCredentials(creds)
+
+	if err := sso.clearRoleCredentialsCache(); err != nil {
+		t.Fatalf("clearRoleCredentialsCache() error = %v", err)
+	}
+	if sso.readCachedRoleCredentials() != nil {
+		t.Fatal("credentials of the logged out session are still cached")
+	}
+	if other.readCachedRoleCredentials() == nil {
+		t.Fatal("credentials of another session were removed")
+	}
+}
+
+func TestSsoLoginCreatesMissingSessionFromFlags(t *testing.T) {
+	setupSsoTokenTest(t)
+	dir := withTestConfigDir(t)
+	withTestCtxConfig(t, &Configure{Profiles: map[string]*Profile{}, SsoSession: map[string]*SsoSession{}})
+	fakeOAuth := &fakeOAuthClient{}
+	var oauthRegion string
+	newOAuthClientForSSO = func(region string) OAuthClientAPI {
+		oauthRegion = region
+		return fakeOAuth
+	}
+
+	cmd := newSsoLoginCmd()
+	cmd.SetArgs([]string{"--sso-session", "first", "--start-url", "https://example.com/userportal", "--region", "cn-shanghai", "--no-browser"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("sso login error = %v", err)
+	}
+
+	session := ctx.config.SsoSession["first"]
+	if session == nil || session.StartURL != "https://example.com/userportal" || session.Region != "cn-shanghai" {
+		t.Fatalf("created session = %#v", session)
+	}
+	if oauthRegion != "cn-shanghai" || len(fakeOAuth.startRequests) != 1 {
+		t.Fatalf("login used region %q with %d device authorizations, want cn-shanghai and 1", oauthRegion, len(fakeOAuth.startRequests))
+	}
+	saved := readConfigFileAsMap(t, dir)
+	if sessions, _ := saved["sso-session"].(map[string]interface{}); sessions["first"] == nil {
+		t.Fatalf("saved config sso-session = %#v, want first", saved["sso-session"])
+	}
+}
+
+func TestSsoLoginWithStartURLDoesNotSaveSession(t *testing.T) {
+	setupSsoTokenTest(t)
+	dir := withTestConfigDir(t)
+	withTestCtxConfig(t, &Configure{Profiles: map[string]*Profile{}, SsoSession: map[string]*SsoSession{}})
+	fakeOAuth := &fakeOAuthClient{}
+	var oauthRegion string
+	newOAuthClientForSSO = func(region string) OAuthClientAPI {
+		oauthRegion = region
+		return fakeOAuth
+	}
+
+	cmd := newSsoLoginCmd()
+	cmd.SetArgs([]string{"--start-url", "https://acme.byteplusidentity.com/userportal", "--region", "cn-shanghai", "--no-browser"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("sso login error = %v", err)
+	}
+
+	if oauthRegion != "cn-shanghai" || len(fakeOAuth.startRequests) != 1 {
+		t.Fatalf("login used region %q with %d device authorizations, want cn-shanghai and 1", oauthRegion, len(fakeOAuth.startRequests))
+	}
+	if len(ctx.config.SsoSession) != 0 {
+		t.Fatalf("sso-sessions = %#v, want none saved", ctx.config.SsoSession)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ConfigFile)); !os.IsNotExist(err) {
+		t.Fatalf("config file written by a login without sso-session: %v", err)
+	}
+	cached, err := (&Sso{SsoSessionName: "acme", StartURL: "https://acme.byteplusidentity.com/userportal", Region: "cn-shanghai"}).readTokenCache()
+	if err != nil || cached == nil {
+		t.Fatalf("token cached as sso-session acme = %#v, err = %v", cached, err)
+	}
+
+	cmd = newSsoLoginCmd()
+	cmd.SetArgs([]string{"--region", "cn-shanghai"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "--region requires --start-url") {
+		t.Fatalf("sso login --region error = %v, want --start-url hint", err)
+	}
+}
+
+func TestTransientSsoSessionName(t *testing.T) {
+	for startURL, want := range map[string]string{
+		"https://acme.byteplusidentity.com/userportal": "acme",
+		"https://localhost:8443/userportal":            "localhost",
+		"not a url":                                    "default",
+	} {
+		if got := transientSsoSessionName(startURL); got != want {
+			t.Fatalf("transientSsoSessionName(%q) = %q, want %q", startURL, got, want)
+		}
+	}
+}
+
+func TestSsoLoginReauthIgnoresValidCachedToken(t *testing.T) {
+	sso := setupSsoTokenTest(t)
+	withTestConfigDir(t)
+	withTestCtxConfig(t, &Configure{
+		Profiles:   map[string]*Profile{},
+		SsoSession: map[string]*SsoSession{"test-session": {Name: "test-session", StartURL: sso.StartURL, Region: sso.Region}},
+	})
+	cacheTokenForTest(t, sso, &SsoTokenCache{
+		AccessToken:           "cached-access",
+		RefreshToken:          "cached-refresh",
+		ExpiresAt:             time.Now().Add(time.Hour).Format(time.RFC3339),
+		ClientId:              "cached-client",
+		ClientSecret:          "cached-secret",
+		ClientSecretExpiresAt: validClientSecretExpiry(),
+	})
+	fakeOAuth := &fakeOAuthClient{
+		deviceResp: &CreateTokenResponse{AccessToken: "reauth-access", RefreshToken: "reauth-refresh", ExpiresIn: 3600},
+	}
+	newOAuthClientForSSO = func(region string) OAuthClientAPI {
+		return fakeOAuth
+	}
+
+	cmd := newSsoLoginCmd()
+	cmd.SetArgs([]string{"--sso-session", "test-session", "--no-browser", "--reauth"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("sso login --reauth error = %v", err)
+	}
+	if len(fakeOAuth.startRequests) != 1 {
+		t.Fatalf("StartDeviceAuthorization calls = %d, want 1", len(fakeOAuth.startRequests))
+	}
+	for _, req := range fakeOAuth.createRequests {
+		if req.GrantType == "refresh_token" {
+			t.Fatalf("--reauth must not use refresh_token grant, got request %#v", req)
+		}
+	}
+	if cached, err := newDeviceCodeFetcher(sso).loadCachedToken(); err != nil || cached == nil || cached.AccessToken != "reauth-access" {
+		t.Fatalf("cached token = %#v, err = %v, want reauth-access", cached, err)
+	}
+}
+
+func TestSsoLoginRejectsSessionFlagsForExistingSession(t *testing.T) {
+	setupSsoTokenTest(t)
+	withTestConfigDir(t)
+	withTestCtxConfig(t, &Configure{
+		Profiles:   map[string]*Profile{},
+		SsoSession: map[string]*SsoSession{"existing": {Name: "existing", StartURL: "https://example.com/userportal", Region: "cn-beijing"}},
+	})
+
+	cmd := newSsoLoginCmd()
+	cmd.SetArgs([]string{"--sso-session", "existing", "--start-url", "https://other.example.com/userportal"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("sso login error = %v, want already exists error", err)
+	}
+
+	cmd = newSsoLoginCmd()
+	cmd.SetArgs([]string{"--sso-session", "missing"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "--start-url") {
+		t.Fatalf("sso login error = %v, want hint about --start-url", err)
+	}
+}
+
+func TestIdentityClientsRequireAllowInsecureForHTTPBaseURL(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case defaultDeviceAuthPath:
+			_, _ = w.Write([]byte(`{"device_code":"dc","user_code":"uc","verification_uri":"http://local/verify","expires_in":600}`))
+		case portalListAccountsPath:
+			_, _ = w.Write([]byte(`{"ResponseMetadata":{"RequestId":"req"},"Result":{"AccountList":[{"AccountId":"a-1"}],"Total":1}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	deviceReq := &StartDeviceAuthorizationRequest{ClientID: "id", ClientSecret: "secret"}
+	accountsReq := &ListAccountsRequest{AccessToken: "token"}
+
+	oauth := NewOAuthClient(&OAuthClientConfig{BaseURL: server.URL})
+	if _, err := oauth.StartDeviceAuthorization(context.Background(), deviceReq); err == nil || !strings.Contains(err.Error(), "AllowInsecure") {
+		t.Fatalf("StartDeviceAuthorization() error = %v, want AllowInsecure error", err)
+	}
+	portal := NewPortalClient(&PortalClientConfig{BaseURL: server.URL})
+	if _, err := portal.ListAccounts(context.Background(), accountsReq); err == nil || !strings.Contains(err.Error(), "AllowInsecure") {
+		t.Fatalf("ListAccounts() error = %v, want AllowInsecure error", err)
+	}
+	if calls != 0 {
+		t.Fatalf("server received %d requests before AllowInsecure was set", calls)
+	}
+
+	oauth = NewOAuthClient(&OAuthClientConfig{BaseURL: server.URL, AllowInsecure: true})
+	resp, err := oauth.StartDeviceAuthorization(context.Background(), deviceReq)
+	if err != nil || resp.DeviceCode != "dc" {
+		t.Fatalf("StartDeviceAuthorization() = %#v, %v", resp, err)
+	}
+	portal = NewPortalClient(&PortalClientConfig{BaseURL: server.URL, AllowInsecure: true})
+	if _, err := portal.ListAccounts(context.Background(), accountsReq); err != nil {
+		t.Fatalf("ListAccounts() error = %v", err)
+	}
+
+	if err := validateIdentityBaseURL("ftp://example.com", true); err == nil {
+		t.Fatal("validateIdentityBaseURL(ftp) error = nil, want unsupported scheme")
+	}
+}
+
+func TestPortalListAccountsDistinguishesNoAccountsFromEmptyResponse(t *testing.T) {
+	body := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+	portal := NewPortalClient(&PortalClientConfig{BaseURL: server.URL, AllowInsecure: true})
+	req := &ListAccountsRequest{AccessToken: "token"}
+
+	body = `{"ResponseMetadata":{"RequestId":"req"},"Result":{"Total":0,"PageNumber":1,"PageSize":10}}`
+	resp, err := portal.ListAccounts(context.Background(), req)
+	if err != nil {
+		t.Fatalf("ListAccounts() error = %v, want an empty list", err)
+	}
+	if resp.AccountList == nil || len(resp.AccountList) != 0 || resp.NextToken != "" {
+		t.Fatalf("ListAccounts() = %#v, want a non-nil empty list without NextToken", resp)
+	}
+
+	for _, body = range []string{
+		`{"ResponseMetadata":{"RequestId":"req"}}`,
+		`{"ResponseMetadata":{"RequestId":"req"},"Result":null}`,
+	} {
+		if _, err := portal.ListAccounts(context.Background(), req); err == nil || !strings.Contains(err.Error(), "response was empty") {
+			t.Fatalf("ListAccounts(%s) error = %v, want empty response error", body, err)
+		}
+	}
+
+	body = `{"ResponseMetadata":{"RequestId":"req","Error":{"Code":"AccessDenied","Message":"denied"}}}`
+	var apiErr *PortalAPIError
+	if _, err := portal.ListAccounts(context.Background(), req); !errors.As(err, &apiErr) || apiErr.Code != "AccessDenied" {
+		t.Fatalf("ListAccounts() error = %v, want the metadata error", err)
+	}
+}
+
+func TestOAuthClientFormEncoding(t *testing.T) {
+	var contentTypes []string
+	var forms []url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentTypes = append(contentTypes, r.Header.Get("Content-Type"))
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("ParseForm() error = %v", err)
+		}
+		forms = append(forms, r.PostForm)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case defaultRegisterPath:
+			_, _ = w.Write([]byte(`{"client_id":"id","client_secret":"secret"}`))
+		case defaultTokenPath:
+			_, _ = w.Write([]byte(`{"access_token":"at","token_type":"Bearer","expires_in":3600}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	oauth := NewOAuthClient(&OAuthClientConfig{BaseURL: server.URL, AllowInsecure: true, Encoding: OAuthEncodingForm})
+	if _, err := oauth.RegisterClient(context.Background(), &RegisterClientRequest{ClientName: "bp", ClientType: "public", Scopes: []string{"a", "b"}}); err != nil {
+		t.Fatalf("RegisterClient() error = %v", err)
+	}
+	token, err := oauth.CreateToken(context.Background(), &CreateTokenRequest{GrantType: "refresh_token", ClientID: "id", ClientSecret: "secret", RefreshToken: "rt"})
+	if err != nil || token.AccessToken != "at" {
+		t.Fatalf("CreateToken() = %#v, %v", token, err)
+	}
+	for _, ct := range contentTypes {
+		if ct != "application/x-www-form-urlencoded" {
+			t.Fatalf("Content-Type = %q, want form encoding", ct)
+		}
+	}
+	if got := forms[0]["scopes"]; len(got) != 2 || got[0] != "a" || got[1] != "b" || forms[0].Get("client_name") != "bp" {
+		t.Fatalf("register form = %v", forms[0])
+	}
+	if forms[1].Get("grant_type") != "refresh_token" || forms[1].Get("refresh_token") != "rt" {
+		t.Fatalf("token form = %v", forms[1])
+	}
+	if _, ok := forms[1]["device_code"]; ok {
+		t.Fatalf("token form = %v, want empty device_code omitted", forms[1])
+	}
+
+	oauth = NewOAuthClient(&OAuthClientConfig{BaseURL: server.URL, AllowInsecure: true, Encoding: "xml"})
+	if _, err := oauth.CreateToken(context.Background(), &CreateTokenRequest{GrantType: "refresh_token", ClientID: "id", ClientSecret: "secret", RefreshToken: "rt"}); err == nil || !strings.Contains(err.Error(), "unsupported OAuth request encoding") {
+		t.Fatalf("CreateToken() error = %v, want unsupported encoding", err)
+	}
+	if len(forms) != 2 {
+		t.Fatalf("server received %d requests, want none for an invalid encoding", len(forms)-2)
+	}
+}
+
+func TestSsoLoginJSONOutput(t *testing.T) {
+	sso := setupSsoTokenTest(t)
+	withTestConfigDir(t)
+	withTestCtxConfig(t, &Configure{
+		Profiles: map[string]*Profile{},
+		SsoSession: map[string]*SsoSession{"test-session": {
+			Name:               "test-session",
+			StartURL:           sso.StartURL,
+			Region:             sso.Region,
+			RegistrationScopes: sso.Scopes,
+		}},
+	})
+	expiresAt := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	cacheTokenForTest(t, sso, &SsoTokenCache{
+		AccessToken:           "cached-access",
+		RefreshToken:          "cached-refresh",
+		ExpiresAt:             expiresAt,
+		ClientId:              "cached-client",
+		ClientSecret:          "cached-secret",
+		ClientSecretExpiresAt: validClientSecretExpiry(),
+	})
+	newOAuthClientForSSO = func(string) OAuthClientAPI { return &fakeOAuthClient{} }
+
+	var out bytes.Buffer
+	oldOut := ssoCommandResultOut
+	ssoCommandResultOut = &out
+	t.Cleanup(func() { ssoCommandResultOut = oldOut })
+
+	cmd := newSsoLoginCmd()
+	cmd.SetArgs([]string{"--sso-session", "test-session", "--json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("sso login error = %v", err)
+	}
+	want := `{"session":"test-session","status":"success","expiresAt":"` + expiresAt + `"}` + "\n"
+	if out.String() != want {
+		t.Fatalf("sso login --json = %q, want %q", out.String(), want)
+	}
+
+	out.Reset()
+	cmd = newSsoLoginCmd()
+	cmd.SetArgs([]string{"--sso-session", "missing", "--json"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("sso login error = nil, want missing session error")
+	}
+	var result ssoCommandResult
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("decode result %q: %v", out.String(), err)
+	}
+	if result.Session != "missing" || result.Status != "failed" || !strings.Contains(result.Error, "was not found") || strings.Count(out.String(), "\n") != 1 {
+		t.Fatalf("sso login --json failure = %q", out.String())
+	}
+}
+
+func TestSsoLogoutJSONOutput(t *testing.T) {
+	setupSsoTokenTest(t)
+	withTestConfigDir(t)
+	withTestCtxConfig(t, &Configure{
+		Profiles:   map[string]*Profile{},
+		SsoSession: map[string]*SsoSession{"test-session": {Name: "test-session", StartURL: "https://example.com/userportal", Region: "cn-beijing"}},
+	})
+	newOAuthClientForSSO = func(string) OAuthClientAPI { return &fakeOAuthClient{} }
+
+	var out bytes.Buffer
+	oldOut := ssoCommandResultOut
+	ssoCommandResultOut = &out
+	t.Cleanup(func() { ssoCommandResultOut = oldOut })
+
+	cmd := newSsoLogoutCmd()
+	cmd.SetArgs([]string{"--sso-session", "test-session", "--json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("sso logout error = %v", err)
+	}
+	if want := `{"session":"test-session","status":"success"}` + "\n"; out.String() != want {
+		t.Fatalf("sso logout --json = %q, want %q", out.String(), want)
+	}
+}
+
+func TestConfigureSsoJSONOutput(t *testing.T) {
+	sso := setupSsoTokenTest(t)
+	withTestConfigDir(t)
+	withTestCtxConfig(t, &Configure{
+		Profiles: map[string]*Profile{},
+		SsoSession: map[string]*SsoSession{"test-session": {
+			Name:               "test-session",
+			StartURL:           sso.StartURL,
+			Region:             sso.Region,
+			RegistrationScopes: sso.Scopes,
+		}},
+	})
+	cacheTokenForTest(t, sso, &SsoTokenCache{
+		AccessToken:           "cached-access",
+		RefreshToken:          "cached-refresh",
+		ExpiresAt:             time.Now().Add(time.Hour).Format(time.RFC3339),
+		ClientId:              "cached-client",
+		ClientSecret:          "cached-secret",
+		ClientSecretExpiresAt: validClientSecretExpiry(),
+	})
+	newPortalClientForSSO = func(string) PortalClientAPI {
+		return &fakePortalClient{
+			accountsResp: &ListAccountsResponse{AccountList: []AccountInfo{{AccountID: "2100000000"}}},
+			rolesResp:    &ListAccountRolesResponse{RoleList: []RoleInfo{{AccountID: "2100000000", RoleName: "Admin"}}},
+		}
+	}
+	oldSelectAccount, oldSelectRole, oldFlags := selectSsoAccount, selectSsoRole, ssoFlags
+	selectSsoAccount = func(accounts []AccountInfo) (AccountInfo, error) { return accounts[0], nil }
+	selectSsoRole = func(roles []RoleInfo) (RoleInfo, error) { return roles[0], nil }
+	t.Cleanup(func() {
+		selectSsoAccount, selectSsoRole, ssoFlags = oldSelectAccount, oldSelectRole, oldFlags
+	})
+
+	var out bytes.Buffer
+	oldOut := ssoCommandResultOut
+	ssoCommandResultOut = &out
+	t.Cleanup(func() { ssoCommandResultOut = oldOut })
+
+	cmd := newConfigureSsoCmd()
+	cmd.SetArgs([]string{"--profile", "dev", "--sso-session", "test-session", "--json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("configure sso error = %v", err)
+	}
+	if want := `{"accountId":"2100000000","roleName":"Admin","profile":"dev"}` + "\n"; out.String() != want {
+		t.Fatalf("configure sso --json = %q, want %q", out.String(), want)
+	}
+}
+
+func TestGetTokenReauthorizesWhenCachedScopesDoNotCoverRequest(t *testing.T) {
+	sso := setupSsoTokenTest(t)
+	cacheTokenForTest(t, sso, &SsoTokenCache{
+		AccessToken:           "narrow-access",
+		RefreshToken:          "narrow-refresh",
+		ExpiresAt:             time.Now().Add(time.Hour).Format(time.RFC3339),
+		ClientId:              "cached-client",
+		ClientSecret:          "cached-secret",
+		ClientSecretExpiresAt: validClientSecretExpiry(),
+		Scopes:                []string{"cloudidentity:account:access"},
+	})
+	fakeOAuth := &fakeOAuthClient{}
+	newOAuthClientForSSO = func(region string) OAuthClientAPI {
+		return fakeOAuth
+	}
+
+	token, err := newDeviceCodeFetcher(sso).GetToken()
+	if err != nil {
+		t.Fatalf("GetToken() error = %v", err)
+	}
+	if token.AccessToken != "device-access" {
+		t.Fatalf("access token = %q, want device-access from a new authorization", token.AccessToken)
+	}
+	for _, req := range fakeOAuth.createRequests {
+		if req.GrantType == "refresh_token" {
+			t.Fatal("narrow refresh token must not be used when scopes were widened")
+		}
+	}
+	cached, err := sso.readTokenCache()
+	if err != nil {
+		t.Fatalf("readTokenCache() error = %v", err)
+	}
+	if strings.Join(cached.Scopes, ",") != strings.Join(sso.Scopes, ",") {
+		t.Fatalf("cached scopes = %v, want %v", cached.Scopes, sso.Scopes)
+	}
+}
+
+func TestRefreshTokenKeepsGrantedScopes(t *testing.T) {
+	sso := setupSsoTokenTest(t)
+	cacheTokenForTest(t, sso, &SsoTokenCache{
+		AccessToken:           "expiring-access",
+		RefreshToken:          "cached-refresh",
+		ExpiresAt:             time.Now().Add(time.Minute).Format(time.RFC3339),
+		ClientId:              "cached-client",
+		ClientSecret:          "cached-secret",
+		ClientSecretExpiresAt: validClientSecretExpiry(),
+		Scopes:                []string{"offline_access", "cloudidentity:account:access"},
+	})
+	newOAuthClientForSSO = func(region string) OAuthClientAPI {
+		return &fakeOAuthClient{}
+	}
+
+	token, reused, err := newDeviceCodeFetcher(sso).GetTokenForLogin()
+	if err != nil || !reused {
+		t.Fatalf("GetTokenForLogin() = %v, %v, want silent refresh", reused, err)
+	}
+	if strings.Join(token.Scopes, ",") != "offline_access,cloudidentity:account:access" {
+		t.Fatalf("refreshed token scopes = %v, want scopes of the original grant", token.Scopes)
+	}
+}
+
+func TestSsoRejectsUnknownIdentityRegionBeforeCreatingClients(t *testing.T) {
+	sso := setupSsoTokenTest(t)
+	sso.Region = "ap-nowhere-1"
+	newOAuthClientForSSO = func(region string) OAuthClientAPI {
+		t.Fatalf("OAuth client created for invalid region %q", region)
+		return nil
+	}
+	newPortalClientForSSO = func(region string) PortalClientAPI {
+		t.Fatalf("Portal client created for invalid region %q", region)
+		return nil
+	}
+
+	if _, err := sso.GetValidAccessToken(); err == nil || !strings.Contains(err.Error(), "region ap-nowhere-1 is not a valid identity region") {
+		t.Fatalf("GetValidAccessToken() error = %v, want invalid identity region", err)
+	}
+	if _, _, err := sso.chooseAccountAndRole(&SsoTokenCache{AccessToken: "access"}); err == nil || !strings.Contains(err.Error(), "not a valid identity region") {
+		t.Fatalf("chooseAccountAndRole() error = %v, want invalid identity region", err)
+	}
+	if err := validateSsoRegion(""); err != nil {
+		t.Fatalf("validateSsoRegion(\"\") error = %v, want nil for default region", err)
+	}
+}
+
+func TestTokenCacheFileNameIncludesRegion(t *testing.T) {
+	sso := setupSsoTokenTest(t)
+	other := *sso
+	other.Region = "cn-shanghai"
+	if sso.Region == other.Region {
+		t.Fatalf("test setup expects distinct regions, got %q", sso.Region)
+	}
+
+	cacheTokenForTest(t, sso, &SsoTokenCache{AccessToken: "token-a", ExpiresAt: time.Now().Add(time.Hour).Format(time.RFC3339)})
+	cacheTokenForTest(t, &other, &SsoTokenCache{AccessToken: "token-b", ExpiresAt: time.Now().Add(time.Hour).Format(time.RFC3339)})
+
+	for _, tc := range []struct {
+		sso  *Sso
+		want string
+	}{{sso, "token-a"}, {&other, "token-b"}} {
+		token, err := tc.sso.readTokenCache()
+		if err != nil {
+			t.Fatalf("readTokenCache() error = %v", err)
+		}
+		if token == nil || token.AccessToken != tc.want {
+			t.Fatalf("region %s token = %+v, want %s", tc.sso.Region, token, tc.want)
+		}
+	}
+}
+
+func TestReadTokenCacheFallsBackToLegacyFileName(t *testing.T) {
+	sso := setupSsoTokenTest(t)
+	legacyPath, err := sso.legacyTokenCacheFilePath()
+	if err != nil || legacyPath == "" {
+		t.Fatalf("legacyTokenCacheFilePath() = %q, %v", legacyPath, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(legacyPath), 0700); err != nil {
+		t.Fatal(err)
+	}
+	writeLegacy := func(region string) {
+		data, _ := json.Marshal(&SsoTokenCache{AccessToken: "legacy", Region: region, ExpiresAt: time.Now().Add(time.Hour).Format(time.RFC3339)})
+		if err := os.WriteFile(legacyPath, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writeLegacy("other-region")
+	if token, err := sso.readTokenCache(); err != nil || token != nil {
+		t.Fatalf("legacy token for another region = %+v, %v; want nil", token, err)
+	}
+
+	writeLegacy(sso.Region)
+	token, err := sso.readTokenCache()
+	if err != nil || token == nil || token.AccessToken != "legacy" {
+		t.Fatalf("readTokenCache() = %+v, %v; want legacy token", token, err)
+	}
+
+	if err := sso.clearCachedToken(token); err != nil {
+		t.Fatalf("clearCachedToken() error = %v", err)
+	}
+	if _, err := os.Stat(legacyPath); !os.IsNotExist(err) {
+		t.Fatalf("legacy cache file still exists: %v", err)
+	}
+}
+
+func TestLogoutAllSessionsClearsEverySession(t *testing.T) {
+	setupSsoTokenTest(t)
+	withTestConfigDir(t)
+	cfg := &Configure{Profiles: map[string]*Profile{}, SsoSession: map[string]*SsoSession{}}
+	var sessions []*Sso
+	for i := 1; i <= 6; i++ {
+		name := fmt.Sprintf("session-%d", i)
+		cfg.SsoSession[name] = &SsoSession{Name: name, StartURL: "https://example.com/userportal", Region: "cn-beijing"}
+		cfg.Profiles["profile-"+name] = &Profile{Name: "profile-" + name, Mode: ModeSSO, SsoSessionName: name, SessionToken: "sts-token"}
+		sso := &Sso{SsoSessionName: name, StartURL: "https://example.com/userportal", Region: "cn-beijing"}
+		cacheTokenForTest(t, sso, &SsoTokenCache{AccessToken: "access-" + name, ClientId: "client", ClientSecret: "secret", ExpiresAt: time.Now().Add(time.Hour).Format(time.RFC3339)})
+		sessions = append(sessions, sso)
+	}
+	cfg.SsoSession["broken"] = &SsoSession{Name: "broken", Region: "cn-beijing"}
+	withTestCtxConfig(t, cfg)
+
+	err := logoutAllSessions(cfg)
+	if err == nil || !strings.Contains(err.Error(), "broken:") || strings.Contains(err.Error(), "session-") {
+		t.Fatalf("logoutAllSessions() error = %v, want only the broken session to fail", err)
+	}
+	for _, sso := range sessions {
+		if token, err := sso.readTokenCache(); err != nil || token != nil {
+			t.Fatalf("token of %s after logout = %#v, %v", sso.SsoSessionName, token, err)
+		}
+		if profile := cfg.Profiles["profile-"+sso.SsoSessionName]; profile.SessionToken != "" {
+			t.Fatalf("STS credentials of %s not cleared", profile.Name)
+		}
+	}
+}
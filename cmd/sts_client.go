@@ -0,0 +1,184 @@
+/*
+ * // Copyright (c) 2024 Bytedance Ltd. and/or its affiliates
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at
+ * //
+ * //	http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultSTSTimeout         = 30 * time.Second
+	defaultSTSBaseURL         = "https://sts.byteplusapi.com"
+	stsAPIVersion             = "2018-01-01"
+	stsAssumeRoleWithOIDC     = "AssumeRoleWithOIDC"
+	defaultSTSDurationSeconds = 3600
+)
+
+// STSClientConfig 用于配置 STS 客户端的可选项，比如自定义 BaseURL 或 HTTPClient。
+type STSClientConfig struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+// STSClient 封装 web identity 换取角色凭证所需的 STS 调用。
+// AssumeRoleWithOIDC 本身以 OIDC token 作为身份证明，请求不需要签名。
+type STSClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// STSClientAPI 定义 STS 客户端对外暴露的方法集合，便于测试或替换实现。
+type STSClientAPI interface {
+	AssumeRoleWithOIDC(ctx context.Context, req *AssumeRoleWithOIDCRequest) (*RoleCredentials, error)
+}
+
+var _ STSClientAPI = (*STSClient)(nil)
+
+// AssumeRoleWithOIDCRequest 为 AssumeRoleWithOIDC 的请求参数封装。
+type AssumeRoleWithOIDCRequest struct {
+	OIDCToken       string
+	RoleTrn         string
+	RoleSessionName string
+	DurationSeconds int
+}
+
+// assumeRoleWithOIDCResult 对应 AssumeRoleWithOIDC 响应中的 Result 字段。
+type assumeRoleWithOIDCResult struct {
+	Credentials struct {
+		AccessKeyId     string `json:"AccessKeyId"`
+		SecretAccessKey string `json:"SecretAccessKey"`
+		SessionToken    string `json:"SessionToken"`
+		ExpiredTime     string `json:"ExpiredTime"`
+	} `json:"Credentials"`
+}
+
+// NewSTSClient 根据配置创建 STS 客户端，未指定时使用全局 STS 地址。
+func NewSTSClient(cfg *STSClientConfig) *STSClient {
+	base := defaultSTSBaseURL
+	if cfg != nil && strings.TrimSpace(cfg.BaseURL) != "" {
+		base = strings.TrimSpace(cfg.BaseURL)
+	}
+	client := &http.Client{Timeout: defaultSTSTimeout}
+	if cfg != nil && cfg.HTTPClient != nil {
+		client = cfg.HTTPClient
+	}
+	return &STSClient{
+		baseURL:    strings.TrimRight(base, "/"),
+		httpClient: client,
+	}
+}
+
+// AssumeRoleWithOIDC 使用 OIDC token 扮演目标角色，返回与 Portal 相同结构的临时凭证。
+func (c *STSClient) AssumeRoleWithOIDC(ctx context.Context, req *AssumeRoleWithOIDCRequest) (*RoleCredentials, error) {
+	if req == nil {
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+	if strings.TrimSpace(req.OIDCToken) == "" {
+		return nil, fmt.Errorf("OIDC token is required")
+	}
+	if strings.TrimSpace(req.RoleTrn) == "" {
+		return nil, fmt.Errorf("roleTrn is required")
+	}
+	duration := req.DurationSeconds
+	if duration <= 0 {
+		duration = defaultSTSDurationSeconds
+	}
+
+	q := url.Values{}
+	q.Set("Action", stsAssumeRoleWithOIDC)
+	q.Set("Version", stsAPIVersion)
+	q.Set("OIDCToken", req.OIDCToken)
+	q.Set("RoleTrn", req.RoleTrn)
+	q.Set("RoleSessionName", req.RoleSessionName)
+	q.Set("DurationSeconds", strconv.Itoa(duration))
+	endpoint := c.baseURL + "/?" + q.Encode()
+
+	var body []byte
+	err := doWithRetry(ctx, retryOptions{maxAttempts: 3}, func() error {
+		b, err := c.doGetOnce(ctx, endpoint)
+		if err != nil {
+			return err
+		}
+		body = b
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	env, err := decodePortalEnvelope(body, stsAssumeRoleWithOIDC)
+	if err != nil {
+		return nil, err
+	}
+	if len(env.Result) == 0 {
+		return nil, fmt.Errorf("%s succeeded but response was empty", stsAssumeRoleWithOIDC)
+	}
+	var result assumeRoleWithOIDCResult
+	if err := json.Unmarshal(env.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode %s result: %w", stsAssumeRoleWithOIDC, err)
+	}
+
+	creds := result.Credentials
+	if creds.AccessKeyId == "" || creds.SecretAccessKey == "" {
+		return nil, fmt.Errorf("%s returned no credentials", stsAssumeRoleWithOIDC)
+	}
+	expiredAt, err := time.Parse(time.RFC3339, creds.ExpiredTime)
+	if err != nil {
+		return nil, fmt.Errorf("%s returned invalid ExpiredTime %q: %w", stsAssumeRoleWithOIDC, creds.ExpiredTime, err)
+	}
+	return &RoleCredentials{
+		AccessKeyID:     creds.AccessKeyId,
+		SecretAccessKey: creds.SecretAccessKey,
+		SessionToken:    creds.SessionToken,
+		Expiration:      expiredAt.Unix(),
+	}, nil
+}
+
+func (c *STSClient) doGetOnce(ctx context.Context, fullURL string) ([]byte, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fullURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Accept", portalDefaultAcceptHeader)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	// STS 与 Portal 使用相同的 ResponseMetadata 错误结构，复用其解析与重试判定。
+	if resp.StatusCode/100 != 2 {
+		return nil, parsePortalAPIError(resp.StatusCode, body)
+	}
+	return body, nil
+}
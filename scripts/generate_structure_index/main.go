@@ -0,0 +1,94 @@
+// generate_structure_index writes structset/index.go, which maps every
+// embedded structure asset to its PkgName. NewRootSupport reads the package
+// names from the index instead of decompressing and parsing every structure
+// file at startup.
+//
+// Run it from the repository root after go-bindata has generated
+// structset/structset.go from the same directory:
+//
+//	go run ./scripts/generate_structure_index --structure-dir byteplus-sdk-metadata/structure --out structset/index.go
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+func main() {
+	structureDir := flag.String("structure-dir", "byteplus-sdk-metadata/structure", "structure metadata directory, relative to the repository root")
+	out := flag.String("out", filepath.Join("structset", "index.go"), "output Go file")
+	flag.Parse()
+
+	index, err := buildIndex(*structureDir)
+	if err != nil {
+		fatal(err)
+	}
+	src, err := render(index)
+	if err != nil {
+		fatal(err)
+	}
+	if err := ioutil.WriteFile(*out, src, 0644); err != nil {
+		fatal(err)
+	}
+	fmt.Printf("wrote %d structure assets to %s\n", len(index), *out)
+}
+
+// buildIndex reads the PkgName of every <service>/<version>/structure.json.
+// Asset names use the same slash-separated relative path as go-bindata.
+func buildIndex(dir string) (map[string]string, error) {
+	index := make(map[string]string)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !strings.HasSuffix(path, ".json") {
+			return err
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var st struct {
+			PkgName string
+		}
+		if err := json.Unmarshal(data, &st); err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		if st.PkgName == "" {
+			return fmt.Errorf("%s: PkgName is empty", path)
+		}
+		index[filepath.ToSlash(path)] = st.PkgName
+		return nil
+	})
+	return index, err
+}
+
+func render(index map[string]string) ([]byte, error) {
+	names := make([]string, 0, len(index))
+	for name := range index {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by scripts/generate_structure_index. DO NOT EDIT.\n\n")
+	buf.WriteString("package structset\n\n")
+	buf.WriteString("// PkgNames maps each structure asset name to the PkgName in the file, so the\n")
+	buf.WriteString("// service list can be built without decompressing every structure asset.\n")
+	buf.WriteString("var PkgNames = map[string]string{\n")
+	for _, name := range names {
+		fmt.Fprintf(&buf, "\t%q: %q,\n", name, index[name])
+	}
+	buf.WriteString("}\n")
+	return format.Source(buf.Bytes())
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
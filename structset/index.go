@@ -0,0 +1,63 @@
+// Code generated by scripts/generate_structure_index. DO NOT EDIT.
+
+package structset
+
+// PkgNames maps each structure asset name to the PkgName in the file, so the
+// service list can be built without decompressing every structure asset.
+var PkgNames = map[string]string{
+	"byteplus-sdk-metadata/structure/alb/2020-04-01/structure.json":            "alb",
+	"byteplus-sdk-metadata/structure/apig/2021-03-03/structure.json":           "apig",
+	"byteplus-sdk-metadata/structure/apig/2022-11-12/structure.json":           "apig20221112",
+	"byteplus-sdk-metadata/structure/ark/2024-01-01/structure.json":            "ark",
+	"byteplus-sdk-metadata/structure/auto_scaling/2020-01-01/structure.json":   "autoscaling",
+	"byteplus-sdk-metadata/structure/billing/2022-01-01/structure.json":        "billing",
+	"byteplus-sdk-metadata/structure/cen/2020-04-01/structure.json":            "cen",
+	"byteplus-sdk-metadata/structure/clb/2020-04-01/structure.json":            "clb",
+	"byteplus-sdk-metadata/structure/cloudmonitor/2018-01-01/structure.json":   "cloudmonitor",
+	"byteplus-sdk-metadata/structure/cp/2023-05-01/structure.json":             "cp",
+	"byteplus-sdk-metadata/structure/cpaas/2026-04-30/structure.json":          "cpaas",
+	"byteplus-sdk-metadata/structure/cr/2022-05-12/structure.json":             "cr",
+	"byteplus-sdk-metadata/structure/directconnect/2020-04-01/structure.json":  "directconnect",
+	"byteplus-sdk-metadata/structure/dms/2018-01-01/structure.json":            "dms",
+	"byteplus-sdk-metadata/structure/eco_partner/2025-05-29/structure.json":    "ecopartner",
+	"byteplus-sdk-metadata/structure/ecs/2020-04-01/structure.json":            "ecs",
+	"byteplus-sdk-metadata/structure/ecs/2025-11-01/structure.json":            "ecs20251101",
+	"byteplus-sdk-metadata/structure/escloud/2023-01-01/structure.json":        "escloud",
+	"byteplus-sdk-metadata/structure/filenas/2022-01-01/structure.json":        "filenas",
+	"byteplus-sdk-metadata/structure/hbase/2018-01-01/structure.json":          "hbase",
+	"byteplus-sdk-metadata/structure/iam/2018-01-01/structure.json":            "iam",
+	"byteplus-sdk-metadata/structure/iam/2021-08-01/structure.json":            "iam20210801",
+	"byteplus-sdk-metadata/structure/id/2025-10-30/structure.json":             "id",
+	"byteplus-sdk-metadata/structure/kafka/2022-05-01/structure.json":          "kafka",
+	"byteplus-sdk-metadata/structure/kickart/2026-02-27/structure.json":        "kickart",
+	"byteplus-sdk-metadata/structure/kms/2021-02-18/structure.json":            "kms",
+	"byteplus-sdk-metadata/structure/milvus/2023-01-01/structure.json":         "milvus",
+	"byteplus-sdk-metadata/structure/ml_platform/2024-07-01/structure.json":    "mlplatform20240701",
+	"byteplus-sdk-metadata/structure/mongodb/2022-01-01/structure.json":        "mongodb",
+	"byteplus-sdk-metadata/structure/natgateway/2020-04-01/structure.json":     "natgateway",
+	"byteplus-sdk-metadata/structure/private_zone/2022-06-01/structure.json":   "privatezone",
+	"byteplus-sdk-metadata/structure/privatelink/2020-04-01/structure.json":    "privatelink",
+	"byteplus-sdk-metadata/structure/quota/2022-07-01/structure.json":          "quota",
+	"byteplus-sdk-metadata/structure/rabbitmq/2022-01-01/structure.json":       "rabbitmq",
+	"byteplus-sdk-metadata/structure/rds_mssql/2022-01-01/structure.json":      "rdsmssql",
+	"byteplus-sdk-metadata/structure/rds_mysql/2022-01-01/structure.json":      "rdsmysqlv2",
+	"byteplus-sdk-metadata/structure/rds_postgresql/2022-01-01/structure.json": "rdspostgresql",
+	"byteplus-sdk-metadata/structure/redis/2020-12-07/structure.json":          "redis",
+	"byteplus-sdk-metadata/structure/resource_share/2024-01-01/structure.json": "resourceshare",
+	"byteplus-sdk-metadata/structure/resourcecenter/2023-06-01/structure.json": "resourcecenter",
+	"byteplus-sdk-metadata/structure/smc/2020-04-01/structure.json":            "smc",
+	"byteplus-sdk-metadata/structure/storage_ebs/2020-04-01/structure.json":    "storageebs",
+	"byteplus-sdk-metadata/structure/sts/2018-01-01/structure.json":            "sts",
+	"byteplus-sdk-metadata/structure/tag/2022-06-01/structure.json":            "tag",
+	"byteplus-sdk-metadata/structure/transitrouter/2020-04-01/structure.json":  "transitrouter",
+	"byteplus-sdk-metadata/structure/vedbm/2022-01-01/structure.json":          "vedbm",
+	"byteplus-sdk-metadata/structure/vefaas/2024-06-06/structure.json":         "vefaas",
+	"byteplus-sdk-metadata/structure/vepfs/2022-01-01/structure.json":          "vepfs",
+	"byteplus-sdk-metadata/structure/vke/2022-05-12/structure.json":            "vke",
+	"byteplus-sdk-metadata/structure/vmp/2021-03-03/structure.json":            "vmp",
+	"byteplus-sdk-metadata/structure/vod/2025-07-01/structure.json":            "vod20250701",
+	"byteplus-sdk-metadata/structure/vpc/2020-04-01/structure.json":            "vpc",
+	"byteplus-sdk-metadata/structure/vpc/2025-09-01/structure.json":            "vpc20250901",
+	"byteplus-sdk-metadata/structure/vpn/2020-04-01/structure.json":            "vpn",
+	"byteplus-sdk-metadata/structure/vs/2026-06-31/structure.json":             "vs",
+}
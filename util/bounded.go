@@ -0,0 +1,117 @@
+/*
+ * // Copyright (c) 2024 Bytedance Ltd. and/or its affiliates
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at
+ * //
+ * //	http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+package util
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// BoundedResult 是 RunBounded 中一个任务的结果，Index 为任务下标。
+type BoundedResult struct {
+	Index int
+	Value interface{}
+	Err   error
+}
+
+// BoundedOptions 控制 RunBounded 的并发数与进度输出。
+type BoundedOptions struct {
+	// Concurrency 为同时执行的任务数上限，小于 1 时按 1 处理。
+	Concurrency int
+	// Progress 非空时在同一行刷新 "<Label>: 完成数/总数" 进度，全部完成后换行；应只在终端上启用。
+	Progress io.Writer
+	Label    string
+}
+
+// RunBounded 用固定数量的 worker 执行 n 个任务，fn 的参数为任务下标。
+// 返回的结果按下标排列，与任务完成顺序无关；单个任务失败不影响其他任务。
+func RunBounded(n int, opts BoundedOptions, fn func(i int) (interface{}, error)) []BoundedResult {
+	results := make([]BoundedResult, n)
+	if n == 0 {
+		return results
+	}
+	concurrency := opts.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if concurrency > n {
+		concurrency = n
+	}
+
+	var (
+		mu       sync.Mutex
+		done     int
+		failed   int
+		wg       sync.WaitGroup
+		progress = opts.Progress
+	)
+	report := func() {
+		if progress == nil {
+			return
+		}
+		line := fmt.Sprintf("%s: %d/%d", opts.Label, done, n)
+		if failed > 0 {
+			line += fmt.Sprintf(", %d failed", failed)
+		}
+		fmt.Fprintf(progress, "\r%s", line)
+	}
+
+	jobs := make(chan int)
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				value, err := fn(i)
+				mu.Lock()
+				results[i] = BoundedResult{Index: i, Value: value, Err: err}
+				done++
+				if err != nil {
+					failed++
+				}
+				report()
+				mu.Unlock()
+			}
+		}()
+	}
+	mu.Lock()
+	report()
+	mu.Unlock()
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	if progress != nil {
+		fmt.Fprintln(progress)
+	}
+	return results
+}
+
+// IsTerminal 判断 f 是否为字符设备（终端），用于决定是否输出会刷新同一行的进度。
+func IsTerminal(f *os.File) bool {
+	if f == nil {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
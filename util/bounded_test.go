@@ -0,0 +1,69 @@
+package util
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRunBoundedLimitsConcurrencyAndKeepsOrder(t *testing.T) {
+	var running, peak int32
+	release := make(chan struct{})
+	results := make(chan []BoundedResult)
+	go func() {
+		results <- RunBounded(6, BoundedOptions{Concurrency: 2}, func(i int) (interface{}, error) {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			<-release
+			atomic.AddInt32(&running, -1)
+			if i == 3 {
+				return nil, errors.New("boom")
+			}
+			return i * 10, nil
+		})
+	}()
+	close(release)
+	got := <-results
+
+	if peak > 2 {
+		t.Fatalf("peak concurrency = %d, want at most 2", peak)
+	}
+	for i, r := range got {
+		if r.Index != i {
+			t.Fatalf("results[%d].Index = %d", i, r.Index)
+		}
+		if i == 3 {
+			if r.Err == nil {
+				t.Fatalf("results[3].Err = nil, want boom")
+			}
+			continue
+		}
+		if r.Err != nil || r.Value != i*10 {
+			t.Fatalf("results[%d] = %+v", i, r)
+		}
+	}
+}
+
+func TestRunBoundedReportsProgress(t *testing.T) {
+	var buf bytes.Buffer
+	RunBounded(3, BoundedOptions{Concurrency: 1, Progress: &buf, Label: "logout"}, func(i int) (interface{}, error) {
+		if i == 1 {
+			return nil, errors.New("boom")
+		}
+		return nil, nil
+	})
+	want := "\rlogout: 0/3\rlogout: 1/3\rlogout: 2/3, 1 failed\rlogout: 3/3, 1 failed\n"
+	if buf.String() != want {
+		t.Fatalf("progress = %q, want %q", buf.String(), want)
+	}
+	if RunBounded(0, BoundedOptions{Progress: &buf}, nil); !strings.HasSuffix(buf.String(), "failed\n") {
+		t.Fatalf("RunBounded with no tasks wrote progress: %q", buf.String())
+	}
+}
@@ -0,0 +1,89 @@
+/*
+ * // Copyright (c) 2024 Bytedance Ltd. and/or its affiliates
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at
+ * //
+ * //	http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+package util
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/itchyny/gojq"
+)
+
+// JQ 是编译后的 jq 表达式，由 gojq 执行，支持完整的 jq 语言（变量、if、reduce、正则、def 等）。
+type JQ struct {
+	code *gojq.Code
+}
+
+// ParseJQ 编译 jq 表达式，语法错误或引用了未定义的函数、变量时返回错误。
+func ParseJQ(expr string) (*JQ, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, fmt.Errorf("jq: empty expression")
+	}
+	query, err := gojq.Parse(expr)
+	if err != nil {
+		return nil, fmt.Errorf("jq: %v", err)
+	}
+	code, err := gojq.Compile(query)
+	if err != nil {
+		return nil, fmt.Errorf("jq: %v", err)
+	}
+	return &JQ{code: code}, nil
+}
+
+// Run 以 input 为输入执行表达式，按顺序返回全部结果。
+// gojq 会就地把 json.Number 等数值转换为 int/float64，因此先复制 input，调用方仍可原样输出完整响应。
+func (q *JQ) Run(input interface{}) ([]interface{}, error) {
+	iter := q.code.Run(copyJQInput(input))
+	var results []interface{}
+	for {
+		v, ok := iter.Next()
+		if !ok {
+			return results, nil
+		}
+		if err, ok := v.(error); ok {
+			// halt 正常结束执行，halt_error 等其余错误作为失败返回
+			if halt, ok := err.(interface {
+				IsHaltError() bool
+				IsEmptyError() bool
+			}); ok && halt.IsHaltError() && halt.IsEmptyError() {
+				return results, nil
+			}
+			return nil, fmt.Errorf("jq: %v", err)
+		}
+		results = append(results, v)
+	}
+}
+
+// copyJQInput 深拷贝 JSON 解码得到的对象与数组，其余值原样返回。
+func copyJQInput(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, x := range v {
+			m[k] = copyJQInput(x)
+		}
+		return m
+	case []interface{}:
+		a := make([]interface{}, len(v))
+		for i, x := range v {
+			a[i] = copyJQInput(x)
+		}
+		return a
+	default:
+		return v
+	}
+}
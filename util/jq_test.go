@@ -0,0 +1,108 @@
+package util
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func runJQForTest(t *testing.T, expr, input string) string {
+	t.Helper()
+	var v interface{}
+	dec := json.NewDecoder(strings.NewReader(input))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		t.Fatalf("decode input: %v", err)
+	}
+	q, err := ParseJQ(expr)
+	if err != nil {
+		t.Fatalf("ParseJQ(%q) error = %v", expr, err)
+	}
+	results, err := q.Run(v)
+	if err != nil {
+		t.Fatalf("Run(%q) error = %v", expr, err)
+	}
+	parts := make([]string, len(results))
+	for i, r := range results {
+		b, err := json.Marshal(r)
+		if err != nil {
+			t.Fatalf("marshal result: %v", err)
+		}
+		parts[i] = string(b)
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestJQRun(t *testing.T) {
+	input := `{"Result":{"Total":3,"Instances":[
+		{"InstanceId":"i-1","Status":"RUNNING","Cpus":4,"Tags":[{"Key":"env","Value":"prod"}]},
+		{"InstanceId":"i-2","Status":"STOPPED","Cpus":2,"Tags":[]},
+		{"InstanceId":"i-3","Status":"RUNNING","Cpus":8}]}}`
+	cases := []struct {
+		expr string
+		want string
+	}{
+		{`.`, `{"Result":{"Instances":[{"Cpus":4,"InstanceId":"i-1","Status":"RUNNING","Tags":[{"Key":"env","Value":"prod"}]},{"Cpus":2,"InstanceId":"i-2","Status":"STOPPED","Tags":[]},{"Cpus":8,"InstanceId":"i-3","Status":"RUNNING"}],"Total":3}}`},
+		{`.Result.Instances[].InstanceId`, `"i-1" "i-2" "i-3"`},
+		{`.Result.Instances[0].Tags[0].Value`, `"prod"`},
+		{`.Result.Instances[-1].InstanceId`, `"i-3"`},
+		{`.Result.Instances[1:].[0].InstanceId`, `"i-2"`},
+		{`.Result.Missing.Deep`, `null`},
+		{`[.Result.Instances[] | select(.Status == "RUNNING") | .InstanceId]`, `["i-1","i-3"]`},
+		{`.Result.Instances | map(.Cpus) | add`, `14`},
+		{`.Result.Instances | length`, `3`},
+		{`.Result.Instances | sort_by(.Cpus) | map(.InstanceId) | join(",")`, `"i-2,i-1,i-3"`},
+		{`.Result.Instances[0] | {InstanceId, Zone: (.ZoneId // "none")}`, `{"InstanceId":"i-1","Zone":"none"}`},
+		{`.Result.Instances[] | select(.Cpus > 2 and has("Tags")) | .InstanceId`, `"i-1"`},
+		{`.Result.Total * 2 - 1, .Result.Total % 2`, `5 1`},
+		{`.Result.Instances[2].Tags[]?`, ``},
+		{`.Result | keys`, `["Instances","Total"]`},
+		{`[.Result.Instances[].Status] | unique`, `["RUNNING","STOPPED"]`},
+		{`.Result.Instances | map(.Cpus) | max, min`, `8 2`},
+		{`.Result.Total | tostring | type`, `"string"`},
+		{`.Result.Instances[0].Tags | first | to_entries | map(.key)`, `["Key","Value"]`},
+		{`.Result.Instances[] | if .Status == "RUNNING" then .InstanceId else empty end`, `"i-1" "i-3"`},
+		{`.Result.Total as $n | [.Result.Instances[] | .Cpus * $n]`, `[12,6,24]`},
+		{`reduce .Result.Instances[] as $i (0; . + $i.Cpus)`, `14`},
+		{`[.Result.Instances[].InstanceId | select(test("^i-[12]$"))]`, `["i-1","i-2"]`},
+		{`.Result.Instances[0] | contains({Tags: [{Value: "prod"}]})`, `true`},
+		{`def cpus: map(.Cpus); .Result.Instances | cpus`, `[4,2,8]`},
+	}
+	for _, tc := range cases {
+		if got := runJQForTest(t, tc.expr, input); got != tc.want {
+			t.Errorf("jq %q = %s, want %s", tc.expr, got, tc.want)
+		}
+	}
+}
+
+func TestParseJQRejectsInvalidExpressions(t *testing.T) {
+	for _, expr := range []string{``, `.a |`, `.[`, `{a:}`, `.a ==`, `foo`, `select()`, `"unterminated`} {
+		if _, err := ParseJQ(expr); err == nil {
+			t.Errorf("ParseJQ(%q) error = nil, want error", expr)
+		}
+	}
+}
+
+func TestJQRunReportsTypeErrors(t *testing.T) {
+	q, err := ParseJQ(`.Result[]`)
+	if err != nil {
+		t.Fatalf("ParseJQ() error = %v", err)
+	}
+	if _, err := q.Run(map[string]interface{}{"Result": "ok"}); err == nil || !strings.Contains(err.Error(), "cannot iterate over") {
+		t.Fatalf("Run() error = %v, want iterate error", err)
+	}
+}
+
+func TestJQRunKeepsInputNumbers(t *testing.T) {
+	q, err := ParseJQ(`.Price`)
+	if err != nil {
+		t.Fatalf("ParseJQ() error = %v", err)
+	}
+	input := map[string]interface{}{"Price": json.Number("1.10")}
+	if _, err := q.Run(input); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if input["Price"] != json.Number("1.10") {
+		t.Fatalf("input Price = %#v, want the json.Number unchanged", input["Price"])
+	}
+}
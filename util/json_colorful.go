@@ -17,33 +17,52 @@
 package util
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 )
 
 // ShowJson print data as json
 // data should be map[string]interface{}
 func ShowJson(data interface{}, color bool) {
-	if color {
-		colorfulJson(data, 0, false, true)
-	} else {
-		buf := bytes.NewBuffer([]byte{})
-		encoder := json.NewEncoder(buf)
-		encoder.SetEscapeHTML(false)
-		encoder.SetIndent("", "    ")
-		encoder.Encode(data)
+	_ = WriteJson(os.Stdout, data, color)
+}
 
-		fmt.Println(buf.String())
+// WriteJson writes data to w the way ShowJson prints it: indented, with
+// color codes when color is true.
+func WriteJson(w io.Writer, data interface{}, color bool) error {
+	if color {
+		bw := bufio.NewWriter(w)
+		colorfulJson(bw, data, 0, false, true)
+		return bw.Flush()
 	}
+	buf := bytes.NewBuffer([]byte{})
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "    ")
+	encoder.Encode(data)
+
+	_, err := fmt.Fprintln(w, buf.String())
+	return err
+}
+
+// WriteCompactJson writes data to w as a single line of JSON without color,
+// so each document stays on one line for log pipelines.
+func WriteCompactJson(w io.Writer, data interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(data)
 }
 
-func colorfulJson(data interface{}, indent int, indentValue, lastValue bool) {
+func colorfulJson(w io.Writer, data interface{}, indent int, indentValue, lastValue bool) {
 	if data == nil {
 		if !lastValue {
-			printlnWithIndent(0, "\033[1;33mnull\033[0m,")
+			printlnWithIndent(w, 0, "\033[1;33mnull\033[0m,")
 		} else {
-			printlnWithIndent(0, "\033[1;33mnull\033[0m")
+			printlnWithIndent(w, 0, "\033[1;33mnull\033[0m")
 		}
 		return
 	}
@@ -51,110 +70,110 @@ func colorfulJson(data interface{}, indent int, indentValue, lastValue bool) {
 	switch v := data.(type) {
 	case map[string]interface{}:
 		if !indentValue {
-			printlnWithIndent(0, "{")
+			printlnWithIndent(w, 0, "{")
 		} else {
-			printlnWithIndent(indent, "{")
+			printlnWithIndent(w, indent, "{")
 		}
 		defer func() {
-			printWithIndent(indent, "}")
+			printWithIndent(w, indent, "}")
 			if !lastValue {
-				fmt.Print(",\n")
+				fmt.Fprint(w, ",\n")
 			} else {
-				fmt.Print("\n")
+				fmt.Fprint(w, "\n")
 			}
 		}()
 
 		loop, mapLen := 1, len(v)
 		for k1, v1 := range v {
-			printfWithIndent(indent+1, "\033[1;35m%q\033[0m", k1)
-			fmt.Print(": ")
-			colorfulJson(v1, indent+1, false, loop == mapLen)
+			printfWithIndent(w, indent+1, "\033[1;35m%q\033[0m", k1)
+			fmt.Fprint(w, ": ")
+			colorfulJson(w, v1, indent+1, false, loop == mapLen)
 			loop++
 		}
 	case []interface{}:
 		if !indentValue {
-			printlnWithIndent(0, "[")
+			printlnWithIndent(w, 0, "[")
 		} else {
-			printlnWithIndent(indent, "[")
+			printlnWithIndent(w, indent, "[")
 		}
 		defer func() {
-			printWithIndent(indent, "]")
+			printWithIndent(w, indent, "]")
 			if !lastValue {
-				fmt.Print(",\n")
+				fmt.Fprint(w, ",\n")
 			} else {
-				fmt.Print("\n")
+				fmt.Fprint(w, "\n")
 			}
 		}()
 
 		loop, arrLen := 1, len(v)
 		for _, v1 := range v {
-			colorfulJson(v1, indent+1, true, loop == arrLen)
+			colorfulJson(w, v1, indent+1, true, loop == arrLen)
 			loop++
 		}
 	case string:
 		if indentValue {
-			printfWithIndent(indent, "\033[1;32m%q\033[0m", v)
+			printfWithIndent(w, indent, "\033[1;32m%q\033[0m", v)
 		} else {
-			printfWithIndent(0, "\033[1;32m%q\033[0m", v)
+			printfWithIndent(w, 0, "\033[1;32m%q\033[0m", v)
 		}
 		if !lastValue {
-			fmt.Print(",\n")
+			fmt.Fprint(w, ",\n")
 		} else {
-			fmt.Print("\n")
+			fmt.Fprint(w, "\n")
 		}
 	case json.Number:
 		if indentValue {
-			printfWithIndent(indent, "\033[1;94m%v\033[0m", v)
+			printfWithIndent(w, indent, "\033[1;94m%v\033[0m", v)
 		} else {
-			printfWithIndent(0, "\033[1;94m%v\033[0m", v)
+			printfWithIndent(w, 0, "\033[1;94m%v\033[0m", v)
 		}
 		if !lastValue {
-			fmt.Print(",\n")
+			fmt.Fprint(w, ",\n")
 		} else {
-			fmt.Print("\n")
+			fmt.Fprint(w, "\n")
 		}
 	case bool:
 		if indentValue {
-			printfWithIndent(indent, "\033[1;91m%v\033[0m", v)
+			printfWithIndent(w, indent, "\033[1;91m%v\033[0m", v)
 		} else {
-			printfWithIndent(0, "\033[1;91m%v\033[0m", v)
+			printfWithIndent(w, 0, "\033[1;91m%v\033[0m", v)
 		}
 		if !lastValue {
-			fmt.Print(",\n")
+			fmt.Fprint(w, ",\n")
 		} else {
-			fmt.Print("\n")
+			fmt.Fprint(w, "\n")
 		}
 	default:
 		if indentValue {
-			printfWithIndent(indent, "\033[1;32m%v\033[0m", v)
+			printfWithIndent(w, indent, "\033[1;32m%v\033[0m", v)
 		} else {
-			printfWithIndent(0, "\033[1;32m%v\033[0m", v)
+			printfWithIndent(w, 0, "\033[1;32m%v\033[0m", v)
 		}
 		if !lastValue {
-			fmt.Print(",\n")
+			fmt.Fprint(w, ",\n")
 		} else {
-			fmt.Print("\n")
+			fmt.Fprint(w, "\n")
 		}
 	}
 }
 
-func printWithIndent(indent int, a ...interface{}) {
+func printWithIndent(w io.Writer, indent int, a ...interface{}) {
 	for i := 0; i < 4*indent; i++ {
-		fmt.Print(" ")
+		fmt.Fprint(w, " ")
 	}
-	fmt.Print(a...)
+	fmt.Fprint(w, a...)
 }
 
-func printlnWithIndent(indent int, a ...interface{}) {
+func printlnWithIndent(w io.Writer, indent int, a ...interface{}) {
 	for i := 0; i < 4*indent; i++ {
-		fmt.Print(" ")
+		fmt.Fprint(w, " ")
 	}
-	fmt.Println(a...)
+	fmt.Fprintln(w, a...)
 }
 
-func printfWithIndent(indent int, format string, a ...interface{}) {
+func printfWithIndent(w io.Writer, indent int, format string, a ...interface{}) {
 	for i := 0; i < 4*indent; i++ {
-		fmt.Print(" ")
+		fmt.Fprint(w, " ")
 	}
-	fmt.Printf(format, a...)
+	fmt.Fprintf(w, format, a...)
 }
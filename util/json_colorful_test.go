@@ -21,7 +21,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"os"
 	"testing"
 )
 
@@ -109,20 +108,8 @@ func TestColorfulJson(t *testing.T) {
 }
 
 func checkValid(data interface{}) {
-	stdout := os.Stdout
-	r, w, err := os.Pipe()
-	if err != nil {
-		panic(err)
-	}
-	os.Stdout = w
-
-	colorfulJsonTest(data, 0, false, true)
-
-	w.Close()
-	os.Stdout = stdout
-
 	var buf bytes.Buffer
-	io.Copy(&buf, r)
+	colorfulJsonTest(&buf, data, 0, false, true)
 
 	if data == nil && !(buf.String() == "null") {
 		panic("invalid json output")
@@ -134,12 +121,12 @@ func checkValid(data interface{}) {
 }
 
 // test colorfulJson, not to print color character to check json
-func colorfulJsonTest(data interface{}, indent int, indentValue, lastValue bool) {
+func colorfulJsonTest(w io.Writer, data interface{}, indent int, indentValue, lastValue bool) {
 	if data == nil {
 		if !lastValue {
-			printfWithIndent(0, "null,")
+			printfWithIndent(w, 0, "null,")
 		} else {
-			printfWithIndent(0, "null")
+			printfWithIndent(w, 0, "null")
 		}
 		return
 	}
@@ -147,89 +134,89 @@ func colorfulJsonTest(data interface{}, indent int, indentValue, lastValue bool)
 	switch v := data.(type) {
 	case map[string]interface{}:
 		if !indentValue {
-			printlnWithIndent(0, "{")
+			printlnWithIndent(w, 0, "{")
 		} else {
-			printlnWithIndent(indent, "{")
+			printlnWithIndent(w, indent, "{")
 		}
 		defer func() {
-			printWithIndent(indent, "}")
+			printWithIndent(w, indent, "}")
 			if !lastValue {
-				fmt.Print(",\n")
+				fmt.Fprint(w, ",\n")
 			} else {
-				fmt.Print("\n")
+				fmt.Fprint(w, "\n")
 			}
 		}()
 
 		loop, mapLen := 1, len(v)
 		for k1, v1 := range v {
-			printfWithIndent(indent+1, "%q", k1)
-			fmt.Print(": ")
-			colorfulJsonTest(v1, indent+1, false, loop == mapLen)
+			printfWithIndent(w, indent+1, "%q", k1)
+			fmt.Fprint(w, ": ")
+			colorfulJsonTest(w, v1, indent+1, false, loop == mapLen)
 			loop++
 		}
 	case []interface{}:
 		if !indentValue {
-			printlnWithIndent(0, "[")
+			printlnWithIndent(w, 0, "[")
 		} else {
-			printlnWithIndent(indent, "[")
+			printlnWithIndent(w, indent, "[")
 		}
 		defer func() {
-			printWithIndent(indent, "]")
+			printWithIndent(w, indent, "]")
 			if !lastValue {
-				fmt.Print(",\n")
+				fmt.Fprint(w, ",\n")
 			} else {
-				fmt.Print("\n")
+				fmt.Fprint(w, "\n")
 			}
 		}()
 
 		loop, arrLen := 1, len(v)
 		for _, v1 := range v {
-			colorfulJsonTest(v1, indent+1, true, loop == arrLen)
+			colorfulJsonTest(w, v1, indent+1, true, loop == arrLen)
 			loop++
 		}
 	case string:
 		if indentValue {
-			printfWithIndent(indent, "%q", v)
+			printfWithIndent(w, indent, "%q", v)
 		} else {
-			printfWithIndent(0, "%q", v)
+			printfWithIndent(w, 0, "%q", v)
 		}
 		if !lastValue {
-			fmt.Print(",\n")
+			fmt.Fprint(w, ",\n")
 		} else {
-			fmt.Print("\n")
+			fmt.Fprint(w, "\n")
 		}
 	case json.Number:
 		if indentValue {
-			printfWithIndent(indent, "%v", v)
+			printfWithIndent(w, indent, "%v", v)
 		} else {
-			printfWithIndent(0, "%v", v)
+			printfWithIndent(w, 0, "%v", v)
 		}
 		if !lastValue {
-			fmt.Print(",\n")
+			fmt.Fprint(w, ",\n")
 		} else {
-			fmt.Print("\n")
+			fmt.Fprint(w, "\n")
 		}
 	case bool:
 		if indentValue {
-			printfWithIndent(indent, "%v", v)
+			printfWithIndent(w, indent, "%v", v)
 		} else {
-			printfWithIndent(0, "%v", v)
+			printfWithIndent(w, 0, "%v", v)
 		}
 		if !lastValue {
-			fmt.Print(",\n")
+			fmt.Fprint(w, ",\n")
 		} else {
-			fmt.Print("\n")
+			fmt.Fprint(w, "\n")
 		}
 	default:
 		if indentValue {
-			printfWithIndent(indent, "%v", v)
+			printfWithIndent(w, indent, "%v", v)
 		} else {
-			printfWithIndent(0, "%v", v)
+			printfWithIndent(w, 0, "%v", v)
 		}
 		if !lastValue {
-			fmt.Print(",\n")
+			fmt.Fprint(w, ",\n")
 		} else {
-			fmt.Print("\n")
+			fmt.Fprint(w, "\n")
 		}
 	}
 }
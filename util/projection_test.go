@@ -0,0 +1,58 @@
+package util
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestParseFields(t *testing.T) {
+	got := ParseFields(" Name, Status,,Placement.ZoneId ")
+	want := []string{"Name", "Status", "Placement.ZoneId"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseFields() = %#v, want %#v", got, want)
+	}
+}
+
+func TestLookupPath(t *testing.T) {
+	row := map[string]interface{}{
+		"Placement": map[string]interface{}{"ZoneId": "z-1"},
+		"Tags":      []interface{}{map[string]interface{}{"Key": "env"}},
+	}
+	if v, ok := LookupPath(row, "Placement.ZoneId"); !ok || v != "z-1" {
+		t.Fatalf("LookupPath(Placement.ZoneId) = %v, %v", v, ok)
+	}
+	if v, ok := LookupPath(row, "Tags.0.Key"); !ok || v != "env" {
+		t.Fatalf("LookupPath(Tags.0.Key) = %v, %v", v, ok)
+	}
+	for _, path := range []string{"Missing", "Tags.1.Key", "Placement.ZoneId.Extra"} {
+		if _, ok := LookupPath(row, path); ok {
+			t.Fatalf("LookupPath(%s) found a value, want missing", path)
+		}
+	}
+}
+
+func TestTableWriterWithProjectedColumns(t *testing.T) {
+	buf := &bytes.Buffer{}
+	fields := []string{"Status", "Placement.ZoneId", "Missing"}
+	tw := NewTableWriter(buf, 0)
+	tw.SetColumns(fields)
+
+	row := map[string]interface{}{
+		"InstanceId": "i-1",
+		"Status":     "RUNNING",
+		"Placement":  map[string]interface{}{"ZoneId": "z-1"},
+	}
+	if err := tw.Write(ProjectFields(row, fields)); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := tw.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	want := "Status   Placement.ZoneId  Missing\n" +
+		"-------  ----------------  -------\n" +
+		"RUNNING  z-1               \n"
+	if buf.String() != want {
+		t.Fatalf("output =\n%q\nwant\n%q", buf.String(), want)
+	}
+}
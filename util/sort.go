@@ -0,0 +1,141 @@
+/*
+ * // Copyright (c) 2024 Bytedance Ltd. and/or its affiliates
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at
+ * //
+ * //	http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// sortKey 是列表元素排序字段的取值：kind 决定不同类型之间的先后，同类型再按 num、t 或 s 比较。
+type sortKey struct {
+	kind int
+	num  float64
+	t    time.Time
+	s    string
+}
+
+const (
+	sortKindNumber = iota
+	sortKindTime
+	sortKindString
+	sortKindOther
+	sortKindMissing
+)
+
+// SortByField 按点分路径 field 的值对列表元素稳定排序，reverse 为 true 时降序，返回排序后的新切片。
+// 数字按数值比较；字符串全部可以解析为时间时按时间先后比较，否则按字典序比较。
+// 缺少字段或值为 null 的元素无论升降序都排在最后；所有元素都缺少该字段时返回错误，避免字段名写错时静默保持原顺序。
+func SortByField(items []interface{}, field string, reverse bool) ([]interface{}, error) {
+	keys := make([]sortKey, len(items))
+	allTimes, found := true, false
+	for i, item := range items {
+		v, ok := LookupPath(item, field)
+		if !ok || v == nil {
+			keys[i] = sortKey{kind: sortKindMissing}
+			continue
+		}
+		found = true
+		keys[i] = newSortKey(v)
+		if keys[i].kind == sortKindString {
+			if t, ok := parseTimestampString(keys[i].s); ok {
+				keys[i].t = t
+			} else {
+				allTimes = false
+			}
+		}
+	}
+	if len(items) > 0 && !found {
+		return nil, fmt.Errorf("no list element has the field %s", field)
+	}
+	if allTimes {
+		for i := range keys {
+			if keys[i].kind == sortKindString {
+				keys[i].kind = sortKindTime
+			}
+		}
+	}
+
+	idx := make([]int, len(items))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool {
+		ka, kb := keys[idx[a]], keys[idx[b]]
+		if ka.kind == sortKindMissing || kb.kind == sortKindMissing {
+			return kb.kind == sortKindMissing && ka.kind != sortKindMissing
+		}
+		if reverse {
+			ka, kb = kb, ka
+		}
+		return ka.less(kb)
+	})
+	sorted := make([]interface{}, len(items))
+	for i, j := range idx {
+		sorted[i] = items[j]
+	}
+	return sorted, nil
+}
+
+func newSortKey(v interface{}) sortKey {
+	switch value := v.(type) {
+	case float64:
+		return sortKey{kind: sortKindNumber, num: value}
+	case int:
+		return sortKey{kind: sortKindNumber, num: float64(value)}
+	case int64:
+		return sortKey{kind: sortKindNumber, num: float64(value)}
+	case json.Number:
+		if f, err := value.Float64(); err == nil {
+			return sortKey{kind: sortKindNumber, num: f}
+		}
+		return sortKey{kind: sortKindString, s: value.String()}
+	case string:
+		return sortKey{kind: sortKindString, s: value}
+	default:
+		b, _ := json.Marshal(value)
+		return sortKey{kind: sortKindOther, s: string(b)}
+	}
+}
+
+func (k sortKey) less(o sortKey) bool {
+	if k.kind != o.kind {
+		return k.kind < o.kind
+	}
+	switch k.kind {
+	case sortKindNumber:
+		return k.num < o.num
+	case sortKindTime:
+		return k.t.Before(o.t)
+	default:
+		return k.s < o.s
+	}
+}
+
+// parseTimestampString 按 timestampLayouts 解析时间字符串；纯数字字符串不视为时间。
+func parseTimestampString(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
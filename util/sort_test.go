@@ -0,0 +1,61 @@
+/*
+ * // Copyright (c) 2024 Bytedance Ltd. and/or its affiliates
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at
+ * //
+ * //	http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+package util
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestSortByField(t *testing.T) {
+	items := []interface{}{
+		map[string]interface{}{"Id": "a", "Cpu": json.Number("8"), "CreatedAt": "2024-05-03T00:00:00Z", "Name": "web"},
+		map[string]interface{}{"Id": "b", "Cpu": float64(2), "CreatedAt": "2024-05-01 08:00:00"},
+		map[string]interface{}{"Id": "c", "Cpu": float64(16), "CreatedAt": "2024-05-02T00:00:00+08:00", "Name": "api"},
+		map[string]interface{}{"Id": "d", "Name": nil},
+	}
+	ids := func(sorted []interface{}) []string {
+		var out []string
+		for _, item := range sorted {
+			out = append(out, item.(map[string]interface{})["Id"].(string))
+		}
+		return out
+	}
+	cases := []struct {
+		field   string
+		reverse bool
+		want    []string
+	}{
+		{field: "Cpu", want: []string{"b", "a", "c", "d"}},
+		{field: "Cpu", reverse: true, want: []string{"c", "a", "b", "d"}},
+		{field: "CreatedAt", want: []string{"b", "c", "a", "d"}},
+		{field: "Name", want: []string{"c", "a", "b", "d"}},
+	}
+	for _, tc := range cases {
+		sorted, err := SortByField(items, tc.field, tc.reverse)
+		if err != nil {
+			t.Fatalf("SortByField(%s) error = %v", tc.field, err)
+		}
+		if got := ids(sorted); !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("SortByField(%s, reverse=%v) = %v, want %v", tc.field, tc.reverse, got, tc.want)
+		}
+	}
+	if _, err := SortByField(items, "Missing", false); err == nil {
+		t.Fatal("expected error when no element has the field")
+	}
+}
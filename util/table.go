@@ -0,0 +1,206 @@
+/*
+ * // Copyright (c) 2024 Bytedance Ltd. and/or its affiliates
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at
+ * //
+ * //	http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultTableSampleSize 是计算列和列宽时缓存的行数。
+	DefaultTableSampleSize = 100
+	// maxTableColumnWidth 限制单列宽度，超出部分截断并以 "..." 结尾。
+	maxTableColumnWidth = 60
+	tableColumnGap      = "  "
+)
+
+// TableWriter 以流式方式输出表格。
+// 前 sampleSize 行先缓存，用于确定列和列宽；之后每写入一行立即输出，不再整体缓存。
+// 采样之后出现的新字段不会增加列，超出列宽的值会被截断。
+type TableWriter struct {
+	out        io.Writer
+	sampleSize int
+	columns    []string
+	widths     []int
+	pending    []map[string]interface{}
+	started    bool
+}
+
+// NewTableWriter 创建写入 out 的 TableWriter，sampleSize 小于 1 时使用 DefaultTableSampleSize。
+func NewTableWriter(out io.Writer, sampleSize int) *TableWriter {
+	if sampleSize < 1 {
+		sampleSize = DefaultTableSampleSize
+	}
+	return &TableWriter{out: out, sampleSize: sampleSize}
+}
+
+// SetColumns 固定输出的列及其顺序，不再从采样行推断；需在第一次 Write 之前调用。
+func (t *TableWriter) SetColumns(columns []string) {
+	t.columns = append([]string(nil), columns...)
+}
+
+// Write 写入一行。采样阶段只缓存，采样完成后直接输出。
+func (t *TableWriter) Write(row map[string]interface{}) error {
+	if t.started {
+		return t.writeRow(row)
+	}
+	t.pending = append(t.pending, row)
+	if len(t.pending) >= t.sampleSize {
+		return t.start()
+	}
+	return nil
+}
+
+// Flush 输出仍在缓存中的行。行数不足采样大小时，表格在 Flush 时才输出。
+func (t *TableWriter) Flush() error {
+	if t.started {
+		return nil
+	}
+	return t.start()
+}
+
+func (t *TableWriter) start() error {
+	t.started = true
+	if len(t.pending) == 0 {
+		return nil
+	}
+	if t.columns == nil {
+		t.columns = tableColumns(t.pending)
+	}
+	t.widths = make([]int, len(t.columns))
+	for i, col := range t.columns {
+		t.widths[i] = utf8.RuneCountInString(col)
+	}
+	for _, row := range t.pending {
+		for i, col := range t.columns {
+			if w := utf8.RuneCountInString(FormatTableCell(row[col])); w > t.widths[i] {
+				t.widths[i] = w
+			}
+		}
+	}
+	for i := range t.widths {
+		if t.widths[i] > maxTableColumnWidth {
+			t.widths[i] = maxTableColumnWidth
+		}
+	}
+
+	header := make([]string, len(t.columns))
+	rule := make([]string, len(t.columns))
+	for i, col := range t.columns {
+		header[i] = col
+		rule[i] = strings.Repeat("-", t.widths[i])
+	}
+	if err := t.writeLine(header); err != nil {
+		return err
+	}
+	if err := t.writeLine(rule); err != nil {
+		return err
+	}
+	for _, row := range t.pending {
+		if err := t.writeRow(row); err != nil {
+			return err
+		}
+	}
+	t.pending = nil
+	return nil
+}
+
+func (t *TableWriter) writeRow(row map[string]interface{}) error {
+	if len(t.columns) == 0 {
+		return nil
+	}
+	cells := make([]string, len(t.columns))
+	for i, col := range t.columns {
+		cells[i] = FormatTableCell(row[col])
+	}
+	return t.writeLine(cells)
+}
+
+func (t *TableWriter) writeLine(cells []string) error {
+	var b strings.Builder
+	for i, cell := range cells {
+		cell = truncateTableCell(cell, t.widths[i])
+		if i == len(cells)-1 {
+			b.WriteString(cell)
+			break
+		}
+		b.WriteString(cell)
+		b.WriteString(strings.Repeat(" ", t.widths[i]-utf8.RuneCountInString(cell)))
+		b.WriteString(tableColumnGap)
+	}
+	b.WriteString("\n")
+	_, err := io.WriteString(t.out, b.String())
+	return err
+}
+
+// tableColumns 返回采样行中出现过的全部字段，按字母序排列。
+func tableColumns(rows []map[string]interface{}) []string {
+	seen := map[string]struct{}{}
+	var columns []string
+	for _, row := range rows {
+		for k := range row {
+			if _, ok := seen[k]; ok {
+				continue
+			}
+			seen[k] = struct{}{}
+			columns = append(columns, k)
+		}
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+// FormatTableCell 把 JSON 值格式化为单元格文本：标量直接输出，对象和数组输出为单行 JSON。
+func FormatTableCell(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.ReplaceAll(val, "\n", " ")
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case json.Number:
+		return val.String()
+	case bool:
+		return strconv.FormatBool(val)
+	default:
+		buf := bytes.NewBuffer([]byte{})
+		encoder := json.NewEncoder(buf)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(val); err != nil {
+			return fmt.Sprint(val)
+		}
+		return strings.TrimSpace(buf.String())
+	}
+}
+
+func truncateTableCell(cell string, width int) string {
+	if utf8.RuneCountInString(cell) <= width {
+		return cell
+	}
+	if width <= 3 {
+		return string([]rune(cell)[:width])
+	}
+	return string([]rune(cell)[:width-3]) + "..."
+}
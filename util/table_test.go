@@ -0,0 +1,68 @@
+package util
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTableWriterBuffersUntilSampleIsComplete(t *testing.T) {
+	buf := &bytes.Buffer{}
+	tw := NewTableWriter(buf, 2)
+
+	if err := tw.Write(map[string]interface{}{"InstanceId": "i-1", "Status": "RUNNING"}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("output before sample is complete = %q, want empty", buf.String())
+	}
+	if err := tw.Write(map[string]interface{}{"InstanceId": "i-2", "Status": "STOPPED"}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	want := "InstanceId  Status\n" +
+		"----------  -------\n" +
+		"i-1         RUNNING\n" +
+		"i-2         STOPPED\n"
+	if buf.String() != want {
+		t.Fatalf("output after sample =\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	if err := tw.Write(map[string]interface{}{"InstanceId": "i-3", "Status": "TERMINATING", "Extra": true}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if got := lines[len(lines)-1]; got != "i-3         TERM..." {
+		t.Fatalf("streamed row = %q, want value truncated to sampled width", got)
+	}
+	if err := tw.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+}
+
+func TestTableWriterFlushWritesShortTable(t *testing.T) {
+	buf := &bytes.Buffer{}
+	tw := NewTableWriter(buf, 0)
+
+	if err := tw.Write(map[string]interface{}{"Count": float64(3), "Tags": []interface{}{"a"}}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := tw.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	want := "Count  Tags\n" +
+		"-----  -----\n" +
+		"3      [\"a\"]\n"
+	if buf.String() != want {
+		t.Fatalf("output =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestTableWriterFlushWithoutRows(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := NewTableWriter(buf, 10).Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("output = %q, want empty", buf.String())
+	}
+}
@@ -0,0 +1,102 @@
+/*
+ * // Copyright (c) 2024 Bytedance Ltd. and/or its affiliates
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at
+ * //
+ * //	http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// templateEscapes 把命令行中常见的 \n、\t 写法还原为换行与制表符，shell 单引号内无法直接输入这些字符。
+var templateEscapes = strings.NewReplacer(`\n`, "\n", `\t`, "\t")
+
+// OutputTemplate 是编译后的 text/template 输出模板。
+type OutputTemplate struct {
+	tmpl *template.Template
+}
+
+// ParseOutputTemplate 编译输出模板，除 text/template 内置函数外还提供：
+// json（单行 JSON）、default（值为空时取默认值）、join、upper、lower 与 str（按表格单元格格式化标量）。
+func ParseOutputTemplate(text string) (*OutputTemplate, error) {
+	tmpl, err := template.New("output").Funcs(template.FuncMap{
+		"json":    templateJSON,
+		"default": templateDefault,
+		"join":    templateJoin,
+		"upper":   strings.ToUpper,
+		"lower":   strings.ToLower,
+		"str":     FormatTableCell,
+	}).Option("missingkey=zero").Parse(templateEscapes.Replace(text))
+	if err != nil {
+		return nil, err
+	}
+	return &OutputTemplate{tmpl: tmpl}, nil
+}
+
+// Execute 以 data 为根对象渲染模板。
+func (t *OutputTemplate) Execute(out io.Writer, data interface{}) error {
+	return t.tmpl.Execute(out, data)
+}
+
+func templateJSON(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// templateDefault 的参数顺序为 default "x" .Value，便于在管道中写成 .Value | default "x"。
+func templateDefault(def, v interface{}) interface{} {
+	switch val := v.(type) {
+	case nil:
+		return def
+	case string:
+		if val == "" {
+			return def
+		}
+	case []interface{}:
+		if len(val) == 0 {
+			return def
+		}
+	case map[string]interface{}:
+		if len(val) == 0 {
+			return def
+		}
+	}
+	return v
+}
+
+// templateJoin 用 sep 连接列表元素，元素按表格单元格格式化；参数顺序同 default，支持 .Tags | join ","。
+func templateJoin(sep string, v interface{}) (string, error) {
+	switch val := v.(type) {
+	case nil:
+		return "", nil
+	case []string:
+		return strings.Join(val, sep), nil
+	case []interface{}:
+		parts := make([]string, 0, len(val))
+		for _, item := range val {
+			parts = append(parts, FormatTableCell(item))
+		}
+		return strings.Join(parts, sep), nil
+	default:
+		return "", fmt.Errorf("join expects a list, got %T", v)
+	}
+}
@@ -0,0 +1,43 @@
+package util
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestOutputTemplate(t *testing.T) {
+	data := map[string]interface{}{
+		"Instances": []interface{}{
+			map[string]interface{}{"InstanceId": "i-1", "Status": "RUNNING", "Cpus": float64(1000000), "Tags": []interface{}{"a", "b"}},
+			map[string]interface{}{"InstanceId": "i-2", "Status": ""},
+		},
+	}
+	cases := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "range with escapes", text: `{{range .Instances}}{{.InstanceId}}\t{{.Status}}\n{{end}}`, want: "i-1\tRUNNING\ni-2\t\n"},
+		{name: "default", text: `{{range .Instances}}{{.Status | default "-"}} {{default "none" .Missing}};{{end}}`, want: "RUNNING none;- none;"},
+		{name: "json and join", text: `{{with index .Instances 0}}{{json .Tags}} {{.Tags | join ","}} {{str .Cpus}} {{lower .Status}}{{end}}`, want: `["a","b"] a,b 1000000 running`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpl, err := ParseOutputTemplate(tc.text)
+			if err != nil {
+				t.Fatalf("ParseOutputTemplate() error = %v", err)
+			}
+			var buf bytes.Buffer
+			if err := tmpl.Execute(&buf, data); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if buf.String() != tc.want {
+				t.Fatalf("output = %q, want %q", buf.String(), tc.want)
+			}
+		})
+	}
+
+	if _, err := ParseOutputTemplate(`{{range .Instances}`); err == nil {
+		t.Fatalf("ParseOutputTemplate() with a syntax error succeeded")
+	}
+}
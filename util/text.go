@@ -0,0 +1,68 @@
+/*
+ * // Copyright (c) 2024 Bytedance Ltd. and/or its affiliates
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at
+ * //
+ * //	http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+package util
+
+import (
+	"io"
+	"sort"
+	"strings"
+)
+
+// TextWriter 以制表符分隔的纯文本输出记录，每条记录一行，不输出表头，便于 cut/awk 处理。
+// 未指定列时以第一条记录的字段（按字母序）作为列，之后的记录按相同列输出，缺失字段输出为空。
+type TextWriter struct {
+	out     io.Writer
+	columns []string
+}
+
+// NewTextWriter 创建写入 out 的 TextWriter。
+func NewTextWriter(out io.Writer) *TextWriter {
+	return &TextWriter{out: out}
+}
+
+// SetColumns 固定输出的列及其顺序；需在第一次 Write 之前调用。
+func (t *TextWriter) SetColumns(columns []string) {
+	t.columns = append([]string(nil), columns...)
+}
+
+// Write 按列输出一条记录。
+func (t *TextWriter) Write(row map[string]interface{}) error {
+	if t.columns == nil {
+		t.columns = make([]string, 0, len(row))
+		for k := range row {
+			t.columns = append(t.columns, k)
+		}
+		sort.Strings(t.columns)
+	}
+	cells := make([]string, len(t.columns))
+	for i, col := range t.columns {
+		cells[i] = FormatTextCell(row[col])
+	}
+	_, err := io.WriteString(t.out, strings.Join(cells, "\t")+"\n")
+	return err
+}
+
+// WriteValue 原样输出一个标量值，用于元素不是对象的列表。
+func (t *TextWriter) WriteValue(v interface{}) error {
+	_, err := io.WriteString(t.out, FormatTextCell(v)+"\n")
+	return err
+}
+
+// FormatTextCell 与 FormatTableCell 相同，另把制表符替换为空格，避免破坏列分隔。
+func FormatTextCell(v interface{}) string {
+	return strings.ReplaceAll(FormatTableCell(v), "\t", " ")
+}
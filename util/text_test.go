@@ -0,0 +1,30 @@
+package util
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTextWriterUsesFirstRowColumnsWithoutHeader(t *testing.T) {
+	buf := &bytes.Buffer{}
+	tw := NewTextWriter(buf)
+
+	rows := []map[string]interface{}{
+		{"Status": "Running", "InstanceId": "i-1", "Count": float64(2)},
+		{"InstanceId": "i-2", "Status": "has\ttab", "Extra": "ignored"},
+	}
+	for _, row := range rows {
+		if err := tw.Write(row); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := tw.WriteValue("raw"); err != nil {
+		t.Fatalf("WriteValue() error = %v", err)
+	}
+	want := "2\ti-1\tRunning\n" +
+		"\ti-2\thas tab\n" +
+		"raw\n"
+	if buf.String() != want {
+		t.Fatalf("output = %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,161 @@
+/*
+ * // Copyright (c) 2024 Bytedance Ltd. and/or its affiliates
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at
+ * //
+ * //	http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTimeFields 是未指定时间字段时依次尝试的常见创建时间字段。
+var DefaultTimeFields = []string{"CreatedAt", "CreationTime", "CreateTime", "CreatedTime"}
+
+// timestampLayouts 是列表元素中时间字符串可能使用的格式，不带时区的按 UTC 处理。
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02",
+}
+
+// TimeWindow 按时间字段过滤列表元素，保留 [After, Before) 内的元素；零值的边界不做限制。
+type TimeWindow struct {
+	After  time.Time
+	Before time.Time
+	// Field 为点分路径，为空时依次尝试 DefaultTimeFields。
+	Field string
+}
+
+// ParseTimeBound 解析时间窗口边界：RFC3339 时间、日期（2006-01-02，UTC），
+// 或相对 now 之前的时长，例如 90m、36h、7d。
+func ParseTimeBound(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, fmt.Errorf("empty time")
+	}
+	if d, ok := parseDays(value); ok {
+		return now.Add(-d), nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		if d < 0 {
+			return time.Time{}, fmt.Errorf("duration %q must not be negative", value)
+		}
+		return now.Add(-d), nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is neither an RFC3339 time, a date (2006-01-02) nor a duration such as 36h or 7d", value)
+}
+
+// parseDays 解析以 d 结尾的天数，例如 7d。
+func parseDays(value string) (time.Duration, bool) {
+	if !strings.HasSuffix(value, "d") {
+		return 0, false
+	}
+	days, err := strconv.ParseUint(strings.TrimSuffix(value, "d"), 10, 16)
+	if err != nil {
+		return 0, false
+	}
+	return time.Duration(days) * 24 * time.Hour, true
+}
+
+// Filter 返回时间落在窗口内的元素。元素不是对象、缺少时间字段或时间无法解析时返回错误，
+// 避免因字段名写错而静默得到空列表。
+func (w *TimeWindow) Filter(items []interface{}) ([]interface{}, error) {
+	kept := make([]interface{}, 0, len(items))
+	for i, item := range items {
+		t, err := w.itemTime(item)
+		if err != nil {
+			return nil, fmt.Errorf("list element %d: %w", i, err)
+		}
+		if !w.After.IsZero() && t.Before(w.After) {
+			continue
+		}
+		if !w.Before.IsZero() && !t.Before(w.Before) {
+			continue
+		}
+		kept = append(kept, item)
+	}
+	return kept, nil
+}
+
+func (w *TimeWindow) itemTime(item interface{}) (time.Time, error) {
+	if _, ok := item.(map[string]interface{}); !ok {
+		return time.Time{}, fmt.Errorf("not an object")
+	}
+	fields := DefaultTimeFields
+	if w.Field != "" {
+		fields = []string{w.Field}
+	}
+	for _, field := range fields {
+		v, ok := LookupPath(item, field)
+		if !ok || v == nil {
+			continue
+		}
+		t, err := ParseTimestamp(v)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("field %s: %w", field, err)
+		}
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("no %s field", strings.Join(fields, ", "))
+}
+
+// ParseTimestamp 把响应中的时间值转换为 time.Time：字符串按 timestampLayouts 解析，
+// 数字按 Unix 时间戳处理，大于 1e12 时视为毫秒。
+func ParseTimestamp(v interface{}) (time.Time, error) {
+	var n float64
+	switch value := v.(type) {
+	case string:
+		if t, ok := parseTimestampString(value); ok {
+			return t, nil
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("cannot parse time %q", value)
+		}
+		n = f
+	case json.Number:
+		f, err := value.Float64()
+		if err != nil {
+			return time.Time{}, fmt.Errorf("cannot parse time %q", value.String())
+		}
+		n = f
+	case float64:
+		n = value
+	case int64:
+		n = float64(value)
+	case int:
+		n = float64(value)
+	default:
+		return time.Time{}, fmt.Errorf("cannot parse time from %T", v)
+	}
+	if n > 1e12 {
+		ms := int64(n)
+		return time.Unix(ms/1000, (ms%1000)*int64(time.Millisecond)).UTC(), nil
+	}
+	sec, frac := math.Modf(n)
+	return time.Unix(int64(sec), int64(frac*float64(time.Second))).UTC(), nil
+}
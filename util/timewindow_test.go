@@ -0,0 +1,83 @@
+package util
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTimeBound(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		value string
+		want  time.Time
+	}{
+		{value: "36h", want: now.Add(-36 * time.Hour)},
+		{value: "7d", want: now.Add(-7 * 24 * time.Hour)},
+		{value: "2024-05-01T08:00:00+08:00", want: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{value: "2024-05-01", want: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range cases {
+		got, err := ParseTimeBound(tc.value, now)
+		if err != nil {
+			t.Fatalf("ParseTimeBound(%q) error = %v", tc.value, err)
+		}
+		if !got.Equal(tc.want) {
+			t.Fatalf("ParseTimeBound(%q) = %v, want %v", tc.value, got, tc.want)
+		}
+	}
+	for _, value := range []string{"", "yesterday", "-2h"} {
+		if _, err := ParseTimeBound(value, now); err == nil {
+			t.Fatalf("ParseTimeBound(%q) succeeded", value)
+		}
+	}
+}
+
+func TestTimeWindowFilter(t *testing.T) {
+	items := []interface{}{
+		map[string]interface{}{"Id": "rfc3339", "CreatedAt": "2024-05-09T10:00:00Z"},
+		map[string]interface{}{"Id": "space", "CreationTime": "2024-05-01 10:00:00"},
+		map[string]interface{}{"Id": "seconds", "CreateTime": json.Number("1715335200")},
+		map[string]interface{}{"Id": "millis", "CreatedTime": float64(1714521600000)},
+	}
+	ids := func(kept []interface{}) string {
+		var out []string
+		for _, item := range kept {
+			out = append(out, item.(map[string]interface{})["Id"].(string))
+		}
+		return strings.Join(out, ",")
+	}
+
+	w := &TimeWindow{After: time.Date(2024, 5, 5, 0, 0, 0, 0, time.UTC)}
+	kept, err := w.Filter(items)
+	if err != nil {
+		t.Fatalf("Filter() error = %v", err)
+	}
+	if got := ids(kept); got != "rfc3339,seconds" {
+		t.Fatalf("Filter(after) = %s, want rfc3339,seconds", got)
+	}
+
+	w = &TimeWindow{Before: time.Date(2024, 5, 10, 10, 0, 0, 0, time.UTC)}
+	if kept, err = w.Filter(items); err != nil {
+		t.Fatalf("Filter() error = %v", err)
+	}
+	if got := ids(kept); got != "rfc3339,space,millis" {
+		t.Fatalf("Filter(before) = %s, want rfc3339,space,millis", got)
+	}
+
+	nested := []interface{}{map[string]interface{}{"Meta": map[string]interface{}{"Created": "2024-05-09T10:00:00Z"}}}
+	w = &TimeWindow{After: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), Field: "Meta.Created"}
+	if kept, err = w.Filter(nested); err != nil || len(kept) != 1 {
+		t.Fatalf("Filter(nested) = %v, %v, want the element kept", kept, err)
+	}
+
+	w = &TimeWindow{After: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), Field: "LaunchTime"}
+	if _, err := w.Filter(items); err == nil || !strings.Contains(err.Error(), "no LaunchTime field") {
+		t.Fatalf("Filter() error = %v, want missing field", err)
+	}
+	bad := []interface{}{map[string]interface{}{"CreatedAt": "soon"}}
+	if _, err := (&TimeWindow{}).Filter(bad); err == nil {
+		t.Fatalf("Filter() with an unparseable time succeeded")
+	}
+}
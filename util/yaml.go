@@ -0,0 +1,77 @@
+/*
+ * // Copyright (c) 2024 Bytedance Ltd. and/or its affiliates
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at
+ * //
+ * //	http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ParseYAML 用 gopkg.in/yaml.v3 解析 YAML 文档，并转换为与 encoding/json 相同形态的值：
+// map[string]interface{}、[]interface{}、string、bool、int64、float64 或 nil。
+// 支持锚点、别名与标签；只允许一个文档，空文档返回 nil。
+func ParseYAML(data []byte) (interface{}, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		if err == io.EOF {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var next interface{}
+	if err := dec.Decode(&next); err != io.EOF {
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("yaml: multiple documents are not supported")
+	}
+	return jsonShapedYAML(doc), nil
+}
+
+// jsonShapedYAML 把 yaml.v3 解码出的值转换为 encoding/json 的形态：非字符串的映射键转为字符串，
+// int 转为 int64，时间戳转为 RFC3339 字符串。
+func jsonShapedYAML(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, x := range v {
+			v[k] = jsonShapedYAML(x)
+		}
+		return v
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, x := range v {
+			m[fmt.Sprint(k)] = jsonShapedYAML(x)
+		}
+		return m
+	case []interface{}:
+		for i, x := range v {
+			v[i] = jsonShapedYAML(x)
+		}
+		return v
+	case int:
+		return int64(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return v
+	}
+}
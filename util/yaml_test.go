@@ -0,0 +1,130 @@
+package util
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseYAML(t *testing.T) {
+	doc := `---
+# instance settings
+Name: "web 1"   # quoted
+Count: 3
+Ratio: 0.5
+Enabled: true
+Empty:
+Code: "007"
+Quote: 'it''s'
+Placement:
+  ZoneId: zone-a
+Tags:
+- Key: env
+  Value: prod
+- Key: team
+  Value: [a, "b c", 2]
+Flow: {k: v, n: 1}
+Script: |
+  echo one
+  echo two
+Folded: >-
+  first
+  line
+
+  second
+`
+	got, err := ParseYAML([]byte(doc))
+	if err != nil {
+		t.Fatalf("ParseYAML() error = %v", err)
+	}
+	want := map[string]interface{}{
+		"Name":      "web 1",
+		"Count":     int64(3),
+		"Ratio":     0.5,
+		"Enabled":   true,
+		"Empty":     nil,
+		"Code":      "007",
+		"Quote":     "it's",
+		"Placement": map[string]interface{}{"ZoneId": "zone-a"},
+		"Tags": []interface{}{
+			map[string]interface{}{"Key": "env", "Value": "prod"},
+			map[string]interface{}{"Key": "team", "Value": []interface{}{"a", "b c", int64(2)}},
+		},
+		"Flow":   map[string]interface{}{"k": "v", "n": int64(1)},
+		"Script": "echo one\necho two\n",
+		"Folded": "first line\nsecond",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseYAML() = %#v, want %#v", got, want)
+	}
+}
+
+func TestParseYAMLNestedSequences(t *testing.T) {
+	got, err := ParseYAML([]byte("Matrix:\n  - - 1\n    - 2\n  -\n    - x\n"))
+	if err != nil {
+		t.Fatalf("ParseYAML() error = %v", err)
+	}
+	want := map[string]interface{}{
+		"Matrix": []interface{}{
+			[]interface{}{int64(1), int64(2)},
+			[]interface{}{"x"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseYAML() = %#v, want %#v", got, want)
+	}
+}
+
+func TestParseYAMLAnchorsAliasesAndTags(t *testing.T) {
+	doc := `defaults: &defaults
+  ZoneId: zone-a
+  Count: 2
+Placement:
+  <<: *defaults
+  Count: 3
+Zones: [*defaults]
+Port: !!str 8080
+When: 2024-01-02T03:04:05Z
+`
+	got, err := ParseYAML([]byte(doc))
+	if err != nil {
+		t.Fatalf("ParseYAML() error = %v", err)
+	}
+	defaults := map[string]interface{}{"ZoneId": "zone-a", "Count": int64(2)}
+	want := map[string]interface{}{
+		"defaults":  defaults,
+		"Placement": map[string]interface{}{"ZoneId": "zone-a", "Count": int64(3)},
+		"Zones":     []interface{}{defaults},
+		"Port":      "8080",
+		"When":      "2024-01-02T03:04:05Z",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseYAML() = %#v, want %#v", got, want)
+	}
+}
+
+func TestParseYAMLErrors(t *testing.T) {
+	cases := map[string]string{
+		"A: 1\nA: 2\n":       "already defined",
+		"A: 1\n  B: 2\n":     "line 2",
+		"A: 1\n---\nB: 2\n":  "multiple documents",
+		"A:\n\t- 1\n":        "line 2",
+		"A: [1, 2\n":         "line 1",
+		"A: 1\nplain text\n": "line 2",
+		"A: *missing\n":      "unknown anchor",
+	}
+	for doc, want := range cases {
+		if _, err := ParseYAML([]byte(doc)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ParseYAML(%q) error = %v, want containing %q", doc, err, want)
+		}
+	}
+}
+
+func TestParseYAMLEmptyDocument(t *testing.T) {
+	for _, doc := range []string{"", "# only a comment\n", "---\n"} {
+		got, err := ParseYAML([]byte(doc))
+		if err != nil || got != nil {
+			t.Errorf("ParseYAML(%q) = %#v, %v; want nil", doc, got, err)
+		}
+	}
+}